@@ -0,0 +1,121 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+var epoch = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestFake_AdvanceFiresInOrder(t *testing.T) {
+	f := NewFake(epoch)
+	late := f.NewTimer(2 * time.Second)
+	early := f.NewTimer(time.Second)
+	if f.Timers() != 2 {
+		t.Fatalf("expected 2 pending timers, got %d", f.Timers())
+	}
+
+	f.Advance(1500 * time.Millisecond)
+	select {
+	case at := <-early.C():
+		if !at.Equal(epoch.Add(time.Second)) {
+			t.Errorf("early timer fired at %v", at)
+		}
+	default:
+		t.Fatal("early timer did not fire")
+	}
+	select {
+	case <-late.C():
+		t.Fatal("late timer fired early")
+	default:
+	}
+	if got := f.Since(epoch); got != 1500*time.Millisecond {
+		t.Errorf("expected clock at 1.5s, got %v", got)
+	}
+
+	if !late.Stop() {
+		t.Error("expected Stop to remove a pending timer")
+	}
+	if late.Stop() {
+		t.Error("expected a second Stop to report nothing removed")
+	}
+	f.Advance(time.Minute)
+	select {
+	case <-late.C():
+		t.Fatal("stopped timer fired")
+	default:
+	}
+}
+
+func TestFake_TickerReschedules(t *testing.T) {
+	f := NewFake(epoch)
+	ticker := f.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for i := 1; i <= 3; i++ {
+		f.Advance(time.Second)
+		select {
+		case at := <-ticker.C():
+			if want := epoch.Add(time.Duration(i) * time.Second); !at.Equal(want) {
+				t.Errorf("tick %d at %v, want %v", i, at, want)
+			}
+		default:
+			t.Fatalf("tick %d missing", i)
+		}
+	}
+	// slow receivers lose ticks instead of blocking Advance
+	f.Advance(5 * time.Second)
+	<-ticker.C()
+	select {
+	case <-ticker.C():
+		t.Fatal("expected missed ticks to be dropped")
+	default:
+	}
+	if f.Timers() != 0 {
+		t.Errorf("tickers must not count as timers, got %d", f.Timers())
+	}
+}
+
+func TestFake_NonPositiveTimerFiresImmediately(t *testing.T) {
+	f := NewFake(epoch)
+	select {
+	case <-f.After(0):
+	default:
+		t.Fatal("expected a zero timer to fire without advancing")
+	}
+}
+
+func TestFake_BlockUntilTimers(t *testing.T) {
+	f := NewFake(epoch)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		<-f.After(time.Second)
+	}()
+
+	if !f.BlockUntilTimers(1, time.Second) {
+		t.Fatal("timer of the goroutine never showed up")
+	}
+	f.Advance(time.Second)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("goroutine not released by Advance")
+	}
+	if f.BlockUntilTimers(1, 10*time.Millisecond) {
+		t.Fatal("expected no pending timer after it fired")
+	}
+}
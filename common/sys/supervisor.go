@@ -0,0 +1,249 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package sys
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"time"
+)
+
+const (
+	defaultMaxBackoff = 30 * time.Second
+	defaultWindow     = time.Minute
+)
+
+// TaskState is where a supervised task is in its life
+type TaskState string
+
+const (
+	TaskRunning    TaskState = "running"
+	TaskRestarting TaskState = "restarting"
+	// TaskFailed is a task that failed more often than its policy allows and is no longer restarted
+	TaskFailed TaskState = "failed"
+	// TaskStopped is a task that returned nil or whose engine stopped
+	TaskStopped TaskState = "stopped"
+)
+
+// Escalation is what happens once a task exceeds its restart budget
+type Escalation int
+
+const (
+	// Escalate marks the task failed and reports it through Hooks.OnFailed, other tasks keep running
+	Escalate Escalation = iota
+	// StopEngine marks the task failed and cancels every task of the engine
+	StopEngine
+)
+
+// Policy bounds how a failing task is restarted
+type Policy struct {
+	// MinBackoff is the delay before the first restart, doubled for every further restart within Window
+	MinBackoff time.Duration
+	// MaxBackoff caps the delay, zero selects thirty seconds
+	MaxBackoff time.Duration
+	// MaxRestarts is how many restarts are allowed within Window, zero restarts forever
+	MaxRestarts int
+	// Window is the period restarts are counted over, zero selects one minute
+	Window time.Duration
+	// OnExceeded is applied once MaxRestarts is exceeded
+	OnExceeded Escalation
+}
+
+func (p Policy) withDefaults() Policy {
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = defaultMaxBackoff
+	}
+	if p.MaxBackoff < p.MinBackoff {
+		p.MaxBackoff = p.MinBackoff
+	}
+	if p.Window <= 0 {
+		p.Window = defaultWindow
+	}
+	return p
+}
+
+// backoff returns the delay before a restart, given how many restarts happened within the window
+func (p Policy) backoff(recent int) time.Duration {
+	if p.MinBackoff <= 0 {
+		return 0
+	}
+	delay := p.MinBackoff
+	for i := 1; i < recent && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, p.MaxBackoff)
+}
+
+// Hooks observe the lifecycle of supervised tasks, every hook is optional and must not block
+type Hooks struct {
+	OnStart   func(name string)
+	OnPanic   func(name string, r any, stack []byte)
+	OnError   func(name string, err error)
+	OnRestart func(name string, restarts int, delay time.Duration)
+	OnFailed  func(name string, r any)
+	OnStop    func(name string)
+}
+
+// Task is a named function run under supervision
+type Task struct {
+	Name string
+	// Run is restarted when it panics or returns an error before the engine stops
+	Run func(ctx context.Context) error
+	// OnRestart runs after a failure, before the task is started again
+	OnRestart func()
+	// Policy overrides Engine.Policy when set
+	Policy *Policy
+}
+
+// TaskStatus is a snapshot of a supervised task
+type TaskStatus struct {
+	Name        string    `json:"name"`
+	State       TaskState `json:"state"`
+	Restarts    int       `json:"restarts"`
+	LastPanic   string    `json:"last_panic,omitempty"`
+	LastPanicAt time.Time `json:"last_panic_at,omitzero"`
+	LastError   string    `json:"last_error,omitempty"`
+	LastErrorAt time.Time `json:"last_error_at,omitzero"`
+}
+
+// Supervise runs task until it returns nil or the engine stops, restarting it with backoff after every panic or error
+func (e *Engine) Supervise(task Task) {
+	policy := e.Policy
+	if task.Policy != nil {
+		policy = *task.Policy
+	}
+	policy = policy.withDefaults()
+
+	e.mu.Lock()
+	if task.Name == "" {
+		task.Name = fmt.Sprintf("task-%d", len(e.tasks)+1)
+	}
+	status := &TaskStatus{Name: task.Name}
+	e.tasks = append(e.tasks, status)
+	e.mu.Unlock()
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		var recent []time.Time
+		for {
+			e.setState(status, TaskRunning)
+			if e.Hooks.OnStart != nil {
+				e.Hooks.OnStart(task.Name)
+			}
+			r, stack, panicked, err := run(e.ctx, task.Run)
+			if !panicked && (err == nil || e.ctx.Err() != nil) {
+				e.stopped(status)
+				return
+			}
+
+			now := time.Now()
+			if panicked {
+				log.Printf("[Engine.Supervise] task %s panic recovered: %v\n%s", task.Name, r, stack)
+				e.mu.Lock()
+				status.LastPanic, status.LastPanicAt = fmt.Sprint(r), now
+				e.mu.Unlock()
+				if e.Hooks.OnPanic != nil {
+					e.Hooks.OnPanic(task.Name, r, stack)
+				}
+			} else {
+				r = err
+				log.Printf("[Engine.Supervise] task %s failed: %v", task.Name, err)
+				e.mu.Lock()
+				status.LastError, status.LastErrorAt = err.Error(), now
+				e.mu.Unlock()
+				if e.Hooks.OnError != nil {
+					e.Hooks.OnError(task.Name, err)
+				}
+			}
+
+			recent = append(prune(recent, now.Add(-policy.Window)), now)
+			if policy.MaxRestarts > 0 && len(recent) > policy.MaxRestarts {
+				e.setState(status, TaskFailed)
+				if e.Hooks.OnFailed != nil {
+					e.Hooks.OnFailed(task.Name, r)
+				}
+				if policy.OnExceeded == StopEngine {
+					e.cancel()
+				}
+				return
+			}
+
+			delay := policy.backoff(len(recent))
+			e.mu.Lock()
+			status.State = TaskRestarting
+			status.Restarts++
+			restarts := status.Restarts
+			e.mu.Unlock()
+			if e.Hooks.OnRestart != nil {
+				e.Hooks.OnRestart(task.Name, restarts, delay)
+			}
+			if task.OnRestart != nil {
+				task.OnRestart()
+			}
+
+			select {
+			case <-e.ctx.Done():
+				e.stopped(status)
+				return
+			case <-time.After(delay):
+			}
+		}
+	}()
+}
+
+// Status returns a snapshot of every supervised task in the order they were started
+func (e *Engine) Status() []TaskStatus {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	out := make([]TaskStatus, len(e.tasks))
+	for i, t := range e.tasks {
+		out[i] = *t
+	}
+	return out
+}
+
+func (e *Engine) setState(status *TaskStatus, state TaskState) {
+	e.mu.Lock()
+	status.State = state
+	e.mu.Unlock()
+}
+
+func (e *Engine) stopped(status *TaskStatus) {
+	e.setState(status, TaskStopped)
+	if e.Hooks.OnStop != nil {
+		e.Hooks.OnStop(status.Name)
+	}
+}
+
+// run calls fn and returns its error, or what it panicked with
+func run(ctx context.Context, fn func(ctx context.Context) error) (r any, stack []byte, panicked bool, err error) {
+	defer func() {
+		if r = recover(); r != nil {
+			stack, panicked = debug.Stack(), true
+		}
+	}()
+	return nil, nil, false, fn(ctx)
+}
+
+// prune drops the times before since, times are in ascending order
+func prune(times []time.Time, since time.Time) []time.Time {
+	i := 0
+	for i < len(times) && times[i].Before(since) {
+		i++
+	}
+	return times[i:]
+}
@@ -0,0 +1,164 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package sys
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// waitFor polls cond until it holds or a second passed
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func status(e *Engine, name string) TaskStatus {
+	for _, s := range e.Status() {
+		if s.Name == name {
+			return s
+		}
+	}
+	return TaskStatus{}
+}
+
+func TestSupervise_RestartsAfterPanic(t *testing.T) {
+	e := NewEngine(time.Millisecond, time.Second)
+	var restarted, hooked atomic.Int32
+	e.Hooks.OnRestart = func(name string, restarts int, delay time.Duration) { hooked.Add(1) }
+	var runs atomic.Int32
+	e.Supervise(Task{
+		Name: "flaky",
+		Run: func(ctx context.Context) error {
+			if runs.Add(1) <= 2 {
+				panic("boom")
+			}
+			return nil
+		},
+		OnRestart: func() { restarted.Add(1) },
+	})
+	waitFor(t, func() bool { return status(e, "flaky").State == TaskStopped })
+	e.Stop()
+
+	s := status(e, "flaky")
+	if s.Restarts != 2 || restarted.Load() != 2 || hooked.Load() != 2 {
+		t.Errorf("expected 2 restarts, got status %d, task hook %d, engine hook %d", s.Restarts, restarted.Load(), hooked.Load())
+	}
+	if s.LastPanic != "boom" || s.LastPanicAt.IsZero() {
+		t.Errorf("expected the last panic to be recorded, got %+v", s)
+	}
+}
+
+func TestSupervise_EscalatesAfterMaxRestarts(t *testing.T) {
+	e := NewEngine(time.Millisecond, time.Second)
+	failed := make(chan string, 1)
+	e.Hooks.OnFailed = func(name string, r any) { failed <- name }
+	e.Supervise(Task{
+		Name:   "broken",
+		Run:    func(ctx context.Context) error { panic("boom") },
+		Policy: &Policy{MinBackoff: time.Millisecond, MaxRestarts: 2, Window: time.Minute},
+	})
+	e.Supervise(Task{Name: "healthy", Run: func(ctx context.Context) error { <-ctx.Done(); return nil }})
+
+	select {
+	case name := <-failed:
+		if name != "broken" {
+			t.Errorf("unexpected failed task %s", name)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("task never failed")
+	}
+	if s := status(e, "broken"); s.State != TaskFailed || s.Restarts != 2 {
+		t.Errorf("expected failed after 2 restarts, got %+v", s)
+	}
+	if s := status(e, "healthy"); s.State != TaskRunning {
+		t.Errorf("expected other tasks to keep running, got %+v", s)
+	}
+	e.Stop()
+	if s := status(e, "healthy"); s.State != TaskStopped {
+		t.Errorf("expected stopped after Stop, got %+v", s)
+	}
+}
+
+func TestSupervise_StopEngine(t *testing.T) {
+	e := NewEngine(0, time.Second)
+	e.Supervise(Task{
+		Name:   "fatal",
+		Run:    func(ctx context.Context) error { panic("boom") },
+		Policy: &Policy{MaxRestarts: 1, OnExceeded: StopEngine},
+	})
+	select {
+	case <-e.Done():
+	case <-time.After(time.Second):
+		t.Fatal("engine was not stopped")
+	}
+	e.Stop()
+}
+
+func TestPolicy_Backoff(t *testing.T) {
+	p := Policy{MinBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}.withDefaults()
+	for recent, want := range map[int]time.Duration{
+		1:  100 * time.Millisecond,
+		2:  200 * time.Millisecond,
+		4:  800 * time.Millisecond,
+		5:  time.Second,
+		80: time.Second,
+	} {
+		if got := p.backoff(recent); got != want {
+			t.Errorf("backoff after %d restarts: expected %v, got %v", recent, want, got)
+		}
+	}
+}
+
+func TestSafeGo_NamesTasks(t *testing.T) {
+	e := NewEngine(time.Millisecond, time.Second)
+	e.SafeGo(func(ctx context.Context) { <-ctx.Done() }, nil)
+	waitFor(t, func() bool { return status(e, "task-1").State == TaskRunning })
+	e.Stop()
+}
+
+func TestSupervise_RestartsAfterError(t *testing.T) {
+	e := NewEngine(time.Millisecond, time.Second)
+	var errs atomic.Int32
+	e.Hooks.OnError = func(name string, err error) { errs.Add(1) }
+	var runs atomic.Int32
+	e.Supervise(Task{
+		Name: "reader",
+		Run: func(ctx context.Context) error {
+			if runs.Add(1) == 1 {
+				return errors.New("connection reset")
+			}
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	})
+	waitFor(t, func() bool { return runs.Load() == 2 })
+	s := status(e, "reader")
+	if s.Restarts != 1 || errs.Load() != 1 || s.LastError != "connection reset" || s.LastErrorAt.IsZero() {
+		t.Errorf("expected one restart after the error, got %+v", s)
+	}
+	e.Stop()
+	if s := status(e, "reader"); s.State != TaskStopped || s.Restarts != 1 {
+		t.Errorf("expected an error after Stop to stop the task, got %+v", s)
+	}
+}
@@ -16,7 +16,6 @@ package sys
 import (
 	"context"
 	"log"
-	"runtime/debug"
 	"sync"
 	"time"
 )
@@ -27,6 +26,13 @@ type Engine struct {
 	wg            sync.WaitGroup
 	HealthCheck   time.Duration
 	RetryInterval time.Duration
+	// Policy restarts supervised tasks that do not bring their own
+	Policy Policy
+	// Hooks observe every supervised task, set them before starting any
+	Hooks Hooks
+
+	mu    sync.Mutex
+	tasks []*TaskStatus
 }
 
 func NewEngine(retry time.Duration, health time.Duration) *Engine {
@@ -36,6 +42,7 @@ func NewEngine(retry time.Duration, health time.Duration) *Engine {
 		cancel:        cancel,
 		RetryInterval: retry,
 		HealthCheck:   health,
+		Policy:        Policy{MinBackoff: retry},
 	}
 }
 
@@ -57,47 +64,12 @@ func (e *Engine) Go(fn func(ctx context.Context), recoverFunc func(r any)) {
 	}()
 }
 
+// SafeGo supervises fn as an unnamed task, see Supervise
 func (e *Engine) SafeGo(fn func(ctx context.Context), restartFunc func()) {
-	e.wg.Add(1)
-	go func() {
-		defer e.wg.Done()
-
-		for {
-			done := make(chan struct{})
-			panicCh := make(chan any, 1)
-
-			go func() {
-				defer func() {
-					if r := recover(); r != nil {
-						select {
-						case panicCh <- r:
-						default:
-						}
-					}
-				}()
-				fn(e.ctx)
-				close(done)
-			}()
-
-			select {
-			case <-done:
-				return
-			case r := <-panicCh:
-				log.Printf("[Engine.SafeGo] goroutine panic recovered: %v\n%s", r, debug.Stack())
-				if restartFunc != nil {
-					restartFunc()
-				}
-			case <-e.ctx.Done():
-				return
-			}
-
-			select {
-			case <-e.ctx.Done():
-				return
-			case <-time.After(e.RetryInterval):
-			}
-		}
-	}()
+	e.Supervise(Task{Run: func(ctx context.Context) error {
+		fn(ctx)
+		return nil
+	}, OnRestart: restartFunc})
 }
 
 func (e *Engine) Stop() {
@@ -2,7 +2,9 @@ package config
 
 import (
 	"github.com/wang900115/quant/exchange/binance"
+	"github.com/wang900115/quant/exchange/bybit"
 	"github.com/wang900115/quant/exchange/coinbase"
+	"github.com/wang900115/quant/exchange/kraken"
 	"github.com/wang900115/quant/exchange/okx"
 	"github.com/wang900115/quant/stoploss/engine"
 )
@@ -14,6 +16,8 @@ type Config struct {
 	Binance  binance.BinanceConfig
 	Coinbase coinbase.CoinbaseConfig
 	Okx      okx.OkxConfig
+	Bybit    bybit.BybitConfig
+	Kraken   kraken.KrakenConfig
 }
 
 type configOpts func(c *Config)
@@ -47,3 +51,15 @@ func (c *Config) WithOkx(opt okx.OkxConfig) configOpts {
 		c.Okx = opt
 	}
 }
+
+func (c *Config) WithBybit(opt bybit.BybitConfig) configOpts {
+	return func(c *Config) {
+		c.Bybit = opt
+	}
+}
+
+func (c *Config) WithKraken(opt kraken.KrakenConfig) configOpts {
+	return func(c *Config) {
+		c.Kraken = opt
+	}
+}
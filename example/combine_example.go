@@ -51,15 +51,16 @@ func CombineExample() {
 	manger.RegisterStrategy("Fixed-Trailing-Stop-3%", trailingStopStrategy)
 	manger.Start()
 
+	// ============ Start Dispatch ============
+	// subscriptions are acknowledged on the stream, so it has to run first
+	providers.StartStream(ctx)
+
 	// ============ Stream Subscribe ============
 	err = providers.SubscribeStream(QuotesPair, []string{"ticker"})
 	if err != nil {
 		panic(err)
 	}
 
-	// ============ Start Dispatch ============
-	providers.StartStream(ctx)
-
 	// ============ Channels ============
 	ch1, ch2, ch3, err := providers.ReceiveStream(QuotesPair)
 	if err != nil {
@@ -19,7 +19,9 @@ import (
 
 	"github.com/wang900115/quant/exchange"
 	"github.com/wang900115/quant/exchange/binance"
+	"github.com/wang900115/quant/exchange/bybit"
 	"github.com/wang900115/quant/exchange/coinbase"
+	"github.com/wang900115/quant/exchange/kraken"
 	"github.com/wang900115/quant/exchange/okx"
 	"github.com/wang900115/quant/model"
 	"github.com/wang900115/quant/model/currency"
@@ -31,6 +33,8 @@ func exchangeExample1() {
 	ps.Register(model.BINANCE, binance.New(binance.BinanceConfig{}))
 	ps.Register(model.COINBASE, coinbase.New(coinbase.CoinbaseConfig{}))
 	ps.Register(model.OKX, okx.New(okx.OkxConfig{}))
+	ps.Register(model.BYBIT, bybit.New(bybit.BybitConfig{}))
+	ps.Register(model.KRAKEN, kraken.New(kraken.KrakenConfig{}))
 
 	log.Printf("exchanges registered: %+v \n", ps.ListProviders())
 
@@ -70,6 +74,8 @@ func exchangeExample2() {
 	ps.Register(model.BINANCE, binance.New(binance.BinanceConfig{}))
 	ps.Register(model.COINBASE, coinbase.New(coinbase.CoinbaseConfig{}))
 	ps.Register(model.OKX, okx.New(okx.OkxConfig{}))
+	ps.Register(model.BYBIT, bybit.New(bybit.BybitConfig{}))
+	ps.Register(model.KRAKEN, kraken.New(kraken.KrakenConfig{}))
 	log.Printf("exchanges registered: %+v \n", ps.ListProviders())
 	QuotesPair := model.QuotesPair{
 		ExchangeID: model.COINBASE,
@@ -77,10 +83,11 @@ func exchangeExample2() {
 		Quote:      currency.USDTSymbol,
 		Category:   trade.SPOT,
 	}
+	ps.StartStream(context.Background())
 	if err := ps.SubscribeStream(QuotesPair, []string{"ticker", "order_book"}); err != nil {
 		log.Fatalf("Failed to subscribe to stream: %v \n", err)
 	}
-	ps.StartStream(context.Background())
+	log.Printf("Active subscriptions: %+v \n", ps.Subscriptions())
 
 	ch1, ch2, ch3, err := ps.ReceiveStream(QuotesPair)
 	if err != nil {
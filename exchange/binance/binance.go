@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"github.com/shopspring/decimal"
+	"github.com/wang900115/quant/exchange/rest"
 	"github.com/wang900115/quant/model"
 	"github.com/wang900115/quant/model/trade"
 )
@@ -49,11 +50,10 @@ const (
 )
 
 var (
-	errBinanceNoData  = errors.New("binance: no data returned")
-	errResponseFailed = errors.New("binance: response failed")
-	errInvalidPair    = errors.New("binance: invalid trading pair")
-	errInitFailed     = errors.New("binance: initialization failed")
-	errNonAssetFound  = errors.New("binance: no such asset found")
+	errBinanceNoData = errors.New("binance: no data returned")
+	errInvalidPair   = errors.New("binance: invalid trading pair")
+	errInitFailed    = errors.New("binance: initialization failed")
+	errNonAssetFound = errors.New("binance: no such asset found")
 )
 
 type BinanceConfig struct {
@@ -63,15 +63,22 @@ type BinanceConfig struct {
 	BufferSize     int
 	Callback       func(message []byte) error
 
+	// AckTimeout bounds how long SubscribeStream waits for the venue to confirm, zero selects ten seconds
+	AckTimeout time.Duration
+
 	APIKey    string
 	SecretKey string
 
 	RetryInterval       time.Duration
 	HealthCheckInterval time.Duration
+
+	// Scheduler shares rate limits between clients, one is created when nil
+	Scheduler *rest.Scheduler
 }
 
 type BinanceSingleClient struct {
-	client *http.Client
+	client    *http.Client
+	scheduler *rest.Scheduler
 }
 
 func NewSingleClient(cfg BinanceConfig) *BinanceSingleClient {
@@ -79,8 +86,12 @@ func NewSingleClient(cfg BinanceConfig) *BinanceSingleClient {
 	if timeout == 0 {
 		timeout = defaultTimeout
 	}
+	if cfg.Scheduler == nil {
+		cfg.Scheduler = NewScheduler()
+	}
 	return &BinanceSingleClient{
-		client: &http.Client{Timeout: timeout},
+		client:    &http.Client{Timeout: timeout},
+		scheduler: cfg.Scheduler,
 	}
 }
 
@@ -93,13 +104,13 @@ func (bc *BinanceSingleClient) GetPrice(ctx context.Context, pair model.QuotesPa
 	if err != nil {
 		return nil, err
 	}
-	resp, err := bc.client.Do(req)
+	resp, err := bc.scheduler.Do(bc.client, req, tickerCost(pair))
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return nil, errResponseFailed
+		return nil, parseError(resp)
 	}
 	var raw struct {
 		Symbol string `json:"symbol"`
@@ -131,13 +142,13 @@ func (bc *BinanceSingleClient) GetKlines(ctx context.Context, pair model.QuotesP
 	if err != nil {
 		return nil, err
 	}
-	resp, err := bc.client.Do(req)
+	resp, err := bc.scheduler.Do(bc.client, req, klinesCost(pair, limit))
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return nil, errResponseFailed
+		return nil, parseError(resp)
 	}
 	// Binance response struct: [[openTime, open, high, low, close, volume, closeTime, ...], ...]
 	var rawKlines [][]interface{}
@@ -197,13 +208,13 @@ func (bc *BinanceSingleClient) GetOrderBook(ctx context.Context, pair model.Quot
 	if err != nil {
 		return nil, err
 	}
-	resp, err := bc.client.Do(req)
+	resp, err := bc.scheduler.Do(bc.client, req, depthCost(pair, limit))
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return nil, errResponseFailed
+		return nil, parseError(resp)
 	}
 	var raw struct {
 		LastUpdateID int             `json:"lastUpdateId"`
@@ -237,6 +248,9 @@ type BinanceClient struct {
 }
 
 func New(cfg BinanceConfig) *BinanceClient {
+	if cfg.Scheduler == nil {
+		cfg.Scheduler = NewScheduler()
+	}
 	binanceStreamClient, err := NewStreamClient(cfg)
 	if err != nil {
 		panic(err)
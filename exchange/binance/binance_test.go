@@ -316,6 +316,10 @@ func TestBinanceStreamClient_Subscribe(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed to create stream client: %v", err)
 	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// subscriptions wait for acknowledgements, which the dispatcher reads
+	go client.Dispatch(ctx)
 	defer client.Close()
 	pair := model.QuotesPair{
 		Base:     currency.BTCSymbol,
@@ -370,13 +374,6 @@ func TestBinanceStreamClient_ReceiveData(t *testing.T) {
 		Category: trade.SPOT,
 	}
 
-	err = client.SubscribeStream(pair, []string{"ticker"})
-	if err != nil {
-		t.Fatalf("failed to subscribe: %v", err)
-	}
-
-	priceChan, _, _ := client.ReceiveStream()
-
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
@@ -387,6 +384,13 @@ func TestBinanceStreamClient_ReceiveData(t *testing.T) {
 		}
 	}()
 
+	err = client.SubscribeStream(pair, []string{"ticker"})
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+
+	priceChan, _, _ := client.ReceiveStream()
+
 	receivedCount := 0
 	maxUpdates := 2
 
@@ -412,6 +416,10 @@ func TestBinanceStreamClient_MultipleCategories(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed to create stream client: %v", err)
 	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// subscriptions wait for acknowledgements, which the dispatcher reads
+	go client.Dispatch(ctx)
 
 	testCases := []struct {
 		name string
@@ -0,0 +1,75 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package binance
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/wang900115/quant/exchange/rest"
+)
+
+const requestIDHeader = "X-Mbx-Uuid"
+
+// parseError turns a failed Binance response carrying {"code": -1121, "msg": "..."} into a *rest.Error
+func parseError(resp *http.Response) error {
+	e := rest.NewError("binance", resp, requestIDHeader)
+	body := rest.ReadBody(resp)
+	var payload struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil || payload.Code == 0 {
+		e.Message = strings.TrimSpace(string(body))
+		return e
+	}
+	e.Code = strconv.Itoa(payload.Code)
+	e.Message = payload.Msg
+	if kind := classify(payload.Code, payload.Msg); kind != rest.KindUnknown {
+		e.Classify(kind)
+	}
+	// a request outside recvWindow succeeds once re-signed
+	if payload.Code == -1021 {
+		e.Retryable = true
+	}
+	return e
+}
+
+func classify(code int, msg string) rest.Kind {
+	switch code {
+	case -1003, -1015:
+		return rest.KindRateLimited
+	case -1000, -1001, -1006, -1007, -1008:
+		return rest.KindUnavailable
+	case -1121:
+		return rest.KindInvalidSymbol
+	case -2013:
+		return rest.KindOrderNotFound
+	case -2014, -2015, -2008:
+		return rest.KindUnauthorized
+	case -2018, -2019:
+		return rest.KindInsufficientBalance
+	case -2010:
+		if strings.Contains(strings.ToLower(msg), "insufficient balance") {
+			return rest.KindInsufficientBalance
+		}
+	case -2011:
+		if strings.Contains(strings.ToLower(msg), "unknown order") {
+			return rest.KindOrderNotFound
+		}
+	}
+	return rest.KindUnknown
+}
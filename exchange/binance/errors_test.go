@@ -0,0 +1,93 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+package binance
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/wang900115/quant/exchange/rest"
+	"github.com/wang900115/quant/exchange/stream"
+)
+
+func response(status int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{requestIDHeader: []string{"uuid-1"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		code      string
+		target    error
+		retryable bool
+	}{
+		{"Invalid symbol", 400, `{"code":-1121,"msg":"Invalid symbol."}`, "-1121", rest.ErrInvalidSymbol, false},
+		{"Insufficient balance", 400, `{"code":-2010,"msg":"Account has insufficient balance for requested action."}`, "-2010", rest.ErrInsufficientBalance, false},
+		{"Rate limited", 429, `{"code":-1003,"msg":"Too many requests."}`, "-1003", rest.ErrRateLimited, true},
+		{"Unknown order", 400, `{"code":-2011,"msg":"Unknown order sent."}`, "-2011", rest.ErrOrderNotFound, false},
+		{"Timestamp", 400, `{"code":-1021,"msg":"Timestamp for this request is outside of the recvWindow."}`, "-1021", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := parseError(response(tt.status, tt.body))
+			var e *rest.Error
+			if !errors.As(err, &e) {
+				t.Fatalf("expected *rest.Error, got %T", err)
+			}
+			if e.Code != tt.code || e.Status != tt.status || e.RequestID != "uuid-1" {
+				t.Errorf("unexpected error fields: %+v", e)
+			}
+			if tt.target != nil && !errors.Is(err, tt.target) {
+				t.Errorf("expected %v to match %v", err, tt.target)
+			}
+			if e.Retryable != tt.retryable {
+				t.Errorf("expected retryable=%v, got %v", tt.retryable, e.Retryable)
+			}
+		})
+	}
+}
+
+func TestParseErrorWithoutPayload(t *testing.T) {
+	err := parseError(response(http.StatusBadGateway, "<html>bad gateway</html>"))
+	var e *rest.Error
+	if !errors.As(err, &e) {
+		t.Fatalf("expected *rest.Error, got %T", err)
+	}
+	if e.Code != "" || e.Message != "<html>bad gateway</html>" || !e.Retryable {
+		t.Errorf("unexpected error fields: %+v", e)
+	}
+}
+
+func TestParseAck(t *testing.T) {
+	a, ok := parseAck([]byte(`{"result":null,"id":7}`))
+	if !ok || a.id != "7" || a.err != nil {
+		t.Errorf("expected a successful ack for id 7, got %+v %v", a, ok)
+	}
+	a, ok = parseAck([]byte(`{"error":{"code":2,"msg":"Invalid request: unknown variable"},"id":8}`))
+	var e *stream.Error
+	if !ok || a.id != "8" || !errors.As(a.err, &e) || e.Code != "2" {
+		t.Errorf("expected a rejection for id 8, got %+v %v", a, ok)
+	}
+	if _, ok := parseAck([]byte(`{"e":"24hrTicker","E":1,"s":"BTCUSDT","c":"1"}`)); ok {
+		t.Error("expected market data not to be taken for an ack")
+	}
+}
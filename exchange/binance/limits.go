@@ -0,0 +1,139 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package binance
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wang900115/quant/exchange/rest"
+	"github.com/wang900115/quant/model"
+	"github.com/wang900115/quant/model/trade"
+)
+
+// Binance accounts request weight per IP in one-minute windows, separately for each API family
+const (
+	spotWeightBucket    = "api"
+	futuresWeightBucket = "fapi"
+	inverseWeightBucket = "dapi"
+	spotOrderBucket     = "order"
+)
+
+const usedWeightHeader = "X-Mbx-Used-Weight-1m"
+
+// NewScheduler creates the request scheduler shared by the Binance REST clients
+func NewScheduler() *rest.Scheduler {
+	return rest.NewScheduler(rest.Config{
+		Buckets: []rest.Bucket{
+			{Name: spotWeightBucket, Capacity: 6000, Window: time.Minute},
+			{Name: futuresWeightBucket, Capacity: 2400, Window: time.Minute},
+			{Name: inverseWeightBucket, Capacity: 2400, Window: time.Minute},
+			{Name: spotOrderBucket, Capacity: 100, Window: 10 * time.Second},
+		},
+		Observe: observeUsedWeight,
+	})
+}
+
+// observeUsedWeight syncs the local weight counter with X-MBX-USED-WEIGHT-1M
+func observeUsedWeight(s *rest.Scheduler, resp *http.Response) {
+	value := resp.Header.Get(usedWeightHeader)
+	if value == "" {
+		return
+	}
+	used, err := strconv.Atoi(value)
+	if err != nil {
+		return
+	}
+	switch resp.Request.URL.Host {
+	case hostOf(futuresEndpoint):
+		s.Sync(futuresWeightBucket, used)
+	case hostOf(inverseEndpoint):
+		s.Sync(inverseWeightBucket, used)
+	default:
+		s.Sync(spotWeightBucket, used)
+	}
+}
+
+func weightBucket(pair model.QuotesPair) string {
+	switch pair.Category {
+	case trade.FUTURES:
+		return futuresWeightBucket
+	case trade.INVERSE:
+		return inverseWeightBucket
+	default:
+		return spotWeightBucket
+	}
+}
+
+func tickerCost(pair model.QuotesPair) rest.Cost {
+	if pair.Category == trade.SPOT {
+		return rest.Cost{Bucket: spotWeightBucket, Weight: 2}
+	}
+	return rest.Cost{Bucket: weightBucket(pair), Weight: 1}
+}
+
+func klinesCost(pair model.QuotesPair, limit int) rest.Cost {
+	if pair.Category == trade.SPOT {
+		return rest.Cost{Bucket: spotWeightBucket, Weight: 2}
+	}
+	weight := 1
+	switch {
+	case limit > 1000:
+		weight = 10
+	case limit >= 500:
+		weight = 5
+	case limit >= 100:
+		weight = 2
+	}
+	return rest.Cost{Bucket: weightBucket(pair), Weight: weight}
+}
+
+func depthCost(pair model.QuotesPair, limit int) rest.Cost {
+	if pair.Category == trade.SPOT {
+		weight := 5
+		switch {
+		case limit > 1000:
+			weight = 250
+		case limit > 500:
+			weight = 50
+		case limit > 100:
+			weight = 25
+		}
+		return rest.Cost{Bucket: spotWeightBucket, Weight: weight}
+	}
+	weight := 2
+	switch {
+	case limit > 500:
+		weight = 20
+	case limit > 100:
+		weight = 10
+	case limit > 50:
+		weight = 5
+	}
+	return rest.Cost{Bucket: weightBucket(pair), Weight: weight}
+}
+
+var (
+	placeOrderCost  = []rest.Cost{{Bucket: spotWeightBucket, Weight: 1}, {Bucket: spotOrderBucket, Weight: 1}}
+	queryOrderCost  = []rest.Cost{{Bucket: spotWeightBucket, Weight: 4}}
+	cancelOrderCost = []rest.Cost{{Bucket: spotWeightBucket, Weight: 1}}
+	accountCost     = []rest.Cost{{Bucket: spotWeightBucket, Weight: 20}}
+	userDataCost    = []rest.Cost{{Bucket: spotWeightBucket, Weight: 2}}
+)
+
+func hostOf(endpoint string) string {
+	return strings.TrimPrefix(endpoint, "https://")
+}
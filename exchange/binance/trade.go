@@ -24,14 +24,16 @@ import (
 	"github.com/gorilla/websocket"
 	"github.com/shopspring/decimal"
 	"github.com/wang900115/quant/common/sys"
+	"github.com/wang900115/quant/exchange/rest"
 	"github.com/wang900115/quant/model"
 	"github.com/wang900115/quant/model/currency"
 	"github.com/wang900115/quant/model/trade"
 )
 
 type BinanceTradeClient struct {
-	client *http.Client
-	ws     *websocket.Conn
+	client    *http.Client
+	scheduler *rest.Scheduler
+	ws        *websocket.Conn
 
 	engine    *sys.Engine
 	eventChan chan model.OrderEvent
@@ -47,9 +49,13 @@ func NewTradeClient(cfg BinanceConfig) (*BinanceTradeClient, error) {
 	if cfg.BufferSize == 0 {
 		cfg.BufferSize = defaultBufferSize
 	}
+	if cfg.Scheduler == nil {
+		cfg.Scheduler = NewScheduler()
+	}
 	b := &BinanceTradeClient{
 		engine:    sys.NewEngine(cfg.RetryInterval, cfg.HealthCheckInterval),
 		client:    &http.Client{Timeout: cfg.PrivateTimeout},
+		scheduler: cfg.Scheduler,
 		apiKey:    cfg.APIKey,
 		secretKey: cfg.SecretKey,
 		eventChan: make(chan model.OrderEvent, cfg.BufferSize),
@@ -65,11 +71,14 @@ func (btc *BinanceTradeClient) createListenKey() (string, error) {
 	url := fmt.Sprintf("%s/api/v3/userDataStream", spotEndpoint)
 	httpReq, _ := http.NewRequest(http.MethodPost, url, nil)
 	httpReq.Header.Set("X-MBX-APIKEY", btc.apiKey)
-	resp, err := btc.client.Do(httpReq)
+	resp, err := btc.scheduler.Do(btc.client, httpReq, userDataCost...)
 	if err != nil {
 		return "", err
 	}
 	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", parseError(resp)
+	}
 	var result struct {
 		ListenKey string `json:"listenKey"`
 	}
@@ -90,7 +99,10 @@ func (btc *BinanceTradeClient) connect() error {
 		return err
 	}
 	btc.ws = ws
-	go btc.listen()
+	btc.engine.Supervise(sys.Task{Name: "binance.orders", Run: func(context.Context) error {
+		btc.listen()
+		return nil
+	}})
 	return nil
 }
 
@@ -113,11 +125,14 @@ func (btc *BinanceTradeClient) PlaceOrder(ctx context.Context, req model.OrderRe
 	httpReq.Header.Set("X-MBX-APIKEY", btc.apiKey)
 	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
-	resp, err := btc.client.Do(httpReq)
+	resp, err := btc.scheduler.Do(btc.client, httpReq, placeOrderCost...)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, parseError(resp)
+	}
 
 	var result struct {
 		OrderID       int64  `json:"orderId"`
@@ -142,11 +157,14 @@ func (btc *BinanceTradeClient) GetOrder(ctx context.Context, symbol string, orde
 	httpReq, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	httpReq.Header.Set("X-MBX-APIKEY", btc.apiKey)
 
-	resp, err := btc.client.Do(httpReq)
+	resp, err := btc.scheduler.Do(btc.client, httpReq, queryOrderCost...)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, parseError(resp)
+	}
 	var od struct {
 		OrderID       int64  `json:"orderId"`
 		Symbol        string `json:"symbol"`
@@ -183,13 +201,13 @@ func (btc *BinanceTradeClient) CancelOrder(ctx context.Context, symbol string, o
 	httpReq, _ := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
 	httpReq.Header.Set("X-MBX-APIKEY", btc.apiKey)
 
-	resp, err := btc.client.Do(httpReq)
+	resp, err := btc.scheduler.Do(btc.client, httpReq, cancelOrderCost...)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("cancel order failed: %v", resp.Status)
+		return parseError(resp)
 	}
 	return nil
 }
@@ -199,11 +217,14 @@ func (btc *BinanceTradeClient) GetAssetBalance(ctx context.Context, asset string
 	httpReq, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	httpReq.Header.Set("X-MBX-APIKEY", btc.apiKey)
 
-	resp, err := btc.client.Do(httpReq)
+	resp, err := btc.scheduler.Do(btc.client, httpReq, accountCost...)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, parseError(resp)
+	}
 
 	var account struct {
 		Balances []struct {
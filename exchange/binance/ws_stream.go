@@ -17,22 +17,24 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
-	"github.com/gorilla/websocket"
 	"github.com/shopspring/decimal"
+	"github.com/wang900115/quant/exchange/stream"
 	"github.com/wang900115/quant/model"
 	"github.com/wang900115/quant/model/trade"
 )
 
 type BinanceStreamClient struct {
-	spotClient    *websocket.Conn
-	futuresClient *websocket.Conn
-	inverseClient *websocket.Conn
+	spotClient    *stream.Conn
+	futuresClient *stream.Conn
+	inverseClient *stream.Conn
 
 	handler    func(message []byte) error
 	bufferSize int
+	tracker    *stream.Tracker
 
 	newPriceChan      chan model.PricePoint
 	priceIntervalChan chan model.PriceInterval
@@ -49,6 +51,7 @@ func NewStreamClient(cfg BinanceConfig) (*BinanceStreamClient, error) {
 	c := &BinanceStreamClient{
 		handler:           cfg.Callback,
 		bufferSize:        cfg.BufferSize,
+		tracker:           stream.NewTracker(cfg.AckTimeout),
 		newPriceChan:      make(chan model.PricePoint, cfg.BufferSize),
 		priceIntervalChan: make(chan model.PriceInterval, cfg.BufferSize),
 		orderBookChan:     make(chan model.OrderBook, cfg.BufferSize),
@@ -84,12 +87,8 @@ func NewStreamClient(cfg BinanceConfig) (*BinanceStreamClient, error) {
 	return c, nil
 }
 
-func (bc *BinanceStreamClient) connect(endpoint string) (*websocket.Conn, error) {
-	conn, _, err := websocket.DefaultDialer.Dial(endpoint, nil)
-	if err != nil {
-		return nil, err
-	}
-	return conn, nil
+func (bc *BinanceStreamClient) connect(endpoint string) (*stream.Conn, error) {
+	return stream.Dial(endpoint)
 }
 
 func (bc *BinanceStreamClient) ReceiveStream() (<-chan model.PricePoint, <-chan model.PriceInterval, <-chan model.OrderBook) {
@@ -97,6 +96,27 @@ func (bc *BinanceStreamClient) ReceiveStream() (<-chan model.PricePoint, <-chan
 }
 
 func (bc *BinanceStreamClient) SubscribeStream(pair model.QuotesPair, streamType []string) error {
+	if err := bc.request(pair, "SUBSCRIBE", streamType); err != nil {
+		return err
+	}
+	bc.tracker.Add(stream.Of(pair, streamType)...)
+	return nil
+}
+
+func (bc *BinanceStreamClient) UnsubscribeStream(pair model.QuotesPair, streamType []string) error {
+	if err := bc.request(pair, "UNSUBSCRIBE", streamType); err != nil {
+		return err
+	}
+	bc.tracker.Remove(stream.Of(pair, streamType)...)
+	return nil
+}
+
+func (bc *BinanceStreamClient) Subscriptions() []model.Subscription {
+	return bc.tracker.Subscriptions()
+}
+
+// request sends a SUBSCRIBE or UNSUBSCRIBE and waits for the response carrying its id
+func (bc *BinanceStreamClient) request(pair model.QuotesPair, method string, streamType []string) error {
 	client, err := bc.getClient(pair)
 	if err != nil {
 		return err
@@ -106,35 +126,41 @@ func (bc *BinanceStreamClient) SubscribeStream(pair model.QuotesPair, streamType
 		params = append(params, fmt.Sprintf("%s@%s", strings.ToLower(pair.Base.String()+pair.Quote.String()), st))
 	}
 
+	id := bc.tracker.NextID()
 	msg := map[string]interface{}{
-		"method": "SUBSCRIBE",
+		"method": method,
 		"params": params,
-		"id":     time.Now().Unix(),
+		"id":     id,
 	}
-	return client.WriteJSON(msg)
+	return bc.tracker.Do(id, func() error { return client.WriteJSON(msg) })
 }
 
 func (bc *BinanceStreamClient) Dispatch(ctx context.Context) error {
 	dispatchers := bc.getDispatchers()
-	clients := []*websocket.Conn{bc.spotClient, bc.futuresClient, bc.inverseClient}
+	clients := []*stream.Conn{bc.spotClient, bc.futuresClient, bc.inverseClient}
 
 	// Start goroutine for each WebSocket connection
 	for _, client := range clients {
-		go func(conn *websocket.Conn) {
+		go func(conn *stream.Conn) {
 			for {
 				select {
 				case <-ctx.Done():
 					return
 				default:
 					_, message, err := conn.ReadMessage()
+					received := time.Now()
 					if err != nil {
 						return
 					}
 					if bc.handler != nil {
 						bc.handler(message)
 					}
+					if a, ok := parseAck(message); ok {
+						bc.tracker.Resolve(a.id, a.err)
+						continue
+					}
 					if fn, ok := dispatchers[getMessageType(message)]; ok {
-						fn(bc, message)
+						fn(bc, message, received)
 					}
 				}
 			}
@@ -146,6 +172,7 @@ func (bc *BinanceStreamClient) Dispatch(ctx context.Context) error {
 }
 
 func (bc *BinanceStreamClient) Close() error {
+	bc.tracker.Close()
 	if err := bc.spotClient.Close(); err != nil {
 		return err
 	}
@@ -161,23 +188,23 @@ func (bc *BinanceStreamClient) Close() error {
 	return nil
 }
 
-type dispatchFunc func(*BinanceStreamClient, []byte)
+type dispatchFunc func(*BinanceStreamClient, []byte, time.Time)
 
 func (bc *BinanceStreamClient) getDispatchers() map[string]dispatchFunc {
 	return map[string]dispatchFunc{
-		"24hrTicker": func(client *BinanceStreamClient, msg []byte) {
+		"24hrTicker": func(client *BinanceStreamClient, msg []byte, received time.Time) {
 			if p, err := parsePricePoint(msg); err == nil {
-				model.PushToChan(client.newPriceChan, *p)
+				model.DispatchPrice(client.newPriceChan, *p, received)
 			}
 		},
-		"kline": func(client *BinanceStreamClient, msg []byte) {
+		"kline": func(client *BinanceStreamClient, msg []byte, received time.Time) {
 			if intervals, err := parsePriceInterval(msg); err == nil {
 				for _, interval := range intervals {
 					model.PushToChan(client.priceIntervalChan, interval)
 				}
 			}
 		},
-		"depthUpdate": func(client *BinanceStreamClient, msg []byte) {
+		"depthUpdate": func(client *BinanceStreamClient, msg []byte, received time.Time) {
 			if ob, err := parseOrderBook(msg); err == nil {
 				model.PushToChan(client.orderBookChan, *ob)
 			}
@@ -185,7 +212,7 @@ func (bc *BinanceStreamClient) getDispatchers() map[string]dispatchFunc {
 	}
 }
 
-func (bc *BinanceStreamClient) getClient(pair model.QuotesPair) (*websocket.Conn, error) {
+func (bc *BinanceStreamClient) getClient(pair model.QuotesPair) (*stream.Conn, error) {
 	switch pair.Category {
 	case trade.SPOT:
 		return bc.spotClient, nil
@@ -198,6 +225,31 @@ func (bc *BinanceStreamClient) getClient(pair model.QuotesPair) (*websocket.Conn
 	}
 }
 
+// ack is the response to a SUBSCRIBE or UNSUBSCRIBE request
+type ack struct {
+	id  string
+	err error
+}
+
+// parseAck recognizes {"result":null,"id":1} and {"error":{"code":2,"msg":"..."},"id":1}
+func parseAck(msg []byte) (ack, bool) {
+	var raw struct {
+		ID    json.Number `json:"id"`
+		Error *struct {
+			Code int    `json:"code"`
+			Msg  string `json:"msg"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(msg, &raw); err != nil || raw.ID == "" {
+		return ack{}, false
+	}
+	a := ack{id: raw.ID.String()}
+	if raw.Error != nil {
+		a.err = &stream.Error{Exchange: "binance", Code: strconv.Itoa(raw.Error.Code), Message: raw.Error.Msg}
+	}
+	return a, true
+}
+
 func getMessageType(msg []byte) string {
 	// Important: Must declare both 'e' and 'E' fields to avoid Go JSON parsing bug
 	// when both lowercase and uppercase keys exist in the same JSON
@@ -0,0 +1,84 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+package bybit
+
+import (
+	"slices"
+
+	"github.com/shopspring/decimal"
+	"github.com/wang900115/quant/model"
+)
+
+// orderBook is the local copy of a depth topic, kept up to date from snapshots and deltas
+type orderBook struct {
+	bidLevels map[string]model.OrderBookBase
+	askLevels map[string]model.OrderBookBase
+}
+
+func newOrderBook() *orderBook {
+	return &orderBook{
+		bidLevels: make(map[string]model.OrderBookBase),
+		askLevels: make(map[string]model.OrderBookBase),
+	}
+}
+
+func (ob *orderBook) apply(bids, asks [][]string) error {
+	if err := applyLevels(ob.bidLevels, bids); err != nil {
+		return err
+	}
+	return applyLevels(ob.askLevels, asks)
+}
+
+// applyLevels sets each [price, size] level, a zero size removes the level
+func applyLevels(levels map[string]model.OrderBookBase, updates [][]string) error {
+	for _, u := range updates {
+		if len(u) < 2 {
+			return errBybitNoData
+		}
+		price, err := decimal.NewFromString(u[0])
+		if err != nil {
+			return err
+		}
+		qty, err := decimal.NewFromString(u[1])
+		if err != nil {
+			return err
+		}
+		key := price.String()
+		if qty.IsZero() {
+			delete(levels, key)
+			continue
+		}
+		levels[key] = model.OrderBookBase{Price: price, Quantity: qty}
+	}
+	return nil
+}
+
+// bids returns the bid side, best (highest) price first
+func (ob *orderBook) bids() []model.OrderBookBid {
+	out := make([]model.OrderBookBid, 0, len(ob.bidLevels))
+	for _, l := range ob.bidLevels {
+		out = append(out, model.OrderBookBid(l))
+	}
+	slices.SortFunc(out, func(a, b model.OrderBookBid) int { return b.Price.Cmp(a.Price) })
+	return out
+}
+
+// asks returns the ask side, best (lowest) price first
+func (ob *orderBook) asks() []model.OrderBookAsk {
+	out := make([]model.OrderBookAsk, 0, len(ob.askLevels))
+	for _, l := range ob.askLevels {
+		out = append(out, model.OrderBookAsk(l))
+	}
+	slices.SortFunc(out, func(a, b model.OrderBookAsk) int { return a.Price.Cmp(b.Price) })
+	return out
+}
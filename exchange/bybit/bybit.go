@@ -0,0 +1,353 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+package bybit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/wang900115/quant/common/parse"
+	"github.com/wang900115/quant/exchange/rest"
+	"github.com/wang900115/quant/model"
+	"github.com/wang900115/quant/model/trade"
+)
+
+const (
+	endPoint          = "https://api.bybit.com"
+	wsEndPointPublic  = "wss://stream.bybit.com/v5/public"
+	wsEndPointPrivate = "wss://stream.bybit.com/v5/private"
+
+	testEndPoint          = "https://api-testnet.bybit.com"
+	wsTestEndPointPublic  = "wss://stream-testnet.bybit.com/v5/public"
+	wsTestEndPointPrivate = "wss://stream-testnet.bybit.com/v5/private"
+)
+
+var defaultCallback = func(message []byte) error {
+	return nil
+}
+
+const (
+	defaultTimeout      = 10 * time.Second
+	defaultBufferSize   = 100
+	defaultTradeTimeout = 15 * time.Second
+	defaultRecvWindow   = 5 * time.Second
+	defaultPingInterval = 20 * time.Second
+)
+
+var (
+	errBybitNoData   = errors.New("bybit: no data returned")
+	errInvalidPair   = errors.New("bybit: invalid trading pair")
+	errNotValidType  = errors.New("bybit: not valid type")
+	errInitFailed    = errors.New("bybit: initialization failed")
+	errNonAssetFound = errors.New("bybit: no such asset found")
+	errAuthFailed    = errors.New("bybit: private stream authentication failed")
+)
+
+type BybitConfig struct {
+	IsTestNet      bool
+	PublicTimeout  time.Duration
+	PrivateTimeout time.Duration
+	BufferSize     int
+	Callback       func(message []byte) error
+
+	// AckTimeout bounds how long SubscribeStream waits for the venue to confirm, zero selects ten seconds
+	AckTimeout time.Duration
+
+	APIKey     string
+	SecretKey  string
+	RecvWindow time.Duration
+
+	RetryInterval       time.Duration
+	HealthCheckInterval time.Duration
+	PingInterval        time.Duration
+
+	// Scheduler shares rate limits between clients, one is created when nil
+	Scheduler *rest.Scheduler
+
+	// Endpoint overrides, empty values select mainnet or testnet
+	RestEndpoint      string
+	PublicWsEndpoint  string
+	PrivateWsEndpoint string
+}
+
+func (cfg BybitConfig) restEndpoint() string {
+	switch {
+	case cfg.RestEndpoint != "":
+		return cfg.RestEndpoint
+	case cfg.IsTestNet:
+		return testEndPoint
+	default:
+		return endPoint
+	}
+}
+
+func (cfg BybitConfig) publicWsEndpoint() string {
+	switch {
+	case cfg.PublicWsEndpoint != "":
+		return cfg.PublicWsEndpoint
+	case cfg.IsTestNet:
+		return wsTestEndPointPublic
+	default:
+		return wsEndPointPublic
+	}
+}
+
+func (cfg BybitConfig) privateWsEndpoint() string {
+	switch {
+	case cfg.PrivateWsEndpoint != "":
+		return cfg.PrivateWsEndpoint
+	case cfg.IsTestNet:
+		return wsTestEndPointPrivate
+	default:
+		return wsEndPointPrivate
+	}
+}
+
+type BybitSingleClient struct {
+	client    *http.Client
+	scheduler *rest.Scheduler
+	endpoint  string
+}
+
+func NewSingleClient(cfg BybitConfig) *BybitSingleClient {
+	timeout := cfg.PublicTimeout
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+	if cfg.Scheduler == nil {
+		cfg.Scheduler = NewScheduler()
+	}
+	return &BybitSingleClient{
+		client:    &http.Client{Timeout: timeout},
+		scheduler: cfg.Scheduler,
+		endpoint:  cfg.restEndpoint(),
+	}
+}
+
+func (bc *BybitSingleClient) GetPrice(ctx context.Context, pair model.QuotesPair) (*model.PricePoint, error) {
+	category, err := getCategory(pair)
+	if err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/v5/market/tickers?category=%s&symbol=%s", bc.endpoint, category, getSymbol(pair))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := bc.scheduler.Do(bc.client, req, publicCost)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var raw struct {
+		List []struct {
+			Symbol    string `json:"symbol"`
+			LastPrice string `json:"lastPrice"`
+		} `json:"list"`
+	}
+	ts, err := readResult(resp, &raw)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw.List) == 0 || raw.List[0].LastPrice == "" {
+		return nil, errBybitNoData
+	}
+	price, err := decimal.NewFromString(raw.List[0].LastPrice)
+	if err != nil {
+		return nil, err
+	}
+	return &model.PricePoint{
+		NewPrice:  price,
+		UpdatedAt: ts,
+	}, nil
+}
+
+// interval: 1m, 3m, 5m, 15m, 30m, 1h, 2h, 4h, 6h, 12h, 1d, 1w, 1mth
+func (bc *BybitSingleClient) GetKlines(ctx context.Context, pair model.QuotesPair, interval string, limit int) ([]model.PriceInterval, error) {
+	category, err := getCategory(pair)
+	if err != nil {
+		return nil, err
+	}
+	bybitInterval, ok := klineIntervals[interval]
+	if !ok {
+		return nil, errNotValidType
+	}
+	url := fmt.Sprintf("%s/v5/market/kline?category=%s&symbol=%s&interval=%s&limit=%d",
+		bc.endpoint, category, getSymbol(pair), bybitInterval, limit)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := bc.scheduler.Do(bc.client, req, publicCost)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var raw struct {
+		List [][]string `json:"list"` // [startTime, open, high, low, close, volume, turnover]
+	}
+	if _, err := readResult(resp, &raw); err != nil {
+		return nil, err
+	}
+
+	duration := parse.ParseInterval(interval)
+	intervals := make([]model.PriceInterval, 0, len(raw.List))
+	for _, kline := range raw.List {
+		if len(kline) < 6 {
+			return nil, errBybitNoData
+		}
+		start, err := strconv.ParseInt(kline[0], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		values := make([]decimal.Decimal, 5)
+		for i := range values {
+			if values[i], err = decimal.NewFromString(kline[i+1]); err != nil {
+				return nil, err
+			}
+		}
+		openTime := time.UnixMilli(start)
+		intervals = append(intervals, model.PriceInterval{
+			OpenTime:         openTime.Format(time.RFC3339),
+			OpeningPrice:     values[0],
+			HighestPrice:     values[1],
+			LowestPrice:      values[2],
+			ClosingPrice:     values[3],
+			Volume:           values[4],
+			CloseTime:        openTime.Add(duration).Format(time.RFC3339),
+			IntervalDuration: duration,
+		})
+	}
+	// Bybit lists the newest candle first
+	slices.Reverse(intervals)
+	return intervals, nil
+}
+
+func (bc *BybitSingleClient) GetOrderBook(ctx context.Context, pair model.QuotesPair, limit int) (*model.OrderBook, error) {
+	category, err := getCategory(pair)
+	if err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/v5/market/orderbook?category=%s&symbol=%s&limit=%d", bc.endpoint, category, getSymbol(pair), limit)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := bc.scheduler.Do(bc.client, req, publicCost)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var raw struct {
+		Symbol string          `json:"s"`
+		Bids   [][]interface{} `json:"b"`
+		Asks   [][]interface{} `json:"a"`
+		Ts     int64           `json:"ts"`
+	}
+	if _, err := readResult(resp, &raw); err != nil {
+		return nil, err
+	}
+	bids, err := model.ParseOrderEntries[model.OrderBookBid](raw.Bids)
+	if err != nil {
+		return nil, err
+	}
+	asks, err := model.ParseOrderEntries[model.OrderBookAsk](raw.Asks)
+	if err != nil {
+		return nil, err
+	}
+	return &model.OrderBook{
+		Symbol: pair.Symbol(),
+		Time:   time.UnixMilli(raw.Ts),
+		Bids:   bids,
+		Asks:   asks,
+	}, nil
+}
+
+type BybitClient struct {
+	*BybitSingleClient
+	*BybitStreamClient
+	*BybitTradeClient
+}
+
+func New(cfg BybitConfig) *BybitClient {
+	if cfg.Scheduler == nil {
+		cfg.Scheduler = NewScheduler()
+	}
+	streamClient, err := NewStreamClient(cfg)
+	if err != nil {
+		panic(err)
+	}
+	tradeClient, err := NewTradeClient(cfg)
+	if err != nil {
+		panic(err)
+	}
+	return &BybitClient{
+		BybitSingleClient: NewSingleClient(cfg),
+		BybitStreamClient: streamClient,
+		BybitTradeClient:  tradeClient,
+	}
+}
+
+// Close shuts down the public streams and the private order stream
+func (c *BybitClient) Close() error {
+	c.BybitTradeClient.Close()
+	return c.BybitStreamClient.Close()
+}
+
+var klineIntervals = map[string]string{
+	"1m":   "1",
+	"3m":   "3",
+	"5m":   "5",
+	"15m":  "15",
+	"30m":  "30",
+	"1h":   "60",
+	"2h":   "120",
+	"4h":   "240",
+	"6h":   "360",
+	"12h":  "720",
+	"1d":   "D",
+	"1w":   "W",
+	"1mth": "M",
+}
+
+// getCategory maps a pair onto the v5 category: spot, linear (USDT/USDC margined) or inverse (coin margined)
+func getCategory(pair model.QuotesPair) (string, error) {
+	return categoryOf(pair.Category)
+}
+
+func categoryOf(category trade.Category) (string, error) {
+	switch category {
+	case trade.SPOT, "":
+		return "spot", nil
+	case trade.FUTURES:
+		return "linear", nil
+	case trade.INVERSE:
+		return "inverse", nil
+	default:
+		return "", errInvalidPair
+	}
+}
+
+// getSymbol returns the venue symbol, inverse perpetuals are quoted in USD
+func getSymbol(pair model.QuotesPair) string {
+	if pair.Category == trade.INVERSE {
+		return pair.Base.String() + "USD"
+	}
+	return pair.Base.String() + pair.Quote.String()
+}
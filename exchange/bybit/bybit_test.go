@@ -0,0 +1,654 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+package bybit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/shopspring/decimal"
+	"github.com/wang900115/quant/exchange/rest"
+	"github.com/wang900115/quant/exchange/stream"
+	"github.com/wang900115/quant/model"
+	"github.com/wang900115/quant/model/currency"
+	"github.com/wang900115/quant/model/trade"
+)
+
+const (
+	testAPIKey    = "test-key"
+	testSecretKey = "test-secret"
+)
+
+// fakeBybit serves canned v5 REST responses and scripted public and private streams
+type fakeBybit struct {
+	*httptest.Server
+	t *testing.T
+
+	mu       sync.Mutex
+	requests map[string]*http.Request
+	bodies   map[string]string
+	rest     map[string]string
+	// categories records the category of every REST request in order
+	categories []string
+
+	// public is sent on a public stream after a subscription, keyed by category
+	public map[string][]string
+	// private is sent on the private stream after the order subscription
+	private []string
+	// dropPrivate closes the first private stream after its frames, resumed is sent on the ones after it
+	dropPrivate  bool
+	resumed      []string
+	privateConns int
+}
+
+func newFakeBybit(t *testing.T) *fakeBybit {
+	f := &fakeBybit{
+		t:        t,
+		requests: make(map[string]*http.Request),
+		bodies:   make(map[string]string),
+		rest:     make(map[string]string),
+		public:   make(map[string][]string),
+	}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(f.Close)
+	return f
+}
+
+func (f *fakeBybit) config() BybitConfig {
+	wsURL := "ws" + strings.TrimPrefix(f.URL, "http")
+	return BybitConfig{
+		RestEndpoint:      f.URL,
+		PublicWsEndpoint:  wsURL + "/v5/public",
+		PrivateWsEndpoint: wsURL + "/v5/private",
+		APIKey:            testAPIKey,
+		SecretKey:         testSecretKey,
+	}
+}
+
+func (f *fakeBybit) serve(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/v5/public/"):
+		f.servePublic(w, r, strings.TrimPrefix(r.URL.Path, "/v5/public/"))
+		return
+	case r.URL.Path == "/v5/private":
+		f.servePrivate(w, r)
+		return
+	}
+	body, _ := io.ReadAll(r.Body)
+	category := r.URL.Query().Get("category")
+	if category == "" {
+		var sent map[string]string
+		json.Unmarshal(body, &sent)
+		category = sent["category"]
+	}
+	f.mu.Lock()
+	f.requests[r.URL.Path] = r
+	f.bodies[r.URL.Path] = string(body)
+	f.categories = append(f.categories, category)
+	// a payload keyed by path?category=<category> answers requests for that category only
+	payload, ok := f.rest[r.URL.Path+"?category="+category]
+	if !ok {
+		payload, ok = f.rest[r.URL.Path]
+	}
+	f.mu.Unlock()
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	io.WriteString(w, payload)
+}
+
+// takeCategories returns the categories requested since the last call, comma separated
+func (f *fakeBybit) takeCategories() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	got := strings.Join(f.categories, ",")
+	f.categories = nil
+	return got
+}
+
+var upgrader = websocket.Upgrader{}
+
+func (f *fakeBybit) servePublic(w http.ResponseWriter, r *http.Request, category string) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	for {
+		var msg struct {
+			ReqID string          `json:"req_id"`
+			Op    string          `json:"op"`
+			Args  json.RawMessage `json:"args"`
+		}
+		if err := conn.ReadJSON(&msg); err != nil {
+			return
+		}
+		if msg.Op != "subscribe" && msg.Op != "unsubscribe" {
+			continue
+		}
+		if strings.Contains(string(msg.Args), "UNKNOWN") {
+			conn.WriteJSON(map[string]interface{}{"success": false, "ret_msg": "error:handler not found", "op": msg.Op, "req_id": msg.ReqID})
+			continue
+		}
+		conn.WriteJSON(map[string]interface{}{"success": true, "ret_msg": "", "op": msg.Op, "req_id": msg.ReqID})
+		if msg.Op != "subscribe" {
+			continue
+		}
+		f.mu.Lock()
+		frames := f.public[category]
+		f.mu.Unlock()
+		for _, frame := range frames {
+			conn.WriteMessage(websocket.TextMessage, []byte(frame))
+		}
+	}
+}
+
+func (f *fakeBybit) servePrivate(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	var auth struct {
+		Op   string        `json:"op"`
+		Args []interface{} `json:"args"`
+	}
+	if err := conn.ReadJSON(&auth); err != nil || auth.Op != "auth" || len(auth.Args) != 3 {
+		return
+	}
+	expires := int64(auth.Args[1].(float64))
+	want := (&BybitTradeClient{secretKey: testSecretKey}).signature("GET/realtime" + decimal.NewFromInt(expires).String())
+	conn.WriteJSON(map[string]interface{}{"success": auth.Args[0] == testAPIKey && auth.Args[2] == want, "op": "auth"})
+
+	var sub struct {
+		Op string `json:"op"`
+	}
+	if err := conn.ReadJSON(&sub); err != nil || sub.Op != "subscribe" {
+		return
+	}
+	f.mu.Lock()
+	f.privateConns++
+	frames, drop := f.private, f.dropPrivate && f.privateConns == 1
+	if f.privateConns > 1 {
+		frames = f.resumed
+	}
+	f.mu.Unlock()
+	for _, frame := range frames {
+		conn.WriteMessage(websocket.TextMessage, []byte(frame))
+	}
+	if drop {
+		return
+	}
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+func (f *fakeBybit) lastRequest(path string) (*http.Request, string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.requests[path], f.bodies[path]
+}
+
+var btcusdt = model.QuotesPair{
+	ExchangeID: model.BYBIT,
+	Base:       currency.BTCSymbol,
+	Quote:      currency.USDTSymbol,
+	Category:   trade.SPOT,
+}
+
+func TestBybitSingleClient_GetPrice(t *testing.T) {
+	f := newFakeBybit(t)
+	f.rest["/v5/market/tickers"] = `{"retCode":0,"retMsg":"OK","result":{"category":"linear","list":[{"symbol":"BTCUSDT","lastPrice":"64123.5"}]},"time":1700000000000}`
+
+	pair := btcusdt
+	pair.Category = trade.FUTURES
+	p, err := NewSingleClient(f.config()).GetPrice(context.Background(), pair)
+	if err != nil {
+		t.Fatalf("GetPrice failed: %v", err)
+	}
+	if !p.NewPrice.Equal(decimal.RequireFromString("64123.5")) || !p.UpdatedAt.Equal(time.UnixMilli(1700000000000)) {
+		t.Errorf("unexpected price point: %+v", p)
+	}
+	req, _ := f.lastRequest("/v5/market/tickers")
+	if got := req.URL.Query().Get("category"); got != "linear" {
+		t.Errorf("expected linear category for futures, got %s", got)
+	}
+}
+
+func TestBybitSingleClient_InversePairs(t *testing.T) {
+	f := newFakeBybit(t)
+	f.rest["/v5/market/tickers"] = `{"retCode":0,"result":{"list":[{"symbol":"BTCUSD","lastPrice":"64000"}]},"time":1}`
+
+	pair := btcusdt
+	pair.Category = trade.INVERSE
+	if _, err := NewSingleClient(f.config()).GetPrice(context.Background(), pair); err != nil {
+		t.Fatalf("GetPrice failed: %v", err)
+	}
+	req, _ := f.lastRequest("/v5/market/tickers")
+	if q := req.URL.Query(); q.Get("category") != "inverse" || q.Get("symbol") != "BTCUSD" {
+		t.Errorf("expected inverse BTCUSD, got %s", req.URL.RawQuery)
+	}
+}
+
+func TestBybitSingleClient_GetKlines(t *testing.T) {
+	f := newFakeBybit(t)
+	f.rest["/v5/market/kline"] = `{"retCode":0,"result":{"list":[
+		["1700000060000","101","103","100","102","5","510"],
+		["1700000000000","100","102","99","101","4","404"]]},"time":1}`
+
+	klines, err := NewSingleClient(f.config()).GetKlines(context.Background(), btcusdt, "1m", 2)
+	if err != nil {
+		t.Fatalf("GetKlines failed: %v", err)
+	}
+	if len(klines) != 2 {
+		t.Fatalf("expected 2 klines, got %d", len(klines))
+	}
+	if !klines[0].OpeningPrice.Equal(decimal.NewFromInt(100)) || !klines[1].ClosingPrice.Equal(decimal.NewFromInt(102)) {
+		t.Errorf("expected klines oldest first, got %v", klines)
+	}
+	if klines[0].IntervalDuration != time.Minute {
+		t.Errorf("expected 1m duration, got %v", klines[0].IntervalDuration)
+	}
+	req, _ := f.lastRequest("/v5/market/kline")
+	if got := req.URL.Query().Get("interval"); got != "1" {
+		t.Errorf("expected interval 1, got %s", got)
+	}
+
+	if _, err := NewSingleClient(f.config()).GetKlines(context.Background(), btcusdt, "7m", 2); !errors.Is(err, errNotValidType) {
+		t.Errorf("expected errNotValidType for unsupported interval, got %v", err)
+	}
+}
+
+func TestBybitSingleClient_GetOrderBook(t *testing.T) {
+	f := newFakeBybit(t)
+	f.rest["/v5/market/orderbook"] = `{"retCode":0,"result":{"s":"BTCUSDT","b":[["100","1"],["99","2"]],"a":[["101","3"]],"ts":1700000000000,"u":5},"time":1}`
+
+	ob, err := NewSingleClient(f.config()).GetOrderBook(context.Background(), btcusdt, 2)
+	if err != nil {
+		t.Fatalf("GetOrderBook failed: %v", err)
+	}
+	if len(ob.Bids) != 2 || len(ob.Asks) != 1 || !ob.Bids[0].Price.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("unexpected order book: %+v", ob)
+	}
+}
+
+func TestBybitSingleClient_Error(t *testing.T) {
+	f := newFakeBybit(t)
+	f.rest["/v5/market/tickers"] = `{"retCode":10001,"retMsg":"params error: symbol invalid","result":{},"time":1}`
+
+	_, err := NewSingleClient(f.config()).GetPrice(context.Background(), btcusdt)
+	var e *rest.Error
+	if !errors.As(err, &e) {
+		t.Fatalf("expected *rest.Error, got %v", err)
+	}
+	if e.Code != "10001" || !errors.Is(err, rest.ErrInvalidSymbol) {
+		t.Errorf("unexpected error: %+v", e)
+	}
+}
+
+func TestBybitTradeClient_PlaceAndCancelOrder(t *testing.T) {
+	f := newFakeBybit(t)
+	f.rest["/v5/order/create"] = `{"retCode":0,"result":{"orderId":"o-1","orderLinkId":"c-1"},"time":1}`
+	f.rest["/v5/order/cancel"] = `{"retCode":0,"result":{"orderId":"o-1"},"time":1}`
+
+	cfg := f.config()
+	cfg.APIKey = "" // REST only
+	client, err := NewTradeClient(cfg)
+	if err != nil {
+		t.Fatalf("NewTradeClient failed: %v", err)
+	}
+	client.apiKey = testAPIKey
+	defer client.Close()
+
+	res, err := client.PlaceOrder(context.Background(), model.OrderRequest{
+		Symbol:        "BTCUSDT",
+		Side:          trade.SELL,
+		Type:          trade.LIMIT,
+		Price:         decimal.NewFromInt(70000),
+		Quantity:      decimal.RequireFromString("0.01"),
+		TimeInForce:   trade.GTC,
+		ClientOrderID: "c-1",
+		Category:      trade.FUTURES,
+	})
+	if err != nil {
+		t.Fatalf("PlaceOrder failed: %v", err)
+	}
+	if res.OrderID != "o-1" || res.ClientOrderID != "c-1" {
+		t.Errorf("unexpected order result: %+v", res)
+	}
+
+	req, body := f.lastRequest("/v5/order/create")
+	payload := req.Header.Get("X-BAPI-TIMESTAMP") + testAPIKey + req.Header.Get("X-BAPI-RECV-WINDOW") + body
+	if got, want := req.Header.Get("X-BAPI-SIGN"), client.signature(payload); got != want {
+		t.Errorf("signature mismatch: got %s want %s", got, want)
+	}
+	var sent map[string]string
+	json.Unmarshal([]byte(body), &sent)
+	if sent["category"] != "linear" || sent["side"] != "Sell" || sent["orderType"] != "Limit" || sent["price"] != "70000" {
+		t.Errorf("unexpected order payload: %v", sent)
+	}
+
+	if err := client.CancelOrder(context.Background(), "BTCUSDT", "o-1"); err != nil {
+		t.Fatalf("CancelOrder failed: %v", err)
+	}
+	_, body = f.lastRequest("/v5/order/cancel")
+	json.Unmarshal([]byte(body), &sent)
+	if sent["category"] != "linear" {
+		t.Errorf("expected cancel to reuse the order category, got %v", sent)
+	}
+}
+
+func TestBybitTradeClient_UnknownOrderTriesEveryCategory(t *testing.T) {
+	f := newFakeBybit(t)
+	notFound := `{"retCode":110001,"retMsg":"order not exists or too late to cancel","result":{},"time":1}`
+	f.rest["/v5/order/cancel"] = notFound
+	f.rest["/v5/order/cancel?category=inverse"] = `{"retCode":0,"result":{"orderId":"o-9"},"time":1}`
+	f.rest["/v5/order/realtime"] = `{"retCode":0,"result":{"list":[]},"time":1}`
+	f.rest["/v5/order/realtime?category=inverse"] = `{"retCode":0,"result":{"list":[{"orderId":"o-9","symbol":"BTCUSD","price":"60000","qty":"100","cumExecQty":"0","orderStatus":"New","side":"Buy","orderType":"Limit","updatedTime":"1"}]},"time":1}`
+
+	cfg := f.config()
+	cfg.APIKey = ""
+	client, err := NewTradeClient(cfg)
+	if err != nil {
+		t.Fatalf("NewTradeClient failed: %v", err)
+	}
+	defer client.Close()
+
+	// an order placed before a restart is found in the category that knows it
+	if err := client.CancelOrder(context.Background(), "BTCUSD", "o-9"); err != nil {
+		t.Fatalf("CancelOrder failed: %v", err)
+	}
+	if got := f.takeCategories(); got != "spot,linear,inverse" {
+		t.Errorf("expected the cancel to try every category, got %s", got)
+	}
+	if od, err := client.GetOrder(context.Background(), "BTCUSD", "o-9"); err != nil || od.OrderID != "o-9" {
+		t.Fatalf("GetOrder failed: %v", err)
+	}
+	if got := f.takeCategories(); got != "inverse" {
+		t.Errorf("expected the found category to be remembered, got %s", got)
+	}
+
+	f.mu.Lock()
+	delete(f.rest, "/v5/order/realtime?category=inverse")
+	f.mu.Unlock()
+	if _, err := client.GetOrder(context.Background(), "BTCUSD", "o-10"); !errors.Is(err, rest.ErrOrderNotFound) {
+		t.Errorf("expected ErrOrderNotFound after every category, got %v", err)
+	}
+}
+
+func TestBybitTradeClient_UnknownOrderSkipsCategoriesWithoutTheSymbol(t *testing.T) {
+	f := newFakeBybit(t)
+	// spot does not list linear symbols and rejects them before looking for the order
+	invalid := `{"retCode":10001,"retMsg":"params error: symbol invalid","result":{},"time":1}`
+	f.rest["/v5/order/realtime?category=spot"] = invalid
+	f.rest["/v5/order/realtime?category=inverse"] = invalid
+	f.rest["/v5/order/cancel?category=spot"] = `{"retCode":170121,"retMsg":"Invalid symbol.","result":{},"time":1}`
+	f.rest["/v5/order/realtime?category=linear"] = `{"retCode":0,"result":{"list":[{"orderId":"o-11","symbol":"ETHUSDT","price":"3000","qty":"1","cumExecQty":"0","orderStatus":"New","side":"Sell","orderType":"Limit","updatedTime":"1"}]},"time":1}`
+	f.rest["/v5/order/cancel?category=linear"] = `{"retCode":0,"result":{"orderId":"o-12"},"time":1}`
+
+	cfg := f.config()
+	cfg.APIKey = ""
+	client, err := NewTradeClient(cfg)
+	if err != nil {
+		t.Fatalf("NewTradeClient failed: %v", err)
+	}
+	defer client.Close()
+
+	if od, err := client.GetOrder(context.Background(), "ETHUSDT", "o-11"); err != nil || od.OrderID != "o-11" {
+		t.Fatalf("GetOrder failed: %v", err)
+	}
+	if got := f.takeCategories(); got != "spot,linear" {
+		t.Errorf("expected the lookup to move on from spot, got %s", got)
+	}
+	if err := client.CancelOrder(context.Background(), "ETHUSDT", "o-12"); err != nil {
+		t.Fatalf("CancelOrder failed: %v", err)
+	}
+	if got := f.takeCategories(); got != "spot,linear" {
+		t.Errorf("expected the cancel to move on from spot, got %s", got)
+	}
+
+	// linear knows the symbol but not the order, which is what is reported
+	f.mu.Lock()
+	f.rest["/v5/order/realtime?category=linear"] = `{"retCode":0,"result":{"list":[]},"time":1}`
+	f.mu.Unlock()
+	if _, err := client.GetOrder(context.Background(), "ETHUSDT", "o-13"); !errors.Is(err, rest.ErrOrderNotFound) {
+		t.Errorf("expected ErrOrderNotFound, got %v", err)
+	}
+}
+
+func TestBybitTradeClient_InsufficientBalance(t *testing.T) {
+	f := newFakeBybit(t)
+	f.rest["/v5/order/create"] = `{"retCode":170131,"retMsg":"Insufficient balance.","result":{},"time":1}`
+
+	cfg := f.config()
+	cfg.APIKey = ""
+	client, _ := NewTradeClient(cfg)
+	defer client.Close()
+
+	_, err := client.PlaceOrder(context.Background(), model.OrderRequest{
+		Symbol:   "BTCUSDT",
+		Side:     trade.BUY,
+		Type:     trade.MARKET,
+		Quantity: decimal.NewFromInt(1),
+	})
+	if !errors.Is(err, rest.ErrInsufficientBalance) {
+		t.Errorf("expected ErrInsufficientBalance, got %v", err)
+	}
+	_, body := f.lastRequest("/v5/order/create")
+	if !strings.Contains(body, `"marketUnit":"baseCoin"`) {
+		t.Errorf("expected spot market order sized in base coin, got %s", body)
+	}
+}
+
+func TestBybitTradeClient_GetAssetBalance(t *testing.T) {
+	f := newFakeBybit(t)
+	f.rest["/v5/account/wallet-balance"] = `{"retCode":0,"result":{"list":[{"coin":[{"coin":"USDT","walletBalance":"1500","locked":"500"}]}]},"time":1}`
+
+	cfg := f.config()
+	cfg.APIKey = ""
+	client, _ := NewTradeClient(cfg)
+	defer client.Close()
+
+	balance, err := client.GetAssetBalance(context.Background(), "usdt")
+	if err != nil {
+		t.Fatalf("GetAssetBalance failed: %v", err)
+	}
+	if !balance.Free.Equal(decimal.NewFromInt(1000)) || !balance.Locked.Equal(decimal.NewFromInt(500)) {
+		t.Errorf("unexpected balance: %+v", balance)
+	}
+}
+
+func TestBybitTradeClient_OrderStream(t *testing.T) {
+	f := newFakeBybit(t)
+	f.private = []string{
+		`{"topic":"order","data":[{"category":"spot","symbol":"BTCUSDT","orderId":"o-1","side":"Buy","orderType":"Limit","orderStatus":"PartiallyFilled","cumExecQty":"0.4","updatedTime":"1700000000000"}]}`,
+		`{"topic":"order","data":[{"category":"spot","symbol":"BTCUSDT","orderId":"o-1","side":"Buy","orderType":"Limit","orderStatus":"Filled","cumExecQty":"1","updatedTime":"1700000001000"}]}`,
+	}
+
+	client, err := NewTradeClient(f.config())
+	if err != nil {
+		t.Fatalf("NewTradeClient failed: %v", err)
+	}
+	defer client.Close()
+
+	want := []struct {
+		status trade.Status
+		last   string
+	}{
+		{trade.PARTIALLY_FILLED, "0.4"},
+		{trade.FILLED, "0.6"},
+	}
+	for _, w := range want {
+		select {
+		case evt := <-client.OrderEvents():
+			if evt.Status != w.status || !evt.LastQty.Equal(decimal.RequireFromString(w.last)) || evt.Side != trade.BUY {
+				t.Errorf("unexpected order event: %+v", evt)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for order event")
+		}
+	}
+}
+
+func TestBybitTradeClient_OrderStreamReconnects(t *testing.T) {
+	f := newFakeBybit(t)
+	f.private = []string{
+		`{"topic":"order","data":[{"category":"spot","symbol":"BTCUSDT","orderId":"o-1","side":"Buy","orderType":"Limit","orderStatus":"PartiallyFilled","cumExecQty":"0.4","updatedTime":"1700000000000"}]}`,
+	}
+	f.dropPrivate = true
+	f.resumed = []string{
+		`{"topic":"order","data":[{"category":"spot","symbol":"BTCUSDT","orderId":"o-1","side":"Buy","orderType":"Limit","orderStatus":"Filled","cumExecQty":"1","updatedTime":"1700000001000"}]}`,
+	}
+
+	cfg := f.config()
+	cfg.RetryInterval = 10 * time.Millisecond
+	client, err := NewTradeClient(cfg)
+	if err != nil {
+		t.Fatalf("NewTradeClient failed: %v", err)
+	}
+	defer client.Close()
+
+	// the fill after the reconnect is measured against the one before it
+	for _, last := range []string{"0.4", "0.6"} {
+		select {
+		case evt := <-client.OrderEvents():
+			if !evt.LastQty.Equal(decimal.RequireFromString(last)) {
+				t.Errorf("expected last quantity %s, got %+v", last, evt)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for order event")
+		}
+	}
+}
+
+func TestBybitTradeClient_AuthFailure(t *testing.T) {
+	f := newFakeBybit(t)
+	cfg := f.config()
+	cfg.SecretKey = "wrong"
+	if _, err := NewTradeClient(cfg); err == nil {
+		t.Error("expected an error when the private stream rejects the signature")
+	}
+}
+
+func TestBybitStreamClient_Dispatch(t *testing.T) {
+	f := newFakeBybit(t)
+	f.public["spot"] = []string{
+		`{"topic":"tickers.BTCUSDT","type":"snapshot","ts":1700000000000,"data":{"symbol":"BTCUSDT","lastPrice":"64000.1"}}`,
+		`{"topic":"orderbook.50.BTCUSDT","type":"snapshot","ts":1700000000001,"data":{"s":"BTCUSDT","b":[["100","1"],["99","2"]],"a":[["101","1"]],"u":10}}`,
+		`{"topic":"orderbook.50.BTCUSDT","type":"delta","ts":1700000000002,"data":{"s":"BTCUSDT","b":[["100","0"],["98","5"]],"a":[["100.5","2"]],"u":11}}`,
+		`{"topic":"kline.1.BTCUSDT","type":"snapshot","ts":1700000000003,"data":[{"start":1699999980000,"end":1700000039999,"interval":"1","open":"1","close":"2","high":"3","low":"0.5","volume":"10","confirm":false}]}`,
+	}
+
+	client, err := NewStreamClient(f.config())
+	if err != nil {
+		t.Fatalf("NewStreamClient failed: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go client.Dispatch(ctx)
+
+	if err := client.SubscribeStream(btcusdt, []string{"tickers", "orderbook.50", "kline.1"}); err != nil {
+		t.Fatalf("SubscribeStream failed: %v", err)
+	}
+	prices, intervals, books := client.ReceiveStream()
+
+	select {
+	case p := <-prices:
+		if !p.NewPrice.Equal(decimal.RequireFromString("64000.1")) {
+			t.Errorf("unexpected price: %v", p.NewPrice)
+		}
+		if !p.Trace.EventTime.Equal(time.UnixMilli(1700000000000)) {
+			t.Errorf("expected the venue time as event time, got %v", p.Trace.EventTime)
+		}
+		if p.Trace.ReceivedAt.IsZero() || p.Trace.DispatchedAt.Before(p.Trace.ReceivedAt) {
+			t.Errorf("expected receive then dispatch stamps, got %+v", p.Trace)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for ticker")
+	}
+
+	var ob model.OrderBook
+	for i := 0; i < 2; i++ {
+		select {
+		case ob = <-books:
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for order book")
+		}
+	}
+	if len(ob.Bids) != 2 || !ob.Bids[0].Price.Equal(decimal.NewFromInt(99)) || !ob.Bids[1].Price.Equal(decimal.NewFromInt(98)) {
+		t.Errorf("expected delta to remove 100 and add 98, got bids %+v", ob.Bids)
+	}
+	if len(ob.Asks) != 2 || !ob.Asks[0].Price.Equal(decimal.RequireFromString("100.5")) {
+		t.Errorf("expected asks sorted best first, got %+v", ob.Asks)
+	}
+
+	select {
+	case k := <-intervals:
+		if k.IntervalDuration != time.Minute {
+			t.Errorf("expected 1m kline, got %v", k.IntervalDuration)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for kline")
+	}
+
+	cancel()
+	if err := client.Close(); err != nil {
+		t.Errorf("Close failed: %v", err)
+	}
+}
+
+func TestBybitStreamClient_Subscriptions(t *testing.T) {
+	f := newFakeBybit(t)
+	client, err := NewStreamClient(f.config())
+	if err != nil {
+		t.Fatalf("NewStreamClient failed: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go client.Dispatch(ctx)
+
+	if err := client.SubscribeStream(btcusdt, []string{"tickers", "orderbook.50"}); err != nil {
+		t.Fatalf("SubscribeStream failed: %v", err)
+	}
+	unknown := btcusdt
+	unknown.Base = "UNKNOWN"
+	err = client.SubscribeStream(unknown, []string{"tickers"})
+	var e *stream.Error
+	if !errors.As(err, &e) || e.Message != "error:handler not found" {
+		t.Errorf("expected the rejection to surface as *stream.Error, got %v", err)
+	}
+
+	if err := client.UnsubscribeStream(btcusdt, []string{"orderbook.50"}); err != nil {
+		t.Fatalf("UnsubscribeStream failed: %v", err)
+	}
+	subs := client.Subscriptions()
+	if len(subs) != 1 || subs[0] != (model.Subscription{Pair: btcusdt, Channel: "tickers"}) {
+		t.Errorf("expected only the ticker subscription, got %+v", subs)
+	}
+
+	cancel()
+	client.Close()
+}
@@ -0,0 +1,104 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+package bybit
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wang900115/quant/exchange/rest"
+)
+
+const requestIDHeader = "Traceid"
+
+// readResult decodes the result of a v5 response into v and returns the server time.
+// v5 reports most failures as HTTP 200 with a non-zero retCode, so both are checked.
+func readResult(resp *http.Response, v any) (time.Time, error) {
+	if resp.StatusCode != http.StatusOK {
+		return time.Time{}, newError(resp, rest.ReadBody(resp))
+	}
+	var envelope struct {
+		RetCode int             `json:"retCode"`
+		RetMsg  string          `json:"retMsg"`
+		Result  json.RawMessage `json:"result"`
+		Time    int64           `json:"time"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return time.Time{}, err
+	}
+	if envelope.RetCode != 0 {
+		e := rest.NewError("bybit", resp, requestIDHeader)
+		e.Code = strconv.Itoa(envelope.RetCode)
+		e.Message = envelope.RetMsg
+		classifyError(e, envelope.RetCode)
+		return time.Time{}, e
+	}
+	if v != nil && len(envelope.Result) > 0 {
+		if err := json.Unmarshal(envelope.Result, v); err != nil {
+			return time.Time{}, err
+		}
+	}
+	return time.UnixMilli(envelope.Time), nil
+}
+
+// newError parses a non-200 response, which may or may not carry the v5 envelope
+func newError(resp *http.Response, body []byte) error {
+	e := rest.NewError("bybit", resp, requestIDHeader)
+	var payload struct {
+		RetCode int    `json:"retCode"`
+		RetMsg  string `json:"retMsg"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil || payload.RetCode == 0 {
+		e.Message = strings.TrimSpace(string(body))
+		return e
+	}
+	e.Code = strconv.Itoa(payload.RetCode)
+	e.Message = payload.RetMsg
+	classifyError(e, payload.RetCode)
+	return e
+}
+
+func classifyError(e *rest.Error, code int) {
+	if kind := classify(code, e.Message); kind != rest.KindUnknown {
+		e.Classify(kind)
+	}
+	// a request outside recv_window succeeds once re-signed
+	if code == 10002 {
+		e.Retryable = true
+	}
+}
+
+func classify(code int, msg string) rest.Kind {
+	switch code {
+	case 10006, 10018:
+		return rest.KindRateLimited
+	case 10000, 10016:
+		return rest.KindUnavailable
+	case 10003, 10004, 10005, 10007, 33004:
+		return rest.KindUnauthorized
+	case 110004, 110007, 110012, 170131:
+		return rest.KindInsufficientBalance
+	case 110001, 170213:
+		return rest.KindOrderNotFound
+	case 170121:
+		return rest.KindInvalidSymbol
+	case 10001:
+		if strings.Contains(strings.ToLower(msg), "symbol") {
+			return rest.KindInvalidSymbol
+		}
+	}
+	return rest.KindUnknown
+}
@@ -0,0 +1,79 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+package bybit
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/wang900115/quant/exchange/rest"
+)
+
+// Bybit limits every IP to 600 requests per 5 seconds, and each account per endpoint per second
+const (
+	ipBucket          = "ip"
+	placeOrderBucket  = "order/create"
+	queryOrderBucket  = "order/realtime"
+	cancelOrderBucket = "order/cancel"
+	balanceBucket     = "account/wallet-balance"
+)
+
+const (
+	limitHeader       = "X-Bapi-Limit"
+	limitStatusHeader = "X-Bapi-Limit-Status"
+)
+
+var (
+	publicCost      = rest.Cost{Bucket: ipBucket, Weight: 1}
+	placeOrderCost  = []rest.Cost{publicCost, {Bucket: placeOrderBucket, Weight: 1}}
+	queryOrderCost  = []rest.Cost{publicCost, {Bucket: queryOrderBucket, Weight: 1}}
+	cancelOrderCost = []rest.Cost{publicCost, {Bucket: cancelOrderBucket, Weight: 1}}
+	balanceCost     = []rest.Cost{publicCost, {Bucket: balanceBucket, Weight: 1}}
+)
+
+// NewScheduler creates the request scheduler shared by the Bybit REST clients
+func NewScheduler() *rest.Scheduler {
+	return rest.NewScheduler(rest.Config{
+		Buckets: []rest.Bucket{
+			{Name: ipBucket, Capacity: 600, Window: 5 * time.Second},
+			{Name: placeOrderBucket, Capacity: 10, Window: time.Second},
+			{Name: queryOrderBucket, Capacity: 50, Window: time.Second},
+			{Name: cancelOrderBucket, Capacity: 10, Window: time.Second},
+			{Name: balanceBucket, Capacity: 50, Window: time.Second},
+		},
+		Observe: observeLimitStatus,
+	})
+}
+
+// observeLimitStatus syncs the per-endpoint bucket with X-Bapi-Limit and X-Bapi-Limit-Status
+func observeLimitStatus(s *rest.Scheduler, resp *http.Response) {
+	limit, err := strconv.Atoi(resp.Header.Get(limitHeader))
+	if err != nil {
+		return
+	}
+	remaining, err := strconv.Atoi(resp.Header.Get(limitStatusHeader))
+	if err != nil {
+		return
+	}
+	switch resp.Request.URL.Path {
+	case "/v5/order/create":
+		s.Sync(placeOrderBucket, limit-remaining)
+	case "/v5/order/realtime":
+		s.Sync(queryOrderBucket, limit-remaining)
+	case "/v5/order/cancel":
+		s.Sync(cancelOrderBucket, limit-remaining)
+	case "/v5/account/wallet-balance":
+		s.Sync(balanceBucket, limit-remaining)
+	}
+}
@@ -0,0 +1,554 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+package bybit
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/wang900115/quant/common/sys"
+	"github.com/wang900115/quant/exchange/rest"
+	"github.com/wang900115/quant/exchange/stream"
+	"github.com/wang900115/quant/model"
+	"github.com/wang900115/quant/model/currency"
+	"github.com/wang900115/quant/model/trade"
+)
+
+type BybitTradeClient struct {
+	client    *http.Client
+	scheduler *rest.Scheduler
+	endpoint  string
+	ws        *stream.Conn
+
+	engine       *sys.Engine
+	eventChan    chan model.OrderEvent
+	pingInterval time.Duration
+	// filled is the quantity each open order had filled, kept across reconnects of the private stream
+	filled map[string]decimal.Decimal
+	closed atomic.Bool
+
+	// categories remembers where each order was placed, GetOrder and CancelOrder only receive the symbol
+	// and try every category for orders placed before a restart or by another process
+	mu         sync.Mutex
+	categories map[string]string
+
+	apiKey     string
+	secretKey  string
+	recvWindow time.Duration
+}
+
+// NewTradeClient creates the signed v5 client, the private order stream is only opened when an API key is set
+func NewTradeClient(cfg BybitConfig) (*BybitTradeClient, error) {
+	if cfg.PrivateTimeout == 0 {
+		cfg.PrivateTimeout = defaultTradeTimeout
+	}
+	if cfg.BufferSize == 0 {
+		cfg.BufferSize = defaultBufferSize
+	}
+	if cfg.RecvWindow == 0 {
+		cfg.RecvWindow = defaultRecvWindow
+	}
+	if cfg.PingInterval == 0 {
+		cfg.PingInterval = defaultPingInterval
+	}
+	if cfg.Scheduler == nil {
+		cfg.Scheduler = NewScheduler()
+	}
+	b := &BybitTradeClient{
+		client:       &http.Client{Timeout: cfg.PrivateTimeout},
+		scheduler:    cfg.Scheduler,
+		endpoint:     cfg.restEndpoint(),
+		engine:       sys.NewEngine(cfg.RetryInterval, cfg.HealthCheckInterval),
+		eventChan:    make(chan model.OrderEvent, cfg.BufferSize),
+		pingInterval: cfg.PingInterval,
+		categories:   make(map[string]string),
+		filled:       make(map[string]decimal.Decimal),
+		apiKey:       cfg.APIKey,
+		secretKey:    cfg.SecretKey,
+		recvWindow:   cfg.RecvWindow,
+	}
+	if cfg.APIKey == "" {
+		return b, nil
+	}
+	if err := b.connect(cfg.privateWsEndpoint()); err != nil {
+		return nil, errInitFailed
+	}
+	return b, nil
+}
+
+// connect opens the private stream and supervises it, a dropped stream is dialled, authenticated and subscribed again
+func (bt *BybitTradeClient) connect(endpoint string) error {
+	ws, err := stream.Dial(endpoint)
+	if err != nil {
+		return err
+	}
+	if err := bt.subscribe(ws); err != nil {
+		ws.Close()
+		return err
+	}
+
+	bt.ws = ws
+	started := false
+	bt.engine.Supervise(sys.Task{Name: "bybit.orders", Run: func(context.Context) error {
+		if started {
+			if err := bt.reconnect(); err != nil {
+				return err
+			}
+		}
+		started = true
+		return bt.listen()
+	}})
+	bt.engine.Supervise(sys.Task{Name: "bybit.keepAlive", Run: bt.keepAlive})
+	return nil
+}
+
+// subscribe authenticates ws and subscribes to order updates
+func (bt *BybitTradeClient) subscribe(ws *stream.Conn) error {
+	expires := time.Now().Add(10 * time.Second).UnixMilli()
+	signature := bt.signature(fmt.Sprintf("GET/realtime%d", expires))
+	if err := ws.WriteJSON(map[string]interface{}{
+		"op":   "auth",
+		"args": []interface{}{bt.apiKey, expires, signature},
+	}); err != nil {
+		return err
+	}
+	for {
+		var ack struct {
+			Success bool   `json:"success"`
+			RetMsg  string `json:"ret_msg"`
+			Op      string `json:"op"`
+		}
+		if err := ws.ReadJSON(&ack); err != nil {
+			return err
+		}
+		// a ping sent by keepAlive on a redialled stream may be answered first
+		if ack.Op == "pong" || ack.Op == "ping" {
+			continue
+		}
+		if ack.Op != "auth" || !ack.Success {
+			return errAuthFailed
+		}
+		break
+	}
+	return ws.WriteJSON(map[string]interface{}{
+		"op":   "subscribe",
+		"args": []string{"order"},
+	})
+}
+
+// reconnect dials the private stream again after listen failed
+func (bt *BybitTradeClient) reconnect() error {
+	if bt.closed.Load() {
+		return nil
+	}
+	if err := bt.ws.Redial(); err != nil {
+		return err
+	}
+	return bt.subscribe(bt.ws)
+}
+
+// OrderEvents streams updates of the account's orders from the private stream
+func (bt *BybitTradeClient) OrderEvents() <-chan model.OrderEvent {
+	return bt.eventChan
+}
+
+func (bt *BybitTradeClient) PlaceOrder(ctx context.Context, req model.OrderRequest) (*model.OrderResult, error) {
+	category, err := categoryOf(req.Category)
+	if err != nil {
+		return nil, err
+	}
+	data := map[string]string{
+		"category":  category,
+		"symbol":    strings.ToUpper(req.Symbol),
+		"side":      toBybitSide(req.Side),
+		"orderType": toBybitType(req.Type),
+		"qty":       req.Quantity.String(),
+	}
+	if req.Type == trade.LIMIT {
+		data["price"] = req.Price.String()
+		if req.TimeInForce != "" {
+			data["timeInForce"] = string(req.TimeInForce)
+		}
+	} else if category == "spot" {
+		// spot market orders are sized in the quote coin unless told otherwise
+		data["marketUnit"] = "baseCoin"
+	}
+	if req.ClientOrderID != "" {
+		data["orderLinkId"] = req.ClientOrderID
+	}
+
+	var result struct {
+		OrderID     string `json:"orderId"`
+		OrderLinkID string `json:"orderLinkId"`
+	}
+	if err := bt.post(ctx, "/v5/order/create", data, placeOrderCost, &result); err != nil {
+		return nil, err
+	}
+	if result.OrderID == "" {
+		return nil, errBybitNoData
+	}
+
+	bt.rememberCategory(result.OrderID, category)
+
+	return &model.OrderResult{
+		OrderID:       result.OrderID,
+		ClientOrderID: result.OrderLinkID,
+		Symbol:        req.Symbol,
+		Status:        trade.NEW,
+		ExecutedQty:   decimal.Zero,
+	}, nil
+}
+
+func (bt *BybitTradeClient) GetOrder(ctx context.Context, symbol string, orderID string) (*model.OrderDetail, error) {
+	var errs categoryErrors
+	for _, category := range bt.categoriesOfOrder(orderID) {
+		detail, err := bt.getOrder(ctx, category, symbol, orderID)
+		if err == nil {
+			bt.rememberCategory(orderID, category)
+			return detail, nil
+		}
+		if !errs.next(err) {
+			return nil, err
+		}
+	}
+	return nil, errs.err()
+}
+
+func (bt *BybitTradeClient) getOrder(ctx context.Context, category, symbol, orderID string) (*model.OrderDetail, error) {
+	query := url.Values{}
+	query.Set("category", category)
+	query.Set("symbol", strings.ToUpper(symbol))
+	query.Set("orderId", orderID)
+
+	var result struct {
+		List []struct {
+			OrderID     string `json:"orderId"`
+			Symbol      string `json:"symbol"`
+			Price       string `json:"price"`
+			Qty         string `json:"qty"`
+			CumExecQty  string `json:"cumExecQty"`
+			OrderStatus string `json:"orderStatus"`
+			Side        string `json:"side"`
+			OrderType   string `json:"orderType"`
+			UpdatedTime string `json:"updatedTime"`
+		} `json:"list"`
+	}
+	if err := bt.get(ctx, "/v5/order/realtime", query, queryOrderCost, &result); err != nil {
+		return nil, err
+	}
+	if len(result.List) == 0 {
+		return nil, &rest.Error{Exchange: "bybit", Status: http.StatusOK, Message: "order not found", Kind: rest.KindOrderNotFound}
+	}
+
+	od := result.List[0]
+	price, _ := decimal.NewFromString(od.Price)
+	origQty, _ := decimal.NewFromString(od.Qty)
+	executedQty, _ := decimal.NewFromString(od.CumExecQty)
+	updateTime, _ := strconv.ParseInt(od.UpdatedTime, 10, 64)
+	return &model.OrderDetail{
+		OrderID:     od.OrderID,
+		Symbol:      od.Symbol,
+		Price:       price,
+		OrigQty:     origQty,
+		ExecutedQty: executedQty,
+		Status:      bybitStatusToTradeStatus(od.OrderStatus),
+		Side:        fromBybitSide(od.Side),
+		Type:        trade.Type(strings.ToUpper(od.OrderType)),
+		UpdateTime:  updateTime,
+	}, nil
+}
+
+func (bt *BybitTradeClient) CancelOrder(ctx context.Context, symbol string, orderID string) error {
+	var errs categoryErrors
+	for _, category := range bt.categoriesOfOrder(orderID) {
+		data := map[string]string{
+			"category": category,
+			"symbol":   strings.ToUpper(symbol),
+			"orderId":  orderID,
+		}
+		err := bt.post(ctx, "/v5/order/cancel", data, cancelOrderCost, nil)
+		if err == nil {
+			bt.rememberCategory(orderID, category)
+			return nil
+		}
+		if !errs.next(err) {
+			return err
+		}
+	}
+	return errs.err()
+}
+
+// GetAssetBalance reads the unified trading account, which holds spot and derivatives collateral
+func (bt *BybitTradeClient) GetAssetBalance(ctx context.Context, asset string) (*model.AssetBalance, error) {
+	query := url.Values{}
+	query.Set("accountType", "UNIFIED")
+	query.Set("coin", strings.ToUpper(asset))
+
+	var result struct {
+		List []struct {
+			Coin []struct {
+				Coin          string `json:"coin"`
+				WalletBalance string `json:"walletBalance"`
+				Locked        string `json:"locked"`
+			} `json:"coin"`
+		} `json:"list"`
+	}
+	if err := bt.get(ctx, "/v5/account/wallet-balance", query, balanceCost, &result); err != nil {
+		return nil, err
+	}
+	for _, account := range result.List {
+		for _, c := range account.Coin {
+			if strings.EqualFold(c.Coin, asset) {
+				balance, _ := decimal.NewFromString(c.WalletBalance)
+				locked, _ := decimal.NewFromString(c.Locked)
+				return &model.AssetBalance{
+					Asset:  currency.CurrencySymbol(c.Coin),
+					Free:   balance.Sub(locked),
+					Locked: locked,
+				}, nil
+			}
+		}
+	}
+	return nil, errNonAssetFound
+}
+
+// Close stops the private order stream
+func (bt *BybitTradeClient) Close() error {
+	var err error
+	bt.closed.Store(true)
+	if bt.ws != nil {
+		err = bt.ws.Close()
+	}
+	bt.engine.Stop()
+	return err
+}
+
+func (bt *BybitTradeClient) get(ctx context.Context, path string, query url.Values, costs []rest.Cost, v any) error {
+	encoded := query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, bt.endpoint+path+"?"+encoded, nil)
+	if err != nil {
+		return err
+	}
+	bt.sign(req, encoded)
+	resp, err := bt.scheduler.Do(bt.client, req, costs...)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, err = readResult(resp, v)
+	return err
+}
+
+func (bt *BybitTradeClient) post(ctx context.Context, path string, data map[string]string, costs []rest.Cost, v any) error {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, bt.endpoint+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	bt.sign(req, string(body))
+	resp, err := bt.scheduler.Do(bt.client, req, costs...)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, err = readResult(resp, v)
+	return err
+}
+
+// sign adds the v5 headers, the signature covers timestamp + api key + recv window + query string or JSON body
+func (bt *BybitTradeClient) sign(req *http.Request, payload string) {
+	timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	recvWindow := strconv.FormatInt(bt.recvWindow.Milliseconds(), 10)
+	req.Header.Set("X-BAPI-API-KEY", bt.apiKey)
+	req.Header.Set("X-BAPI-TIMESTAMP", timestamp)
+	req.Header.Set("X-BAPI-RECV-WINDOW", recvWindow)
+	req.Header.Set("X-BAPI-SIGN", bt.signature(timestamp+bt.apiKey+recvWindow+payload))
+}
+
+func (bt *BybitTradeClient) signature(payload string) string {
+	mac := hmac.New(sha256.New, []byte(bt.secretKey))
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// categoriesOfOrder returns the category the order was placed in, every category when it is not known
+func (bt *BybitTradeClient) categoriesOfOrder(orderID string) []string {
+	bt.mu.Lock()
+	defer bt.mu.Unlock()
+	if category, ok := bt.categories[orderID]; ok {
+		return []string{category}
+	}
+	return []string{"spot", "linear", "inverse"}
+}
+
+func (bt *BybitTradeClient) rememberCategory(orderID, category string) {
+	bt.mu.Lock()
+	defer bt.mu.Unlock()
+	bt.categories[orderID] = category
+}
+
+// categoryErrors collects the answers of the categories that did not know an order
+type categoryErrors struct {
+	notFound, last error
+}
+
+// next records err and reports whether the next category should be tried.
+// A category that does not list the symbol answers with an invalid symbol, e.g. spot asked for BTCUSD.
+func (c *categoryErrors) next(err error) bool {
+	switch {
+	case errors.Is(err, rest.ErrOrderNotFound):
+		c.notFound = err
+	case errors.Is(err, rest.ErrInvalidSymbol):
+		c.last = err
+	default:
+		return false
+	}
+	return true
+}
+
+// err prefers a category that knew the symbol but not the order
+func (c *categoryErrors) err() error {
+	if c.notFound != nil {
+		return c.notFound
+	}
+	return c.last
+}
+
+func (bt *BybitTradeClient) keepAlive(ctx context.Context) error {
+	ticker := time.NewTicker(bt.pingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := bt.ws.WriteJSON(map[string]string{"op": "ping"}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// listen reads the private stream until it fails, the error restarts it through reconnect unless the client is closed
+func (bt *BybitTradeClient) listen() error {
+	for {
+		_, message, err := bt.ws.ReadMessage()
+		if err != nil {
+			if bt.closed.Load() {
+				return nil
+			}
+			return err
+		}
+		bt.handleMessage(message, bt.filled)
+	}
+}
+
+func (bt *BybitTradeClient) handleMessage(msg []byte, orderPrevFilled map[string]decimal.Decimal) {
+	var raw struct {
+		Topic string `json:"topic"`
+		Data  []struct {
+			Category    string `json:"category"`
+			Symbol      string `json:"symbol"`
+			OrderID     string `json:"orderId"`
+			Side        string `json:"side"`
+			OrderType   string `json:"orderType"`
+			OrderStatus string `json:"orderStatus"`
+			CumExecQty  string `json:"cumExecQty"`
+			UpdatedTime string `json:"updatedTime"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(msg, &raw); err != nil {
+		return
+	}
+	if raw.Topic != "order" {
+		return
+	}
+
+	for _, d := range raw.Data {
+		filledQty, _ := decimal.NewFromString(d.CumExecQty)
+		lastQty := filledQty.Sub(orderPrevFilled[d.OrderID])
+		status := bybitStatusToTradeStatus(d.OrderStatus)
+		if status == trade.FILLED || status == trade.CANCELED {
+			delete(orderPrevFilled, d.OrderID)
+		} else {
+			orderPrevFilled[d.OrderID] = filledQty
+		}
+		updateTime, _ := strconv.ParseInt(d.UpdatedTime, 10, 64)
+
+		evt := model.OrderEvent{
+			OrderID:    d.OrderID,
+			Symbol:     d.Symbol,
+			Status:     status,
+			FilledQty:  filledQty,
+			LastQty:    lastQty,
+			Side:       fromBybitSide(d.Side),
+			Type:       trade.Type(strings.ToUpper(d.OrderType)),
+			UpdateTime: updateTime,
+		}
+		select {
+		case bt.eventChan <- evt:
+		default:
+		}
+	}
+}
+
+func toBybitSide(side trade.Signal) string {
+	if side == trade.SELL {
+		return "Sell"
+	}
+	return "Buy"
+}
+
+func fromBybitSide(side string) trade.Signal {
+	return trade.Signal(strings.ToUpper(side))
+}
+
+func toBybitType(t trade.Type) string {
+	if t == trade.LIMIT {
+		return "Limit"
+	}
+	return "Market"
+}
+
+func bybitStatusToTradeStatus(status string) trade.Status {
+	switch status {
+	case "New", "Untriggered":
+		return trade.NEW
+	case "PartiallyFilled":
+		return trade.PARTIALLY_FILLED
+	case "Filled":
+		return trade.FILLED
+	case "Cancelled", "PartiallyFilledCanceled", "Deactivated":
+		return trade.CANCELED
+	default:
+		return trade.UNKNOWN
+	}
+}
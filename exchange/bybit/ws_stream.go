@@ -0,0 +1,415 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+package bybit
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/shopspring/decimal"
+	"github.com/wang900115/quant/exchange/stream"
+	"github.com/wang900115/quant/model"
+	"github.com/wang900115/quant/model/trade"
+)
+
+type BybitStreamClient struct {
+	spotClient    *stream.Conn
+	linearClient  *stream.Conn
+	inverseClient *stream.Conn
+
+	handler      func(message []byte) error
+	bufferSize   int
+	pingInterval time.Duration
+	tracker      *stream.Tracker
+
+	booksMu sync.Mutex
+	books   map[string]*orderBook // category + topic -> local book
+
+	wg        sync.WaitGroup
+	done      chan struct{}
+	closeOnce sync.Once
+
+	newPriceChan      chan model.PricePoint
+	priceIntervalChan chan model.PriceInterval
+	orderBookChan     chan model.OrderBook
+}
+
+func NewStreamClient(cfg BybitConfig) (*BybitStreamClient, error) {
+	if cfg.Callback == nil {
+		cfg.Callback = defaultCallback
+	}
+	if cfg.BufferSize == 0 {
+		cfg.BufferSize = defaultBufferSize
+	}
+	if cfg.PingInterval == 0 {
+		cfg.PingInterval = defaultPingInterval
+	}
+	c := &BybitStreamClient{
+		handler:           cfg.Callback,
+		bufferSize:        cfg.BufferSize,
+		pingInterval:      cfg.PingInterval,
+		tracker:           stream.NewTracker(cfg.AckTimeout),
+		books:             make(map[string]*orderBook),
+		done:              make(chan struct{}),
+		newPriceChan:      make(chan model.PricePoint, cfg.BufferSize),
+		priceIntervalChan: make(chan model.PriceInterval, cfg.BufferSize),
+		orderBookChan:     make(chan model.OrderBook, cfg.BufferSize),
+	}
+	endpoint := cfg.publicWsEndpoint()
+	var err error
+	if c.spotClient, err = c.connect(endpoint + "/spot"); err != nil {
+		return nil, errInitFailed
+	}
+	if c.linearClient, err = c.connect(endpoint + "/linear"); err != nil {
+		return nil, errInitFailed
+	}
+	if c.inverseClient, err = c.connect(endpoint + "/inverse"); err != nil {
+		return nil, errInitFailed
+	}
+	return c, nil
+}
+
+func (bc *BybitStreamClient) connect(endpoint string) (*stream.Conn, error) {
+	conn, _, err := websocket.DefaultDialer.Dial(endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &stream.Conn{Conn: conn}, nil
+}
+
+func (bc *BybitStreamClient) ReceiveStream() (<-chan model.PricePoint, <-chan model.PriceInterval, <-chan model.OrderBook) {
+	return bc.newPriceChan, bc.priceIntervalChan, bc.orderBookChan
+}
+
+// SubscribeStream subscribes to v5 topics such as "tickers", "kline.1" or "orderbook.50" for the pair
+func (bc *BybitStreamClient) SubscribeStream(pair model.QuotesPair, channels []string) error {
+	if err := bc.request(pair, "subscribe", channels); err != nil {
+		return err
+	}
+	bc.tracker.Add(stream.Of(pair, channels)...)
+	return nil
+}
+
+// UnsubscribeStream stops the topics and drops the local copy of unsubscribed books
+func (bc *BybitStreamClient) UnsubscribeStream(pair model.QuotesPair, channels []string) error {
+	if err := bc.request(pair, "unsubscribe", channels); err != nil {
+		return err
+	}
+	bc.tracker.Remove(stream.Of(pair, channels)...)
+	category, _ := getCategory(pair)
+	bc.booksMu.Lock()
+	for _, ch := range channels {
+		delete(bc.books, category+":"+ch+"."+getSymbol(pair))
+	}
+	bc.booksMu.Unlock()
+	return nil
+}
+
+func (bc *BybitStreamClient) Subscriptions() []model.Subscription {
+	return bc.tracker.Subscriptions()
+}
+
+// request sends a subscribe or unsubscribe and waits for the response echoing its req_id
+func (bc *BybitStreamClient) request(pair model.QuotesPair, op string, channels []string) error {
+	client, err := bc.getClient(pair)
+	if err != nil {
+		return err
+	}
+	symbol := getSymbol(pair)
+	args := make([]string, 0, len(channels))
+	for _, ch := range channels {
+		args = append(args, ch+"."+symbol)
+	}
+	id := bc.tracker.NextID()
+	msg := map[string]interface{}{
+		"req_id": strconv.FormatInt(id, 10),
+		"op":     op,
+		"args":   args,
+	}
+	return bc.tracker.Do(id, func() error { return client.WriteJSON(msg) })
+}
+
+func (bc *BybitStreamClient) Dispatch(ctx context.Context) error {
+	dispatchers := bc.getDispatchers()
+	clients := map[string]*stream.Conn{
+		"spot":    bc.spotClient,
+		"linear":  bc.linearClient,
+		"inverse": bc.inverseClient,
+	}
+
+	for category, client := range clients {
+		bc.wg.Add(2)
+		go func(category string, conn *stream.Conn) {
+			defer bc.wg.Done()
+			for {
+				_, message, err := conn.ReadMessage()
+				received := time.Now()
+				if err != nil {
+					return
+				}
+				if bc.handler != nil {
+					bc.handler(message)
+				}
+				if a, ok := parseAck(message); ok {
+					bc.tracker.Resolve(a.id, a.err)
+					continue
+				}
+				if fn, ok := dispatchers[getMessageType(message)]; ok {
+					fn(bc, category, message, received)
+				}
+			}
+		}(category, client)
+		// Bybit drops connections that stay silent for more than 20 seconds
+		go func(conn *stream.Conn) {
+			defer bc.wg.Done()
+			ticker := time.NewTicker(bc.pingInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-bc.done:
+					return
+				case <-ticker.C:
+					if err := conn.WriteJSON(map[string]string{"op": "ping"}); err != nil {
+						return
+					}
+				}
+			}
+		}(client)
+	}
+
+	<-ctx.Done()
+	return nil
+}
+
+func (bc *BybitStreamClient) Close() error {
+	var err error
+	bc.closeOnce.Do(func() {
+		close(bc.done)
+		bc.tracker.Close()
+		for _, client := range []*stream.Conn{bc.spotClient, bc.linearClient, bc.inverseClient} {
+			if cerr := client.Close(); cerr != nil && err == nil {
+				err = cerr
+			}
+		}
+		// readers exit once their connection is closed, after that nothing writes to the channels
+		bc.wg.Wait()
+		close(bc.newPriceChan)
+		close(bc.priceIntervalChan)
+		close(bc.orderBookChan)
+	})
+	return err
+}
+
+type dispatchFunc func(client *BybitStreamClient, category string, msg []byte, received time.Time)
+
+func (bc *BybitStreamClient) getDispatchers() map[string]dispatchFunc {
+	return map[string]dispatchFunc{
+		"tickers": func(client *BybitStreamClient, category string, msg []byte, received time.Time) {
+			if p, err := parsePricePoint(msg); err == nil {
+				model.DispatchPrice(client.newPriceChan, *p, received)
+			}
+		},
+		"kline": func(client *BybitStreamClient, category string, msg []byte, received time.Time) {
+			if intervals, err := parsePriceInterval(msg); err == nil {
+				for _, interval := range intervals {
+					model.PushToChan(client.priceIntervalChan, interval)
+				}
+			}
+		},
+		"orderbook": func(client *BybitStreamClient, category string, msg []byte, received time.Time) {
+			if ob, err := client.applyOrderBook(category, msg); err == nil {
+				model.PushToChan(client.orderBookChan, *ob)
+			}
+		},
+	}
+}
+
+func (bc *BybitStreamClient) getClient(pair model.QuotesPair) (*stream.Conn, error) {
+	switch pair.Category {
+	case trade.SPOT:
+		return bc.spotClient, nil
+	case trade.FUTURES:
+		return bc.linearClient, nil
+	case trade.INVERSE:
+		return bc.inverseClient, nil
+	default:
+		return nil, errInvalidPair
+	}
+}
+
+// ack is the response to a subscribe or unsubscribe request
+type ack struct {
+	id  string
+	err error
+}
+
+// parseAck recognizes {"success":false,"ret_msg":"...","req_id":"1","op":"subscribe"}, pongs are ignored
+func parseAck(msg []byte) (ack, bool) {
+	var raw struct {
+		Success bool   `json:"success"`
+		RetMsg  string `json:"ret_msg"`
+		ReqID   string `json:"req_id"`
+		Op      string `json:"op"`
+	}
+	if err := json.Unmarshal(msg, &raw); err != nil || raw.ReqID == "" {
+		return ack{}, false
+	}
+	if raw.Op != "subscribe" && raw.Op != "unsubscribe" {
+		return ack{}, false
+	}
+	a := ack{id: raw.ReqID}
+	if !raw.Success {
+		a.err = &stream.Error{Exchange: "bybit", Message: raw.RetMsg}
+	}
+	return a, true
+}
+
+// getMessageType returns the topic family, "orderbook" for "orderbook.50.BTCUSDT"
+func getMessageType(msg []byte) string {
+	var raw struct {
+		Topic string `json:"topic"`
+		Op    string `json:"op"`
+	}
+	if err := json.Unmarshal(msg, &raw); err != nil {
+		return ""
+	}
+	if raw.Topic == "" {
+		return raw.Op
+	}
+	family, _, _ := strings.Cut(raw.Topic, ".")
+	return family
+}
+
+func parsePricePoint(msg []byte) (*model.PricePoint, error) {
+	var raw struct {
+		Ts   int64 `json:"ts"`
+		Data struct {
+			Symbol    string `json:"symbol"`
+			LastPrice string `json:"lastPrice"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(msg, &raw); err != nil {
+		return nil, err
+	}
+	// derivative deltas only carry the fields that changed
+	if raw.Data.LastPrice == "" {
+		return nil, errBybitNoData
+	}
+	price, err := decimal.NewFromString(raw.Data.LastPrice)
+	if err != nil {
+		return nil, err
+	}
+	return &model.PricePoint{
+		NewPrice:  price,
+		UpdatedAt: time.UnixMilli(raw.Ts),
+	}, nil
+}
+
+func parsePriceInterval(msg []byte) ([]model.PriceInterval, error) {
+	var raw struct {
+		Data []struct {
+			Start  int64  `json:"start"`
+			End    int64  `json:"end"`
+			Open   string `json:"open"`
+			Close  string `json:"close"`
+			High   string `json:"high"`
+			Low    string `json:"low"`
+			Volume string `json:"volume"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(msg, &raw); err != nil {
+		return nil, err
+	}
+	intervals := make([]model.PriceInterval, 0, len(raw.Data))
+	for _, k := range raw.Data {
+		open, err := decimal.NewFromString(k.Open)
+		if err != nil {
+			return nil, err
+		}
+		closeP, err := decimal.NewFromString(k.Close)
+		if err != nil {
+			return nil, err
+		}
+		high, err := decimal.NewFromString(k.High)
+		if err != nil {
+			return nil, err
+		}
+		low, err := decimal.NewFromString(k.Low)
+		if err != nil {
+			return nil, err
+		}
+		volume, err := decimal.NewFromString(k.Volume)
+		if err != nil {
+			return nil, err
+		}
+		// end is the last millisecond of the candle
+		duration := time.Duration(k.End-k.Start+1) * time.Millisecond
+		intervals = append(intervals, model.PriceInterval{
+			OpenTime:         time.UnixMilli(k.Start).Format(time.RFC3339),
+			CloseTime:        time.UnixMilli(k.Start).Add(duration).Format(time.RFC3339),
+			OpeningPrice:     open,
+			ClosingPrice:     closeP,
+			HighestPrice:     high,
+			LowestPrice:      low,
+			Volume:           volume,
+			IntervalDuration: duration,
+		})
+	}
+	return intervals, nil
+}
+
+// applyOrderBook folds a snapshot or delta into the local book for the topic and returns the full book
+func (bc *BybitStreamClient) applyOrderBook(category string, msg []byte) (*model.OrderBook, error) {
+	var raw struct {
+		Topic string `json:"topic"`
+		Type  string `json:"type"`
+		Ts    int64  `json:"ts"`
+		Data  struct {
+			Symbol string     `json:"s"`
+			Bids   [][]string `json:"b"`
+			Asks   [][]string `json:"a"`
+			Update int64      `json:"u"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(msg, &raw); err != nil {
+		return nil, err
+	}
+
+	bc.booksMu.Lock()
+	defer bc.booksMu.Unlock()
+	key := category + ":" + raw.Topic
+	book, ok := bc.books[key]
+	// update id 1 means the venue restarted the book, it is a snapshot regardless of type
+	if raw.Type == "snapshot" || raw.Data.Update == 1 {
+		book = newOrderBook()
+		bc.books[key] = book
+	} else if !ok {
+		return nil, errBybitNoData
+	}
+	if err := book.apply(raw.Data.Bids, raw.Data.Asks); err != nil {
+		return nil, err
+	}
+	return &model.OrderBook{
+		Symbol: raw.Data.Symbol,
+		Time:   time.UnixMilli(raw.Ts),
+		Bids:   book.bids(),
+		Asks:   book.asks(),
+	}, nil
+}
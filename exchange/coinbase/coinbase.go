@@ -23,6 +23,7 @@ import (
 
 	"github.com/shopspring/decimal"
 	"github.com/wang900115/quant/common/parse"
+	"github.com/wang900115/quant/exchange/rest"
 	"github.com/wang900115/quant/model"
 )
 
@@ -45,14 +46,14 @@ const (
 
 var (
 	errCoinbaseNoData = errors.New("coinbase: no data returned")
-	errResponseFailed = errors.New("coinbase: response failed")
 	errNotValidType   = errors.New("coinbase: not valid type")
 	errInitFailed     = errors.New("coinbase: initialization failed")
 	errNonAssetFound  = errors.New("coinbase: no such asset found")
 )
 
 type CoinbaseSingleClient struct {
-	client *http.Client
+	client    *http.Client
+	scheduler *rest.Scheduler
 }
 
 func NewSingleClient(cfg CoinbaseConfig) *CoinbaseSingleClient {
@@ -60,8 +61,12 @@ func NewSingleClient(cfg CoinbaseConfig) *CoinbaseSingleClient {
 	if timeout == 0 {
 		timeout = defaultTimeout
 	}
+	if cfg.Scheduler == nil {
+		cfg.Scheduler = NewScheduler()
+	}
 	return &CoinbaseSingleClient{
-		client: &http.Client{Timeout: timeout},
+		client:    &http.Client{Timeout: timeout},
+		scheduler: cfg.Scheduler,
 	}
 }
 
@@ -72,13 +77,13 @@ func (cc *CoinbaseSingleClient) GetPrice(ctx context.Context, pair model.QuotesP
 	if err != nil {
 		return nil, err
 	}
-	resp, err := cc.client.Do(req)
+	resp, err := cc.scheduler.Do(cc.client, req, publicCost)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return nil, errResponseFailed
+		return nil, parseError(resp)
 	}
 	var raw struct {
 		Price string `json:"price"`
@@ -120,13 +125,13 @@ func (cc *CoinbaseSingleClient) GetKlines(ctx context.Context, pair model.Quotes
 		return nil, err
 	}
 
-	resp, err := cc.client.Do(req)
+	resp, err := cc.scheduler.Do(cc.client, req, publicCost)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return nil, errResponseFailed
+		return nil, parseError(resp)
 	}
 	// Coinbase response: [[time, low, high, open, close, volume], ...]
 	var rawCandles [][]interface{}
@@ -200,14 +205,14 @@ func (cc *CoinbaseSingleClient) GetOrderBook(ctx context.Context, pair model.Quo
 	if err != nil {
 		return nil, err
 	}
-	resp, err := cc.client.Do(req)
+	resp, err := cc.scheduler.Do(cc.client, req, publicCost)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, errResponseFailed
+		return nil, parseError(resp)
 	}
 
 	var raw struct {
@@ -242,12 +247,18 @@ type CoinbaseConfig struct {
 	BufferSize     int
 	Callback       func(message []byte) error
 
+	// AckTimeout bounds how long SubscribeStream waits for the venue to confirm, zero selects ten seconds
+	AckTimeout time.Duration
+
 	APIKey     string
 	SecretKey  string
 	Passphrase string
 
 	RetryInterval       time.Duration
 	HealthCheckInterval time.Duration
+
+	// Scheduler shares rate limits between clients, one is created when nil
+	Scheduler *rest.Scheduler
 }
 
 type CoinbaseClient struct {
@@ -257,6 +268,9 @@ type CoinbaseClient struct {
 }
 
 func New(config CoinbaseConfig) *CoinbaseClient {
+	if config.Scheduler == nil {
+		config.Scheduler = NewScheduler()
+	}
 	streamClient, err := NewStreamClient(config)
 	if err != nil {
 		panic(err)
@@ -329,6 +329,10 @@ func TestCoinbaseStreamClient_Subscribe(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed to create stream client: %v", err)
 	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// subscriptions wait for acknowledgements, which the dispatcher reads
+	go client.Dispatch(ctx)
 	pair := model.QuotesPair{
 		Base:     currency.BTCSymbol,
 		Quote:    currency.USDSymbol,
@@ -389,11 +393,6 @@ func TestCoinbaseStreamClient_ReceiveData(t *testing.T) {
 	defer client.Close()
 
 	// Subscribe to ticker channel
-	err = client.SubscribeStream(pair, []string{"ticker"})
-	if err != nil {
-		t.Fatalf("failed to subscribe: %v", err)
-	}
-
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
@@ -406,6 +405,11 @@ func TestCoinbaseStreamClient_ReceiveData(t *testing.T) {
 		}
 	}()
 
+	err = client.SubscribeStream(pair, []string{"ticker"})
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+
 	// Collect price updates
 	receivedCount := 0
 	maxUpdates := 5
@@ -445,13 +449,6 @@ func TestCoinbaseStreamClient_MultipleChannels(t *testing.T) {
 
 	channels := []string{"ticker", "matches", "level2"}
 
-	err = client.SubscribeStream(pair, channels)
-	if err != nil {
-		t.Fatalf("failed to subscribe to multiple channels: %v", err)
-	}
-
-	t.Logf("Successfully subscribed to %d channels: %v", len(channels), channels)
-
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
 
@@ -464,6 +461,13 @@ func TestCoinbaseStreamClient_MultipleChannels(t *testing.T) {
 		}
 	}()
 
+	err = client.SubscribeStream(pair, channels)
+	if err != nil {
+		t.Fatalf("failed to subscribe to multiple channels: %v", err)
+	}
+
+	t.Logf("Successfully subscribed to %d channels: %v", len(channels), channels)
+
 	// Collect data from different channels
 	priceCount := 0
 	orderBookCount := 0
@@ -0,0 +1,66 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package coinbase
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/wang900115/quant/exchange/rest"
+)
+
+const requestIDHeader = "X-Request-Id"
+
+// parseError turns a failed Coinbase response carrying {"message": "..."} into a *rest.Error.
+// Coinbase sends no error codes, so the kind is derived from the message and the requested resource.
+func parseError(resp *http.Response) error {
+	e := rest.NewError("coinbase", resp, requestIDHeader)
+	body := rest.ReadBody(resp)
+	var payload struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil || payload.Message == "" {
+		e.Message = strings.TrimSpace(string(body))
+	} else {
+		e.Message = payload.Message
+	}
+	if kind := classify(resp, e.Message); kind != rest.KindUnknown {
+		e.Classify(kind)
+	}
+	return e
+}
+
+func classify(resp *http.Response, msg string) rest.Kind {
+	msg = strings.ToLower(msg)
+	switch {
+	case strings.Contains(msg, "insufficient funds"):
+		return rest.KindInsufficientBalance
+	case strings.Contains(msg, "rate limit"):
+		return rest.KindRateLimited
+	case strings.Contains(msg, "product not found"), strings.Contains(msg, "invalid product"):
+		return rest.KindInvalidSymbol
+	case strings.Contains(msg, "order not found"):
+		return rest.KindOrderNotFound
+	}
+	if resp.StatusCode == http.StatusNotFound && resp.Request != nil {
+		switch path := resp.Request.URL.Path; {
+		case strings.Contains(path, "/orders/"):
+			return rest.KindOrderNotFound
+		case strings.Contains(path, "/products/"):
+			return rest.KindInvalidSymbol
+		}
+	}
+	return rest.KindUnknown
+}
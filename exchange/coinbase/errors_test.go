@@ -0,0 +1,75 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+package coinbase
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/wang900115/quant/exchange/rest"
+	"github.com/wang900115/quant/exchange/stream"
+)
+
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		path   string
+		body   string
+		target error
+	}{
+		{"Insufficient funds", 400, "/orders", `{"message":"Insufficient funds"}`, rest.ErrInsufficientBalance},
+		{"Unknown product", 404, "/products/FOO-BAR/ticker", `{"message":"NotFound"}`, rest.ErrInvalidSymbol},
+		{"Unknown order", 404, "/orders/123", `{"message":"NotFound"}`, rest.ErrOrderNotFound},
+		{"Rate limited", 429, "/orders", `{"message":"Private rate limit exceeded"}`, rest.ErrRateLimited},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{
+				StatusCode: tt.status,
+				Header:     http.Header{},
+				Body:       io.NopCloser(strings.NewReader(tt.body)),
+				Request:    &http.Request{URL: &url.URL{Path: tt.path}},
+			}
+			err := parseError(resp)
+			var e *rest.Error
+			if !errors.As(err, &e) {
+				t.Fatalf("expected *rest.Error, got %T", err)
+			}
+			if e.Status != tt.status || e.Message == "" {
+				t.Errorf("unexpected error fields: %+v", e)
+			}
+			if !errors.Is(err, tt.target) {
+				t.Errorf("expected %v to match %v", err, tt.target)
+			}
+		})
+	}
+}
+
+func TestParseAck(t *testing.T) {
+	if ok, rejected := parseAck([]byte(`{"type":"subscriptions","channels":[{"name":"ticker","product_ids":["BTC-USD"]}]}`)); !ok || rejected != nil {
+		t.Errorf("expected a successful ack, got %v %v", ok, rejected)
+	}
+	ok, rejected := parseAck([]byte(`{"type":"error","message":"Failed to subscribe","reason":"FOO-BAR is not a valid product"}`))
+	var e *stream.Error
+	if !ok || !errors.As(rejected, &e) || e.Message != "Failed to subscribe: FOO-BAR is not a valid product" {
+		t.Errorf("expected a rejection, got %v %v", ok, rejected)
+	}
+	if ok, _ := parseAck([]byte(`{"type":"ticker","product_id":"BTC-USD","price":"1"}`)); ok {
+		t.Error("expected market data not to be taken for an ack")
+	}
+}
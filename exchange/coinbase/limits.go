@@ -0,0 +1,41 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package coinbase
+
+import (
+	"time"
+
+	"github.com/wang900115/quant/exchange/rest"
+)
+
+// Coinbase Exchange limits public calls per IP and private calls per profile, both in requests per second
+const (
+	publicBucket  = "public"
+	privateBucket = "private"
+)
+
+var (
+	publicCost  = rest.Cost{Bucket: publicBucket, Weight: 1}
+	privateCost = rest.Cost{Bucket: privateBucket, Weight: 1}
+)
+
+// NewScheduler creates the request scheduler shared by the Coinbase REST clients
+func NewScheduler() *rest.Scheduler {
+	return rest.NewScheduler(rest.Config{
+		Buckets: []rest.Bucket{
+			{Name: publicBucket, Capacity: 10, Window: time.Second},
+			{Name: privateBucket, Capacity: 15, Window: time.Second},
+		},
+	})
+}
@@ -25,14 +25,16 @@ import (
 	"github.com/gorilla/websocket"
 	"github.com/shopspring/decimal"
 	"github.com/wang900115/quant/common/sys"
+	"github.com/wang900115/quant/exchange/rest"
 	"github.com/wang900115/quant/model"
 	"github.com/wang900115/quant/model/currency"
 	"github.com/wang900115/quant/model/trade"
 )
 
 type CoinbaseTradeClient struct {
-	client *http.Client
-	ws     *websocket.Conn
+	client    *http.Client
+	scheduler *rest.Scheduler
+	ws        *websocket.Conn
 
 	engine    *sys.Engine
 	eventChan chan model.OrderEvent
@@ -49,8 +51,12 @@ func NewTradeClient(cfg CoinbaseConfig) (*CoinbaseTradeClient, error) {
 	if cfg.BufferSize == 0 {
 		cfg.BufferSize = defaultBufferSize
 	}
+	if cfg.Scheduler == nil {
+		cfg.Scheduler = NewScheduler()
+	}
 	c := &CoinbaseTradeClient{
 		client:     &http.Client{Timeout: cfg.PrivateTimeout},
+		scheduler:  cfg.Scheduler,
 		apiKey:     cfg.APIKey,
 		secretKey:  cfg.SecretKey,
 		passphrase: cfg.Passphrase,
@@ -70,7 +76,10 @@ func (cb *CoinbaseTradeClient) connect() error {
 		return err
 	}
 	cb.ws = c
-	go cb.listen()
+	cb.engine.Supervise(sys.Task{Name: "coinbase.orders", Run: func(context.Context) error {
+		cb.listen()
+		return nil
+	}})
 	return nil
 }
 
@@ -94,11 +103,14 @@ func (cb *CoinbaseTradeClient) PlaceOrder(ctx context.Context, req model.OrderRe
 	httpReq.Header.Set("CB-ACCESS-PASSPHRASE", cb.passphrase)
 	httpReq.Header.Set("Content-Type", "application/json")
 
-	resp, err := cb.client.Do(httpReq)
+	resp, err := cb.scheduler.Do(cb.client, httpReq, privateCost)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, parseError(resp)
+	}
 
 	var result struct {
 		ID string `json:"id"`
@@ -124,11 +136,14 @@ func (cb *CoinbaseTradeClient) GetOrder(ctx context.Context, symbol string, orde
 	req.Header.Set("CB-ACCESS-SIGN", cb.secretKey) // in Note: In practice, the signature should be generated properly
 	req.Header.Set("CB-ACCESS-PASSPHRASE", cb.passphrase)
 
-	resp, err := cb.client.Do(req)
+	resp, err := cb.scheduler.Do(cb.client, req, privateCost)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, parseError(resp)
+	}
 
 	var od struct {
 		ID         string `json:"id"`
@@ -171,14 +186,14 @@ func (cb *CoinbaseTradeClient) CancelOrder(ctx context.Context, symbol string, o
 	req.Header.Set("CB-ACCESS-SIGN", cb.secretKey) // in Note: In practice, the signature should be generated properly
 	req.Header.Set("CB-ACCESS-PASSPHRASE", cb.passphrase)
 
-	resp, err := cb.client.Do(req)
+	resp, err := cb.scheduler.Do(cb.client, req, privateCost)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("cancel order failed: %v", resp.Status)
+		return parseError(resp)
 	}
 	return nil
 }
@@ -191,11 +206,14 @@ func (cb *CoinbaseTradeClient) GetAssetBalance(ctx context.Context, asset string
 	req.Header.Set("CB-ACCESS-SIGN", cb.secretKey) // Note: In practice, the signature should be generated properly
 	req.Header.Set("CB-ACCESS-PASSPHRASE", cb.passphrase)
 
-	resp, err := cb.client.Do(req)
+	resp, err := cb.scheduler.Do(cb.client, req, privateCost)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, parseError(resp)
+	}
 
 	var accounts []struct {
 		Currency string `json:"currency"`
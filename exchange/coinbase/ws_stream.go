@@ -18,16 +18,18 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/gorilla/websocket"
 	"github.com/shopspring/decimal"
+	"github.com/wang900115/quant/exchange/stream"
 	"github.com/wang900115/quant/model"
 )
 
 type CoinbaseStreamClient struct {
-	client *websocket.Conn
+	client *stream.Conn
 
 	handler    func(message []byte) error
 	bufferSize int
+	// tracker resolves requests in order, the feed answers each one with a subscriptions or error message
+	tracker *stream.Tracker
 
 	newPriceChan      chan model.PricePoint
 	priceIntervalChan chan model.PriceInterval
@@ -45,6 +47,7 @@ func NewStreamClient(cfg CoinbaseConfig) (*CoinbaseStreamClient, error) {
 	c := &CoinbaseStreamClient{
 		handler:           cfg.Callback,
 		bufferSize:        cfg.BufferSize,
+		tracker:           stream.NewTracker(cfg.AckTimeout),
 		newPriceChan:      make(chan model.PricePoint, cfg.BufferSize),
 		priceIntervalChan: make(chan model.PriceInterval, cfg.BufferSize),
 		orderBookChan:     make(chan model.OrderBook, cfg.BufferSize),
@@ -62,8 +65,7 @@ func NewStreamClient(cfg CoinbaseConfig) (*CoinbaseStreamClient, error) {
 }
 
 func (cc *CoinbaseStreamClient) connect(url string) error {
-	var dialer websocket.Dialer
-	conn, _, err := dialer.Dial(url, nil)
+	conn, err := stream.Dial(url)
 	if err != nil {
 		return err
 	}
@@ -71,18 +73,47 @@ func (cc *CoinbaseStreamClient) connect(url string) error {
 	return nil
 }
 
+// Reconnect dials the feed again after Dispatch failed, the subscriptions have to be requested again once Dispatch runs
+func (cc *CoinbaseStreamClient) Reconnect() error {
+	if err := cc.client.Redial(); err != nil {
+		return err
+	}
+	cc.tracker.Reopen()
+	return nil
+}
+
 func (cc *CoinbaseStreamClient) ReceiveStream() (<-chan model.PricePoint, <-chan model.PriceInterval, <-chan model.OrderBook) {
 	return cc.newPriceChan, cc.priceIntervalChan, cc.orderBookChan
 }
 
 func (cc *CoinbaseStreamClient) SubscribeStream(pair model.QuotesPair, channelsType []string) error {
+	if err := cc.request(pair, "subscribe", channelsType); err != nil {
+		return err
+	}
+	cc.tracker.Add(stream.Of(pair, channelsType)...)
+	return nil
+}
+
+func (cc *CoinbaseStreamClient) UnsubscribeStream(pair model.QuotesPair, channelsType []string) error {
+	if err := cc.request(pair, "unsubscribe", channelsType); err != nil {
+		return err
+	}
+	cc.tracker.Remove(stream.Of(pair, channelsType)...)
+	return nil
+}
+
+func (cc *CoinbaseStreamClient) Subscriptions() []model.Subscription {
+	return cc.tracker.Subscriptions()
+}
+
+func (cc *CoinbaseStreamClient) request(pair model.QuotesPair, msgType string, channelsType []string) error {
 	symbol := fmt.Sprintf("%s-%s", pair.Base, pair.Quote)
-	subscribeMsg := map[string]interface{}{
-		"type":        "subscribe",
+	msg := map[string]interface{}{
+		"type":        msgType,
 		"product_ids": []string{symbol},
 		"channels":    channelsType,
 	}
-	return cc.client.WriteJSON(subscribeMsg)
+	return cc.tracker.Do(cc.tracker.NextID(), func() error { return cc.client.WriteJSON(msg) })
 }
 
 func (cc *CoinbaseStreamClient) Dispatch(ctx context.Context) error {
@@ -93,20 +124,27 @@ func (cc *CoinbaseStreamClient) Dispatch(ctx context.Context) error {
 			return cc.client.Close()
 		default:
 			_, message, err := cc.client.ReadMessage()
+			received := time.Now()
 			if err != nil {
+				cc.tracker.Close()
 				return err
 			}
 			if cc.handler != nil {
 				cc.handler(message)
 			}
+			if ok, rejected := parseAck(message); ok {
+				cc.tracker.ResolveNext(rejected)
+				continue
+			}
 			if fn, ok := dispatchers[getMessageType(message)]; ok {
-				fn(cc, message)
+				fn(cc, message, received)
 			}
 		}
 	}
 }
 
 func (cc *CoinbaseStreamClient) Close() error {
+	cc.tracker.Close()
 	if err := cc.client.Close(); err != nil {
 		return err
 	}
@@ -116,23 +154,23 @@ func (cc *CoinbaseStreamClient) Close() error {
 	return nil
 }
 
-type dispatchFunc func(*CoinbaseStreamClient, []byte)
+type dispatchFunc func(*CoinbaseStreamClient, []byte, time.Time)
 
 func (cc *CoinbaseStreamClient) getDispatchers() map[string]dispatchFunc {
 	return map[string]dispatchFunc{
-		"ticker": func(client *CoinbaseStreamClient, msg []byte) {
+		"ticker": func(client *CoinbaseStreamClient, msg []byte, received time.Time) {
 			if p, err := parsePricePoint(msg); err == nil {
-				model.PushToChan(client.newPriceChan, *p)
+				model.DispatchPrice(client.newPriceChan, *p, received)
 			}
 		},
-		"candles": func(client *CoinbaseStreamClient, msg []byte) {
+		"candles": func(client *CoinbaseStreamClient, msg []byte, received time.Time) {
 			if intervals, err := parsePriceInterval(msg); err == nil {
 				for _, interval := range intervals {
 					model.PushToChan(client.priceIntervalChan, interval)
 				}
 			}
 		},
-		"level2": func(client *CoinbaseStreamClient, msg []byte) {
+		"level2": func(client *CoinbaseStreamClient, msg []byte, received time.Time) {
 			if orderBook, err := parseOrderBook(msg); err == nil {
 				model.PushToChan(client.orderBookChan, *orderBook)
 			}
@@ -140,11 +178,37 @@ func (cc *CoinbaseStreamClient) getDispatchers() map[string]dispatchFunc {
 	}
 }
 
+// parseAck recognizes the subscriptions message that confirms a request and the error message that rejects it,
+// rejected carries the reason of the latter
+func parseAck(msg []byte) (matched bool, rejected error) {
+	var raw struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+		Reason  string `json:"reason"`
+	}
+	if err := json.Unmarshal(msg, &raw); err != nil {
+		return false, nil
+	}
+	switch raw.Type {
+	case "subscriptions":
+		return true, nil
+	case "error":
+		message := raw.Message
+		if raw.Reason != "" {
+			message += ": " + raw.Reason
+		}
+		return true, &stream.Error{Exchange: "coinbase", Message: message}
+	default:
+		return false, nil
+	}
+}
+
 func parsePricePoint(msg []byte) (*model.PricePoint, error) {
 	var raw struct {
-		Type      string `json:"type"`
-		ProductID string `json:"product_id"`
-		Price     string `json:"price"`
+		Type      string    `json:"type"`
+		ProductID string    `json:"product_id"`
+		Price     string    `json:"price"`
+		Time      time.Time `json:"time"`
 	}
 	if err := json.Unmarshal(msg, &raw); err != nil {
 		return nil, err
@@ -153,9 +217,14 @@ func parsePricePoint(msg []byte) (*model.PricePoint, error) {
 	if err != nil {
 		return nil, err
 	}
+	// older feeds omit the venue time, fall back to the local clock
+	updatedAt := raw.Time
+	if updatedAt.IsZero() {
+		updatedAt = time.Now()
+	}
 	return &model.PricePoint{
 		NewPrice:  p,
-		UpdatedAt: time.Now(),
+		UpdatedAt: updatedAt,
 	}, nil
 }
 
@@ -0,0 +1,128 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+package kraken
+
+import (
+	"encoding/json"
+	"hash/crc32"
+	"slices"
+	"strings"
+
+	"github.com/shopspring/decimal"
+	"github.com/wang900115/quant/model"
+)
+
+// checksumDepth is the number of levels per side the book checksum covers
+const checksumDepth = 10
+
+type wsLevel struct {
+	Price json.Number `json:"price"`
+	Qty   json.Number `json:"qty"`
+}
+
+// orderBook is the local copy of a book subscription, truncated to the subscribed depth
+type orderBook struct {
+	depth     int
+	precision assetPair
+	bidLevels map[string]model.OrderBookBase
+	askLevels map[string]model.OrderBookBase
+	// stale is set after a checksum mismatch until the next snapshot arrives
+	stale bool
+}
+
+func newOrderBook(depth int, precision assetPair) *orderBook {
+	return &orderBook{
+		depth:     depth,
+		precision: precision,
+		bidLevels: make(map[string]model.OrderBookBase),
+		askLevels: make(map[string]model.OrderBookBase),
+	}
+}
+
+// apply folds levels into the book, a zero quantity removes a level, then drops levels beyond the depth
+func (ob *orderBook) apply(bids, asks []wsLevel) error {
+	if err := applyLevels(ob.bidLevels, bids); err != nil {
+		return err
+	}
+	if err := applyLevels(ob.askLevels, asks); err != nil {
+		return err
+	}
+	for _, b := range ob.bids()[min(ob.depth, len(ob.bidLevels)):] {
+		delete(ob.bidLevels, b.Price.String())
+	}
+	for _, a := range ob.asks()[min(ob.depth, len(ob.askLevels)):] {
+		delete(ob.askLevels, a.Price.String())
+	}
+	return nil
+}
+
+func applyLevels(levels map[string]model.OrderBookBase, updates []wsLevel) error {
+	for _, u := range updates {
+		price, err := decimal.NewFromString(u.Price.String())
+		if err != nil {
+			return err
+		}
+		qty, err := decimal.NewFromString(u.Qty.String())
+		if err != nil {
+			return err
+		}
+		if qty.IsZero() {
+			delete(levels, price.String())
+			continue
+		}
+		levels[price.String()] = model.OrderBookBase{Price: price, Quantity: qty}
+	}
+	return nil
+}
+
+// bids returns the bid side, best (highest) price first
+func (ob *orderBook) bids() []model.OrderBookBid {
+	out := make([]model.OrderBookBid, 0, len(ob.bidLevels))
+	for _, l := range ob.bidLevels {
+		out = append(out, model.OrderBookBid(l))
+	}
+	slices.SortFunc(out, func(a, b model.OrderBookBid) int { return b.Price.Cmp(a.Price) })
+	return out
+}
+
+// asks returns the ask side, best (lowest) price first
+func (ob *orderBook) asks() []model.OrderBookAsk {
+	out := make([]model.OrderBookAsk, 0, len(ob.askLevels))
+	for _, l := range ob.askLevels {
+		out = append(out, model.OrderBookAsk(l))
+	}
+	slices.SortFunc(out, func(a, b model.OrderBookAsk) int { return a.Price.Cmp(b.Price) })
+	return out
+}
+
+// checksum is the CRC32 of the top ten asks then the top ten bids, each level written as
+// price and quantity in the pair's precision without the decimal point and leading zeros
+func (ob *orderBook) checksum() uint32 {
+	var sb strings.Builder
+	asks := ob.asks()
+	for _, a := range asks[:min(checksumDepth, len(asks))] {
+		sb.WriteString(checksumField(a.Price, ob.precision.PairDecimals))
+		sb.WriteString(checksumField(a.Quantity, ob.precision.LotDecimals))
+	}
+	bids := ob.bids()
+	for _, b := range bids[:min(checksumDepth, len(bids))] {
+		sb.WriteString(checksumField(b.Price, ob.precision.PairDecimals))
+		sb.WriteString(checksumField(b.Quantity, ob.precision.LotDecimals))
+	}
+	return crc32.ChecksumIEEE([]byte(sb.String()))
+}
+
+func checksumField(d decimal.Decimal, precision int) string {
+	s := strings.Replace(d.StringFixed(int32(precision)), ".", "", 1)
+	return strings.TrimLeft(s, "0")
+}
@@ -0,0 +1,96 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+package kraken
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/wang900115/quant/exchange/rest"
+)
+
+// readResult decodes the result of a Kraken response into v.
+// Kraken reports failures as HTTP 200 with {"error": ["EOrder:Insufficient funds"]}, so both are checked.
+func readResult(resp *http.Response, v any) error {
+	if resp.StatusCode != http.StatusOK {
+		return newError(resp, rest.ReadBody(resp))
+	}
+	var envelope struct {
+		Error  []string        `json:"error"`
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return err
+	}
+	if len(envelope.Error) > 0 {
+		return fromMessages(resp, envelope.Error)
+	}
+	if v != nil && len(envelope.Result) > 0 {
+		return json.Unmarshal(envelope.Result, v)
+	}
+	return nil
+}
+
+func newError(resp *http.Response, body []byte) error {
+	var payload struct {
+		Error []string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &payload); err == nil && len(payload.Error) > 0 {
+		return fromMessages(resp, payload.Error)
+	}
+	e := rest.NewError("kraken", resp)
+	e.Message = strings.TrimSpace(string(body))
+	return e
+}
+
+// fromMessages builds an error from "<severity><category>:<message>" entries, the first one decides the kind
+func fromMessages(resp *http.Response, messages []string) error {
+	e := rest.NewError("kraken", resp)
+	code, msg, ok := strings.Cut(messages[0], ":")
+	if !ok {
+		code, msg = "", messages[0]
+	}
+	e.Code = code
+	e.Message = msg
+	if len(messages) > 1 {
+		e.Message = msg + "; " + strings.Join(messages[1:], "; ")
+	}
+	if kind := classify(messages[0]); kind != rest.KindUnknown {
+		e.Classify(kind)
+	}
+	// a nonce that arrived out of order is accepted once resent with a fresh one
+	if messages[0] == "EAPI:Invalid nonce" {
+		e.Retryable = true
+	}
+	return e
+}
+
+func classify(message string) rest.Kind {
+	switch message {
+	case "EAPI:Rate limit exceeded", "EOrder:Rate limit exceeded", "EGeneral:Too many requests":
+		return rest.KindRateLimited
+	case "EService:Unavailable", "EService:Busy", "EService:Market in cancel_only mode", "EGeneral:Internal error":
+		return rest.KindUnavailable
+	case "EAPI:Invalid key", "EAPI:Invalid signature", "EGeneral:Permission denied":
+		return rest.KindUnauthorized
+	case "EOrder:Insufficient funds", "EOrder:Insufficient margin":
+		return rest.KindInsufficientBalance
+	case "EQuery:Unknown asset pair", "EQuery:Unknown asset":
+		return rest.KindInvalidSymbol
+	case "EOrder:Unknown order", "EOrder:Invalid order":
+		return rest.KindOrderNotFound
+	default:
+		return rest.KindUnknown
+	}
+}
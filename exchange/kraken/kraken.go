@@ -0,0 +1,355 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+package kraken
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/wang900115/quant/common/parse"
+	"github.com/wang900115/quant/exchange/rest"
+	"github.com/wang900115/quant/model"
+	"github.com/wang900115/quant/model/currency"
+	"github.com/wang900115/quant/model/trade"
+)
+
+const (
+	endPoint          = "https://api.kraken.com"
+	wsEndPointPublic  = "wss://ws.kraken.com/v2"
+	wsEndPointPrivate = "wss://ws-auth.kraken.com/v2"
+)
+
+var defaultCallback = func(message []byte) error {
+	return nil
+}
+
+const (
+	defaultTimeout      = 10 * time.Second
+	defaultBufferSize   = 100
+	defaultTradeTimeout = 15 * time.Second
+	defaultAckTimeout   = 10 * time.Second
+)
+
+var (
+	errKrakenNoData  = errors.New("kraken: no data returned")
+	errInvalidPair   = errors.New("kraken: invalid trading pair")
+	errNotValidType  = errors.New("kraken: not valid type")
+	errInitFailed    = errors.New("kraken: initialization failed")
+	errNonAssetFound = errors.New("kraken: no such asset found")
+	errChecksum      = errors.New("kraken: order book checksum mismatch")
+	errNoCredentials = errors.New("kraken: api key and secret required")
+)
+
+type KrakenConfig struct {
+	PublicTimeout  time.Duration
+	PrivateTimeout time.Duration
+	BufferSize     int
+	Callback       func(message []byte) error
+
+	// AckTimeout bounds how long SubscribeStream waits for the venue to confirm, zero selects ten seconds
+	AckTimeout time.Duration
+
+	APIKey    string
+	SecretKey string
+
+	RetryInterval       time.Duration
+	HealthCheckInterval time.Duration
+
+	// Scheduler shares rate limits between clients, one is created when nil
+	Scheduler *rest.Scheduler
+
+	// Endpoint overrides, empty values select production
+	RestEndpoint      string
+	PublicWsEndpoint  string
+	PrivateWsEndpoint string
+}
+
+func (cfg KrakenConfig) restEndpoint() string {
+	if cfg.RestEndpoint != "" {
+		return cfg.RestEndpoint
+	}
+	return endPoint
+}
+
+func (cfg KrakenConfig) publicWsEndpoint() string {
+	if cfg.PublicWsEndpoint != "" {
+		return cfg.PublicWsEndpoint
+	}
+	return wsEndPointPublic
+}
+
+func (cfg KrakenConfig) privateWsEndpoint() string {
+	if cfg.PrivateWsEndpoint != "" {
+		return cfg.PrivateWsEndpoint
+	}
+	return wsEndPointPrivate
+}
+
+type KrakenSingleClient struct {
+	client    *http.Client
+	scheduler *rest.Scheduler
+	endpoint  string
+
+	mu    sync.Mutex
+	pairs map[string]assetPair // rest pair name -> precision
+}
+
+// assetPair carries the precision Kraken formats prices and volumes with, used by book checksums
+type assetPair struct {
+	PairDecimals int `json:"pair_decimals"`
+	LotDecimals  int `json:"lot_decimals"`
+}
+
+func NewSingleClient(cfg KrakenConfig) *KrakenSingleClient {
+	timeout := cfg.PublicTimeout
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+	if cfg.Scheduler == nil {
+		cfg.Scheduler = NewScheduler()
+	}
+	return &KrakenSingleClient{
+		client:    &http.Client{Timeout: timeout},
+		scheduler: cfg.Scheduler,
+		endpoint:  cfg.restEndpoint(),
+		pairs:     make(map[string]assetPair),
+	}
+}
+
+func (kc *KrakenSingleClient) GetPrice(ctx context.Context, pair model.QuotesPair) (*model.PricePoint, error) {
+	restPair, err := getRestPair(pair)
+	if err != nil {
+		return nil, err
+	}
+	var result map[string]struct {
+		Last []string `json:"c"` // [price, lot volume]
+	}
+	if err := kc.get(ctx, "/0/public/Ticker", url.Values{"pair": {restPair}}, &result); err != nil {
+		return nil, err
+	}
+	for _, ticker := range result {
+		if len(ticker.Last) == 0 {
+			return nil, errKrakenNoData
+		}
+		price, err := decimal.NewFromString(ticker.Last[0])
+		if err != nil {
+			return nil, err
+		}
+		return &model.PricePoint{
+			NewPrice:  price,
+			UpdatedAt: time.Now(),
+		}, nil
+	}
+	return nil, errKrakenNoData
+}
+
+// interval: 1m, 5m, 15m, 30m, 1h, 4h, 1d, 1w
+func (kc *KrakenSingleClient) GetKlines(ctx context.Context, pair model.QuotesPair, interval string, limit int) ([]model.PriceInterval, error) {
+	restPair, err := getRestPair(pair)
+	if err != nil {
+		return nil, err
+	}
+	duration := parse.ParseInterval(interval)
+	minutes := int(duration / time.Minute)
+	if !validInterval(minutes) {
+		return nil, errNotValidType
+	}
+	query := url.Values{"pair": {restPair}, "interval": {strconv.Itoa(minutes)}}
+	var result map[string]json.RawMessage
+	if err := kc.get(ctx, "/0/public/OHLC", query, &result); err != nil {
+		return nil, err
+	}
+	for key, raw := range result {
+		if key == "last" {
+			continue
+		}
+		// Kraken response: [[time, open, high, low, close, vwap, volume, count], ...] oldest first
+		var rows [][]interface{}
+		if err := json.Unmarshal(raw, &rows); err != nil {
+			return nil, fmt.Errorf("failed to decode ohlc: %w", err)
+		}
+		if limit > 0 && len(rows) > limit {
+			rows = rows[len(rows)-limit:]
+		}
+		intervals := make([]model.PriceInterval, 0, len(rows))
+		for _, row := range rows {
+			if len(row) < 7 {
+				return nil, errKrakenNoData
+			}
+			ts, ok := row[0].(float64)
+			if !ok {
+				return nil, errNotValidType
+			}
+			values := make([]decimal.Decimal, 0, 5)
+			for _, i := range []int{1, 2, 3, 4, 6} {
+				s, ok := row[i].(string)
+				if !ok {
+					return nil, errNotValidType
+				}
+				v, err := decimal.NewFromString(s)
+				if err != nil {
+					return nil, err
+				}
+				values = append(values, v)
+			}
+			openTime := time.Unix(int64(ts), 0)
+			intervals = append(intervals, model.PriceInterval{
+				OpenTime:         openTime.Format(time.RFC3339),
+				OpeningPrice:     values[0],
+				HighestPrice:     values[1],
+				LowestPrice:      values[2],
+				ClosingPrice:     values[3],
+				Volume:           values[4],
+				CloseTime:        openTime.Add(duration).Format(time.RFC3339),
+				IntervalDuration: duration,
+			})
+		}
+		return intervals, nil
+	}
+	return nil, errKrakenNoData
+}
+
+func (kc *KrakenSingleClient) GetOrderBook(ctx context.Context, pair model.QuotesPair, limit int) (*model.OrderBook, error) {
+	restPair, err := getRestPair(pair)
+	if err != nil {
+		return nil, err
+	}
+	query := url.Values{"pair": {restPair}, "count": {strconv.Itoa(limit)}}
+	var result map[string]struct {
+		Bids [][]interface{} `json:"bids"` // [price, volume, timestamp]
+		Asks [][]interface{} `json:"asks"`
+	}
+	if err := kc.get(ctx, "/0/public/Depth", query, &result); err != nil {
+		return nil, err
+	}
+	for _, book := range result {
+		bids, err := model.ParseOrderEntries[model.OrderBookBid](book.Bids)
+		if err != nil {
+			return nil, err
+		}
+		asks, err := model.ParseOrderEntries[model.OrderBookAsk](book.Asks)
+		if err != nil {
+			return nil, err
+		}
+		return &model.OrderBook{
+			Symbol: pair.Symbol(),
+			Time:   time.Now(),
+			Bids:   bids,
+			Asks:   asks,
+		}, nil
+	}
+	return nil, errKrakenNoData
+}
+
+// assetPair returns the precision of pair, looked up once through AssetPairs
+func (kc *KrakenSingleClient) assetPair(ctx context.Context, pair model.QuotesPair) (assetPair, error) {
+	restPair, err := getRestPair(pair)
+	if err != nil {
+		return assetPair{}, err
+	}
+	kc.mu.Lock()
+	info, ok := kc.pairs[restPair]
+	kc.mu.Unlock()
+	if ok {
+		return info, nil
+	}
+	var result map[string]assetPair
+	if err := kc.get(ctx, "/0/public/AssetPairs", url.Values{"pair": {restPair}}, &result); err != nil {
+		return assetPair{}, err
+	}
+	for _, info := range result {
+		kc.mu.Lock()
+		kc.pairs[restPair] = info
+		kc.mu.Unlock()
+		return info, nil
+	}
+	return assetPair{}, errInvalidPair
+}
+
+func (kc *KrakenSingleClient) get(ctx context.Context, path string, query url.Values, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, kc.endpoint+path+"?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := kc.scheduler.Do(kc.client, req, publicCost)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return readResult(resp, v)
+}
+
+type KrakenClient struct {
+	*KrakenSingleClient
+	*KrakenStreamClient
+	*KrakenTradeClient
+}
+
+func New(cfg KrakenConfig) *KrakenClient {
+	if cfg.Scheduler == nil {
+		cfg.Scheduler = NewScheduler()
+	}
+	single := NewSingleClient(cfg)
+	streamClient, err := newStreamClient(cfg, single)
+	if err != nil {
+		panic(err)
+	}
+	tradeClient, err := NewTradeClient(cfg)
+	if err != nil {
+		panic(err)
+	}
+	return &KrakenClient{
+		KrakenSingleClient: single,
+		KrakenStreamClient: streamClient,
+		KrakenTradeClient:  tradeClient,
+	}
+}
+
+// Close shuts down the public stream and the private execution stream
+func (c *KrakenClient) Close() error {
+	c.KrakenTradeClient.Close()
+	return c.KrakenStreamClient.Close()
+}
+
+func validInterval(minutes int) bool {
+	switch minutes {
+	case 1, 5, 15, 30, 60, 240, 1440, 10080, 21600:
+		return true
+	default:
+		return false
+	}
+}
+
+// getRestPair returns the REST pair name, Kraken spells BTC as XBT there
+func getRestPair(pair model.QuotesPair) (string, error) {
+	if pair.Category != trade.SPOT && pair.Category != "" {
+		return "", errInvalidPair
+	}
+	return currency.ToVenue(currency.KrakenVenue, pair.Base) + currency.ToVenue(currency.KrakenVenue, pair.Quote), nil
+}
+
+// getWsSymbol returns the WebSocket v2 symbol, which uses the common asset codes, e.g. BTC/USD
+func getWsSymbol(pair model.QuotesPair) (string, error) {
+	if pair.Category != trade.SPOT && pair.Category != "" {
+		return "", errInvalidPair
+	}
+	return pair.Base.String() + "/" + pair.Quote.String(), nil
+}
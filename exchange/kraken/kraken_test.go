@@ -0,0 +1,691 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+package kraken
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/shopspring/decimal"
+	"github.com/wang900115/quant/exchange/rest"
+	"github.com/wang900115/quant/exchange/stream"
+	"github.com/wang900115/quant/model"
+	"github.com/wang900115/quant/model/currency"
+	"github.com/wang900115/quant/model/trade"
+)
+
+const testAPIKey = "test-key"
+
+var testSecretKey = base64.StdEncoding.EncodeToString([]byte("test-secret"))
+
+// fakeKraken serves canned REST responses and scripted public and private v2 streams
+type fakeKraken struct {
+	*httptest.Server
+	t *testing.T
+
+	mu       sync.Mutex
+	requests map[string]*http.Request
+	bodies   map[string]string
+	rest     map[string]string
+
+	// public is sent on the public stream after each subscription, keyed by channel
+	public map[string][]string
+	// subscriptions records every subscribe and unsubscribe request on the public stream
+	subscriptions []string
+	// reject answers subscriptions to these channels with the error instead of success
+	reject map[string]string
+	// private is sent on the private stream after the executions subscription
+	private []string
+	// dropPrivate closes the first private stream after its frames, resumed is sent on the ones after it
+	dropPrivate  bool
+	resumed      []string
+	privateConns int
+}
+
+func newFakeKraken(t *testing.T) *fakeKraken {
+	f := &fakeKraken{
+		t:        t,
+		requests: make(map[string]*http.Request),
+		bodies:   make(map[string]string),
+		rest:     make(map[string]string),
+		public:   make(map[string][]string),
+		reject:   make(map[string]string),
+	}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(f.Close)
+	return f
+}
+
+func (f *fakeKraken) config() KrakenConfig {
+	wsURL := "ws" + strings.TrimPrefix(f.URL, "http")
+	return KrakenConfig{
+		RestEndpoint:      f.URL,
+		PublicWsEndpoint:  wsURL + "/ws/public",
+		PrivateWsEndpoint: wsURL + "/ws/private",
+		APIKey:            testAPIKey,
+		SecretKey:         testSecretKey,
+	}
+}
+
+func (f *fakeKraken) serve(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/ws/public":
+		f.servePublic(w, r)
+		return
+	case "/ws/private":
+		f.servePrivate(w, r)
+		return
+	}
+	body, _ := io.ReadAll(r.Body)
+	f.mu.Lock()
+	f.requests[r.URL.Path] = r
+	f.bodies[r.URL.Path] = string(body)
+	payload, ok := f.rest[r.URL.Path]
+	f.mu.Unlock()
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	io.WriteString(w, payload)
+}
+
+var upgrader = websocket.Upgrader{}
+
+func (f *fakeKraken) servePublic(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	for {
+		var msg struct {
+			Method string `json:"method"`
+			ReqID  int64  `json:"req_id"`
+			Params struct {
+				Channel string `json:"channel"`
+			} `json:"params"`
+		}
+		if err := conn.ReadJSON(&msg); err != nil {
+			return
+		}
+		f.mu.Lock()
+		f.subscriptions = append(f.subscriptions, msg.Method+" "+msg.Params.Channel)
+		frames := f.public[msg.Params.Channel]
+		if msg.Method == "subscribe" {
+			// the book is only scripted once, a resubscription receives nothing
+			delete(f.public, msg.Params.Channel)
+		}
+		rejection, rejected := f.reject[msg.Params.Channel]
+		f.mu.Unlock()
+		if rejected {
+			conn.WriteJSON(map[string]interface{}{"method": msg.Method, "req_id": msg.ReqID, "success": false, "error": rejection})
+			continue
+		}
+		conn.WriteJSON(map[string]interface{}{"method": msg.Method, "req_id": msg.ReqID, "success": true,
+			"result": map[string]string{"channel": msg.Params.Channel, "symbol": "BTC/USD"}})
+		if msg.Method != "subscribe" {
+			continue
+		}
+		for _, frame := range frames {
+			conn.WriteMessage(websocket.TextMessage, []byte(frame))
+		}
+	}
+}
+
+func (f *fakeKraken) servePrivate(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	var sub struct {
+		Params struct {
+			Channel string `json:"channel"`
+			Token   string `json:"token"`
+		} `json:"params"`
+	}
+	if err := conn.ReadJSON(&sub); err != nil || sub.Params.Channel != "executions" || sub.Params.Token != "ws-token" {
+		return
+	}
+	f.mu.Lock()
+	f.privateConns++
+	frames, drop := f.private, f.dropPrivate && f.privateConns == 1
+	if f.privateConns > 1 {
+		frames = f.resumed
+	}
+	f.mu.Unlock()
+	for _, frame := range frames {
+		conn.WriteMessage(websocket.TextMessage, []byte(frame))
+	}
+	if drop {
+		return
+	}
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+func (f *fakeKraken) lastRequest(path string) (*http.Request, string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.requests[path], f.bodies[path]
+}
+
+func (f *fakeKraken) subscriptionLog() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.subscriptions...)
+}
+
+var btcusd = model.QuotesPair{
+	ExchangeID: model.KRAKEN,
+	Base:       currency.BTCSymbol,
+	Quote:      currency.USDSymbol,
+	Category:   trade.SPOT,
+}
+
+func TestKrakenSingleClient_GetPrice(t *testing.T) {
+	f := newFakeKraken(t)
+	f.rest["/0/public/Ticker"] = `{"error":[],"result":{"XXBTZUSD":{"c":["64123.5","0.01"]}}}`
+
+	p, err := NewSingleClient(f.config()).GetPrice(context.Background(), btcusd)
+	if err != nil {
+		t.Fatalf("GetPrice failed: %v", err)
+	}
+	if !p.NewPrice.Equal(decimal.RequireFromString("64123.5")) {
+		t.Errorf("unexpected price: %v", p.NewPrice)
+	}
+	req, _ := f.lastRequest("/0/public/Ticker")
+	if got := req.URL.Query().Get("pair"); got != "XBTUSD" {
+		t.Errorf("expected BTC to be sent as XBT, got %s", got)
+	}
+}
+
+func TestKrakenSingleClient_GetKlines(t *testing.T) {
+	f := newFakeKraken(t)
+	f.rest["/0/public/OHLC"] = `{"error":[],"result":{"XXBTZUSD":[
+		[1700000000,"100","102","99","101","100.5","4",10],
+		[1700000060,"101","103","100","102","101.5","5",12],
+		[1700000120,"102","104","101","103","102.5","6",14]],"last":1700000120}}`
+
+	klines, err := NewSingleClient(f.config()).GetKlines(context.Background(), btcusd, "1m", 2)
+	if err != nil {
+		t.Fatalf("GetKlines failed: %v", err)
+	}
+	if len(klines) != 2 {
+		t.Fatalf("expected the last 2 klines, got %d", len(klines))
+	}
+	if !klines[0].OpeningPrice.Equal(decimal.NewFromInt(101)) || !klines[1].Volume.Equal(decimal.NewFromInt(6)) {
+		t.Errorf("unexpected klines: %v", klines)
+	}
+	if klines[0].IntervalDuration != time.Minute {
+		t.Errorf("expected 1m duration, got %v", klines[0].IntervalDuration)
+	}
+
+	if _, err := NewSingleClient(f.config()).GetKlines(context.Background(), btcusd, "3m", 2); !errors.Is(err, errNotValidType) {
+		t.Errorf("expected errNotValidType for unsupported interval, got %v", err)
+	}
+}
+
+func TestKrakenSingleClient_GetOrderBook(t *testing.T) {
+	f := newFakeKraken(t)
+	f.rest["/0/public/Depth"] = `{"error":[],"result":{"XXBTZUSD":{
+		"bids":[["100.0","1.0",1700000000],["99.0","2.0",1700000000]],
+		"asks":[["101.0","3.0",1700000000]]}}}`
+
+	ob, err := NewSingleClient(f.config()).GetOrderBook(context.Background(), btcusd, 2)
+	if err != nil {
+		t.Fatalf("GetOrderBook failed: %v", err)
+	}
+	if len(ob.Bids) != 2 || len(ob.Asks) != 1 || !ob.Bids[0].Price.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("unexpected order book: %+v", ob)
+	}
+	req, _ := f.lastRequest("/0/public/Depth")
+	if got := req.URL.Query().Get("count"); got != "2" {
+		t.Errorf("expected count 2, got %s", got)
+	}
+}
+
+func TestKrakenSingleClient_Error(t *testing.T) {
+	f := newFakeKraken(t)
+	f.rest["/0/public/Ticker"] = `{"error":["EQuery:Unknown asset pair"]}`
+
+	_, err := NewSingleClient(f.config()).GetPrice(context.Background(), btcusd)
+	var e *rest.Error
+	if !errors.As(err, &e) {
+		t.Fatalf("expected *rest.Error, got %v", err)
+	}
+	if e.Code != "EQuery" || e.Message != "Unknown asset pair" || !errors.Is(err, rest.ErrInvalidSymbol) {
+		t.Errorf("unexpected error: %+v", e)
+	}
+
+	pair := btcusd
+	pair.Category = trade.FUTURES
+	if _, err := NewSingleClient(f.config()).GetPrice(context.Background(), pair); !errors.Is(err, errInvalidPair) {
+		t.Errorf("expected errInvalidPair for futures, got %v", err)
+	}
+}
+
+func TestKrakenTradeClient_PlaceAndCancelOrder(t *testing.T) {
+	f := newFakeKraken(t)
+	f.rest["/0/private/AddOrder"] = `{"error":[],"result":{"descr":{"order":"sell 0.01 XBTUSD @ limit 70000"},"txid":["OABCDE-12345-FGHIJK"]}}`
+	f.rest["/0/private/CancelOrder"] = `{"error":[],"result":{"count":1}}`
+
+	cfg := f.config()
+	cfg.APIKey = "" // REST only
+	client, err := NewTradeClient(cfg)
+	if err != nil {
+		t.Fatalf("NewTradeClient failed: %v", err)
+	}
+	client.apiKey = testAPIKey
+	defer client.Close()
+
+	res, err := client.PlaceOrder(context.Background(), model.OrderRequest{
+		Symbol:        "XBTUSD",
+		Side:          trade.SELL,
+		Type:          trade.LIMIT,
+		Price:         decimal.NewFromInt(70000),
+		Quantity:      decimal.RequireFromString("0.01"),
+		TimeInForce:   trade.GTC,
+		ClientOrderID: "c-1",
+	})
+	if err != nil {
+		t.Fatalf("PlaceOrder failed: %v", err)
+	}
+	if res.OrderID != "OABCDE-12345-FGHIJK" || res.ClientOrderID != "c-1" {
+		t.Errorf("unexpected order result: %+v", res)
+	}
+
+	req, body := f.lastRequest("/0/private/AddOrder")
+	form, _ := url.ParseQuery(body)
+	if form.Get("type") != "sell" || form.Get("ordertype") != "limit" || form.Get("price") != "70000" || form.Get("pair") != "XBTUSD" {
+		t.Errorf("unexpected order form: %v", form)
+	}
+	secret, _ := base64.StdEncoding.DecodeString(testSecretKey)
+	digest := sha256.Sum256([]byte(form.Get("nonce") + body))
+	mac := hmac.New(sha512.New, secret)
+	mac.Write([]byte("/0/private/AddOrder"))
+	mac.Write(digest[:])
+	if got, want := req.Header.Get("API-Sign"), base64.StdEncoding.EncodeToString(mac.Sum(nil)); got != want {
+		t.Errorf("signature mismatch: got %s want %s", got, want)
+	}
+	if req.Header.Get("API-Key") != testAPIKey {
+		t.Errorf("expected API-Key header, got %q", req.Header.Get("API-Key"))
+	}
+
+	if err := client.CancelOrder(context.Background(), "XBTUSD", res.OrderID); err != nil {
+		t.Fatalf("CancelOrder failed: %v", err)
+	}
+	_, body = f.lastRequest("/0/private/CancelOrder")
+	cancelForm, _ := url.ParseQuery(body)
+	if cancelForm.Get("txid") != res.OrderID {
+		t.Errorf("unexpected cancel form: %v", cancelForm)
+	}
+	if cancelForm.Get("nonce") <= form.Get("nonce") {
+		t.Errorf("expected an increasing nonce, got %s after %s", cancelForm.Get("nonce"), form.Get("nonce"))
+	}
+}
+
+func TestKrakenTradeClient_Nonce(t *testing.T) {
+	client := &KrakenTradeClient{}
+	client.nonce.Store(time.Now().Add(time.Hour).UnixMicro())
+	first := client.nextNonce()
+	if second := client.nextNonce(); second <= first {
+		t.Errorf("expected strictly increasing nonces, got %d then %d", first, second)
+	}
+}
+
+func TestKrakenTradeClient_Errors(t *testing.T) {
+	f := newFakeKraken(t)
+	f.rest["/0/private/AddOrder"] = `{"error":["EOrder:Insufficient funds"]}`
+
+	cfg := f.config()
+	cfg.APIKey = ""
+	client, _ := NewTradeClient(cfg)
+	defer client.Close()
+
+	order := model.OrderRequest{Symbol: "XBTUSD", Side: trade.BUY, Type: trade.MARKET, Quantity: decimal.NewFromInt(1)}
+	if _, err := client.PlaceOrder(context.Background(), order); !errors.Is(err, errNoCredentials) {
+		t.Errorf("expected errNoCredentials without an API key, got %v", err)
+	}
+
+	client.apiKey = testAPIKey
+	if _, err := client.PlaceOrder(context.Background(), order); !errors.Is(err, rest.ErrInsufficientBalance) {
+		t.Errorf("expected ErrInsufficientBalance, got %v", err)
+	}
+
+	f.rest["/0/private/AddOrder"] = `{"error":["EAPI:Invalid nonce"]}`
+	if _, err := client.PlaceOrder(context.Background(), order); !rest.IsRetryable(err) {
+		t.Errorf("expected an invalid nonce to be retryable, got %v", err)
+	}
+}
+
+func TestKrakenTradeClient_GetOrder(t *testing.T) {
+	f := newFakeKraken(t)
+	f.rest["/0/private/QueryOrders"] = `{"error":[],"result":{"O-1":{"status":"open","opentm":1700000000.5,"vol":"1.0","vol_exec":"0.4",
+		"descr":{"pair":"XBTUSD","type":"buy","ordertype":"limit","price":"60000.0"}}}}`
+
+	cfg := f.config()
+	cfg.APIKey = ""
+	client, _ := NewTradeClient(cfg)
+	client.apiKey = testAPIKey
+	defer client.Close()
+
+	od, err := client.GetOrder(context.Background(), "XBTUSD", "O-1")
+	if err != nil {
+		t.Fatalf("GetOrder failed: %v", err)
+	}
+	if od.Status != trade.PARTIALLY_FILLED || od.Side != trade.BUY || od.Type != trade.LIMIT || od.UpdateTime != 1700000000500 {
+		t.Errorf("unexpected order detail: %+v", od)
+	}
+
+	if _, err := client.GetOrder(context.Background(), "XBTUSD", "O-2"); !errors.Is(err, rest.ErrOrderNotFound) {
+		t.Errorf("expected ErrOrderNotFound, got %v", err)
+	}
+}
+
+func TestKrakenTradeClient_GetAssetBalance(t *testing.T) {
+	f := newFakeKraken(t)
+	f.rest["/0/private/BalanceEx"] = `{"error":[],"result":{
+		"XXBT":{"balance":"1.5","hold_trade":"0.5"},
+		"XBT.F":{"balance":"0.25","hold_trade":"0"},
+		"ZUSD":{"balance":"1000","hold_trade":"0"}}}`
+
+	cfg := f.config()
+	cfg.APIKey = ""
+	client, _ := NewTradeClient(cfg)
+	client.apiKey = testAPIKey
+	defer client.Close()
+
+	balance, err := client.GetAssetBalance(context.Background(), "btc")
+	if err != nil {
+		t.Fatalf("GetAssetBalance failed: %v", err)
+	}
+	if balance.Asset != currency.BTCSymbol || !balance.Free.Equal(decimal.RequireFromString("1.25")) || !balance.Locked.Equal(decimal.RequireFromString("0.5")) {
+		t.Errorf("unexpected balance: %+v", balance)
+	}
+	if _, err := client.GetAssetBalance(context.Background(), "ETH"); !errors.Is(err, errNonAssetFound) {
+		t.Errorf("expected errNonAssetFound, got %v", err)
+	}
+}
+
+func TestKrakenTradeClient_OrderStream(t *testing.T) {
+	f := newFakeKraken(t)
+	f.rest["/0/private/GetWebSocketsToken"] = `{"error":[],"result":{"token":"ws-token","expires":900}}`
+	f.private = []string{
+		`{"channel":"heartbeat"}`,
+		`{"channel":"executions","type":"update","data":[{"order_id":"O-1","symbol":"BTC/USD","side":"buy","order_type":"limit","order_status":"partially_filled","cum_qty":0.4,"last_qty":0.4,"timestamp":"2023-11-14T22:13:20Z"}]}`,
+		`{"channel":"executions","type":"update","data":[{"order_id":"O-1","symbol":"BTC/USD","side":"buy","order_type":"limit","order_status":"filled","cum_qty":1,"last_qty":0.6,"timestamp":"2023-11-14T22:13:21Z"}]}`,
+	}
+
+	client, err := NewTradeClient(f.config())
+	if err != nil {
+		t.Fatalf("NewTradeClient failed: %v", err)
+	}
+	defer client.Close()
+
+	want := []struct {
+		status trade.Status
+		last   string
+	}{
+		{trade.PARTIALLY_FILLED, "0.4"},
+		{trade.FILLED, "0.6"},
+	}
+	for _, w := range want {
+		select {
+		case evt := <-client.OrderEvents():
+			if evt.Status != w.status || !evt.LastQty.Equal(decimal.RequireFromString(w.last)) || evt.Side != trade.BUY {
+				t.Errorf("unexpected order event: %+v", evt)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for order event")
+		}
+	}
+}
+
+func TestKrakenTradeClient_OrderStreamReconnects(t *testing.T) {
+	f := newFakeKraken(t)
+	f.rest["/0/private/GetWebSocketsToken"] = `{"error":[],"result":{"token":"ws-token","expires":900}}`
+	f.private = []string{
+		`{"channel":"executions","type":"update","data":[{"order_id":"O-1","symbol":"BTC/USD","side":"buy","order_type":"limit","order_status":"partially_filled","cum_qty":0.4,"last_qty":0.4,"timestamp":"2023-11-14T22:13:20Z"}]}`,
+	}
+	f.dropPrivate = true
+	f.resumed = []string{
+		`{"channel":"executions","type":"update","data":[{"order_id":"O-1","symbol":"BTC/USD","side":"buy","order_type":"limit","order_status":"filled","cum_qty":1,"last_qty":0.6,"timestamp":"2023-11-14T22:13:21Z"}]}`,
+	}
+
+	cfg := f.config()
+	cfg.RetryInterval = 10 * time.Millisecond
+	client, err := NewTradeClient(cfg)
+	if err != nil {
+		t.Fatalf("NewTradeClient failed: %v", err)
+	}
+	defer client.Close()
+
+	for _, status := range []trade.Status{trade.PARTIALLY_FILLED, trade.FILLED} {
+		select {
+		case evt := <-client.OrderEvents():
+			if evt.Status != status {
+				t.Errorf("expected %s, got %+v", status, evt)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for order event")
+		}
+	}
+}
+
+// testChecksum builds the checksum input by hand, levels are "price qty" pairs already in the pair's precision
+func testChecksum(asks, bids [][2]string) uint32 {
+	var sb strings.Builder
+	for _, side := range [][][2]string{asks, bids} {
+		for _, l := range side {
+			for _, field := range l {
+				sb.WriteString(strings.TrimLeft(strings.Replace(field, ".", "", 1), "0"))
+			}
+		}
+	}
+	return crc32.ChecksumIEEE([]byte(sb.String()))
+}
+
+func TestOrderBook_Checksum(t *testing.T) {
+	ob := newOrderBook(10, assetPair{PairDecimals: 1, LotDecimals: 8})
+	err := ob.apply(
+		[]wsLevel{{Price: "45283.5", Qty: "0.1"}, {Price: "45283.4", Qty: "1.54582015"}},
+		[]wsLevel{{Price: "45285.2", Qty: "0.00100000"}, {Price: "45286.4", Qty: "1.54571953"}},
+	)
+	if err != nil {
+		t.Fatalf("apply failed: %v", err)
+	}
+	want := testChecksum(
+		[][2]string{{"45285.2", "0.00100000"}, {"45286.4", "1.54571953"}},
+		[][2]string{{"45283.5", "0.10000000"}, {"45283.4", "1.54582015"}},
+	)
+	if got := ob.checksum(); got != want {
+		t.Errorf("checksum mismatch: got %d want %d", got, want)
+	}
+
+	// removing a level and truncating to depth must be reflected in the checksum
+	ob.depth = 1
+	ob.apply([]wsLevel{{Price: "45283.5", Qty: "0"}}, nil)
+	want = testChecksum([][2]string{{"45285.2", "0.00100000"}}, [][2]string{{"45283.4", "1.54582015"}})
+	if got := ob.checksum(); got != want {
+		t.Errorf("checksum after update mismatch: got %d want %d", got, want)
+	}
+}
+
+func TestKrakenStreamClient_Dispatch(t *testing.T) {
+	f := newFakeKraken(t)
+	f.rest["/0/public/AssetPairs"] = `{"error":[],"result":{"XXBTZUSD":{"pair_decimals":1,"lot_decimals":8}}}`
+	snapshot := testChecksum([][2]string{{"101.0", "1.00000000"}}, [][2]string{{"100.0", "1.00000000"}, {"99.0", "2.00000000"}})
+	update := testChecksum([][2]string{{"100.5", "2.00000000"}, {"101.0", "1.00000000"}}, [][2]string{{"99.0", "2.00000000"}})
+	f.public["ticker"] = []string{
+		`{"channel":"ticker","type":"snapshot","data":[{"symbol":"BTC/USD","last":64000.1}]}`,
+	}
+	f.public["ohlc"] = []string{
+		`{"channel":"ohlc","type":"update","data":[{"symbol":"BTC/USD","open":1,"high":3,"low":0.5,"close":2,"volume":10,"interval_begin":"2023-11-14T22:13:00Z","interval":1}]}`,
+	}
+	f.public["book"] = []string{
+		fmt.Sprintf(`{"channel":"book","type":"snapshot","data":[{"symbol":"BTC/USD","bids":[{"price":100.0,"qty":1.0},{"price":99.0,"qty":2.0}],"asks":[{"price":101.0,"qty":1.0}],"checksum":%d}]}`, snapshot),
+		fmt.Sprintf(`{"channel":"book","type":"update","data":[{"symbol":"BTC/USD","bids":[{"price":100.0,"qty":0}],"asks":[{"price":100.5,"qty":2.0}],"checksum":%d,"timestamp":"2023-11-14T22:13:20.5Z"}]}`, update),
+		`{"channel":"book","type":"update","data":[{"symbol":"BTC/USD","bids":[{"price":98.0,"qty":1.0}],"asks":[],"checksum":1}]}`,
+	}
+
+	var framed atomic.Bool
+	cfg := f.config()
+	cfg.Callback = func(message []byte) error {
+		if !json.Valid(message) {
+			framed.Store(true)
+		}
+		return nil
+	}
+	client, err := NewStreamClient(cfg)
+	if err != nil {
+		t.Fatalf("NewStreamClient failed: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go client.Dispatch(ctx)
+
+	if err := client.SubscribeStream(btcusd, []string{"ticker", "ohlc.1", "book.10"}); err != nil {
+		t.Fatalf("SubscribeStream failed: %v", err)
+	}
+	prices, intervals, books := client.ReceiveStream()
+
+	select {
+	case p := <-prices:
+		if !p.NewPrice.Equal(decimal.RequireFromString("64000.1")) {
+			t.Errorf("unexpected price: %v", p.NewPrice)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for ticker")
+	}
+
+	select {
+	case k := <-intervals:
+		if k.IntervalDuration != time.Minute || !k.ClosingPrice.Equal(decimal.NewFromInt(2)) {
+			t.Errorf("unexpected kline: %+v", k)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for ohlc")
+	}
+
+	var ob model.OrderBook
+	for i := 0; i < 2; i++ {
+		select {
+		case ob = <-books:
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for order book")
+		}
+	}
+	if len(ob.Bids) != 1 || !ob.Bids[0].Price.Equal(decimal.NewFromInt(99)) {
+		t.Errorf("expected update to remove the 100 bid, got %+v", ob.Bids)
+	}
+	if len(ob.Asks) != 2 || !ob.Asks[0].Price.Equal(decimal.RequireFromString("100.5")) {
+		t.Errorf("expected asks sorted best first, got %+v", ob.Asks)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for client.BookErrors() != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the checksum mismatch")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if framed.Load() {
+		t.Error("expected the callback to see venue frames only")
+	}
+	select {
+	case ob := <-books:
+		t.Errorf("expected the corrupted book to be withheld, got %+v", ob)
+	default:
+	}
+	deadline = time.Now().Add(2 * time.Second)
+	for {
+		log := f.subscriptionLog()
+		if len(log) == 5 {
+			if log[3] != "unsubscribe book" || log[4] != "subscribe book" {
+				t.Errorf("expected the book to be resubscribed, got %v", log)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for resubscription, got %v", log)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	cancel()
+	if err := client.Close(); err != nil {
+		t.Errorf("Close failed: %v", err)
+	}
+}
+
+func TestKrakenStreamClient_Subscriptions(t *testing.T) {
+	f := newFakeKraken(t)
+	f.reject["ohlc"] = "Currency pair not supported"
+
+	client, err := NewStreamClient(f.config())
+	if err != nil {
+		t.Fatalf("NewStreamClient failed: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go client.Dispatch(ctx)
+
+	if err := client.SubscribeStream(btcusd, []string{"ticker"}); err != nil {
+		t.Fatalf("SubscribeStream failed: %v", err)
+	}
+	err = client.SubscribeStream(btcusd, []string{"ohlc.5"})
+	var e *stream.Error
+	if !errors.As(err, &e) || e.Message != "Currency pair not supported" {
+		t.Errorf("expected the rejection to surface as *stream.Error, got %v", err)
+	}
+	if subs := client.Subscriptions(); len(subs) != 1 || subs[0].Channel != "ticker" {
+		t.Errorf("expected only the ticker subscription, got %+v", subs)
+	}
+
+	if err := client.UnsubscribeStream(btcusd, []string{"ticker"}); err != nil {
+		t.Fatalf("UnsubscribeStream failed: %v", err)
+	}
+	if subs := client.Subscriptions(); len(subs) != 0 {
+		t.Errorf("expected no subscriptions after unsubscribe, got %+v", subs)
+	}
+	if log := f.subscriptionLog(); log[len(log)-1] != "unsubscribe ticker" {
+		t.Errorf("expected an unsubscribe request, got %v", log)
+	}
+
+	cancel()
+	client.Close()
+	if err := client.SubscribeStream(btcusd, []string{"ticker"}); !errors.Is(err, stream.ErrClosed) {
+		t.Errorf("expected ErrClosed after Close, got %v", err)
+	}
+}
@@ -0,0 +1,385 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+package kraken
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/wang900115/quant/common/sys"
+	"github.com/wang900115/quant/exchange/rest"
+	"github.com/wang900115/quant/exchange/stream"
+	"github.com/wang900115/quant/model"
+	"github.com/wang900115/quant/model/currency"
+	"github.com/wang900115/quant/model/trade"
+)
+
+type KrakenTradeClient struct {
+	client    *http.Client
+	scheduler *rest.Scheduler
+	endpoint  string
+	ws        *stream.Conn
+
+	engine    *sys.Engine
+	eventChan chan model.OrderEvent
+
+	// nonce must strictly increase across every private call made with the key
+	nonce  atomic.Int64
+	closed atomic.Bool
+
+	apiKey    string
+	secretKey string
+}
+
+// NewTradeClient creates the signed REST client, the private execution stream is only opened when an API key is set
+func NewTradeClient(cfg KrakenConfig) (*KrakenTradeClient, error) {
+	if cfg.PrivateTimeout == 0 {
+		cfg.PrivateTimeout = defaultTradeTimeout
+	}
+	if cfg.BufferSize == 0 {
+		cfg.BufferSize = defaultBufferSize
+	}
+	if cfg.Scheduler == nil {
+		cfg.Scheduler = NewScheduler()
+	}
+	k := &KrakenTradeClient{
+		client:    &http.Client{Timeout: cfg.PrivateTimeout},
+		scheduler: cfg.Scheduler,
+		endpoint:  cfg.restEndpoint(),
+		engine:    sys.NewEngine(cfg.RetryInterval, cfg.HealthCheckInterval),
+		eventChan: make(chan model.OrderEvent, cfg.BufferSize),
+		apiKey:    cfg.APIKey,
+		secretKey: cfg.SecretKey,
+	}
+	if cfg.APIKey == "" {
+		return k, nil
+	}
+	if err := k.connect(cfg.privateWsEndpoint()); err != nil {
+		return nil, errInitFailed
+	}
+	return k, nil
+}
+
+// connect opens the private execution stream and supervises it, a dropped stream is dialled and subscribed again
+func (kt *KrakenTradeClient) connect(endpoint string) error {
+	ws, err := stream.Dial(endpoint)
+	if err != nil {
+		return err
+	}
+	if err := kt.subscribe(ws); err != nil {
+		ws.Close()
+		return err
+	}
+
+	kt.ws = ws
+	started := false
+	kt.engine.Supervise(sys.Task{Name: "kraken.orders", Run: func(context.Context) error {
+		if started {
+			if err := kt.reconnect(); err != nil {
+				return err
+			}
+		}
+		started = true
+		return kt.listen()
+	}})
+	return nil
+}
+
+// subscribe fetches a WebSocket token and subscribes ws to the executions channel with it
+func (kt *KrakenTradeClient) subscribe(ws *stream.Conn) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAckTimeout)
+	defer cancel()
+	var token struct {
+		Token string `json:"token"`
+	}
+	if err := kt.post(ctx, "/0/private/GetWebSocketsToken", url.Values{}, privateCost, &token); err != nil {
+		return err
+	}
+	return ws.WriteJSON(map[string]interface{}{
+		"method": "subscribe",
+		"params": map[string]interface{}{
+			"channel":     "executions",
+			"token":       token.Token,
+			"snap_orders": false,
+			"snap_trades": false,
+		},
+	})
+}
+
+// reconnect dials the private stream again after listen failed
+func (kt *KrakenTradeClient) reconnect() error {
+	if kt.closed.Load() {
+		return nil
+	}
+	if err := kt.ws.Redial(); err != nil {
+		return err
+	}
+	return kt.subscribe(kt.ws)
+}
+
+// OrderEvents streams updates of the account's orders from the executions channel
+func (kt *KrakenTradeClient) OrderEvents() <-chan model.OrderEvent {
+	return kt.eventChan
+}
+
+// PlaceOrder takes the REST pair name as symbol, e.g. XBTUSD
+func (kt *KrakenTradeClient) PlaceOrder(ctx context.Context, req model.OrderRequest) (*model.OrderResult, error) {
+	data := url.Values{}
+	data.Set("pair", strings.ToUpper(req.Symbol))
+	data.Set("type", strings.ToLower(string(req.Side)))
+	data.Set("ordertype", strings.ToLower(string(req.Type)))
+	data.Set("volume", req.Quantity.String())
+	if req.Type == trade.LIMIT {
+		data.Set("price", req.Price.String())
+		if req.TimeInForce != "" {
+			data.Set("timeinforce", string(req.TimeInForce))
+		}
+	}
+	if req.ClientOrderID != "" {
+		data.Set("cl_ord_id", req.ClientOrderID)
+	}
+
+	var result struct {
+		TxID []string `json:"txid"`
+	}
+	if err := kt.post(ctx, "/0/private/AddOrder", data, tradingCost, &result); err != nil {
+		return nil, err
+	}
+	if len(result.TxID) == 0 {
+		return nil, errKrakenNoData
+	}
+	return &model.OrderResult{
+		OrderID:       result.TxID[0],
+		ClientOrderID: req.ClientOrderID,
+		Symbol:        req.Symbol,
+		Status:        trade.NEW,
+		ExecutedQty:   decimal.Zero,
+	}, nil
+}
+
+func (kt *KrakenTradeClient) GetOrder(ctx context.Context, symbol string, orderID string) (*model.OrderDetail, error) {
+	var result map[string]struct {
+		Status  string  `json:"status"`
+		OpenTm  float64 `json:"opentm"`
+		Vol     string  `json:"vol"`
+		VolExec string  `json:"vol_exec"`
+		Descr   struct {
+			Pair      string `json:"pair"`
+			Type      string `json:"type"`
+			OrderType string `json:"ordertype"`
+			Price     string `json:"price"`
+		} `json:"descr"`
+	}
+	if err := kt.post(ctx, "/0/private/QueryOrders", url.Values{"txid": {orderID}}, privateCost, &result); err != nil {
+		return nil, err
+	}
+	od, ok := result[orderID]
+	if !ok {
+		return nil, &rest.Error{Exchange: "kraken", Status: http.StatusOK, Message: "order not found", Kind: rest.KindOrderNotFound}
+	}
+
+	price, _ := decimal.NewFromString(od.Descr.Price)
+	origQty, _ := decimal.NewFromString(od.Vol)
+	executedQty, _ := decimal.NewFromString(od.VolExec)
+	return &model.OrderDetail{
+		OrderID:     orderID,
+		Symbol:      od.Descr.Pair,
+		Price:       price,
+		OrigQty:     origQty,
+		ExecutedQty: executedQty,
+		Status:      krakenStatusToTradeStatus(od.Status, executedQty),
+		Side:        trade.Signal(strings.ToUpper(od.Descr.Type)),
+		Type:        trade.Type(strings.ToUpper(od.Descr.OrderType)),
+		UpdateTime:  int64(od.OpenTm * 1000),
+	}, nil
+}
+
+func (kt *KrakenTradeClient) CancelOrder(ctx context.Context, symbol string, orderID string) error {
+	return kt.post(ctx, "/0/private/CancelOrder", url.Values{"txid": {orderID}}, tradingCost, nil)
+}
+
+// GetAssetBalance accepts common codes such as BTC, balances reported as XXBT or XBT.F are folded into them
+func (kt *KrakenTradeClient) GetAssetBalance(ctx context.Context, asset string) (*model.AssetBalance, error) {
+	var result map[string]struct {
+		Balance   string `json:"balance"`
+		HoldTrade string `json:"hold_trade"`
+	}
+	if err := kt.post(ctx, "/0/private/BalanceEx", url.Values{}, privateCost, &result); err != nil {
+		return nil, err
+	}
+
+	want := currency.CurrencySymbol(strings.ToUpper(asset))
+	var balance *model.AssetBalance
+	for code, b := range result {
+		if currency.FromVenue(currency.KrakenVenue, code) != want {
+			continue
+		}
+		total, _ := decimal.NewFromString(b.Balance)
+		held, _ := decimal.NewFromString(b.HoldTrade)
+		if balance == nil {
+			balance = &model.AssetBalance{Asset: want}
+		}
+		balance.Free = balance.Free.Add(total.Sub(held))
+		balance.Locked = balance.Locked.Add(held)
+	}
+	if balance == nil {
+		return nil, errNonAssetFound
+	}
+	return balance, nil
+}
+
+// Close stops the private execution stream
+func (kt *KrakenTradeClient) Close() error {
+	var err error
+	kt.closed.Store(true)
+	if kt.ws != nil {
+		err = kt.ws.Close()
+	}
+	kt.engine.Stop()
+	return err
+}
+
+func (kt *KrakenTradeClient) post(ctx context.Context, path string, data url.Values, cost rest.Cost, v any) error {
+	if kt.apiKey == "" || kt.secretKey == "" {
+		return errNoCredentials
+	}
+	nonce := strconv.FormatInt(kt.nextNonce(), 10)
+	data.Set("nonce", nonce)
+	body := data.Encode()
+	signature, err := kt.signature(path, nonce, body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, kt.endpoint+path, strings.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("API-Key", kt.apiKey)
+	req.Header.Set("API-Sign", signature)
+	resp, err := kt.scheduler.Do(kt.client, req, cost)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return readResult(resp, v)
+}
+
+// nextNonce returns the current time in microseconds, bumped past the last nonce when calls land in the same tick
+func (kt *KrakenTradeClient) nextNonce() int64 {
+	for {
+		last := kt.nonce.Load()
+		next := max(time.Now().UnixMicro(), last+1)
+		if kt.nonce.CompareAndSwap(last, next) {
+			return next
+		}
+	}
+}
+
+// signature is base64(HMAC-SHA512(base64-decoded secret, path + SHA256(nonce + body)))
+func (kt *KrakenTradeClient) signature(path, nonce, body string) (string, error) {
+	secret, err := base64.StdEncoding.DecodeString(kt.secretKey)
+	if err != nil {
+		return "", errNoCredentials
+	}
+	digest := sha256.Sum256([]byte(nonce + body))
+	mac := hmac.New(sha512.New, secret)
+	mac.Write([]byte(path))
+	mac.Write(digest[:])
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+// listen reads the private stream until it fails, the error restarts it through reconnect unless the client is closed
+func (kt *KrakenTradeClient) listen() error {
+	for {
+		_, message, err := kt.ws.ReadMessage()
+		if err != nil {
+			if kt.closed.Load() {
+				return nil
+			}
+			return err
+		}
+		kt.handleMessage(message)
+	}
+}
+
+func (kt *KrakenTradeClient) handleMessage(msg []byte) {
+	var raw struct {
+		Channel string `json:"channel"`
+		Data    []struct {
+			OrderID     string      `json:"order_id"`
+			Symbol      string      `json:"symbol"`
+			Side        string      `json:"side"`
+			OrderType   string      `json:"order_type"`
+			OrderStatus string      `json:"order_status"`
+			CumQty      json.Number `json:"cum_qty"`
+			LastQty     json.Number `json:"last_qty"`
+			Timestamp   string      `json:"timestamp"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(msg, &raw); err != nil {
+		return
+	}
+	if raw.Channel != "executions" {
+		return
+	}
+
+	for _, d := range raw.Data {
+		filledQty, _ := decimal.NewFromString(d.CumQty.String())
+		lastQty, _ := decimal.NewFromString(d.LastQty.String())
+		evt := model.OrderEvent{
+			OrderID:    d.OrderID,
+			Symbol:     d.Symbol,
+			Status:     krakenStatusToTradeStatus(d.OrderStatus, filledQty),
+			FilledQty:  filledQty,
+			LastQty:    lastQty,
+			Side:       trade.Signal(strings.ToUpper(d.Side)),
+			Type:       trade.Type(strings.ToUpper(d.OrderType)),
+			UpdateTime: parseTime(d.Timestamp).UnixMilli(),
+		}
+		select {
+		case kt.eventChan <- evt:
+		default:
+		}
+	}
+}
+
+// krakenStatusToTradeStatus maps REST (pending/open/closed) and WebSocket (new/filled) statuses
+func krakenStatusToTradeStatus(status string, executed decimal.Decimal) trade.Status {
+	switch status {
+	case "pending", "pending_new", "open", "new":
+		if executed.IsPositive() {
+			return trade.PARTIALLY_FILLED
+		}
+		return trade.NEW
+	case "partially_filled":
+		return trade.PARTIALLY_FILLED
+	case "closed", "filled":
+		return trade.FILLED
+	case "canceled", "expired":
+		return trade.CANCELED
+	default:
+		return trade.UNKNOWN
+	}
+}
@@ -0,0 +1,468 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+package kraken
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/wang900115/quant/exchange/stream"
+	"github.com/wang900115/quant/metric"
+	"github.com/wang900115/quant/model"
+)
+
+const defaultBookDepth = 10
+
+type KrakenStreamClient struct {
+	client *stream.Conn
+	// rest looks up pair precision for book checksums
+	rest *KrakenSingleClient
+
+	handler    func(message []byte) error
+	bufferSize int
+	tracker    *stream.Tracker
+
+	booksMu sync.Mutex
+	books   map[string]*orderBook // ws symbol -> local book
+	// bookErrors counts book frames that failed to apply or to verify against their checksum
+	bookErrors metric.CounterInt64
+
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+
+	newPriceChan      chan model.PricePoint
+	priceIntervalChan chan model.PriceInterval
+	orderBookChan     chan model.OrderBook
+}
+
+func NewStreamClient(cfg KrakenConfig) (*KrakenStreamClient, error) {
+	return newStreamClient(cfg, NewSingleClient(cfg))
+}
+
+func newStreamClient(cfg KrakenConfig, rest *KrakenSingleClient) (*KrakenStreamClient, error) {
+	if cfg.Callback == nil {
+		cfg.Callback = defaultCallback
+	}
+	if cfg.BufferSize == 0 {
+		cfg.BufferSize = defaultBufferSize
+	}
+	c := &KrakenStreamClient{
+		rest:              rest,
+		handler:           cfg.Callback,
+		bufferSize:        cfg.BufferSize,
+		tracker:           stream.NewTracker(cfg.AckTimeout),
+		books:             make(map[string]*orderBook),
+		newPriceChan:      make(chan model.PricePoint, cfg.BufferSize),
+		priceIntervalChan: make(chan model.PriceInterval, cfg.BufferSize),
+		orderBookChan:     make(chan model.OrderBook, cfg.BufferSize),
+	}
+	conn, err := stream.Dial(cfg.publicWsEndpoint())
+	if err != nil {
+		return nil, errInitFailed
+	}
+	c.client = conn
+	return c, nil
+}
+
+// Reconnect dials the feed again after Dispatch failed, the subscriptions have to be requested again once Dispatch runs.
+// Local books are dropped, subscribing again fetches fresh snapshots.
+func (kc *KrakenStreamClient) Reconnect() error {
+	if err := kc.client.Redial(); err != nil {
+		return err
+	}
+	kc.booksMu.Lock()
+	kc.books = make(map[string]*orderBook)
+	kc.booksMu.Unlock()
+	kc.tracker.Reopen()
+	return nil
+}
+
+func (kc *KrakenStreamClient) ReceiveStream() (<-chan model.PricePoint, <-chan model.PriceInterval, <-chan model.OrderBook) {
+	return kc.newPriceChan, kc.priceIntervalChan, kc.orderBookChan
+}
+
+// SubscribeStream subscribes to the v2 channels "ticker", "ohlc.<minutes>" and "book.<depth>" for the pair.
+// The interval defaults to one minute and the depth to ten levels.
+func (kc *KrakenStreamClient) SubscribeStream(pair model.QuotesPair, channels []string) error {
+	symbol, err := getWsSymbol(pair)
+	if err != nil {
+		return err
+	}
+	for _, ch := range channels {
+		params, err := channelParams(symbol, ch)
+		if err != nil {
+			return err
+		}
+		if depth, ok := params["depth"].(int); ok {
+			ctx, cancel := context.WithTimeout(context.Background(), defaultAckTimeout)
+			precision, err := kc.rest.assetPair(ctx, pair)
+			cancel()
+			if err != nil {
+				return err
+			}
+			kc.booksMu.Lock()
+			kc.books[symbol] = newOrderBook(depth, precision)
+			kc.booksMu.Unlock()
+		}
+		if err := kc.request("subscribe", params); err != nil {
+			kc.dropBook(symbol, params)
+			return err
+		}
+		kc.tracker.Add(model.Subscription{Pair: pair, Channel: ch})
+	}
+	return nil
+}
+
+// UnsubscribeStream stops the channels, they must be spelled as they were subscribed
+func (kc *KrakenStreamClient) UnsubscribeStream(pair model.QuotesPair, channels []string) error {
+	symbol, err := getWsSymbol(pair)
+	if err != nil {
+		return err
+	}
+	for _, ch := range channels {
+		params, err := channelParams(symbol, ch)
+		if err != nil {
+			return err
+		}
+		if err := kc.request("unsubscribe", params); err != nil {
+			return err
+		}
+		kc.dropBook(symbol, params)
+		kc.tracker.Remove(model.Subscription{Pair: pair, Channel: ch})
+	}
+	return nil
+}
+
+func (kc *KrakenStreamClient) Subscriptions() []model.Subscription {
+	return kc.tracker.Subscriptions()
+}
+
+// BookErrors counts the book frames dropped because they failed to apply or verify, a failed checksum resubscribes the book
+func (kc *KrakenStreamClient) BookErrors() int64 {
+	return kc.bookErrors.Snapshot().Count()
+}
+
+// channelParams translates "ohlc.5" or "book.25" into the params of a v2 request
+func channelParams(symbol, channel string) (map[string]interface{}, error) {
+	name, arg, _ := strings.Cut(channel, ".")
+	params := map[string]interface{}{
+		"channel": name,
+		"symbol":  []string{symbol},
+	}
+	switch name {
+	case "ohlc":
+		interval := 1
+		if arg != "" {
+			var err error
+			if interval, err = strconv.Atoi(arg); err != nil || !validInterval(interval) {
+				return nil, errNotValidType
+			}
+		}
+		params["interval"] = interval
+	case "book":
+		depth := defaultBookDepth
+		if arg != "" {
+			var err error
+			if depth, err = strconv.Atoi(arg); err != nil {
+				return nil, errNotValidType
+			}
+		}
+		params["depth"] = depth
+	}
+	return params, nil
+}
+
+// request sends a subscribe or unsubscribe and waits for the response echoing its req_id
+func (kc *KrakenStreamClient) request(method string, params map[string]interface{}) error {
+	id := kc.tracker.NextID()
+	return kc.tracker.Do(id, func() error { return kc.send(id, method, params) })
+}
+
+func (kc *KrakenStreamClient) send(id int64, method string, params map[string]interface{}) error {
+	return kc.client.WriteJSON(map[string]interface{}{
+		"method": method,
+		"params": params,
+		"req_id": id,
+	})
+}
+
+func (kc *KrakenStreamClient) dropBook(symbol string, params map[string]interface{}) {
+	if params["channel"] != "book" {
+		return
+	}
+	kc.booksMu.Lock()
+	delete(kc.books, symbol)
+	kc.booksMu.Unlock()
+}
+
+func (kc *KrakenStreamClient) Dispatch(ctx context.Context) error {
+	dispatchers := kc.getDispatchers()
+	kc.wg.Add(1)
+	defer kc.wg.Done()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+			_, message, err := kc.client.ReadMessage()
+			received := time.Now()
+			if err != nil {
+				kc.tracker.Close()
+				return err
+			}
+			if kc.handler != nil {
+				kc.handler(message)
+			}
+			if a, ok := parseAck(message); ok {
+				kc.tracker.Resolve(a.id, a.err)
+				continue
+			}
+			if fn, ok := dispatchers[getMessageType(message)]; ok {
+				fn(kc, message, received)
+			}
+		}
+	}
+}
+
+func (kc *KrakenStreamClient) Close() error {
+	var err error
+	kc.closeOnce.Do(func() {
+		kc.tracker.Close()
+		err = kc.client.Close()
+		// the reader exits once the connection is closed, after that nothing writes to the channels
+		kc.wg.Wait()
+		close(kc.newPriceChan)
+		close(kc.priceIntervalChan)
+		close(kc.orderBookChan)
+	})
+	return err
+}
+
+type dispatchFunc func(*KrakenStreamClient, []byte, time.Time)
+
+func (kc *KrakenStreamClient) getDispatchers() map[string]dispatchFunc {
+	return map[string]dispatchFunc{
+		"ticker": func(client *KrakenStreamClient, msg []byte, received time.Time) {
+			if points, err := parsePricePoints(msg); err == nil {
+				for _, p := range points {
+					model.DispatchPrice(client.newPriceChan, p, received)
+				}
+			}
+		},
+		"ohlc": func(client *KrakenStreamClient, msg []byte, received time.Time) {
+			if intervals, err := parsePriceInterval(msg); err == nil {
+				for _, interval := range intervals {
+					model.PushToChan(client.priceIntervalChan, interval)
+				}
+			}
+		},
+		"book": func(client *KrakenStreamClient, msg []byte, received time.Time) {
+			books, err := client.applyOrderBook(msg)
+			for _, ob := range books {
+				model.PushToChan(client.orderBookChan, ob)
+			}
+			if err != nil {
+				client.bookErrors.Inc(1)
+				log.Printf("[kraken] book: %v", err)
+			}
+		},
+	}
+}
+
+// ack is the response to a subscribe or unsubscribe request
+type ack struct {
+	id  string
+	err error
+}
+
+// parseAck recognizes {"method":"subscribe","req_id":1,"success":false,"error":"..."}
+func parseAck(msg []byte) (ack, bool) {
+	var raw struct {
+		Method  string      `json:"method"`
+		ReqID   json.Number `json:"req_id"`
+		Success bool        `json:"success"`
+		Error   string      `json:"error"`
+	}
+	if err := json.Unmarshal(msg, &raw); err != nil || raw.ReqID == "" {
+		return ack{}, false
+	}
+	if raw.Method != "subscribe" && raw.Method != "unsubscribe" {
+		return ack{}, false
+	}
+	a := ack{id: raw.ReqID.String()}
+	if !raw.Success {
+		a.err = &stream.Error{Exchange: "kraken", Message: raw.Error}
+	}
+	return a, true
+}
+
+func getMessageType(msg []byte) string {
+	var raw struct {
+		Channel string `json:"channel"`
+		Method  string `json:"method"`
+	}
+	if err := json.Unmarshal(msg, &raw); err != nil {
+		return ""
+	}
+	if raw.Channel == "" {
+		return raw.Method
+	}
+	return raw.Channel
+}
+
+func parsePricePoints(msg []byte) ([]model.PricePoint, error) {
+	var raw struct {
+		Data []struct {
+			Symbol    string      `json:"symbol"`
+			Last      json.Number `json:"last"`
+			Timestamp string      `json:"timestamp"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(msg, &raw); err != nil {
+		return nil, err
+	}
+	points := make([]model.PricePoint, 0, len(raw.Data))
+	for _, d := range raw.Data {
+		if d.Last == "" {
+			continue
+		}
+		price, err := decimal.NewFromString(d.Last.String())
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, model.PricePoint{
+			NewPrice:  price,
+			UpdatedAt: parseTime(d.Timestamp),
+		})
+	}
+	return points, nil
+}
+
+func parsePriceInterval(msg []byte) ([]model.PriceInterval, error) {
+	var raw struct {
+		Data []struct {
+			Open          json.Number `json:"open"`
+			High          json.Number `json:"high"`
+			Low           json.Number `json:"low"`
+			Close         json.Number `json:"close"`
+			Volume        json.Number `json:"volume"`
+			IntervalBegin string      `json:"interval_begin"`
+			Interval      int         `json:"interval"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(msg, &raw); err != nil {
+		return nil, err
+	}
+	intervals := make([]model.PriceInterval, 0, len(raw.Data))
+	for _, k := range raw.Data {
+		values := make([]decimal.Decimal, 0, 5)
+		for _, n := range []json.Number{k.Open, k.High, k.Low, k.Close, k.Volume} {
+			v, err := decimal.NewFromString(n.String())
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		}
+		openTime := parseTime(k.IntervalBegin)
+		duration := time.Duration(k.Interval) * time.Minute
+		intervals = append(intervals, model.PriceInterval{
+			OpenTime:         openTime.Format(time.RFC3339),
+			CloseTime:        openTime.Add(duration).Format(time.RFC3339),
+			OpeningPrice:     values[0],
+			HighestPrice:     values[1],
+			LowestPrice:      values[2],
+			ClosingPrice:     values[3],
+			Volume:           values[4],
+			IntervalDuration: duration,
+		})
+	}
+	return intervals, nil
+}
+
+// applyOrderBook folds a snapshot or update into the local books and verifies each against its checksum.
+// A book that fails verification is dropped and resubscribed, which makes Kraken send a fresh snapshot.
+func (kc *KrakenStreamClient) applyOrderBook(msg []byte) ([]model.OrderBook, error) {
+	var raw struct {
+		Type string `json:"type"`
+		Data []struct {
+			Symbol    string    `json:"symbol"`
+			Bids      []wsLevel `json:"bids"`
+			Asks      []wsLevel `json:"asks"`
+			Checksum  uint32    `json:"checksum"`
+			Timestamp string    `json:"timestamp"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(msg, &raw); err != nil {
+		return nil, err
+	}
+
+	kc.booksMu.Lock()
+	defer kc.booksMu.Unlock()
+	books := make([]model.OrderBook, 0, len(raw.Data))
+	var mismatch error
+	for _, d := range raw.Data {
+		book, ok := kc.books[d.Symbol]
+		if !ok {
+			continue
+		}
+		if raw.Type == "snapshot" {
+			book = newOrderBook(book.depth, book.precision)
+			kc.books[d.Symbol] = book
+		} else if book.stale {
+			// updates until the next snapshot belong to the abandoned book
+			continue
+		}
+		if err := book.apply(d.Bids, d.Asks); err != nil {
+			return books, err
+		}
+		if book.checksum() != d.Checksum {
+			book.stale = true
+			mismatch = errChecksum
+			kc.resubscribeBook(d.Symbol, book.depth)
+			continue
+		}
+		books = append(books, model.OrderBook{
+			Symbol: d.Symbol,
+			Time:   parseTime(d.Timestamp),
+			Bids:   book.bids(),
+			Asks:   book.asks(),
+		})
+	}
+	return books, mismatch
+}
+
+func (kc *KrakenStreamClient) resubscribeBook(symbol string, depth int) {
+	params := map[string]interface{}{
+		"channel": "book",
+		"symbol":  []string{symbol},
+		"depth":   depth,
+	}
+	// the acknowledgements are not awaited, this runs on the reader that would deliver them
+	if err := kc.send(kc.tracker.NextID(), "unsubscribe", params); err != nil {
+		return
+	}
+	kc.send(kc.tracker.NextID(), "subscribe", params)
+}
+
+func parseTime(value string) time.Time {
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t
+	}
+	return time.Now()
+}
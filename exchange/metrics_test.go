@@ -0,0 +1,73 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package exchange
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/wang900115/quant/metric"
+	"github.com/wang900115/quant/model"
+	"github.com/wang900115/quant/model/currency"
+	"github.com/wang900115/quant/model/trade"
+)
+
+// stubProvider answers GetPrice and Subscriptions, every other call panics through the nil embedded interface
+type stubProvider struct {
+	Provider
+	err  error
+	subs []model.Subscription
+}
+
+func (s stubProvider) GetPrice(context.Context, model.QuotesPair) (*model.PricePoint, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &model.PricePoint{}, nil
+}
+
+func (s stubProvider) Subscriptions() []model.Subscription { return s.subs }
+
+func TestProviders_Collect(t *testing.T) {
+	btc := model.QuotesPair{ExchangeID: model.OKX, Base: currency.BTCSymbol, Quote: currency.USDTSymbol, Category: trade.SPOT}
+	ps := New()
+	ps.Register(model.OKX, stubProvider{subs: []model.Subscription{{Pair: btc, Channel: "tickers"}}})
+	ps.Register(model.BYBIT, stubProvider{err: errors.New("down")})
+
+	ps.GetPrice(context.Background(), btc)
+	ps.GetPrice(context.Background(), btc)
+	ps.GetPrice(context.Background(), model.QuotesPair{ExchangeID: model.BYBIT, Base: currency.BTCSymbol, Quote: currency.USDTSymbol})
+	ps.GetPrice(context.Background(), model.QuotesPair{ExchangeID: model.KRAKEN, Base: currency.BTCSymbol, Quote: currency.USDTSymbol})
+
+	var b strings.Builder
+	if err := metric.WriteText(&b, ps.Collect()); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	out := b.String()
+	for _, line := range []string{
+		`quant_provider_requests_total{exchange="OKX",method="GetPrice",outcome="ok",pair="BTC/USDT"} 2`,
+		`quant_provider_requests_total{exchange="Bybit",method="GetPrice",outcome="error",pair="BTC/USDT"} 1`,
+		`quant_provider_requests_total{exchange="Kraken",method="GetPrice",outcome="error",pair="BTC/USDT"} 1`,
+		`quant_provider_registered{exchange="OKX"} 1`,
+		`quant_provider_subscriptions{channel="tickers",exchange="OKX",pair="BTC/USDT"} 1`,
+		`quant_provider_request_duration_seconds_count{exchange="OKX",method="GetPrice"} 2`,
+		`quant_provider_request_duration_seconds_count{exchange="Kraken",method="GetPrice"} 1`,
+	} {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("missing %s in:\n%s", line, out)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package okx
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/wang900115/quant/exchange/rest"
+)
+
+const requestIDHeader = "X-Request-Id"
+
+// readResponse returns the body of a successful OKX response.
+// OKX reports most failures as HTTP 200 with a non-zero code, and per-order failures in data[].sCode,
+// so both the status and the envelope are checked before the body is handed back.
+func readResponse(resp *http.Response) ([]byte, error) {
+	if resp.StatusCode != http.StatusOK {
+		return nil, newError(resp, rest.ReadBody(resp))
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var envelope struct {
+		Code string `json:"code"`
+		Data []struct {
+			SCode string `json:"sCode"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, err
+	}
+	if envelope.Code != "" && envelope.Code != "0" {
+		return nil, newError(resp, body)
+	}
+	for _, d := range envelope.Data {
+		if d.SCode != "" && d.SCode != "0" {
+			return nil, newError(resp, body)
+		}
+	}
+	return body, nil
+}
+
+// newError parses {"code": "1", "msg": "...", "data": [{"sCode": "51008", "sMsg": "..."}]} into a *rest.Error
+func newError(resp *http.Response, body []byte) error {
+	e := rest.NewError("okx", resp, requestIDHeader)
+	var payload struct {
+		Code string `json:"code"`
+		Msg  string `json:"msg"`
+		Data []struct {
+			SCode string `json:"sCode"`
+			SMsg  string `json:"sMsg"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil || payload.Code == "" {
+		e.Message = strings.TrimSpace(string(body))
+		return e
+	}
+	e.Code, e.Message = payload.Code, payload.Msg
+	// the operation level code only says "failed", the item code carries the reason
+	for _, d := range payload.Data {
+		if d.SCode != "" && d.SCode != "0" {
+			e.Code, e.Message = d.SCode, d.SMsg
+			break
+		}
+	}
+	if kind := classify(e.Code); kind != rest.KindUnknown {
+		e.Classify(kind)
+	}
+	return e
+}
+
+func classify(code string) rest.Kind {
+	switch code {
+	case "50011", "50061":
+		return rest.KindRateLimited
+	case "50001", "50004", "50013", "50026":
+		return rest.KindUnavailable
+	case "51001":
+		return rest.KindInvalidSymbol
+	case "51008", "51119":
+		return rest.KindInsufficientBalance
+	case "51603", "51400":
+		return rest.KindOrderNotFound
+	case "50103", "50104", "50105", "50111", "50113":
+		return rest.KindUnauthorized
+	default:
+		return rest.KindUnknown
+	}
+}
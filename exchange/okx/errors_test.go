@@ -0,0 +1,87 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+package okx
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/wang900115/quant/exchange/rest"
+	"github.com/wang900115/quant/exchange/stream"
+)
+
+func response(status int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func TestReadResponse(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		code   string
+		target error
+	}{
+		{"Invalid instrument", 200, `{"code":"51001","msg":"Instrument ID does not exist","data":[]}`, "51001", rest.ErrInvalidSymbol},
+		{"Order item failure", 200, `{"code":"1","msg":"Operation failed.","data":[{"ordId":"","sCode":"51008","sMsg":"Insufficient balance"}]}`, "51008", rest.ErrInsufficientBalance},
+		{"Rate limited", 429, `{"code":"50011","msg":"Too Many Requests"}`, "50011", rest.ErrRateLimited},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := readResponse(response(tt.status, tt.body))
+			var e *rest.Error
+			if !errors.As(err, &e) {
+				t.Fatalf("expected *rest.Error, got %v", err)
+			}
+			if e.Code != tt.code {
+				t.Errorf("expected code %s, got %s", tt.code, e.Code)
+			}
+			if !errors.Is(err, tt.target) {
+				t.Errorf("expected %v to match %v", err, tt.target)
+			}
+		})
+	}
+}
+
+func TestReadResponseSuccess(t *testing.T) {
+	body := `{"code":"0","msg":"","data":[{"ordId":"1","sCode":"0","sMsg":""}]}`
+	got, err := readResponse(response(http.StatusOK, body))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(got) != body {
+		t.Errorf("expected body to be returned unchanged, got %s", got)
+	}
+}
+
+func TestParseAck(t *testing.T) {
+	a, ok := parseAck([]byte(`{"id":"3","event":"subscribe","arg":{"channel":"tickers","instId":"BTC-USDT"},"connId":"a4d3ae55"}`))
+	if !ok || a.id != "3" || a.err != nil {
+		t.Errorf("expected a successful ack for id 3, got %+v %v", a, ok)
+	}
+	a, ok = parseAck([]byte(`{"id":"4","event":"error","code":"60018","msg":"Wrong URL or channel:tickers,instId:FOO-BAR doesn't exist.","connId":"a4d3ae55"}`))
+	var e *stream.Error
+	if !ok || a.id != "4" || !errors.As(a.err, &e) || e.Code != "60018" {
+		t.Errorf("expected a rejection for id 4, got %+v %v", a, ok)
+	}
+	if _, ok := parseAck([]byte(`{"arg":{"channel":"tickers","instId":"BTC-USDT"},"data":[{"last":"1"}]}`)); ok {
+		t.Error("expected market data not to be taken for an ack")
+	}
+}
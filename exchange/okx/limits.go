@@ -0,0 +1,52 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package okx
+
+import (
+	"time"
+
+	"github.com/wang900115/quant/exchange/rest"
+)
+
+// OKX limits each endpoint separately, counted in requests per two seconds
+const (
+	tickerBucket      = "market/ticker"
+	candlesBucket     = "market/candles"
+	booksBucket       = "market/books"
+	placeOrderBucket  = "trade/order"
+	queryOrderBucket  = "trade/order/get"
+	cancelOrderBucket = "trade/cancel-order"
+	balanceBucket     = "account/balance"
+)
+
+const limitWindow = 2 * time.Second
+
+// NewScheduler creates the request scheduler shared by the OKX REST clients
+func NewScheduler() *rest.Scheduler {
+	return rest.NewScheduler(rest.Config{
+		Buckets: []rest.Bucket{
+			{Name: tickerBucket, Capacity: 20, Window: limitWindow},
+			{Name: candlesBucket, Capacity: 40, Window: limitWindow},
+			{Name: booksBucket, Capacity: 40, Window: limitWindow},
+			{Name: placeOrderBucket, Capacity: 60, Window: limitWindow},
+			{Name: queryOrderBucket, Capacity: 60, Window: limitWindow},
+			{Name: cancelOrderBucket, Capacity: 60, Window: limitWindow},
+			{Name: balanceBucket, Capacity: 10, Window: limitWindow},
+		},
+	})
+}
+
+func cost(bucket string) rest.Cost {
+	return rest.Cost{Bucket: bucket, Weight: 1}
+}
@@ -24,6 +24,7 @@ import (
 
 	"github.com/shopspring/decimal"
 	"github.com/wang900115/quant/common/parse"
+	"github.com/wang900115/quant/exchange/rest"
 	"github.com/wang900115/quant/model"
 	"github.com/wang900115/quant/model/trade"
 )
@@ -47,15 +48,15 @@ const (
 )
 
 var (
-	errOkxNoData      = errors.New("okx: no data returned")
-	errResponseFailed = errors.New("okx: response failed")
-	errNotValidType   = errors.New("okx: not valid type")
-	errInitFailed     = errors.New("okx: initialization failed")
-	errNonAssetFound  = errors.New("okx: no such asset found")
+	errOkxNoData     = errors.New("okx: no data returned")
+	errNotValidType  = errors.New("okx: not valid type")
+	errInitFailed    = errors.New("okx: initialization failed")
+	errNonAssetFound = errors.New("okx: no such asset found")
 )
 
 type OkxSingleClient struct {
 	httpClient *http.Client
+	scheduler  *rest.Scheduler
 }
 
 func NewSingleClient(cfg OkxConfig) *OkxSingleClient {
@@ -63,8 +64,12 @@ func NewSingleClient(cfg OkxConfig) *OkxSingleClient {
 	if timeout == 0 {
 		timeout = defaultTimeout
 	}
+	if cfg.Scheduler == nil {
+		cfg.Scheduler = NewScheduler()
+	}
 	return &OkxSingleClient{
 		httpClient: &http.Client{Timeout: timeout},
+		scheduler:  cfg.Scheduler,
 	}
 }
 
@@ -75,13 +80,14 @@ func (oc *OkxSingleClient) GetPrice(ctx context.Context, pair model.QuotesPair)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := oc.httpClient.Do(req)
+	resp, err := oc.scheduler.Do(oc.httpClient, req, cost(tickerBucket))
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return nil, errResponseFailed
+	body, err := readResponse(resp)
+	if err != nil {
+		return nil, err
 	}
 	var raw struct {
 		Code string `json:"code"`
@@ -90,7 +96,7 @@ func (oc *OkxSingleClient) GetPrice(ctx context.Context, pair model.QuotesPair)
 			Last string `json:"last"`
 		} `json:"data"`
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+	if err := json.Unmarshal(body, &raw); err != nil {
 		return nil, err
 	}
 	if len(raw.Data) == 0 {
@@ -117,13 +123,14 @@ func (oc *OkxSingleClient) GetKlines(ctx context.Context, pair model.QuotesPair,
 		return nil, err
 	}
 
-	resp, err := oc.httpClient.Do(req)
+	resp, err := oc.scheduler.Do(oc.httpClient, req, cost(candlesBucket))
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return nil, errResponseFailed
+	body, err := readResponse(resp)
+	if err != nil {
+		return nil, err
 	}
 	var raw struct {
 		Code string     `json:"code"`
@@ -131,14 +138,10 @@ func (oc *OkxSingleClient) GetKlines(ctx context.Context, pair model.QuotesPair,
 		Data [][]string `json:"data"` // [ts, open, high, low, close, vol, volCcy, volCcyQuote, confirm]
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+	if err := json.Unmarshal(body, &raw); err != nil {
 		return nil, fmt.Errorf("failed to decode klines: %w", err)
 	}
 
-	if raw.Code != "0" {
-		return nil, fmt.Errorf("okx api error: code=%s, msg=%s", raw.Code, raw.Msg)
-	}
-
 	intervals := make([]model.PriceInterval, 0, limit)
 
 	for _, kline := range raw.Data {
@@ -202,14 +205,14 @@ func (oc *OkxSingleClient) GetOrderBook(ctx context.Context, pair model.QuotesPa
 	if err != nil {
 		return nil, err
 	}
-	resp, err := oc.httpClient.Do(req)
+	resp, err := oc.scheduler.Do(oc.httpClient, req, cost(booksBucket))
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, errResponseFailed
+	body, err := readResponse(resp)
+	if err != nil {
+		return nil, err
 	}
 
 	var raw struct {
@@ -220,11 +223,11 @@ func (oc *OkxSingleClient) GetOrderBook(ctx context.Context, pair model.QuotesPa
 			Ts   string          `json:"ts"`
 		} `json:"data"`
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+	if err := json.Unmarshal(body, &raw); err != nil {
 		return nil, err
 	}
-	if raw.Code != "0" || len(raw.Data) == 0 {
-		return nil, errResponseFailed
+	if len(raw.Data) == 0 {
+		return nil, errOkxNoData
 	}
 	ts, _ := strconv.ParseInt(raw.Data[0].Ts, 10, 64)
 	bids, err := model.ParseOrderEntries[model.OrderBookBid](raw.Data[0].Bids)
@@ -253,8 +256,14 @@ type OkxConfig struct {
 	SecretKey      string
 	Passphrase     string
 
+	// AckTimeout bounds how long SubscribeStream waits for the venue to confirm, zero selects ten seconds
+	AckTimeout time.Duration
+
 	RetryInterval       time.Duration
 	HealthCheckInterval time.Duration
+
+	// Scheduler shares rate limits between clients, one is created when nil
+	Scheduler *rest.Scheduler
 }
 
 type OkxClient struct {
@@ -264,6 +273,9 @@ type OkxClient struct {
 }
 
 func New(config OkxConfig) *OkxClient {
+	if config.Scheduler == nil {
+		config.Scheduler = NewScheduler()
+	}
 	streamClient, err := NewStreamClient(config)
 	if err != nil {
 		panic(err)
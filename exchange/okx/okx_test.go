@@ -311,6 +311,10 @@ func TestOkxStreamClient_Subscribe(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed to create stream client: %v", err)
 	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// subscriptions wait for acknowledgements, which the dispatcher reads
+	go client.Dispatch(ctx)
 	defer client.Close()
 
 	pair := model.QuotesPair{
@@ -366,13 +370,6 @@ func TestOkxStreamClient_ReceiveData(t *testing.T) {
 		Category: trade.SPOT,
 	}
 
-	err = client.SubscribeStream(pair, []string{"tickers"})
-	if err != nil {
-		t.Fatalf("failed to subscribe: %v", err)
-	}
-
-	priceChan, _, _ := client.ReceiveStream()
-
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
@@ -383,6 +380,13 @@ func TestOkxStreamClient_ReceiveData(t *testing.T) {
 		}
 	}()
 
+	err = client.SubscribeStream(pair, []string{"tickers"})
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+
+	priceChan, _, _ := client.ReceiveStream()
+
 	receivedCount := 0
 	maxUpdates := 2
 
@@ -408,6 +412,10 @@ func TestOkxStreamClient_MultipleCategories(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed to create stream client: %v", err)
 	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// subscriptions wait for acknowledgements, which the dispatcher reads
+	go client.Dispatch(ctx)
 	defer client.Close()
 
 	testCases := []struct {
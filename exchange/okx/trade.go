@@ -26,14 +26,16 @@ import (
 	"github.com/gorilla/websocket"
 	"github.com/shopspring/decimal"
 	"github.com/wang900115/quant/common/sys"
+	"github.com/wang900115/quant/exchange/rest"
 	"github.com/wang900115/quant/model"
 	"github.com/wang900115/quant/model/currency"
 	"github.com/wang900115/quant/model/trade"
 )
 
 type OkxTradeClient struct {
-	client *http.Client
-	ws     *websocket.Conn
+	client    *http.Client
+	scheduler *rest.Scheduler
+	ws        *websocket.Conn
 
 	engine    *sys.Engine
 	eventChan chan model.OrderEvent
@@ -50,9 +52,13 @@ func NewTradeClient(cfg OkxConfig) (*OkxTradeClient, error) {
 	if cfg.BufferSize == 0 {
 		cfg.BufferSize = defaultBufferSize
 	}
+	if cfg.Scheduler == nil {
+		cfg.Scheduler = NewScheduler()
+	}
 
 	o := &OkxTradeClient{
 		client:     &http.Client{Timeout: cfg.PrivateTimeout},
+		scheduler:  cfg.Scheduler,
 		apiKey:     cfg.APIKey,
 		secretKey:  cfg.SecretKey,
 		passphrase: cfg.Passphrase,
@@ -71,7 +77,10 @@ func (ok *OkxTradeClient) connect() error {
 		return err
 	}
 	ok.ws = c
-	go ok.listen()
+	ok.engine.Supervise(sys.Task{Name: "okx.orders", Run: func(context.Context) error {
+		ok.listen()
+		return nil
+	}})
 	return nil
 }
 
@@ -96,11 +105,15 @@ func (ok *OkxTradeClient) PlaceOrder(ctx context.Context, req model.OrderRequest
 	httpReq.Header.Set("OK-ACCESS-PASSPHRASE", ok.passphrase)
 	httpReq.Header.Set("Content-Type", "application/json")
 
-	resp, err := ok.client.Do(httpReq)
+	resp, err := ok.scheduler.Do(ok.client, httpReq, cost(placeOrderBucket))
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
+	body, err := readResponse(resp)
+	if err != nil {
+		return nil, err
+	}
 
 	var result struct {
 		Code string `json:"code"`
@@ -108,12 +121,12 @@ func (ok *OkxTradeClient) PlaceOrder(ctx context.Context, req model.OrderRequest
 			OrdId string `json:"ordId"`
 		} `json:"data"`
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := json.Unmarshal(body, &result); err != nil {
 		return nil, err
 	}
 
 	if len(result.Data) == 0 {
-		return nil, fmt.Errorf("order failed: %s", result.Code)
+		return nil, errOkxNoData
 	}
 
 	return &model.OrderResult{
@@ -133,11 +146,15 @@ func (ok *OkxTradeClient) GetOrder(ctx context.Context, symbol string, orderID s
 	req.Header.Set("OK-ACCESS-SIGN", ok.secretKey) // Note: In practice, the signature should be generated properly
 	req.Header.Set("OK-ACCESS-PASSPHRASE", ok.passphrase)
 
-	resp, err := ok.client.Do(req)
+	resp, err := ok.scheduler.Do(ok.client, req, cost(queryOrderBucket))
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
+	body, err := readResponse(resp)
+	if err != nil {
+		return nil, err
+	}
 
 	var raw struct {
 		Code string `json:"code"`
@@ -154,11 +171,11 @@ func (ok *OkxTradeClient) GetOrder(ctx context.Context, symbol string, orderID s
 			UTime     string `json:"uTime"`
 		} `json:"data"`
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+	if err := json.Unmarshal(body, &raw); err != nil {
 		return nil, err
 	}
 	if len(raw.Data) == 0 {
-		return nil, fmt.Errorf("order not found")
+		return nil, &rest.Error{Exchange: "okx", Status: resp.StatusCode, Message: "order not found", Kind: rest.KindOrderNotFound}
 	}
 
 	d := raw.Data[0]
@@ -188,16 +205,14 @@ func (ok *OkxTradeClient) CancelOrder(ctx context.Context, symbol string, orderI
 	req.Header.Set("OK-ACCESS-SIGN", ok.secretKey) // Note: In practice, the signature should be generated properly
 	req.Header.Set("OK-ACCESS-PASSPHRASE", ok.passphrase)
 
-	resp, err := ok.client.Do(req)
+	resp, err := ok.scheduler.Do(ok.client, req, cost(cancelOrderBucket))
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("cancel order failed: %v", resp.Status)
-	}
-	return nil
+	_, err = readResponse(resp)
+	return err
 }
 
 func (ok *OkxTradeClient) GetAssetBalance(ctx context.Context, asset string) (*model.AssetBalance, error) {
@@ -208,11 +223,15 @@ func (ok *OkxTradeClient) GetAssetBalance(ctx context.Context, asset string) (*m
 	req.Header.Set("OK-ACCESS-SIGN", ok.secretKey) // Note: In practice, the signature should be generated properly
 	req.Header.Set("OK-ACCESS-PASSPHRASE", ok.passphrase)
 
-	resp, err := ok.client.Do(req)
+	resp, err := ok.scheduler.Do(ok.client, req, cost(balanceBucket))
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
+	body, err := readResponse(resp)
+	if err != nil {
+		return nil, err
+	}
 
 	var raw struct {
 		Code string `json:"code"`
@@ -225,7 +244,7 @@ func (ok *OkxTradeClient) GetAssetBalance(ctx context.Context, asset string) (*m
 			} `json:"details"`
 		} `json:"data"`
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+	if err := json.Unmarshal(body, &raw); err != nil {
 		return nil, err
 	}
 
@@ -20,16 +20,17 @@ import (
 	"strings"
 	"time"
 
-	"github.com/gorilla/websocket"
 	"github.com/shopspring/decimal"
+	"github.com/wang900115/quant/exchange/stream"
 	"github.com/wang900115/quant/model"
 )
 
 type OkxStreamClient struct {
-	client *websocket.Conn
+	client *stream.Conn
 
 	handler    func(message []byte) error
 	bufferSize int
+	tracker    *stream.Tracker
 
 	newPriceChan      chan model.PricePoint
 	priceIntervalChan chan model.PriceInterval
@@ -46,6 +47,7 @@ func NewStreamClient(cfg OkxConfig) (*OkxStreamClient, error) {
 	c := &OkxStreamClient{
 		handler:           cfg.Callback,
 		bufferSize:        cfg.BufferSize,
+		tracker:           stream.NewTracker(cfg.AckTimeout),
 		newPriceChan:      make(chan model.PricePoint, cfg.BufferSize),
 		priceIntervalChan: make(chan model.PriceInterval, cfg.BufferSize),
 		orderBookChan:     make(chan model.OrderBook, cfg.BufferSize),
@@ -63,7 +65,7 @@ func NewStreamClient(cfg OkxConfig) (*OkxStreamClient, error) {
 }
 
 func (oc *OkxStreamClient) connect(url string) error {
-	c, _, err := websocket.DefaultDialer.Dial(url, nil)
+	c, err := stream.Dial(url)
 	if err != nil {
 		return err
 	}
@@ -72,6 +74,7 @@ func (oc *OkxStreamClient) connect(url string) error {
 }
 
 func (oc *OkxStreamClient) Close() error {
+	oc.tracker.Close()
 	if oc.client != nil {
 		return oc.client.Close()
 	}
@@ -81,26 +84,55 @@ func (oc *OkxStreamClient) Close() error {
 	return nil
 }
 
+// Reconnect dials the feed again after Dispatch failed, the subscriptions have to be requested again once Dispatch runs
+func (oc *OkxStreamClient) Reconnect() error {
+	if err := oc.client.Redial(); err != nil {
+		return err
+	}
+	oc.tracker.Reopen()
+	return nil
+}
+
 func (oc *OkxStreamClient) ReceiveStream() (<-chan model.PricePoint, <-chan model.PriceInterval, <-chan model.OrderBook) {
 	return oc.newPriceChan, oc.priceIntervalChan, oc.orderBookChan
 }
 
 func (oc *OkxStreamClient) SubscribeStream(pair model.QuotesPair, channels []string) error {
-	var args []map[string]interface{}
+	for _, ch := range channels {
+		if err := oc.request(pair, "subscribe", ch); err != nil {
+			return err
+		}
+		oc.tracker.Add(model.Subscription{Pair: pair, Channel: ch})
+	}
+	return nil
+}
 
-	instId := getInstId(pair)
+func (oc *OkxStreamClient) UnsubscribeStream(pair model.QuotesPair, channels []string) error {
 	for _, ch := range channels {
-		args = append(args, map[string]interface{}{
-			"channel": ch,
-			"instId":  instId,
-		})
+		if err := oc.request(pair, "unsubscribe", ch); err != nil {
+			return err
+		}
+		oc.tracker.Remove(model.Subscription{Pair: pair, Channel: ch})
 	}
+	return nil
+}
 
+func (oc *OkxStreamClient) Subscriptions() []model.Subscription {
+	return oc.tracker.Subscriptions()
+}
+
+// request sends one channel per message, OKX acknowledges each argument on its own
+func (oc *OkxStreamClient) request(pair model.QuotesPair, op string, channel string) error {
+	id := oc.tracker.NextID()
 	msg := map[string]interface{}{
-		"op":   "subscribe",
-		"args": args,
+		"id": strconv.FormatInt(id, 10),
+		"op": op,
+		"args": []map[string]interface{}{{
+			"channel": channel,
+			"instId":  getInstId(pair),
+		}},
 	}
-	return oc.client.WriteJSON(msg)
+	return oc.tracker.Do(id, func() error { return oc.client.WriteJSON(msg) })
 }
 
 func (oc *OkxStreamClient) Dispatch(ctx context.Context) error {
@@ -111,7 +143,9 @@ func (oc *OkxStreamClient) Dispatch(ctx context.Context) error {
 			return oc.client.Close()
 		default:
 			_, message, err := oc.client.ReadMessage()
+			received := time.Now()
 			if err != nil {
+				oc.tracker.Close()
 				return err
 			}
 			if oc.handler != nil {
@@ -119,30 +153,34 @@ func (oc *OkxStreamClient) Dispatch(ctx context.Context) error {
 					return err
 				}
 			}
+			if a, ok := parseAck(message); ok {
+				oc.tracker.Resolve(a.id, a.err)
+				continue
+			}
 			if fn, ok := dispathcers[getMessageType(message)]; ok {
-				fn(oc, message)
+				fn(oc, message, received)
 			}
 		}
 	}
 }
 
-type dispatchFunc func(*OkxStreamClient, []byte)
+type dispatchFunc func(*OkxStreamClient, []byte, time.Time)
 
 func (oc *OkxStreamClient) getDispatchers() map[string]dispatchFunc {
 	return map[string]dispatchFunc{
-		"tickers": func(client *OkxStreamClient, msg []byte) {
+		"tickers": func(client *OkxStreamClient, msg []byte, received time.Time) {
 			if p, err := parsePricePoint(msg); err == nil {
-				model.PushToChan(client.newPriceChan, *p)
+				model.DispatchPrice(client.newPriceChan, *p, received)
 			}
 		},
-		"candle": func(client *OkxStreamClient, msg []byte) {
+		"candle": func(client *OkxStreamClient, msg []byte, received time.Time) {
 			if intervals, err := parsePriceInterval(msg); err == nil {
 				for _, interval := range intervals {
 					model.PushToChan(client.priceIntervalChan, interval)
 				}
 			}
 		},
-		"books": func(client *OkxStreamClient, msg []byte) {
+		"books": func(client *OkxStreamClient, msg []byte, received time.Time) {
 			if orderBook, err := parseOrderBook(msg); err == nil {
 				model.PushToChan(client.orderBookChan, *orderBook)
 			}
@@ -150,6 +188,33 @@ func (oc *OkxStreamClient) getDispatchers() map[string]dispatchFunc {
 	}
 }
 
+// ack is the response to a subscribe or unsubscribe request
+type ack struct {
+	id  string
+	err error
+}
+
+// parseAck recognizes {"id":"1","event":"subscribe",...} and {"id":"1","event":"error","code":"60018","msg":"..."}
+func parseAck(msg []byte) (ack, bool) {
+	var raw struct {
+		ID    string `json:"id"`
+		Event string `json:"event"`
+		Code  string `json:"code"`
+		Msg   string `json:"msg"`
+	}
+	if err := json.Unmarshal(msg, &raw); err != nil || raw.ID == "" {
+		return ack{}, false
+	}
+	switch raw.Event {
+	case "subscribe", "unsubscribe":
+		return ack{id: raw.ID}, true
+	case "error":
+		return ack{id: raw.ID, err: &stream.Error{Exchange: "okx", Code: raw.Code, Message: raw.Msg}}, true
+	default:
+		return ack{}, false
+	}
+}
+
 func getMessageType(msg []byte) string {
 	var raw struct {
 		Event string `json:"event"`
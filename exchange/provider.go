@@ -16,8 +16,13 @@ package exchange
 import (
 	"context"
 	"errors"
+	"log"
+	"slices"
 	"sync"
+	"time"
 
+	"github.com/wang900115/quant/common/sys"
+	"github.com/wang900115/quant/metric"
 	"github.com/wang900115/quant/model"
 )
 
@@ -29,7 +34,11 @@ type Provider interface {
 	GetPrice(ctx context.Context, pair model.QuotesPair) (*model.PricePoint, error)
 	GetKlines(ctx context.Context, pair model.QuotesPair, interval string, limit int) ([]model.PriceInterval, error)
 	GetOrderBook(ctx context.Context, pair model.QuotesPair, limit int) (*model.OrderBook, error)
+	// SubscribeStream returns once the venue acknowledged every channel, so Dispatch must already be running
 	SubscribeStream(pair model.QuotesPair, channel []string) error
+	UnsubscribeStream(pair model.QuotesPair, channel []string) error
+	// Subscriptions lists the channels the venue confirmed and that were not unsubscribed since
+	Subscriptions() []model.Subscription
 	Dispatch(ctx context.Context) error
 	ReceiveStream() (<-chan model.PricePoint, <-chan model.PriceInterval, <-chan model.OrderBook)
 	PlaceOrder(ctx context.Context, req model.OrderRequest) (*model.OrderResult, error)
@@ -42,11 +51,17 @@ type Provider interface {
 type Providers struct {
 	mu       sync.RWMutex
 	registry map[model.ExchangeId]Provider
+	// requests counts calls per exchange, pair, method and outcome
+	requests *metric.CounterInt64Vec
+	// latency owns the round-trip timers per exchange and method
+	latency *metric.Registry
 }
 
 func New() Providers {
 	return Providers{
 		registry: make(map[model.ExchangeId]Provider),
+		requests: metric.NewCounterInt64Vec("exchange", "pair", "method", "outcome"),
+		latency:  metric.NewRegistry(),
 	}
 }
 
@@ -63,43 +78,89 @@ func (p *Providers) Unregister(exchangeID model.ExchangeId) {
 }
 
 func (p *Providers) GetPrice(ctx context.Context, pair model.QuotesPair) (*model.PricePoint, error) {
+	start := time.Now()
 	p.mu.RLock()
 	provider, ok := p.registry[pair.ExchangeID]
 	p.mu.RUnlock()
 	if !ok {
+		p.observe(pair, "GetPrice", start, errMissingProvider)
 		return nil, errMissingProvider
 	}
-	return provider.GetPrice(ctx, pair)
+	res, err := provider.GetPrice(ctx, pair)
+	p.observe(pair, "GetPrice", start, err)
+	return res, err
 }
 
 func (p *Providers) GetKlines(ctx context.Context, pair model.QuotesPair, interval string, limit int) ([]model.PriceInterval, error) {
+	start := time.Now()
 	p.mu.RLock()
 	provider, ok := p.registry[pair.ExchangeID]
 	p.mu.RUnlock()
 	if !ok {
+		p.observe(pair, "GetKlines", start, errMissingProvider)
 		return nil, errMissingProvider
 	}
-	return provider.GetKlines(ctx, pair, interval, limit)
+	res, err := provider.GetKlines(ctx, pair, interval, limit)
+	p.observe(pair, "GetKlines", start, err)
+	return res, err
 }
 
 func (p *Providers) GetOrderBook(ctx context.Context, pair model.QuotesPair, limit int) (*model.OrderBook, error) {
+	start := time.Now()
 	p.mu.RLock()
 	provider, ok := p.registry[pair.ExchangeID]
 	p.mu.RUnlock()
 	if !ok {
+		p.observe(pair, "GetOrderBook", start, errMissingProvider)
 		return nil, errMissingProvider
 	}
-	return provider.GetOrderBook(ctx, pair, limit)
+	res, err := provider.GetOrderBook(ctx, pair, limit)
+	p.observe(pair, "GetOrderBook", start, err)
+	return res, err
 }
 
 func (p *Providers) SubscribeStream(pair model.QuotesPair, channel []string) error {
+	start := time.Now()
 	p.mu.RLock()
 	provider, ok := p.registry[pair.ExchangeID]
 	p.mu.RUnlock()
 	if !ok {
+		p.observe(pair, "SubscribeStream", start, errMissingProvider)
 		return errMissingProvider
 	}
-	return provider.SubscribeStream(pair, channel)
+	err := provider.SubscribeStream(pair, channel)
+	p.observe(pair, "SubscribeStream", start, err)
+	return err
+}
+
+func (p *Providers) UnsubscribeStream(pair model.QuotesPair, channel []string) error {
+	start := time.Now()
+	p.mu.RLock()
+	provider, ok := p.registry[pair.ExchangeID]
+	p.mu.RUnlock()
+	if !ok {
+		p.observe(pair, "UnsubscribeStream", start, errMissingProvider)
+		return errMissingProvider
+	}
+	err := provider.UnsubscribeStream(pair, channel)
+	p.observe(pair, "UnsubscribeStream", start, err)
+	return err
+}
+
+// Subscriptions lists the active subscriptions of every provider, grouped by exchange
+func (p *Providers) Subscriptions() []model.Subscription {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	ids := make([]model.ExchangeId, 0, len(p.registry))
+	for id := range p.registry {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	var subs []model.Subscription
+	for _, id := range ids {
+		subs = append(subs, p.registry[id].Subscriptions()...)
+	}
+	return subs
 }
 
 func (p *Providers) StartStream(ctx context.Context) {
@@ -110,6 +171,65 @@ func (p *Providers) StartStream(ctx context.Context) {
 	}
 }
 
+// Reconnector is a provider whose stream can be dialled again after Dispatch failed
+type Reconnector interface {
+	Reconnect() error
+}
+
+// SuperviseStreams runs the Dispatch loop of every provider as a task of engine, restarted when it panics or fails.
+// Before a restart the stream of a Reconnector is dialled again and its subscriptions are requested again.
+func (p *Providers) SuperviseStreams(engine *sys.Engine) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	for id, provider := range p.registry {
+		name := string(model.GetExchange(id).Name) + ".stream"
+		started := false
+		engine.Supervise(sys.Task{
+			Name: name,
+			Run: func(ctx context.Context) error {
+				if started {
+					if err := reconnect(ctx, name, provider); err != nil {
+						return err
+					}
+				}
+				started = true
+				return provider.Dispatch(ctx)
+			},
+		})
+	}
+}
+
+// reconnect dials the stream of provider again and, once Dispatch runs, subscribes to what it had
+func reconnect(ctx context.Context, name string, provider Provider) error {
+	rc, ok := provider.(Reconnector)
+	if !ok {
+		return nil
+	}
+	subs := provider.Subscriptions()
+	if err := rc.Reconnect(); err != nil {
+		return err
+	}
+	var pairs []model.QuotesPair
+	channels := make(map[model.QuotesPair][]string)
+	for _, s := range subs {
+		if _, ok := channels[s.Pair]; !ok {
+			pairs = append(pairs, s.Pair)
+		}
+		channels[s.Pair] = append(channels[s.Pair], s.Channel)
+	}
+	go func() {
+		for _, pair := range pairs {
+			if ctx.Err() != nil {
+				return
+			}
+			if err := provider.SubscribeStream(pair, channels[pair]); err != nil {
+				log.Printf("[%s] resubscribe %s: %v", name, pair.Symbol(), err)
+			}
+		}
+	}()
+	return nil
+}
+
 func (p *Providers) ReceiveStream(pair model.QuotesPair) (<-chan model.PricePoint, <-chan model.PriceInterval, <-chan model.OrderBook, error) {
 	p.mu.RLock()
 	provider, ok := p.registry[pair.ExchangeID]
@@ -141,3 +261,34 @@ func (p *Providers) ListProviders() []model.Exchange {
 	}
 	return exchanges
 }
+
+// observe counts a call and records its round-trip time
+func (p *Providers) observe(pair model.QuotesPair, method string, start time.Time, err error) {
+	exchange := string(model.GetExchange(pair.ExchangeID).Name)
+	outcome := "ok"
+	if err != nil {
+		outcome = "error"
+	}
+	p.requests.With(exchange, pair.Symbol(), method, outcome).Inc(1)
+	p.latency.Timer("quant_provider_request_duration_seconds", "Round-trip time of provider calls.", nil, metric.Labels{"exchange": exchange, "method": method}).UpdateSince(start)
+}
+
+// Collect exposes request counts, registered exchanges and active subscriptions as Prometheus families
+func (p *Providers) Collect() []metric.Family {
+	registered := metric.Family{Name: "quant_provider_registered", Help: "Exchanges registered with the providers.", Type: metric.TypeGauge}
+	for _, ex := range p.ListProviders() {
+		registered.Samples = append(registered.Samples, metric.Sample{Labels: metric.Labels{"exchange": string(ex.Name)}, Value: 1})
+	}
+	subscriptions := metric.Family{Name: "quant_provider_subscriptions", Help: "Stream channels acknowledged by the venue.", Type: metric.TypeGauge}
+	for _, sub := range p.Subscriptions() {
+		subscriptions.Samples = append(subscriptions.Samples, metric.Sample{
+			Labels: metric.Labels{"exchange": string(model.GetExchange(sub.Pair.ExchangeID).Name), "pair": sub.Pair.Symbol(), "channel": sub.Channel},
+			Value:  1,
+		})
+	}
+	return append([]metric.Family{
+		p.requests.Family("quant_provider_requests_total", "Provider calls by exchange, pair, method and outcome."),
+		registered,
+		subscriptions,
+	}, p.latency.Collect()...)
+}
@@ -0,0 +1,158 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Sentinels matched by errors.Is against an *Error of the corresponding Kind
+var (
+	ErrRateLimited         = errors.New("rest: rate limited")
+	ErrInsufficientBalance = errors.New("rest: insufficient balance")
+	ErrInvalidSymbol       = errors.New("rest: invalid symbol")
+	ErrUnauthorized        = errors.New("rest: unauthorized")
+	ErrOrderNotFound       = errors.New("rest: order not found")
+)
+
+// maxErrorBody bounds how much of an error payload is read
+const maxErrorBody = 64 << 10
+
+type Kind int
+
+const (
+	KindUnknown Kind = iota
+	KindRateLimited
+	KindInsufficientBalance
+	KindInvalidSymbol
+	KindUnauthorized
+	KindOrderNotFound
+	KindUnavailable
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindRateLimited:
+		return "rate_limited"
+	case KindInsufficientBalance:
+		return "insufficient_balance"
+	case KindInvalidSymbol:
+		return "invalid_symbol"
+	case KindUnauthorized:
+		return "unauthorized"
+	case KindOrderNotFound:
+		return "order_not_found"
+	case KindUnavailable:
+		return "unavailable"
+	default:
+		return "unknown"
+	}
+}
+
+// Error is a failed venue call with the payload the venue returned
+type Error struct {
+	// Exchange is the venue name, e.g. "binance"
+	Exchange string
+	// Status is the HTTP status code
+	Status int
+	// Code is the venue specific error code, empty when the venue sent none
+	Code string
+	// Message is the venue's error message
+	Message string
+	// RequestID identifies the request on the venue side when it reports one
+	RequestID string
+	// Kind classifies the failure
+	Kind Kind
+	// Retryable reports whether sending the same request again may succeed
+	Retryable bool
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("%s: status %d", e.Exchange, e.Status)
+	if e.Code != "" {
+		msg += fmt.Sprintf(", code %s", e.Code)
+	}
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	if e.RequestID != "" {
+		msg += fmt.Sprintf(" (request %s)", e.RequestID)
+	}
+	return msg
+}
+
+// Is lets errors.Is match an *Error against the Kind sentinels
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrRateLimited:
+		return e.Kind == KindRateLimited
+	case ErrInsufficientBalance:
+		return e.Kind == KindInsufficientBalance
+	case ErrInvalidSymbol:
+		return e.Kind == KindInvalidSymbol
+	case ErrUnauthorized:
+		return e.Kind == KindUnauthorized
+	case ErrOrderNotFound:
+		return e.Kind == KindOrderNotFound
+	default:
+		return false
+	}
+}
+
+// NewError builds an *Error from resp with the kind and retryability implied by its status code.
+// Venue parsers refine Code, Message and Kind from the payload afterwards.
+func NewError(exchange string, resp *http.Response, requestIDHeaders ...string) *Error {
+	e := &Error{
+		Exchange: exchange,
+		Status:   resp.StatusCode,
+	}
+	for _, h := range requestIDHeaders {
+		if id := resp.Header.Get(h); id != "" {
+			e.RequestID = id
+			break
+		}
+	}
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusTeapot:
+		e.Kind = KindRateLimited
+		e.Retryable = true
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		e.Kind = KindUnauthorized
+	case resp.StatusCode >= http.StatusInternalServerError:
+		e.Kind = KindUnavailable
+		e.Retryable = true
+	}
+	return e
+}
+
+// Classify sets Kind and derives Retryable from it
+func (e *Error) Classify(kind Kind) {
+	e.Kind = kind
+	e.Retryable = kind == KindRateLimited || kind == KindUnavailable
+}
+
+// ReadBody drains a bounded error payload from resp
+func ReadBody(resp *http.Response) []byte {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	return body
+}
+
+// IsRetryable reports whether err is an *Error the venue considers transient
+func IsRetryable(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && e.Retryable
+}
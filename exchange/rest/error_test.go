@@ -0,0 +1,75 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestErrorMatchesKindSentinels(t *testing.T) {
+	tests := []struct {
+		kind   Kind
+		target error
+	}{
+		{KindRateLimited, ErrRateLimited},
+		{KindInsufficientBalance, ErrInsufficientBalance},
+		{KindInvalidSymbol, ErrInvalidSymbol},
+		{KindUnauthorized, ErrUnauthorized},
+		{KindOrderNotFound, ErrOrderNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.kind.String(), func(t *testing.T) {
+			err := fmt.Errorf("wrapped: %w", &Error{Exchange: "test", Kind: tt.kind})
+			if !errors.Is(err, tt.target) {
+				t.Errorf("expected %v to match %v", err, tt.target)
+			}
+			if errors.Is(&Error{Exchange: "test"}, tt.target) {
+				t.Errorf("unknown kind should not match %v", tt.target)
+			}
+			var e *Error
+			if !errors.As(err, &e) || e.Kind != tt.kind {
+				t.Errorf("expected errors.As to recover kind %v", tt.kind)
+			}
+		})
+	}
+}
+
+func TestNewErrorFromStatus(t *testing.T) {
+	tests := []struct {
+		status    int
+		kind      Kind
+		retryable bool
+	}{
+		{http.StatusTooManyRequests, KindRateLimited, true},
+		{http.StatusTeapot, KindRateLimited, true},
+		{http.StatusUnauthorized, KindUnauthorized, false},
+		{http.StatusServiceUnavailable, KindUnavailable, true},
+		{http.StatusBadRequest, KindUnknown, false},
+	}
+	for _, tt := range tests {
+		resp := &http.Response{StatusCode: tt.status, Header: http.Header{"X-Request-Id": []string{"abc"}}}
+		e := NewError("test", resp, "X-Request-Id")
+		if e.Kind != tt.kind || e.Retryable != tt.retryable {
+			t.Errorf("status %d: expected kind=%v retryable=%v, got kind=%v retryable=%v", tt.status, tt.kind, tt.retryable, e.Kind, e.Retryable)
+		}
+		if e.RequestID != "abc" {
+			t.Errorf("status %d: expected request id abc, got %q", tt.status, e.RequestID)
+		}
+	}
+	if !IsRetryable(&Error{Retryable: true}) || IsRetryable(errors.New("plain")) {
+		t.Error("IsRetryable should only report retryable *Error values")
+	}
+}
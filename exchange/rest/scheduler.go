@@ -0,0 +1,299 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package rest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	ErrUnknownBucket = errors.New("rest: unknown rate limit bucket")
+	ErrThrottled     = errors.New("rest: request would exceed rate limit before deadline")
+)
+
+const (
+	defaultMaxRetries   = 3
+	defaultMinBackoff   = 200 * time.Millisecond
+	defaultMaxBackoff   = 5 * time.Second
+	defaultMaxRetryWait = 30 * time.Second
+)
+
+// Bucket describes an allowance of request weight that refills every Window
+type Bucket struct {
+	// Name identifies the bucket in a Cost
+	Name string
+	// Capacity is the total weight allowed per window
+	Capacity int
+	// Window is the length of a rate limit window
+	Window time.Duration
+}
+
+// Cost is the weight a request consumes from a bucket
+type Cost struct {
+	Bucket string
+	Weight int
+}
+
+// Config configures a Scheduler
+type Config struct {
+	// Buckets known to the scheduler
+	Buckets []Bucket
+	// MaxRetries is the number of retries for idempotent requests
+	MaxRetries int
+	// MinBackoff is the base delay of the jittered exponential backoff
+	MinBackoff time.Duration
+	// MaxBackoff caps the backoff delay
+	MaxBackoff time.Duration
+	// MaxRetryWait is the longest Retry-After the scheduler waits out before giving the response back to the caller
+	MaxRetryWait time.Duration
+	// Observe reconciles local accounting with usage reported by the venue
+	Observe func(s *Scheduler, resp *http.Response)
+}
+
+type bucket struct {
+	capacity int
+	window   time.Duration
+	used     int
+	resetAt  time.Time
+	// turn serializes waiters so callers are served in arrival order
+	turn chan struct{}
+}
+
+// Scheduler queues, throttles and retries REST calls against one venue.
+// It is safe for concurrent use and meant to be shared by every client of that venue.
+type Scheduler struct {
+	mu          sync.Mutex
+	buckets     map[string]*bucket
+	bannedUntil time.Time
+	cfg         Config
+}
+
+// NewScheduler creates a Scheduler from the given configuration
+func NewScheduler(cfg Config) *Scheduler {
+	if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = defaultMaxRetries
+	}
+	if cfg.MinBackoff == 0 {
+		cfg.MinBackoff = defaultMinBackoff
+	}
+	if cfg.MaxBackoff == 0 {
+		cfg.MaxBackoff = defaultMaxBackoff
+	}
+	if cfg.MaxRetryWait == 0 {
+		cfg.MaxRetryWait = defaultMaxRetryWait
+	}
+	s := &Scheduler{
+		buckets: make(map[string]*bucket, len(cfg.Buckets)),
+		cfg:     cfg,
+	}
+	for _, b := range cfg.Buckets {
+		s.buckets[b.Name] = &bucket{
+			capacity: b.Capacity,
+			window:   b.Window,
+			turn:     make(chan struct{}, 1),
+		}
+	}
+	return s
+}
+
+// Do sends req through client once every cost fits into its bucket.
+// GET and HEAD requests are retried with jittered backoff on transport errors, 418, 429 and 5xx responses.
+func (s *Scheduler) Do(client *http.Client, req *http.Request, costs ...Cost) (*http.Response, error) {
+	ctx := req.Context()
+	idempotent := req.Method == http.MethodGet || req.Method == http.MethodHead
+	for attempt := 0; ; attempt++ {
+		for _, cost := range costs {
+			if err := s.acquire(ctx, cost); err != nil {
+				return nil, err
+			}
+		}
+		resp, err := client.Do(req.Clone(ctx))
+		var retryAfter time.Duration
+		if resp != nil {
+			if s.cfg.Observe != nil {
+				s.cfg.Observe(s, resp)
+			}
+			retryAfter = s.inspect(resp)
+		}
+		if !idempotent || attempt >= s.cfg.MaxRetries || !shouldRetry(ctx, resp, err) || retryAfter > s.cfg.MaxRetryWait {
+			return resp, err
+		}
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		delay := s.backoff(attempt)
+		if retryAfter > delay {
+			delay = retryAfter
+		}
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Sync overwrites the weight used in the current window of a bucket with the value reported by the venue
+func (s *Scheduler) Sync(name string, used int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.buckets[name]
+	if !ok {
+		return
+	}
+	s.refill(b, time.Now())
+	b.used = used
+}
+
+// Backoff pauses every bucket until the given time
+func (s *Scheduler) Backoff(until time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if until.After(s.bannedUntil) {
+		s.bannedUntil = until
+	}
+}
+
+// Used returns the weight consumed in the current window of a bucket
+func (s *Scheduler) Used(name string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.buckets[name]
+	if !ok {
+		return 0
+	}
+	s.refill(b, time.Now())
+	return b.used
+}
+
+func (s *Scheduler) acquire(ctx context.Context, cost Cost) error {
+	s.mu.Lock()
+	b, ok := s.buckets[cost.Bucket]
+	s.mu.Unlock()
+	if !ok {
+		return ErrUnknownBucket
+	}
+
+	select {
+	case b.turn <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { <-b.turn }()
+
+	for {
+		s.mu.Lock()
+		wait := s.reserve(b, cost.Weight, time.Now())
+		s.mu.Unlock()
+		if wait <= 0 {
+			return nil
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			return ErrThrottled
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// reserve books weight in b and returns zero, or returns how long to wait before trying again
+func (s *Scheduler) reserve(b *bucket, weight int, now time.Time) time.Duration {
+	if now.Before(s.bannedUntil) {
+		return s.bannedUntil.Sub(now)
+	}
+	s.refill(b, now)
+	if weight > b.capacity {
+		weight = b.capacity
+	}
+	if b.used+weight > b.capacity {
+		return b.resetAt.Sub(now)
+	}
+	b.used += weight
+	return 0
+}
+
+func (s *Scheduler) refill(b *bucket, now time.Time) {
+	if now.Before(b.resetAt) {
+		return
+	}
+	b.used = 0
+	b.resetAt = now.Truncate(b.window).Add(b.window)
+}
+
+// inspect honours Retry-After on 418 and 429 responses and returns the advised delay
+func (s *Scheduler) inspect(resp *http.Response) time.Duration {
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusTeapot {
+		return 0
+	}
+	delay := parseRetryAfter(resp.Header.Get("Retry-After"))
+	if delay == 0 {
+		delay = s.cfg.MinBackoff
+	}
+	s.Backoff(time.Now().Add(delay))
+	return delay
+}
+
+func (s *Scheduler) backoff(attempt int) time.Duration {
+	ceiling := s.cfg.MinBackoff << attempt
+	if ceiling <= 0 || ceiling > s.cfg.MaxBackoff {
+		ceiling = s.cfg.MaxBackoff
+	}
+	// full jitter keeps concurrent callers from retrying in lockstep
+	return s.cfg.MinBackoff/2 + rand.N(ceiling)
+}
+
+func shouldRetry(ctx context.Context, resp *http.Response, err error) bool {
+	if err != nil {
+		return ctx.Err() == nil
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusTeapot,
+		http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return time.Until(at)
+	}
+	return 0
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
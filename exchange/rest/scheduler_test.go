@@ -0,0 +1,175 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package rest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestScheduler(capacity int, window time.Duration) *Scheduler {
+	return NewScheduler(Config{
+		Buckets:    []Bucket{{Name: "test", Capacity: capacity, Window: window}},
+		MinBackoff: time.Millisecond,
+		MaxBackoff: 5 * time.Millisecond,
+	})
+}
+
+func TestSchedulerRetriesIdempotentRequests(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	s := newTestScheduler(10, time.Minute)
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	resp, err := s.Do(srv.Client(), req, Cost{Bucket: "test", Weight: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected 200 after retries, got %d", resp.StatusCode)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Errorf("expected 3 attempts, got %d", got)
+	}
+	if used := s.Used("test"); used != 3 {
+		t.Errorf("expected every attempt to be accounted, got %d", used)
+	}
+}
+
+func TestSchedulerDoesNotRetryNonIdempotentRequests(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	s := newTestScheduler(10, time.Minute)
+	req, _ := http.NewRequest(http.MethodPost, srv.URL, nil)
+	resp, err := s.Do(srv.Client(), req, Cost{Bucket: "test", Weight: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if got := calls.Load(); got != 1 {
+		t.Errorf("expected a single attempt for POST, got %d", got)
+	}
+}
+
+func TestSchedulerHonoursRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	s := newTestScheduler(10, time.Minute)
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	start := time.Now()
+	resp, err := s.Do(srv.Client(), req, Cost{Bucket: "test", Weight: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("expected to wait out Retry-After, returned after %v", elapsed)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected 200 after Retry-After, got %d", resp.StatusCode)
+	}
+}
+
+func TestSchedulerGivesUpOnLongRetryAfter(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer srv.Close()
+
+	s := newTestScheduler(10, time.Minute)
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	resp, err := s.Do(srv.Client(), req, Cost{Bucket: "test", Weight: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTeapot {
+		t.Errorf("expected the 418 to be returned, got %d", resp.StatusCode)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	req, _ = http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	if _, err := s.Do(srv.Client(), req, Cost{Bucket: "test", Weight: 1}); !errors.Is(err, ErrThrottled) {
+		t.Errorf("expected ErrThrottled while banned, got %v", err)
+	}
+}
+
+func TestSchedulerThrottlesToCapacity(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	s := newTestScheduler(2, time.Hour)
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+		resp, err := s.Do(srv.Client(), req, Cost{Bucket: "test", Weight: 1})
+		if err != nil {
+			t.Fatalf("request %d: unexpected error: %v", i, err)
+		}
+		resp.Body.Close()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	if _, err := s.Do(srv.Client(), req, Cost{Bucket: "test", Weight: 1}); !errors.Is(err, ErrThrottled) {
+		t.Errorf("expected ErrThrottled once capacity is spent, got %v", err)
+	}
+}
+
+func TestSchedulerSync(t *testing.T) {
+	s := newTestScheduler(100, time.Hour)
+	s.Sync("test", 42)
+	if used := s.Used("test"); used != 42 {
+		t.Errorf("expected used weight 42 after Sync, got %d", used)
+	}
+}
+
+func TestSchedulerUnknownBucket(t *testing.T) {
+	s := newTestScheduler(1, time.Second)
+	req, _ := http.NewRequest(http.MethodGet, "http://127.0.0.1", nil)
+	if _, err := s.Do(http.DefaultClient, req, Cost{Bucket: "missing", Weight: 1}); !errors.Is(err, ErrUnknownBucket) {
+		t.Errorf("expected ErrUnknownBucket, got %v", err)
+	}
+}
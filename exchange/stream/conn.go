@@ -0,0 +1,56 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+package stream
+
+import (
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// Conn serializes writes, gorilla connections allow one concurrent writer
+type Conn struct {
+	*websocket.Conn
+	mu       sync.Mutex
+	endpoint string
+}
+
+// Dial opens a WebSocket connection to endpoint
+func Dial(endpoint string) (*Conn, error) {
+	conn, _, err := websocket.DefaultDialer.Dial(endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Conn{Conn: conn, endpoint: endpoint}, nil
+}
+
+// Redial replaces the connection with a new one to the same endpoint, nothing may be reading meanwhile
+func (c *Conn) Redial() error {
+	conn, _, err := websocket.DefaultDialer.Dial(c.endpoint, nil)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	old := c.Conn
+	c.Conn = conn
+	c.mu.Unlock()
+	old.Close()
+	return nil
+}
+
+// WriteJSON sends v as one JSON message, it is safe for concurrent use
+func (c *Conn) WriteJSON(v any) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.Conn.WriteJSON(v)
+}
@@ -0,0 +1,203 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+package stream
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/wang900115/quant/model"
+)
+
+var (
+	ErrAckTimeout = errors.New("stream: no acknowledgement before timeout, is Dispatch running")
+	ErrClosed     = errors.New("stream: connection closed")
+)
+
+const defaultAckTimeout = 10 * time.Second
+
+// Error is a subscribe or unsubscribe request the venue rejected
+type Error struct {
+	Exchange string
+	Code     string
+	Message  string
+}
+
+func (e *Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("%s: subscription rejected: %s", e.Exchange, e.Message)
+	}
+	return fmt.Sprintf("%s: subscription rejected: %s (code %s)", e.Exchange, e.Message, e.Code)
+}
+
+// Tracker matches subscription requests with the acknowledgements the reader sees,
+// and keeps the set of subscriptions the venue has confirmed.
+// It is safe for concurrent use.
+type Tracker struct {
+	timeout time.Duration
+	nextID  atomic.Int64
+
+	mu      sync.Mutex
+	pending map[string]chan error
+	// order lists pending ids oldest first, for venues that answer in order without echoing ids
+	order  []string
+	active []model.Subscription
+	closed bool
+}
+
+// NewTracker creates a Tracker that waits up to timeout for each acknowledgement, zero selects ten seconds
+func NewTracker(timeout time.Duration) *Tracker {
+	if timeout == 0 {
+		timeout = defaultAckTimeout
+	}
+	return &Tracker{
+		timeout: timeout,
+		pending: make(map[string]chan error),
+	}
+}
+
+// NextID returns a request id unique to this tracker
+func (t *Tracker) NextID() int64 {
+	return t.nextID.Add(1)
+}
+
+// Do registers id, runs send and waits until the reader resolves id, the timeout passes or the connection fails
+func (t *Tracker) Do(id int64, send func() error) error {
+	key := strconv.FormatInt(id, 10)
+	done := make(chan error, 1)
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return ErrClosed
+	}
+	t.pending[key] = done
+	t.order = append(t.order, key)
+	t.mu.Unlock()
+
+	if err := send(); err != nil {
+		t.forget(key)
+		return err
+	}
+	timer := time.NewTimer(t.timeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		t.forget(key)
+		return ErrAckTimeout
+	}
+}
+
+// Resolve completes the request with the given id, err is nil for a successful acknowledgement.
+// It reports whether a request was waiting for id.
+func (t *Tracker) Resolve(id string, err error) bool {
+	t.mu.Lock()
+	done, ok := t.pending[id]
+	if ok {
+		t.remove(id)
+	}
+	t.mu.Unlock()
+	if ok {
+		done <- err
+	}
+	return ok
+}
+
+// ResolveNext completes the oldest pending request
+func (t *Tracker) ResolveNext(err error) bool {
+	t.mu.Lock()
+	if len(t.order) == 0 {
+		t.mu.Unlock()
+		return false
+	}
+	id := t.order[0]
+	t.mu.Unlock()
+	return t.Resolve(id, err)
+}
+
+// Close fails every pending request with ErrClosed and every later one immediately
+func (t *Tracker) Close() {
+	t.mu.Lock()
+	t.closed = true
+	pending := t.pending
+	t.pending = make(map[string]chan error)
+	t.order = nil
+	t.mu.Unlock()
+	for _, done := range pending {
+		done <- ErrClosed
+	}
+}
+
+// Reopen accepts requests again after Close and forgets the active subscriptions,
+// which a new connection has to request again
+func (t *Tracker) Reopen() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closed = false
+	t.active = nil
+}
+
+// Add records confirmed subscriptions, duplicates are ignored
+func (t *Tracker) Add(subs ...model.Subscription) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, s := range subs {
+		if !slices.Contains(t.active, s) {
+			t.active = append(t.active, s)
+		}
+	}
+}
+
+// Remove forgets subscriptions after the venue confirmed the unsubscribe
+func (t *Tracker) Remove(subs ...model.Subscription) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.active = slices.DeleteFunc(t.active, func(s model.Subscription) bool {
+		return slices.Contains(subs, s)
+	})
+}
+
+// Subscriptions returns the active subscriptions in the order they were confirmed
+func (t *Tracker) Subscriptions() []model.Subscription {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return slices.Clone(t.active)
+}
+
+// Of lists the subscriptions of pair to channels
+func Of(pair model.QuotesPair, channels []string) []model.Subscription {
+	subs := make([]model.Subscription, 0, len(channels))
+	for _, ch := range channels {
+		subs = append(subs, model.Subscription{Pair: pair, Channel: ch})
+	}
+	return subs
+}
+
+func (t *Tracker) forget(id string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.remove(id)
+}
+
+func (t *Tracker) remove(id string) {
+	delete(t.pending, id)
+	if i := slices.Index(t.order, id); i >= 0 {
+		t.order = slices.Delete(t.order, i, i+1)
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+package stream
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/wang900115/quant/model"
+)
+
+func TestTracker_Resolve(t *testing.T) {
+	tr := NewTracker(time.Second)
+	rejected := &Error{Exchange: "test", Code: "1", Message: "bad channel"}
+
+	id := tr.NextID()
+	err := tr.Do(id, func() error {
+		go tr.Resolve(strconv.FormatInt(id, 10), rejected)
+		return nil
+	})
+	var e *Error
+	if !errors.As(err, &e) || e.Code != "1" {
+		t.Errorf("expected the rejection, got %v", err)
+	}
+	if tr.Resolve(strconv.FormatInt(id, 10), nil) {
+		t.Error("expected a resolved id to be forgotten")
+	}
+}
+
+func TestTracker_ResolveNext(t *testing.T) {
+	tr := NewTracker(time.Second)
+	results := make(chan error, 2)
+	sent := make(chan struct{}, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			results <- tr.Do(tr.NextID(), func() error {
+				sent <- struct{}{}
+				return nil
+			})
+		}()
+		// wait for the request to be pending before sending the next one
+		<-sent
+	}
+	tr.ResolveNext(nil)
+	tr.ResolveNext(errors.New("rejected"))
+
+	var failed int
+	for i := 0; i < 2; i++ {
+		if err := <-results; err != nil {
+			failed++
+		}
+	}
+	if failed != 1 {
+		t.Errorf("expected one rejected request, got %d", failed)
+	}
+	if tr.ResolveNext(nil) {
+		t.Error("expected no pending requests")
+	}
+}
+
+func TestTracker_TimeoutAndClose(t *testing.T) {
+	tr := NewTracker(10 * time.Millisecond)
+	if err := tr.Do(tr.NextID(), func() error { return nil }); !errors.Is(err, ErrAckTimeout) {
+		t.Errorf("expected ErrAckTimeout, got %v", err)
+	}
+
+	sendErr := errors.New("write failed")
+	if err := tr.Do(tr.NextID(), func() error { return sendErr }); !errors.Is(err, sendErr) {
+		t.Errorf("expected the write error, got %v", err)
+	}
+
+	tr = NewTracker(time.Minute)
+	done := make(chan error, 1)
+	go func() {
+		done <- tr.Do(tr.NextID(), func() error {
+			go tr.Close()
+			return nil
+		})
+	}()
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrClosed) {
+			t.Errorf("expected ErrClosed, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Close did not release the pending request")
+	}
+	if err := tr.Do(tr.NextID(), func() error { return nil }); !errors.Is(err, ErrClosed) {
+		t.Errorf("expected ErrClosed after Close, got %v", err)
+	}
+}
+
+func TestTracker_Subscriptions(t *testing.T) {
+	tr := NewTracker(0)
+	pair := model.QuotesPair{Base: "BTC", Quote: "USDT"}
+	tr.Add(Of(pair, []string{"ticker", "depth"})...)
+	tr.Add(Of(pair, []string{"ticker"})...)
+	if subs := tr.Subscriptions(); len(subs) != 2 || subs[0].Channel != "ticker" || subs[1].Channel != "depth" {
+		t.Errorf("expected ticker and depth in order, got %+v", subs)
+	}
+	tr.Remove(model.Subscription{Pair: pair, Channel: "ticker"})
+	if subs := tr.Subscriptions(); len(subs) != 1 || subs[0].Channel != "depth" {
+		t.Errorf("expected only depth, got %+v", subs)
+	}
+}
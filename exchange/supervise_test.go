@@ -0,0 +1,115 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package exchange
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wang900115/quant/common/sys"
+	"github.com/wang900115/quant/model"
+	"github.com/wang900115/quant/model/currency"
+	"github.com/wang900115/quant/model/trade"
+)
+
+// flakyStream fails its first Dispatch like a dropped socket and runs the next one until ctx is done
+type flakyStream struct {
+	Provider
+	mu         sync.Mutex
+	dispatches int
+	reconnects int
+	subs       []model.Subscription
+	subscribed chan model.Subscription
+}
+
+func (f *flakyStream) Dispatch(ctx context.Context) error {
+	f.mu.Lock()
+	f.dispatches++
+	first := f.dispatches == 1
+	f.mu.Unlock()
+	if first {
+		return errors.New("read: connection reset by peer")
+	}
+	<-ctx.Done()
+	return nil
+}
+
+func (f *flakyStream) Reconnect() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reconnects++
+	return nil
+}
+
+func (f *flakyStream) Subscriptions() []model.Subscription { return f.subs }
+
+func (f *flakyStream) SubscribeStream(pair model.QuotesPair, channels []string) error {
+	for _, ch := range channels {
+		f.subscribed <- model.Subscription{Pair: pair, Channel: ch}
+	}
+	return nil
+}
+
+func TestProviders_SuperviseStreamsRestartsFailedDispatch(t *testing.T) {
+	btc := model.QuotesPair{ExchangeID: model.COINBASE, Base: currency.BTCSymbol, Quote: currency.USDTSymbol, Category: trade.SPOT}
+	f := &flakyStream{
+		subs:       []model.Subscription{{Pair: btc, Channel: "ticker"}, {Pair: btc, Channel: "level2"}},
+		subscribed: make(chan model.Subscription, 2),
+	}
+	ps := New()
+	ps.Register(model.COINBASE, f)
+
+	engine := sys.NewEngine(time.Millisecond, time.Second)
+	failed := make(chan error, 1)
+	engine.Hooks.OnError = func(name string, err error) { failed <- err }
+	ps.SuperviseStreams(engine)
+	defer engine.Stop()
+
+	select {
+	case <-failed:
+	case <-time.After(time.Second):
+		t.Fatal("dispatch error was not reported")
+	}
+	for _, want := range f.subs {
+		select {
+		case got := <-f.subscribed:
+			if got != want {
+				t.Errorf("expected resubscribe to %+v, got %+v", want, got)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("%s was not subscribed again", want.Channel)
+		}
+	}
+
+	s := engine.Status()[0]
+	if s.State != sys.TaskRunning || s.Restarts != 1 || s.LastError == "" {
+		t.Errorf("expected the stream running after one restart, got %+v", s)
+	}
+	deadline := time.Now().Add(time.Second)
+	for {
+		f.mu.Lock()
+		dispatches, reconnects := f.dispatches, f.reconnects
+		f.mu.Unlock()
+		if dispatches == 2 && reconnects == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected 2 dispatches and 1 reconnect, got %d and %d", dispatches, reconnects)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,233 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package metric
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Metric types of the Prometheus text exposition format
+const (
+	TypeCounter   = "counter"
+	TypeGauge     = "gauge"
+	TypeHistogram = "histogram"
+)
+
+// processStart is when the process loaded the package, used for ProcessUptime
+var processStart = time.Now()
+
+// Labels are the dimensions of a single sample
+type Labels map[string]string
+
+// Sample is one value of a family, Suffix extends the family name as in _bucket, _sum or _count
+type Sample struct {
+	Suffix string
+	Labels Labels
+	Value  float64
+}
+
+// Family is a named group of samples sharing a help text and type
+type Family struct {
+	Name    string
+	Help    string
+	Type    string
+	Samples []Sample
+}
+
+// Collector exposes its current values as families
+type Collector interface {
+	Collect() []Family
+}
+
+// CollectorFunc adapts a function to a Collector
+type CollectorFunc func() []Family
+
+func (f CollectorFunc) Collect() []Family { return f() }
+
+// CounterFamily exposes a single counter
+func CounterFamily(name, help string, c *CounterInt64, labels Labels) Family {
+	return Family{Name: name, Help: help, Type: TypeCounter, Samples: []Sample{{Labels: labels, Value: float64(c.Snapshot().Count())}}}
+}
+
+// PrecisionFamily exposes a single decimal counter, converted to a float
+func PrecisionFamily(name, help string, c *CounterPrecision, labels Labels) Family {
+	return Family{Name: name, Help: help, Type: TypeCounter, Samples: []Sample{{Labels: labels, Value: c.Snapshot().Count().InexactFloat64()}}}
+}
+
+// GaugeFamily exposes a single value as a gauge
+func GaugeFamily(name, help string, value float64, labels Labels) Family {
+	return Family{Name: name, Help: help, Type: TypeGauge, Samples: []Sample{{Labels: labels, Value: value}}}
+}
+
+// InfoFamily exposes the key-value pairs of a GaugeInfo as labels of a constant 1 gauge
+func InfoFamily(name, help string, g *GaugeInfo) Family {
+	g.mutex.Lock()
+	labels := make(Labels, len(g.value))
+	for k, v := range g.value {
+		labels[k] = v
+	}
+	g.mutex.Unlock()
+	return GaugeFamily(name, help, 1, labels)
+}
+
+// ProcessUptime reports the seconds since the process started
+var ProcessUptime = CollectorFunc(func() []Family {
+	return []Family{GaugeFamily("process_uptime_seconds", "Seconds since the process started.", time.Since(processStart).Seconds(), nil)}
+})
+
+// CounterInt64Vec is a set of counters partitioned by label values
+type CounterInt64Vec struct {
+	mu       sync.RWMutex
+	names    []string
+	counters map[string]*CounterInt64
+	values   map[string][]string
+}
+
+// NewCounterInt64Vec creates a vector whose counters are identified by the given label names
+func NewCounterInt64Vec(labelNames ...string) *CounterInt64Vec {
+	return &CounterInt64Vec{
+		names:    labelNames,
+		counters: make(map[string]*CounterInt64),
+		values:   make(map[string][]string),
+	}
+}
+
+// With returns the counter for the label values, given in the order of the label names
+func (v *CounterInt64Vec) With(values ...string) *CounterInt64 {
+	key := strings.Join(values, "\xff")
+	v.mu.RLock()
+	c, ok := v.counters[key]
+	v.mu.RUnlock()
+	if ok {
+		return c
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if c, ok := v.counters[key]; ok {
+		return c
+	}
+	c = NewCounterInt64()
+	v.counters[key] = c
+	v.values[key] = append([]string(nil), values...)
+	return c
+}
+
+// Sum returns the total of every counter in the vector
+func (v *CounterInt64Vec) Sum() int64 {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	var sum int64
+	for _, c := range v.counters {
+		sum += c.Snapshot().Count()
+	}
+	return sum
+}
+
+// Family exposes every counter of the vector as one sample
+func (v *CounterInt64Vec) Family(name, help string) Family {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	f := Family{Name: name, Help: help, Type: TypeCounter, Samples: make([]Sample, 0, len(v.counters))}
+	for key, c := range v.counters {
+		labels := make(Labels, len(v.names))
+		for i, name := range v.names {
+			if i < len(v.values[key]) {
+				labels[name] = v.values[key][i]
+			}
+		}
+		f.Samples = append(f.Samples, Sample{Labels: labels, Value: float64(c.Snapshot().Count())})
+	}
+	sort.Slice(f.Samples, func(i, j int) bool {
+		return formatLabels(f.Samples[i].Labels) < formatLabels(f.Samples[j].Labels)
+	})
+	return f
+}
+
+// WriteText renders families in the Prometheus text exposition format, samples in the order given
+func WriteText(w io.Writer, families []Family) error {
+	bw := bufio.NewWriter(w)
+	for _, f := range families {
+		if f.Help != "" {
+			bw.WriteString("# HELP " + f.Name + " " + escape(f.Help, false) + "\n")
+		}
+		if f.Type != "" {
+			bw.WriteString("# TYPE " + f.Name + " " + f.Type + "\n")
+		}
+		for _, s := range f.Samples {
+			bw.WriteString(f.Name + s.Suffix + formatLabels(s.Labels) + " " + formatValue(s.Value) + "\n")
+		}
+	}
+	return bw.Flush()
+}
+
+// Handler serves the families of every collector in the Prometheus text exposition format
+func Handler(collectors ...Collector) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var families []Family
+		for _, c := range collectors {
+			families = append(families, c.Collect()...)
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		WriteText(w, families)
+	})
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func formatLabels(labels Labels) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(name + `="` + escape(labels[name], true) + `"`)
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+func escape(s string, quote bool) string {
+	r := strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	if quote {
+		r = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+	}
+	return r.Replace(s)
+}
@@ -0,0 +1,91 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package metric
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestWriteText(t *testing.T) {
+	c := NewCounterInt64()
+	c.Inc(3)
+	p := NewCounterPrecision(2)
+	p.Inc(decimal.NewFromFloat(1.25))
+	info := NewGaugeInfo()
+	info.Set("version", `1.0 "beta"`)
+	vec := NewCounterInt64Vec("exchange", "outcome")
+	vec.With("OKX", "ok").Inc(2)
+	vec.With("Binance", "error").Inc(1)
+
+	var b strings.Builder
+	err := WriteText(&b, []Family{
+		CounterFamily("ticks_total", "Ticks seen.", c, Labels{"pair": "BTC/USDT"}),
+		PrecisionFamily("volume_total", "", p, nil),
+		InfoFamily("build_info", "Build.\nInfo", info),
+		vec.Family("requests_total", "Requests."),
+	})
+	if err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	want := `# HELP ticks_total Ticks seen.
+# TYPE ticks_total counter
+ticks_total{pair="BTC/USDT"} 3
+# TYPE volume_total counter
+volume_total 1.25
+# HELP build_info Build.\nInfo
+# TYPE build_info gauge
+build_info{version="1.0 \"beta\""} 1
+# HELP requests_total Requests.
+# TYPE requests_total counter
+requests_total{exchange="Binance",outcome="error"} 1
+requests_total{exchange="OKX",outcome="ok"} 2
+`
+	if b.String() != want {
+		t.Errorf("unexpected exposition:\n%s\nwant:\n%s", b.String(), want)
+	}
+}
+
+func TestCounterInt64Vec(t *testing.T) {
+	vec := NewCounterInt64Vec("a")
+	if vec.With("x") != vec.With("x") {
+		t.Fatal("expected the same counter for the same labels")
+	}
+	vec.With("x").Inc(2)
+	vec.With("y").Inc(3)
+	if sum := vec.Sum(); sum != 5 {
+		t.Errorf("expected sum 5, got %d", sum)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	c := NewCounterInt64()
+	c.Inc(1)
+	h := Handler(ProcessUptime, CollectorFunc(func() []Family {
+		return []Family{CounterFamily("events_total", "", c, nil)}
+	}))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("unexpected content type %q", ct)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "# TYPE process_uptime_seconds gauge\nprocess_uptime_seconds ") || !strings.Contains(body, "events_total 1\n") {
+		t.Errorf("unexpected body:\n%s", body)
+	}
+}
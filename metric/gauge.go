@@ -0,0 +1,62 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package metric
+
+import (
+	"math"
+	"sync/atomic"
+)
+
+// gaugeSnapshot represents a static snapshot of a gauge's value.
+type gaugeSnapshot float64
+
+// Value returns the value of the gauge's static snapshot.
+func (g gaugeSnapshot) Value() float64 {
+	return float64(g)
+}
+
+// Gauge is a thread-safe float64 value that can go up and down.
+type Gauge struct {
+	bits atomic.Uint64
+}
+
+// NewGauge creates and returns a new Gauge set to zero.
+func NewGauge() *Gauge {
+	return new(Gauge)
+}
+
+// Set replaces the gauge value.
+func (g *Gauge) Set(v float64) {
+	g.bits.Store(math.Float64bits(v))
+}
+
+// Add adds delta to the gauge value.
+func (g *Gauge) Add(delta float64) {
+	addFloat(&g.bits, delta)
+}
+
+// Snapshot returns a static snapshot of the current gauge value.
+func (g *Gauge) Snapshot() gaugeSnapshot {
+	return gaugeSnapshot(math.Float64frombits(g.bits.Load()))
+}
+
+// addFloat adds delta to a float64 stored as bits with a compare-and-swap loop.
+func addFloat(bits *atomic.Uint64, delta float64) {
+	for {
+		old := bits.Load()
+		if bits.CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+delta)) {
+			return
+		}
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package metric
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestGauge(t *testing.T) {
+	g := NewGauge()
+	g.Set(1.5)
+	g.Add(-0.25)
+	if v := g.Snapshot().Value(); v != 1.25 {
+		t.Errorf("expected gauge to be 1.25, got %v", v)
+	}
+}
+
+func TestGaugeConcurrentAdd(t *testing.T) {
+	g := NewGauge()
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 1000 {
+				g.Add(0.5)
+			}
+		}()
+	}
+	wg.Wait()
+	if v := g.Snapshot().Value(); v != 4000 {
+		t.Errorf("expected gauge to be 4000, got %v", v)
+	}
+}
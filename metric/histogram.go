@@ -0,0 +1,143 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package metric
+
+import (
+	"math"
+	"sort"
+	"sync/atomic"
+)
+
+// DefaultBuckets are latency bounds in seconds, from half a millisecond to ten seconds.
+var DefaultBuckets = []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// DefaultQuantiles are the quantiles reported by a histogram snapshot unless configured otherwise.
+var DefaultQuantiles = []float64{.5, .9, .99}
+
+// ExponentialBuckets returns count bounds starting at start, each factor times the previous one.
+func ExponentialBuckets(start, factor float64, count int) []float64 {
+	buckets := make([]float64, count)
+	for i := range buckets {
+		buckets[i] = start
+		start *= factor
+	}
+	return buckets
+}
+
+// HistogramSnapshot represents a static snapshot of a histogram.
+type HistogramSnapshot struct {
+	bounds    []float64
+	counts    []uint64 // cumulative, the last entry is the +Inf bucket
+	count     uint64
+	sum       float64
+	quantiles map[float64]float64
+}
+
+// Count returns the number of observations.
+func (h HistogramSnapshot) Count() uint64 { return h.count }
+
+// Sum returns the sum of every observation.
+func (h HistogramSnapshot) Sum() float64 { return h.sum }
+
+// Mean returns the average observation, zero without observations.
+func (h HistogramSnapshot) Mean() float64 {
+	if h.count == 0 {
+		return 0
+	}
+	return h.sum / float64(h.count)
+}
+
+// Buckets returns the upper bounds and their cumulative counts, without the +Inf bucket.
+func (h HistogramSnapshot) Buckets() ([]float64, []uint64) {
+	return h.bounds, h.counts[:len(h.bounds)]
+}
+
+// Quantiles returns the configured quantiles, estimated by interpolating within buckets.
+func (h HistogramSnapshot) Quantiles() map[float64]float64 { return h.quantiles }
+
+// Quantile estimates the q quantile by linear interpolation within the bucket that holds it.
+func (h HistogramSnapshot) Quantile(q float64) float64 {
+	if h.count == 0 || len(h.bounds) == 0 {
+		return math.NaN()
+	}
+	rank := q * float64(h.count)
+	i := sort.Search(len(h.counts), func(i int) bool { return float64(h.counts[i]) >= rank })
+	if i >= len(h.bounds) {
+		return h.bounds[len(h.bounds)-1]
+	}
+	lower, below := 0.0, uint64(0)
+	if i > 0 {
+		lower, below = h.bounds[i-1], h.counts[i-1]
+	}
+	inBucket := h.counts[i] - below
+	if inBucket == 0 {
+		return h.bounds[i]
+	}
+	return lower + (h.bounds[i]-lower)*(rank-float64(below))/float64(inBucket)
+}
+
+// Histogram counts observations into fixed buckets without locking.
+type Histogram struct {
+	bounds    []float64
+	quantiles []float64
+	counts    []atomic.Uint64 // per bucket, the last entry is the +Inf bucket
+	count     atomic.Uint64
+	sum       atomic.Uint64 // float64 bits
+}
+
+// NewHistogram creates a histogram with the given upper bounds, nil selects DefaultBuckets.
+// Quantiles select what Snapshot().Quantiles() reports, none selects DefaultQuantiles.
+func NewHistogram(buckets []float64, quantiles ...float64) *Histogram {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	if len(quantiles) == 0 {
+		quantiles = DefaultQuantiles
+	}
+	bounds := append([]float64(nil), buckets...)
+	sort.Float64s(bounds)
+	return &Histogram{
+		bounds:    bounds,
+		quantiles: append([]float64(nil), quantiles...),
+		counts:    make([]atomic.Uint64, len(bounds)+1),
+	}
+}
+
+// Observe records one value.
+func (h *Histogram) Observe(v float64) {
+	i := sort.SearchFloat64s(h.bounds, v)
+	h.counts[i].Add(1)
+	h.count.Add(1)
+	addFloat(&h.sum, v)
+}
+
+// Snapshot returns a static snapshot of the histogram.
+func (h *Histogram) Snapshot() HistogramSnapshot {
+	s := HistogramSnapshot{
+		bounds:    h.bounds,
+		counts:    make([]uint64, len(h.counts)),
+		sum:       math.Float64frombits(h.sum.Load()),
+		quantiles: make(map[float64]float64, len(h.quantiles)),
+	}
+	var cumulative uint64
+	for i := range h.counts {
+		cumulative += h.counts[i].Load()
+		s.counts[i] = cumulative
+	}
+	s.count = cumulative
+	for _, q := range h.quantiles {
+		s.quantiles[q] = s.Quantile(q)
+	}
+	return s
+}
@@ -0,0 +1,78 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package metric
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestHistogramBuckets(t *testing.T) {
+	h := NewHistogram([]float64{1, 2, 5})
+	for _, v := range []float64{0.5, 1, 1.5, 4, 9} {
+		h.Observe(v)
+	}
+	s := h.Snapshot()
+	if s.Count() != 5 || s.Sum() != 16 {
+		t.Fatalf("expected count 5 and sum 16, got %d and %v", s.Count(), s.Sum())
+	}
+	bounds, counts := s.Buckets()
+	want := []uint64{2, 3, 4}
+	for i := range bounds {
+		if counts[i] != want[i] {
+			t.Errorf("expected %d observations <= %v, got %d", want[i], bounds[i], counts[i])
+		}
+	}
+}
+
+func TestHistogramQuantile(t *testing.T) {
+	h := NewHistogram([]float64{10, 20, 30, 40}, 0.5, 0.9)
+	for i := range 40 {
+		h.Observe(float64(i) + 0.5)
+	}
+	s := h.Snapshot()
+	if q := s.Quantile(0.5); q != 20 {
+		t.Errorf("expected median 20, got %v", q)
+	}
+	if q := s.Quantiles()[0.9]; q != 36 {
+		t.Errorf("expected p90 36, got %v", q)
+	}
+	if q := NewHistogram(nil).Snapshot().Quantile(0.5); !math.IsNaN(q) {
+		t.Errorf("expected NaN without observations, got %v", q)
+	}
+}
+
+func TestExponentialBuckets(t *testing.T) {
+	buckets := ExponentialBuckets(0.001, 10, 4)
+	want := []float64{0.001, 0.01, 0.1, 1}
+	for i := range want {
+		if math.Abs(buckets[i]-want[i]) > 1e-12 {
+			t.Errorf("expected bucket %d to be %v, got %v", i, want[i], buckets[i])
+		}
+	}
+}
+
+func TestTimer(t *testing.T) {
+	timer := NewTimer(nil)
+	timer.Update(20 * time.Millisecond)
+	timer.Time(func() {})
+	s := timer.Snapshot()
+	if s.Count() != 2 || s.Rate.Count() != 2 {
+		t.Fatalf("expected two observations, got %d and %d", s.Count(), s.Rate.Count())
+	}
+	if s.Sum() < 0.02 {
+		t.Errorf("expected sum to be at least 20ms, got %vs", s.Sum())
+	}
+}
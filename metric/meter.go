@@ -0,0 +1,129 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package metric
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// meterTick is the interval the moving averages are updated at.
+const meterTick = 5 * time.Second
+
+// ewma is an exponentially weighted moving average of events per second, ticked every meterTick.
+type ewma struct {
+	alpha float64
+	rate  float64
+	init  bool
+}
+
+func newEWMA(window time.Duration) ewma {
+	return ewma{alpha: 1 - math.Exp(-meterTick.Seconds()/window.Seconds())}
+}
+
+func (e *ewma) tick(count int64) {
+	instant := float64(count) / meterTick.Seconds()
+	if !e.init {
+		e.rate, e.init = instant, true
+		return
+	}
+	e.rate += e.alpha * (instant - e.rate)
+}
+
+// MeterSnapshot represents a static snapshot of a meter.
+type MeterSnapshot struct {
+	count                     int64
+	rate1, rate5, rate15, avg float64
+}
+
+// Count returns the number of events marked.
+func (m MeterSnapshot) Count() int64 { return m.count }
+
+// Rate1 returns the one-minute moving average of events per second.
+func (m MeterSnapshot) Rate1() float64 { return m.rate1 }
+
+// Rate5 returns the five-minute moving average of events per second.
+func (m MeterSnapshot) Rate5() float64 { return m.rate5 }
+
+// Rate15 returns the fifteen-minute moving average of events per second.
+func (m MeterSnapshot) Rate15() float64 { return m.rate15 }
+
+// RateMean returns the mean events per second since the meter was created.
+func (m MeterSnapshot) RateMean() float64 { return m.avg }
+
+// Meter counts events and tracks their 1, 5 and 15 minute rates.
+// Marking is lock-free until a tick is due, the averages catch up on elapsed ticks lazily when read or marked.
+type Meter struct {
+	count     atomic.Int64
+	uncounted atomic.Int64
+	start     time.Time
+	// nextTick is when the next tick is due, as an offset from start
+	nextTick atomic.Int64
+
+	mu                   sync.Mutex
+	lastTick             time.Time
+	rate1, rate5, rate15 ewma
+}
+
+// NewMeter creates and returns a new Meter.
+func NewMeter() *Meter {
+	now := time.Now()
+	m := &Meter{
+		start:    now,
+		lastTick: now,
+		rate1:    newEWMA(time.Minute),
+		rate5:    newEWMA(5 * time.Minute),
+		rate15:   newEWMA(15 * time.Minute),
+	}
+	m.nextTick.Store(int64(meterTick))
+	return m
+}
+
+// Mark records n events.
+func (m *Meter) Mark(n int64) {
+	m.count.Add(n)
+	m.uncounted.Add(n)
+	if now := time.Now(); int64(now.Sub(m.start)) >= m.nextTick.Load() {
+		m.tickIfDue(now)
+	}
+}
+
+// Snapshot returns a static snapshot of the meter.
+func (m *Meter) Snapshot() MeterSnapshot {
+	now := time.Now()
+	m.tickIfDue(now)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	count := m.count.Load()
+	avg := 0.0
+	if elapsed := now.Sub(m.start).Seconds(); elapsed > 0 {
+		avg = float64(count) / elapsed
+	}
+	return MeterSnapshot{count: count, rate1: m.rate1.rate, rate5: m.rate5.rate, rate15: m.rate15.rate, avg: avg}
+}
+
+func (m *Meter) tickIfDue(now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for now.Sub(m.lastTick) >= meterTick {
+		count := m.uncounted.Swap(0)
+		m.rate1.tick(count)
+		m.rate5.tick(count)
+		m.rate15.tick(count)
+		m.lastTick = m.lastTick.Add(meterTick)
+	}
+	m.nextTick.Store(int64(m.lastTick.Add(meterTick).Sub(m.start)))
+}
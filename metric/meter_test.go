@@ -0,0 +1,61 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package metric
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestMeterCount(t *testing.T) {
+	m := NewMeter()
+	m.Mark(3)
+	m.Mark(2)
+	if count := m.Snapshot().Count(); count != 5 {
+		t.Errorf("expected count to be 5, got %d", count)
+	}
+}
+
+func TestMeterRates(t *testing.T) {
+	m := NewMeter()
+	m.uncounted.Add(50)
+	m.tickIfDue(m.lastTick.Add(meterTick))
+	// the first tick seeds every average with the instant rate
+	for window, rate := range map[string]float64{"1m": m.rate1.rate, "5m": m.rate5.rate, "15m": m.rate15.rate} {
+		if rate != 10 {
+			t.Errorf("expected %s rate to be 10/s after the first tick, got %v", window, rate)
+		}
+	}
+
+	// a minute of silence decays the one minute average faster than the longer ones
+	m.tickIfDue(m.lastTick.Add(time.Minute))
+	if want := 10 * math.Exp(-1); math.Abs(m.rate1.rate-want) > 1e-9 {
+		t.Errorf("expected 1m rate to decay to %v, got %v", want, m.rate1.rate)
+	}
+	if !(m.rate1.rate < m.rate5.rate && m.rate5.rate < m.rate15.rate) {
+		t.Errorf("expected 1m < 5m < 15m after idling, got %v %v %v", m.rate1.rate, m.rate5.rate, m.rate15.rate)
+	}
+}
+
+func TestMeterNextTick(t *testing.T) {
+	m := NewMeter()
+	if next := time.Duration(m.nextTick.Load()); next != meterTick {
+		t.Fatalf("expected the first tick due after %v, got %v", meterTick, next)
+	}
+	m.tickIfDue(m.lastTick.Add(2*meterTick + time.Second))
+	if next := time.Duration(m.nextTick.Load()); next != 3*meterTick {
+		t.Errorf("expected the next tick due after %v, got %v", 3*meterTick, next)
+	}
+}
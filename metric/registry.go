@@ -0,0 +1,199 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package metric
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// registered is every series of one metric name
+type registered struct {
+	help   string
+	kind   string
+	series map[string]*series
+}
+
+type series struct {
+	labels Labels
+	metric any
+}
+
+// Registry owns named metrics, each name holds one kind of metric with any number of label sets.
+// It implements Collector, exposing everything it owns.
+type Registry struct {
+	mu     sync.RWMutex
+	names  []string
+	byName map[string]*registered
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{byName: make(map[string]*registered)}
+}
+
+// Counter returns the counter registered under name and labels, creating it on first use
+func (r *Registry) Counter(name, help string, labels Labels) *CounterInt64 {
+	return getOrRegister(r, name, help, "counter", labels, NewCounterInt64)
+}
+
+// Gauge returns the gauge registered under name and labels, creating it on first use
+func (r *Registry) Gauge(name, help string, labels Labels) *Gauge {
+	return getOrRegister(r, name, help, "gauge", labels, NewGauge)
+}
+
+// Meter returns the meter registered under name and labels, creating it on first use
+func (r *Registry) Meter(name, help string, labels Labels) *Meter {
+	return getOrRegister(r, name, help, "meter", labels, NewMeter)
+}
+
+// Histogram returns the histogram registered under name and labels, creating it with buckets on first use
+func (r *Registry) Histogram(name, help string, buckets []float64, labels Labels) *Histogram {
+	return getOrRegister(r, name, help, "histogram", labels, func() *Histogram { return NewHistogram(buckets) })
+}
+
+// Timer returns the timer registered under name and labels, creating it with buckets on first use
+func (r *Registry) Timer(name, help string, buckets []float64, labels Labels) *Timer {
+	return getOrRegister(r, name, help, "timer", labels, func() *Timer { return NewTimer(buckets) })
+}
+
+// Get returns the metric registered under name and labels
+func (r *Registry) Get(name string, labels Labels) (any, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	reg, ok := r.byName[name]
+	if !ok {
+		return nil, false
+	}
+	s, ok := reg.series[formatLabels(labels)]
+	if !ok {
+		return nil, false
+	}
+	return s.metric, true
+}
+
+// getOrRegister panics when name is already registered as another kind of metric
+func getOrRegister[T any](r *Registry, name, help, kind string, labels Labels, create func() T) T {
+	key := formatLabels(labels)
+	r.mu.RLock()
+	if reg, ok := r.byName[name]; ok && reg.kind == kind {
+		if s, ok := reg.series[key]; ok {
+			r.mu.RUnlock()
+			return s.metric.(T)
+		}
+	}
+	r.mu.RUnlock()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	reg, ok := r.byName[name]
+	if !ok {
+		reg = &registered{help: help, kind: kind, series: make(map[string]*series)}
+		r.byName[name] = reg
+		r.names = append(r.names, name)
+	}
+	if reg.kind != kind {
+		panic(fmt.Sprintf("metric: %s is registered as a %s, not a %s", name, reg.kind, kind))
+	}
+	if s, ok := reg.series[key]; ok {
+		return s.metric.(T)
+	}
+	copied := make(Labels, len(labels))
+	for k, v := range labels {
+		copied[k] = v
+	}
+	m := create()
+	reg.series[key] = &series{labels: copied, metric: m}
+	return m
+}
+
+// Collect exposes every metric in registration order of their names, series sorted by labels.
+// Meters and timers add a <name>_rate gauge with the 1m, 5m, 15m and mean rates.
+func (r *Registry) Collect() []Family {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var families []Family
+	for _, name := range r.names {
+		reg := r.byName[name]
+		keys := make([]string, 0, len(reg.series))
+		for key := range reg.series {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		f := Family{Name: name, Help: reg.help}
+		rate := Family{Name: name + "_rate", Help: "Events per second of " + name + ".", Type: TypeGauge}
+		for _, key := range keys {
+			s := reg.series[key]
+			switch m := s.metric.(type) {
+			case *CounterInt64:
+				f.Type = TypeCounter
+				f.Samples = append(f.Samples, Sample{Labels: s.labels, Value: float64(m.Snapshot().Count())})
+			case *Gauge:
+				f.Type = TypeGauge
+				f.Samples = append(f.Samples, Sample{Labels: s.labels, Value: m.Snapshot().Value()})
+			case *Meter:
+				snap := m.Snapshot()
+				f.Type = TypeCounter
+				f.Samples = append(f.Samples, Sample{Labels: s.labels, Value: float64(snap.Count())})
+				rate.Samples = append(rate.Samples, rateSamples(s.labels, snap)...)
+			case *Histogram:
+				f.Type = TypeHistogram
+				f.Samples = append(f.Samples, histogramSamples(s.labels, m.Snapshot())...)
+			case *Timer:
+				snap := m.Snapshot()
+				f.Type = TypeHistogram
+				f.Samples = append(f.Samples, histogramSamples(s.labels, snap.HistogramSnapshot)...)
+				rate.Samples = append(rate.Samples, rateSamples(s.labels, snap.Rate)...)
+			}
+		}
+		families = append(families, f)
+		if len(rate.Samples) > 0 {
+			families = append(families, rate)
+		}
+	}
+	return families
+}
+
+func withLabel(labels Labels, name, value string) Labels {
+	out := make(Labels, len(labels)+1)
+	for k, v := range labels {
+		out[k] = v
+	}
+	out[name] = value
+	return out
+}
+
+func histogramSamples(labels Labels, h HistogramSnapshot) []Sample {
+	bounds, counts := h.Buckets()
+	samples := make([]Sample, 0, len(bounds)+3)
+	for i, bound := range bounds {
+		samples = append(samples, Sample{Suffix: "_bucket", Labels: withLabel(labels, "le", formatValue(bound)), Value: float64(counts[i])})
+	}
+	return append(samples,
+		Sample{Suffix: "_bucket", Labels: withLabel(labels, "le", "+Inf"), Value: float64(h.Count())},
+		Sample{Suffix: "_sum", Labels: labels, Value: h.Sum()},
+		Sample{Suffix: "_count", Labels: labels, Value: float64(h.Count())},
+	)
+}
+
+func rateSamples(labels Labels, m MeterSnapshot) []Sample {
+	return []Sample{
+		{Labels: withLabel(labels, "window", "1m"), Value: m.Rate1()},
+		{Labels: withLabel(labels, "window", "5m"), Value: m.Rate5()},
+		{Labels: withLabel(labels, "window", "15m"), Value: m.Rate15()},
+		{Labels: withLabel(labels, "window", "mean"), Value: m.RateMean()},
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package metric
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRegistryGetOrRegister(t *testing.T) {
+	r := NewRegistry()
+	a := r.Timer("latency_seconds", "Latency.", nil, Labels{"strategy": "a"})
+	if r.Timer("latency_seconds", "Latency.", nil, Labels{"strategy": "a"}) != a {
+		t.Error("expected the same timer for the same name and labels")
+	}
+	if r.Timer("latency_seconds", "Latency.", nil, Labels{"strategy": "b"}) == a {
+		t.Error("expected a new timer for other labels")
+	}
+	if m, ok := r.Get("latency_seconds", Labels{"strategy": "a"}); !ok || m != a {
+		t.Errorf("expected Get to return the registered timer, got %v", m)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic when reusing a name for another kind")
+		}
+	}()
+	r.Gauge("latency_seconds", "Latency.", nil)
+}
+
+func TestRegistryCollect(t *testing.T) {
+	r := NewRegistry()
+	r.Gauge("queue_depth", "Queue depth.", nil).Set(3)
+	r.Timer("latency_seconds", "Latency.", []float64{0.1, 1}, Labels{"strategy": "trail"}).Update(500 * time.Millisecond)
+
+	var out strings.Builder
+	if err := WriteText(&out, r.Collect()); err != nil {
+		t.Fatal(err)
+	}
+	text := out.String()
+	for _, want := range []string{
+		"# TYPE queue_depth gauge\nqueue_depth 3\n",
+		"# TYPE latency_seconds histogram\n",
+		`latency_seconds_bucket{le="0.1",strategy="trail"} 0`,
+		`latency_seconds_bucket{le="1",strategy="trail"} 1`,
+		`latency_seconds_bucket{le="+Inf",strategy="trail"} 1`,
+		`latency_seconds_sum{strategy="trail"} 0.5`,
+		`latency_seconds_count{strategy="trail"} 1`,
+		`latency_seconds_rate{strategy="trail",window="1m"}`,
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, text)
+		}
+	}
+	if strings.Index(text, "queue_depth") > strings.Index(text, "latency_seconds") {
+		t.Error("expected families in registration order")
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package metric
+
+import "time"
+
+// TimerSnapshot represents a static snapshot of a timer, durations in seconds.
+type TimerSnapshot struct {
+	HistogramSnapshot
+	Rate MeterSnapshot
+}
+
+// Timer measures durations in seconds into a histogram and the rate they happen at.
+type Timer struct {
+	histogram *Histogram
+	meter     *Meter
+}
+
+// NewTimer creates a timer with the given histogram bounds in seconds, nil selects DefaultBuckets.
+func NewTimer(buckets []float64, quantiles ...float64) *Timer {
+	return &Timer{histogram: NewHistogram(buckets, quantiles...), meter: NewMeter()}
+}
+
+// Update records a duration.
+func (t *Timer) Update(d time.Duration) {
+	t.histogram.Observe(d.Seconds())
+	t.meter.Mark(1)
+}
+
+// UpdateSince records the time elapsed since start.
+func (t *Timer) UpdateSince(start time.Time) {
+	t.Update(time.Since(start))
+}
+
+// Time runs fn and records how long it took.
+func (t *Timer) Time(fn func()) {
+	start := time.Now()
+	defer t.UpdateSince(start)
+	fn()
+}
+
+// Snapshot returns a static snapshot of the timer.
+func (t *Timer) Snapshot() TimerSnapshot {
+	return TimerSnapshot{HistogramSnapshot: t.histogram.Snapshot(), Rate: t.meter.Snapshot()}
+}
@@ -0,0 +1,78 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+package currency
+
+import "strings"
+
+// Venue names an exchange whose asset codes differ from the common symbols
+type Venue string
+
+const (
+	KrakenVenue Venue = "kraken"
+)
+
+type venueAliases struct {
+	// toVenue holds the code a venue uses in pair names
+	toVenue map[CurrencySymbol]string
+	// fromVenue holds every code a venue reports for an asset, including legacy X/Z prefixed ones
+	fromVenue map[string]CurrencySymbol
+}
+
+var aliases = map[Venue]venueAliases{
+	KrakenVenue: {
+		toVenue: map[CurrencySymbol]string{
+			BTCSymbol:  "XBT",
+			DOGESymbol: "XDG",
+		},
+		fromVenue: map[string]CurrencySymbol{
+			"XBT":  BTCSymbol,
+			"XXBT": BTCSymbol,
+			"XDG":  DOGESymbol,
+			"XXDG": DOGESymbol,
+			"XETH": ETHSymbol,
+			"XLTC": "LTC",
+			"XXRP": "XRP",
+			"XXLM": "XLM",
+			"XETC": "ETC",
+			"XZEC": "ZEC",
+			"XXMR": "XMR",
+			"ZUSD": USDSymbol,
+			"ZEUR": "EUR",
+			"ZGBP": "GBP",
+			"ZCAD": "CAD",
+			"ZJPY": "JPY",
+			"ZAUD": "AUD",
+		},
+	},
+}
+
+// ToVenue returns the code venue uses for symbol, e.g. XBT for BTC on Kraken
+func ToVenue(venue Venue, symbol CurrencySymbol) string {
+	if code, ok := aliases[venue].toVenue[symbol]; ok {
+		return code
+	}
+	return symbol.String()
+}
+
+// FromVenue maps an asset code reported by venue back to the common symbol, e.g. XXBT or XBT to BTC.
+// Kraken suffixes staked and earn balances (XBT.F, ETH2.S), those are reported under the underlying asset.
+func FromVenue(venue Venue, code string) CurrencySymbol {
+	code = strings.ToUpper(code)
+	if base, _, ok := strings.Cut(code, "."); ok {
+		code = base
+	}
+	if symbol, ok := aliases[venue].fromVenue[code]; ok {
+		return symbol
+	}
+	return CurrencySymbol(code)
+}
@@ -48,6 +48,7 @@ const (
 	TWDSymbol  CurrencySymbol = "TWD"
 	BTCSymbol  CurrencySymbol = "BTC"
 	ETHSymbol  CurrencySymbol = "ETH"
+	DOGESymbol CurrencySymbol = "DOGE"
 )
 
 func (cs CurrencySymbol) String() string {
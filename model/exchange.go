@@ -19,6 +19,8 @@ const (
 	BINANCE ExchangeId = iota
 	COINBASE
 	OKX
+	BYBIT
+	KRAKEN
 )
 
 type ExchangeName string
@@ -27,6 +29,8 @@ const (
 	BINANCE_NAME  ExchangeName = "Binance"
 	COINBASE_NAME ExchangeName = "Coinbase"
 	OKX_NAME      ExchangeName = "OKX"
+	BYBIT_NAME    ExchangeName = "Bybit"
+	KRAKEN_NAME   ExchangeName = "Kraken"
 )
 
 type Exchange struct {
@@ -38,6 +42,8 @@ var ExchangeMap = map[ExchangeId]Exchange{
 	BINANCE:  {ID: BINANCE, Name: BINANCE_NAME},
 	COINBASE: {ID: COINBASE, Name: COINBASE_NAME},
 	OKX:      {ID: OKX, Name: OKX_NAME},
+	BYBIT:    {ID: BYBIT, Name: BYBIT_NAME},
+	KRAKEN:   {ID: KRAKEN, Name: KRAKEN_NAME},
 }
 
 func GetExchange(id ExchangeId) Exchange {
@@ -34,6 +34,8 @@ type OrderRequest struct {
 	TimeInForce trade.TimeInForce
 	// Optional: client order ID for tracking
 	ClientOrderID string
+	// Optional: market the order is placed on, venues with a single market ignore it
+	Category trade.Category
 }
 
 type OrderResult struct {
@@ -96,3 +98,19 @@ type OrderEvent struct {
 	Type       trade.Type
 	UpdateTime int64
 }
+
+// Fill is an execution of an order, account guards book it against the pair it is collected for
+type Fill struct {
+	// Unique identifier for the order
+	OrderID string
+	// "BUY" or "SELL"
+	Side trade.Signal
+	// Price the quantity was executed at
+	Price decimal.Decimal
+	// Quantity executed by this fill
+	Quantity decimal.Decimal
+	// Fee paid in the quote currency
+	Fee decimal.Decimal
+	// Execution time in milliseconds since epoch
+	UpdateTime int64
+}
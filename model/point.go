@@ -38,6 +38,12 @@ func (qp QuotesPair) Symbol() string {
 	return fmt.Sprintf("%s/%s", qp.Base, qp.Quote)
 }
 
+// Subscription is a stream channel a provider is subscribed to for a pair
+type Subscription struct {
+	Pair    QuotesPair
+	Channel string
+}
+
 type PriceInterval struct {
 	OpenTime         string
 	CloseTime        string
@@ -73,6 +79,10 @@ func (pi *PriceInterval) CalculateIntervalDuration() error {
 type PricePoint struct {
 	NewPrice  decimal.Decimal
 	UpdatedAt time.Time
+	// Size is the traded quantity when the point is a trade, zero for quotes
+	Size decimal.Decimal
+	// Trace is stamped as the price travels from the venue to a decision
+	Trace Trace
 }
 
 var (
@@ -27,12 +27,32 @@ type StrategyStat struct {
 	PriceThreshold decimal.Decimal
 }
 
+// Ratchet is a move of the stop made by the strategy itself, such as moving it to break-even
+type Ratchet struct {
+	From   decimal.Decimal
+	To     decimal.Decimal
+	Reason string
+}
+
+// PartialExit is the part of the position a laddered strategy closes on this price
+type PartialExit struct {
+	// Rung is the 1-based target that fired, 0 when the protective stop closed the rest
+	Rung   int
+	Target decimal.Decimal
+	// Fraction of the initial position to close
+	Fraction decimal.Decimal
+	// Quantity to close, zero when the strategy was not given a position size
+	Quantity decimal.Decimal
+	Reason   string
+}
+
 type StrategyResult interface {
 	StrategyHybridResult | StrategyGeneralResult
 }
 
 type StrategyGeneralResult struct {
 	StrategyName  string
+	Pair          string
 	StrategyType  model.StrategyType
 	Triggered     bool
 	TriggerType   model.StrategyCategory
@@ -41,10 +61,17 @@ type StrategyGeneralResult struct {
 	LastTime      time.Time
 	TimeThreshold time.Duration
 	Error         error
+	// Trace stamps the price from the venue to this decision
+	Trace model.Trace
+	// Ratchets lists the stop moves the strategy made on this price
+	Ratchets []Ratchet
+	// Exit is set when a triggered strategy closes only part of the position
+	Exit *PartialExit
 }
 
 type StrategyHybridResult struct {
 	StrategyName  string
+	Pair          string
 	StrategyType  model.StrategyType
 	Triggered     bool
 	TriggerType   model.StrategyCategory
@@ -54,11 +81,14 @@ type StrategyHybridResult struct {
 	StopStat      StrategyStat
 	ProfitStat    StrategyStat
 	Error         error
+	// Trace stamps the price from the venue to this decision
+	Trace model.Trace
 }
 
 func (sr *StrategyGeneralResult) Marshall() map[string]interface{} {
 	return map[string]interface{}{
 		"StrategyName":  sr.StrategyName,
+		"Pair":          sr.Pair,
 		"StrategyType":  sr.StrategyType,
 		"Triggered":     sr.Triggered,
 		"TriggerType":   sr.TriggerType,
@@ -66,12 +96,16 @@ func (sr *StrategyGeneralResult) Marshall() map[string]interface{} {
 		"LastTime":      sr.LastTime,
 		"TimeThreshold": sr.TimeThreshold,
 		"Error":         sr.Error,
+		"Latency":       sr.Latency(),
+		"Ratchets":      sr.Ratchets,
+		"Exit":          sr.Exit,
 	}
 }
 
 func (sr *StrategyHybridResult) Marshall() map[string]interface{} {
 	return map[string]interface{}{
 		"StrategyName":  sr.StrategyName,
+		"Pair":          sr.Pair,
 		"StrategyType":  sr.StrategyType,
 		"Triggered":     sr.Triggered,
 		"TriggerType":   sr.TriggerType,
@@ -80,6 +114,7 @@ func (sr *StrategyHybridResult) Marshall() map[string]interface{} {
 		"StopStat":      sr.StopStat,
 		"ProfitStat":    sr.ProfitStat,
 		"Error":         sr.Error,
+		"Latency":       sr.Latency(),
 	}
 }
 
@@ -113,6 +148,16 @@ func NewHybrid(strategyName string, strategyType model.StrategyType, LastPrice,
 	}
 }
 
+// Latency returns the time the price spent in each hop before this decision
+func (sr *StrategyGeneralResult) Latency() model.Latency {
+	return sr.Trace.Latency()
+}
+
+// Latency returns the time the price spent in each hop before this decision
+func (sr *StrategyHybridResult) Latency() model.Latency {
+	return sr.Trace.Latency()
+}
+
 func (sr *StrategyGeneralResult) SetError(err error) {
 	sr.Error = err
 }
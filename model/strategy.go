@@ -7,6 +7,7 @@ const (
 	DEBUNCED        StrategyType = "Debounced"
 	HYBRID_DEBUNCED StrategyType = "Hybrid-Debounced"
 	HYBRID_FIXED    StrategyType = "Hybrid-Fixed"
+	GUARD           StrategyType = "Guard"
 )
 
 func (st StrategyType) String() string {
@@ -16,8 +17,9 @@ func (st StrategyType) String() string {
 type StrategyCategory string
 
 const (
-	STOP_LOSS   StrategyCategory = "stop_loss"
-	TAKE_PROFIT StrategyCategory = "take_profit"
+	STOP_LOSS       StrategyCategory = "stop_loss"
+	TAKE_PROFIT     StrategyCategory = "take_profit"
+	CIRCUIT_BREAKER StrategyCategory = "circuit_breaker"
 )
 
 func (sc StrategyCategory) String() string {
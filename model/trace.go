@@ -0,0 +1,118 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package model
+
+import "time"
+
+// Trace stamps a price update at every hop from the venue to a strategy decision, zero stamps were not reached
+type Trace struct {
+	// EventTime is when the venue produced the update, on the venue clock
+	EventTime time.Time
+	// ReceivedAt is when the frame was read from the socket
+	ReceivedAt time.Time
+	// DispatchedAt is when the parsed price was handed to the stream channel
+	DispatchedAt time.Time
+	// EnqueuedAt is when the engine accepted the price in Collect
+	EnqueuedAt time.Time
+	// DequeuedAt is when a worker picked the price up for a strategy
+	DequeuedAt time.Time
+	// DecidedAt is when the strategy finished evaluating the price
+	DecidedAt time.Time
+}
+
+// Latency is the time spent between consecutive stamps of a trace, zero when either stamp is missing
+type Latency struct {
+	// Exchange is the venue event to local receive, negative when the venue clock runs ahead
+	Exchange time.Duration
+	// Dispatch is parsing the frame and handing it to the stream channel
+	Dispatch time.Duration
+	// Ingest is the stream channel until Collect
+	Ingest time.Duration
+	// Queue is waiting in the engine inbox and shard queue
+	Queue time.Duration
+	// Evaluate is the strategy deciding on the price
+	Evaluate time.Duration
+	// Total is the first to the last stamp
+	Total time.Duration
+}
+
+// Hop names a Latency field
+type Hop string
+
+const (
+	HopExchange Hop = "exchange"
+	HopDispatch Hop = "dispatch"
+	HopIngest   Hop = "ingest"
+	HopQueue    Hop = "queue"
+	HopEvaluate Hop = "evaluate"
+	HopTotal    Hop = "total"
+)
+
+// Hops lists every hop in pipeline order
+var Hops = []Hop{HopExchange, HopDispatch, HopIngest, HopQueue, HopEvaluate, HopTotal}
+
+// Latency returns the per hop durations of the trace
+func (t Trace) Latency() Latency {
+	get := func(hop Hop) time.Duration {
+		d, _ := t.Get(hop)
+		return d
+	}
+	return Latency{
+		Exchange: get(HopExchange),
+		Dispatch: get(HopDispatch),
+		Ingest:   get(HopIngest),
+		Queue:    get(HopQueue),
+		Evaluate: get(HopEvaluate),
+		Total:    get(HopTotal),
+	}
+}
+
+// Get returns the duration of one hop and whether both of its stamps were set
+func (t Trace) Get(hop Hop) (time.Duration, bool) {
+	var from, to time.Time
+	switch hop {
+	case HopExchange:
+		from, to = t.EventTime, t.ReceivedAt
+	case HopDispatch:
+		from, to = t.ReceivedAt, t.DispatchedAt
+	case HopIngest:
+		from, to = t.DispatchedAt, t.EnqueuedAt
+	case HopQueue:
+		from, to = t.EnqueuedAt, t.DequeuedAt
+	case HopEvaluate:
+		from, to = t.DequeuedAt, t.DecidedAt
+	case HopTotal:
+		for _, stamp := range []time.Time{t.EventTime, t.ReceivedAt, t.DispatchedAt, t.EnqueuedAt, t.DequeuedAt, t.DecidedAt} {
+			if stamp.IsZero() {
+				continue
+			}
+			if from.IsZero() {
+				from = stamp
+			}
+			to = stamp
+		}
+	}
+	if from.IsZero() || to.IsZero() {
+		return 0, false
+	}
+	return to.Sub(from), true
+}
+
+// DispatchPrice stamps a parsed price with its venue time, the time its frame was received and now, then pushes it
+func DispatchPrice(ch chan PricePoint, p PricePoint, received time.Time) {
+	p.Trace.EventTime = p.UpdatedAt
+	p.Trace.ReceivedAt = received
+	p.Trace.DispatchedAt = time.Now()
+	PushToChan(ch, p)
+}
@@ -22,6 +22,16 @@ const (
 	TRIGGERED_REASON_FIXED_ATR_TAKEPROFIT         = "ATR Based Take Profit Triggered"
 	TRIGGERED_REASON_FIXED_MA_STOPLOSS            = "Moving Average Stop Loss Triggered"
 	TRIGGERED_REASON_FIXED_MA_TAKEPROFIT          = "Moving Average Take Profit Triggered"
+	TRIGGERED_REASON_FIXED_CHANDELIER_STOPLOSS    = "Chandelier Exit Stop Loss Triggered"
+	TRIGGERED_REASON_FIXED_PARABOLIC_SAR_STOPLOSS = "Parabolic SAR Stop Loss Triggered"
+	TRIGGERED_REASON_FIXED_PARABOLIC_SAR_FLIP     = "Parabolic SAR Trend Flip Triggered"
+	TRIGGERED_REASON_FIXED_BREAK_EVEN_STOPLOSS    = "Break-Even Ratchet Stop Loss Triggered"
+	TRIGGERED_REASON_FIXED_LADDER_TAKEPROFIT      = "Ladder Take Profit Target Triggered"
+	TRIGGERED_REASON_FIXED_LADDER_STOPLOSS        = "Ladder Stop Loss Triggered"
+	TRIGGERED_REASON_FIXED_BAND_STOPLOSS          = "Band Stop Loss Triggered"
+	TRIGGERED_REASON_FIXED_BAND_TAKEPROFIT        = "Band Take Profit Triggered"
+	TRIGGERED_REASON_FIXED_LIQUIDITY_STOPLOSS     = "Liquidity-Adjusted Stop Loss Triggered"
+	TRIGGERED_REASON_FIXED_LIQUIDITY_DEPTH        = "Order Book Depth Collapse Triggered"
 )
 
 const (
@@ -33,6 +43,16 @@ const (
 	TRIGGERED_REASON_DEBOUNCED_ATR_TAKEPROFIT         = "ATR Based Take Profit Triggered with Time Delay"
 	TRIGGERED_REASON_DEBOUNCED_MA_STOPLOSS            = "Moving Average Stop Loss Triggered with Time Delay"
 	TRIGGERED_REASON_DEBOUNCED_MA_TAKEPROFIT          = "Moving Average Take Profit Triggered with Time Delay"
+	TRIGGERED_REASON_DEBOUNCED_CHANDELIER_STOPLOSS    = "Chandelier Exit Stop Loss Triggered with Time Delay"
+	TRIGGERED_REASON_DEBOUNCED_PARABOLIC_SAR_STOPLOSS = "Parabolic SAR Stop Loss Triggered with Time Delay"
+	TRIGGERED_REASON_DEBOUNCED_PARABOLIC_SAR_FLIP     = "Parabolic SAR Trend Flip Triggered with Time Delay"
+	TRIGGERED_REASON_DEBOUNCED_BREAK_EVEN_STOPLOSS    = "Break-Even Ratchet Stop Loss Triggered with Time Delay"
+	TRIGGERED_REASON_DEBOUNCED_LADDER_TAKEPROFIT      = "Ladder Take Profit Target Triggered with Time Delay"
+	TRIGGERED_REASON_DEBOUNCED_LADDER_STOPLOSS        = "Ladder Stop Loss Triggered with Time Delay"
+	TRIGGERED_REASON_DEBOUNCED_BAND_STOPLOSS          = "Band Stop Loss Triggered with Time Delay"
+	TRIGGERED_REASON_DEBOUNCED_BAND_TAKEPROFIT        = "Band Take Profit Triggered with Time Delay"
+	TRIGGERED_REASON_DEBOUNCED_LIQUIDITY_STOPLOSS     = "Liquidity-Adjusted Stop Loss Triggered with Time Delay"
+	TRIGGERED_REASON_DEBOUNCED_LIQUIDITY_DEPTH        = "Order Book Depth Collapse Triggered with Time Delay"
 )
 
 const (
@@ -40,4 +60,26 @@ const (
 	TRIGGERED_REASON_HYBRID_RISK_REWARD_TAKEPROFIT = "Hybrid Risk-Reward Take Profit Triggered"
 	TRIGGERED_REASON_STRUCTURE_SWING_STOPLOSS      = "Structure Swing Stop Loss Triggered"
 	TRIGGERED_REASON_STRUCTURE_SWING_TAKEPROFIT    = "Structure Swing Take Profit Triggered"
+	TRIGGERED_REASON_HYBRID_SUPERTREND_STOPLOSS    = "Hybrid Supertrend Stop Loss Triggered"
+	TRIGGERED_REASON_HYBRID_SUPERTREND_TAKEPROFIT  = "Hybrid Supertrend Take Profit Triggered"
+	TRIGGERED_REASON_HYBRID_VWAP_STOPLOSS          = "Hybrid VWAP Band Stop Loss Triggered"
+	TRIGGERED_REASON_HYBRID_VWAP_TAKEPROFIT        = "Hybrid VWAP Band Take Profit Triggered"
+)
+
+const (
+	TRIGGERED_REASON_TIME_EXIT_MAX_HOLD      = "Maximum Holding Period Exit Triggered"
+	TRIGGERED_REASON_TIME_EXIT_SESSION_CLOSE = "Session Close Exit Triggered"
+	TRIGGERED_REASON_TIME_EXIT_NO_PROGRESS   = "No Progress Exit Triggered"
+)
+
+const (
+	TRIGGERED_REASON_GUARD_DAILY_LOSS         = "Daily Loss Limit Circuit Breaker Triggered"
+	TRIGGERED_REASON_GUARD_MAX_DRAWDOWN       = "Maximum Drawdown Circuit Breaker Triggered"
+	TRIGGERED_REASON_GUARD_CONSECUTIVE_LOSSES = "Consecutive Losses Circuit Breaker Triggered"
+)
+
+const (
+	RATCHETED_REASON_BREAK_EVEN  = "Stop Moved to Break-Even"
+	RATCHETED_REASON_PROFIT_LOCK = "Stop Locked In Profit"
+	RATCHETED_REASON_LADDER_RUNG = "Stop Moved to Previous Target"
 )
@@ -16,12 +16,20 @@ package engine
 import (
 	"context"
 	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/wang900115/quant/common/clock"
 	"github.com/wang900115/quant/common/sys"
+	"github.com/wang900115/quant/metric"
 	"github.com/wang900115/quant/model"
 	"github.com/wang900115/quant/model/result"
 	"github.com/wang900115/quant/stoploss"
+	"github.com/wang900115/quant/stoploss/engine/sink"
 )
 
 type strategyEngineError struct{ msg string }
@@ -33,6 +41,7 @@ func (e *strategyEngineError) Error() string {
 var (
 	errNoStrategies = &strategyEngineError{"no strategies registered"}
 	errNonsupported = &strategyEngineError{"unsupported strategy type"}
+	errNotCreated   = &strategyEngineError{"engine already started or stopped"}
 )
 
 type Config struct {
@@ -48,8 +57,26 @@ type Config struct {
 	HeartbeatInterval time.Duration
 	// Interval between for failed goroutine retry mechanism
 	RetryInterval time.Duration
+	// Restart policy of the supervised goroutines, the zero value backs off from RetryInterval and restarts forever
+	Restart sys.Policy
 	// Report Callback func
 	ReportCallback func(interface{})
+	// Destination of result events, nil logs them through slog, Stop closes it
+	Sink sink.Sink
+	// Number of worker shards evaluating strategies, zero selects one per CPU
+	Shards int
+	// Queue depth of each worker shard, zero falls back to BufferSize
+	ShardQueueSize int
+	// What strategies are grouped by when pinned to a shard
+	ShardBy ShardKey
+	// Back-pressure policy per input channel, channels left out use DropNewest
+	Backpressure map[Inbox]Policy
+	// How long BlockWithDeadline waits for room in a full channel
+	BlockDeadline time.Duration
+	// How long Stop waits for in-flight ticks to be evaluated, zero selects five seconds
+	DrainTimeout time.Duration
+	// Clock stamps ticks and drives timeouts and heartbeats, nil selects the system clock
+	Clock clock.Clock
 }
 
 func DefaultConfig() Config {
@@ -59,6 +86,10 @@ func DefaultConfig() Config {
 		CheckInterval:     5 * time.Second,
 		HeartbeatInterval: 15 * time.Second,
 		RetryInterval:     1 * time.Second,
+		ShardQueueSize:    256,
+		ShardBy:           ShardByStrategy,
+		BlockDeadline:     50 * time.Millisecond,
+		DrainTimeout:      5 * time.Second,
 	}
 }
 
@@ -66,113 +97,147 @@ type StrategyEngine struct {
 	engine    *sys.Engine
 	portfolio *Portfolio
 	execution *Execution
+	workers   *workerPool
 	Reporter  *Report
 	Metrics   *Metrics
 	Config    Config
+
+	// mu guards the input channels, Collect holds it shared so Stop can close them safely
+	mu         sync.RWMutex
+	state      atomic.Int32
+	heartbeats *heartbeats
+	clock      clock.Clock
 }
 
 func New(config Config) *StrategyEngine {
-	return &StrategyEngine{
-		engine:    sys.NewEngine(config.RetryInterval, config.CheckInterval),
-		portfolio: NewPortfolio(),
-		execution: NewExecutionManager(config.BufferSize, config.BufferRSize),
-		Reporter:  NewReport(config.ReportCallback),
-		Metrics:   NewMetrics(),
-		Config:    config,
+	defaults := DefaultConfig()
+	if config.CheckInterval <= 0 {
+		config.CheckInterval = defaults.CheckInterval
+	}
+	if config.HeartbeatInterval <= 0 {
+		config.HeartbeatInterval = defaults.HeartbeatInterval
+	}
+	if config.DrainTimeout <= 0 {
+		config.DrainTimeout = defaults.DrainTimeout
+	}
+	if config.ShardQueueSize <= 0 {
+		config.ShardQueueSize = config.BufferSize
+	}
+	csm := &StrategyEngine{
+		engine:     sys.NewEngine(config.RetryInterval, config.CheckInterval),
+		portfolio:  NewPortfolio(),
+		execution:  NewExecutionManager(config.BufferSize, config.BufferRSize),
+		workers:    newWorkerPool(config.Shards, config.ShardQueueSize),
+		Reporter:   NewReport(config.ReportCallback),
+		Metrics:    NewMetrics(),
+		Config:     config,
+		heartbeats: newHeartbeats(clock.Or(config.Clock)),
+		clock:      clock.Or(config.Clock),
 	}
+	if config.Restart != (sys.Policy{}) {
+		csm.engine.Policy = config.Restart
+	}
+	csm.Reporter.Sink = config.Sink
+	csm.Reporter.Metrics = csm.Metrics
+	csm.Metrics.Info.Set("shards", strconv.Itoa(len(csm.workers.queues)))
+	csm.Metrics.Info.Set("shard_by", config.ShardBy.String())
+	for _, name := range Inboxes {
+		csm.Metrics.Info.Set("policy_"+strings.ToLower(string(name)), config.Backpressure[name].String())
+	}
+	csm.execution.setPolicies(config.Backpressure, config.BlockDeadline, csm.clock)
+	return csm
 }
 
 func (csm *StrategyEngine) RegisterStrategy(name string, strategy interface{}) error {
+	return csm.register(name, "", strategy)
+}
+
+// RegisterPairStrategy registers a strategy that only evaluates updates fed through CollectPair for pair
+func (csm *StrategyEngine) RegisterPairStrategy(pair model.QuotesPair, name string, strategy interface{}) error {
+	return csm.register(name, PairKey(pair), strategy)
+}
+
+func (csm *StrategyEngine) register(name, pair string, strategy interface{}) error {
 	switch s := strategy.(type) {
 	case stoploss.FixedStopLoss:
-		csm.portfolio.RegistFixedStoplossStrategy(name, s)
+		csm.portfolio.registFixedStoploss(name, pair, s)
 	case stoploss.DebouncedStopLoss:
-		csm.portfolio.RegistDebouncedStoplossStrategy(name, s)
+		csm.portfolio.registDebouncedStoploss(name, pair, s)
 	case stoploss.FixedTakeProfit:
-		csm.portfolio.RegistFixedTakeProfitStrategy(name, s)
+		csm.portfolio.registFixedTakeProfit(name, pair, s)
 	case stoploss.DebouncedTakeProfit:
-		csm.portfolio.RegistDebouncedTakeProfitStrategy(name, s)
+		csm.portfolio.registDebouncedTakeProfit(name, pair, s)
 	case stoploss.HybridWithoutTime:
-		csm.portfolio.RegistHybridFixedStrategy(name, s)
+		csm.portfolio.registHybridFixed(name, pair, s)
 	case stoploss.HybridWithTime:
-		csm.portfolio.RegistHybridDebouncedStrategy(name, s)
+		csm.portfolio.registHybridDebounced(name, pair, s)
+	case stoploss.AccountGuard:
+		csm.portfolio.registGuard(name, pair, s)
 	default:
 		return errNonsupported
 	}
 	return nil
 }
 
+// Start launches the handlers, worker shards and reporters and returns once they are running
 func (csm *StrategyEngine) Start() error {
-
 	if csm.portfolio.count == 0 {
 		return errNoStrategies
 	}
-	goroutineCount := 0
-	if len(csm.portfolio.fixedStoplossStrategies) > 0 {
-		goroutineCount++
-		csm.engine.SafeGo(func(ctx context.Context) {
-			csm.handleFixedStopLoss(ctx)
-		}, nil)
+	if !csm.state.CompareAndSwap(int32(StateCreated), int32(StateStarting)) {
+		return errNotCreated
 	}
-	if len(csm.portfolio.DebouncedStoplossStrategies) > 0 {
-		goroutineCount++
-		csm.engine.SafeGo(func(ctx context.Context) {
-			csm.handleDebouncedStopLoss(ctx)
-		}, nil)
-	}
-	if len(csm.portfolio.fixedTakeProfitStrategies) > 0 {
-		goroutineCount++
-		csm.engine.SafeGo(func(ctx context.Context) {
-			csm.handleFixedProfit(ctx)
-		}, nil)
-	}
-	if len(csm.portfolio.DebouncedTakeProfitStrategies) > 0 {
-		goroutineCount++
-		csm.engine.SafeGo(func(ctx context.Context) {
-			csm.handleDebouncedProfit(ctx)
-		}, nil)
-	}
-	if len(csm.portfolio.hybridFixedStrategies) > 0 {
-		goroutineCount++
-		csm.engine.SafeGo(func(ctx context.Context) {
-			csm.handleFixedHybrid(ctx)
-		}, nil)
+
+	csm.workers.start(csm.engine)
+	handlers := []struct {
+		name    string
+		active  bool
+		in      *inbox
+		process func(tick, context.Context)
+	}{
+		{"handleFixedStopLoss", len(csm.portfolio.fixedStoplossStrategies) > 0, csm.execution.fixedStoplossChannel, csm.processFixedStopStrategies},
+		{"handleDebouncedStopLoss", len(csm.portfolio.DebouncedStoplossStrategies) > 0, csm.execution.DebouncedStoplossChannel, csm.processDebouncedStopStrategies},
+		{"handleFixedProfit", len(csm.portfolio.fixedTakeProfitStrategies) > 0, csm.execution.fixedTakeProfitChannel, csm.processFixedProfitStrategies},
+		{"handleDebouncedProfit", len(csm.portfolio.DebouncedTakeProfitStrategies) > 0, csm.execution.DebouncedTakeProfitChannel, csm.processDebouncedProfitStrategies},
+		{"handleFixedHybrid", len(csm.portfolio.hybridFixedStrategies) > 0, csm.execution.hybridFixedChannel, csm.processHybridFixedStrategies},
+		{"handleDebouncedHybrid", len(csm.portfolio.hybridDebouncedStrategies) > 0, csm.execution.hybridDebouncedChannel, csm.processHybridDebouncedStrategies},
+		{"handleGuard", len(csm.portfolio.guards) > 0, csm.execution.guardChannel, csm.processGuards},
 	}
-	if len(csm.portfolio.hybridDebouncedStrategies) > 0 {
+	goroutineCount := 0
+	for _, h := range handlers {
+		if !h.active {
+			continue
+		}
 		goroutineCount++
-		csm.engine.SafeGo(func(ctx context.Context) {
-			csm.handleDebouncedHybrid(ctx)
-		}, nil)
+		csm.heartbeats.register(h.name)
+		csm.engine.Supervise(sys.Task{Name: h.name, Run: func(ctx context.Context) error {
+			csm.handle(ctx, h.name, h.in, h.process)
+			return nil
+		}})
 	}
 
 	generalResult, hybridResult := csm.execution.getResult()
+	reporterCount := 0
 	if csm.portfolio.openGeneral {
-		csm.engine.SafeGo(func(ctx context.Context) {
+		reporterCount++
+		csm.engine.Supervise(sys.Task{Name: "reportGeneral", Run: func(ctx context.Context) error {
 			csm.Reporter.ProcessGeneralResult(generalResult, ctx)
-		}, nil)
+			return nil
+		}})
 	}
 
 	if csm.portfolio.openHybrid {
-		csm.engine.SafeGo(func(ctx context.Context) {
+		reporterCount++
+		csm.engine.Supervise(sys.Task{Name: "reportHybrid", Run: func(ctx context.Context) error {
 			csm.Reporter.ProcessHybridResult(hybridResult, ctx)
-		}, nil)
+			return nil
+		}})
 	}
+	csm.engine.Supervise(sys.Task{Name: "monitor", Run: csm.monitor})
 
 	// Log the number of started goroutines
-	log.Printf("Started %d strategy goroutines + %d reporter goroutines", goroutineCount, func() int {
-		count := 0
-		if csm.portfolio.openGeneral {
-			count++
-		}
-		if csm.portfolio.openHybrid {
-			count++
-		}
-		return count
-	}())
-
-	time.Sleep(30 * time.Second)
-
-	log.Println(csm.Snapshot())
+	log.Printf("Started %d strategy goroutines + %d worker shards + %d reporter goroutines", goroutineCount, len(csm.workers.queues), reporterCount)
+	csm.state.CompareAndSwap(int32(StateStarting), int32(StateRunning))
 	return nil
 }
 
@@ -184,303 +249,511 @@ func (csm *StrategyEngine) GetMetrics() map[string]interface{} {
 	return csm.Metrics.Stats()
 }
 
-func (csm *StrategyEngine) handleFixedStopLoss(ctx context.Context) {
-	ticker := time.NewTicker(csm.Config.HeartbeatInterval)
-	defer ticker.Stop()
-	for {
-		select {
-		case <-ctx.Done():
-			log.Println("[handleFixedStopLoss] stopped")
-			return
-		case update := <-csm.execution.fixedStoplossChannel:
-			csm.processFixedStopStrategies(update, ctx)
-		case <-ticker.C:
-			log.Println("[handleFixedStopLoss] heartbeat")
-			continue
-		}
+// MetricsHandler serves the engine metrics and process uptime in the Prometheus text format
+func (csm *StrategyEngine) MetricsHandler() http.Handler {
+	return metric.Handler(metric.ProcessUptime, csm.Metrics)
+}
+
+// submit queues a strategy evaluation on the shard owning key, each shard runs its queue in order
+func (csm *StrategyEngine) submit(key string, typ model.StrategyType, category model.StrategyCategory, fn job, ctx context.Context) {
+	csm.workers.pending.Add(1)
+	queue := csm.workers.shard(key)
+	select {
+	case queue <- fn:
+		return
+	default:
+	}
+	timer := csm.clock.NewTimer(csm.Config.ReadTimeout)
+	defer timer.Stop()
+	select {
+	case queue <- fn:
+	case <-timer.C():
+		csm.workers.pending.Add(-1)
+		csm.Metrics.RecordChannelTimeout(typ, category)
+	case <-ctx.Done():
+		csm.workers.pending.Add(-1)
+	}
+}
 
+// deliver hands a result to the reporter, waiting at most ReadTimeout.
+// The timer only exists while the send blocks, so a fake clock sees exactly the stalled sends.
+func deliver[T any](csm *StrategyEngine, results chan<- T, r T, typ model.StrategyType, category model.StrategyCategory, ctx context.Context) {
+	select {
+	case results <- r:
+		csm.Reporter.inFlight.Add(1)
+		return
+	default:
+	}
+	timer := csm.clock.NewTimer(csm.Config.ReadTimeout)
+	defer timer.Stop()
+	select {
+	case results <- r:
+		csm.Reporter.inFlight.Add(1)
+	case <-timer.C():
+		csm.Metrics.RecordChannelTimeout(typ, category)
+	case <-ctx.Done():
 	}
 }
 
-func (csm *StrategyEngine) handleDebouncedStopLoss(ctx context.Context) {
-	ticker := time.NewTicker(csm.Config.HeartbeatInterval)
-	defer ticker.Stop()
-	for {
-		select {
-		case <-ctx.Done():
-			log.Println("[handleDebouncedStopLoss] stopped")
-			return
-		case update := <-csm.execution.DebouncedStoplossChannel:
-			csm.processDebouncedStopStrategies(update, ctx)
-		case <-ticker.C:
-			log.Println("[handleDebouncedStopLoss] heartbeat")
+func (csm *StrategyEngine) processFixedStopStrategies(update tick, ctx context.Context) {
+	for _, e := range csm.portfolio.fixedStoplossEntries() {
+		if !e.accepts(update.pair) {
 			continue
 		}
+		name, strategy, point, pair := e.name, e.strategy, update.point, update.pair
+		csm.submit(e.key(csm.Config.ShardBy), model.FIXED, model.STOP_LOSS, func(ctx context.Context) {
+			point.Trace.DequeuedAt = csm.clock.Now()
+			shouldTrigger, err := strategy.ShouldTriggerStopLoss(point.NewPrice)
+			feedTrade(name, strategy, point)
+			newThreshold, calcErr := strategy.CalculateStopLoss(point.NewPrice)
+			point.Trace.DecidedAt = csm.clock.Now()
+			if calcErr == nil {
+				result := result.NewGeneral(name, model.FIXED, model.STOP_LOSS, point.NewPrice, newThreshold, point.UpdatedAt, time.Duration(0))
+				result.Pair = pair
+				result.Trace = point.Trace
+				result.Ratchets = ratchets(strategy)
+				csm.Metrics.RecordDecision(name, point.Trace)
+				if err == nil {
+					result.SetTriggered(shouldTrigger)
+				} else {
+					result.SetError(err)
+				}
+				deliver(csm, csm.execution.generalResults, *result, model.FIXED, model.STOP_LOSS, ctx)
+			}
+		}, ctx)
 	}
 }
 
-func (csm *StrategyEngine) handleFixedProfit(ctx context.Context) {
-	ticker := time.NewTicker(csm.Config.HeartbeatInterval)
-	defer ticker.Stop()
-	for {
-		select {
-		case <-ctx.Done():
-			log.Println("[handleFixedProfit] stopped")
-			return
-		case update := <-csm.execution.fixedTakeProfitChannel:
-			csm.processFixedProfitStrategies(update, ctx)
-		case <-ticker.C:
-			log.Println("[handleFixedProfit] heartbeat")
+func (csm *StrategyEngine) processDebouncedStopStrategies(update tick, ctx context.Context) {
+	for _, e := range csm.portfolio.debouncedStoplossEntries() {
+		if !e.accepts(update.pair) {
 			continue
 		}
+		name, strategy, point, pair := e.name, e.strategy, update.point, update.pair
+		csm.submit(e.key(csm.Config.ShardBy), model.DEBUNCED, model.STOP_LOSS, func(ctx context.Context) {
+			point.Trace.DequeuedAt = csm.clock.Now()
+			timeThreshold, _ := strategy.GetTimeThreshold()
+			shouldTrigger, err := strategy.ShouldTriggerStopLoss(point.NewPrice, point.UpdatedAt.UnixMilli())
+			feedTrade(name, strategy, point)
+			newThreshold, calcErr := strategy.CalculateStopLoss(point.NewPrice)
+			point.Trace.DecidedAt = csm.clock.Now()
+			if calcErr == nil {
+				result := result.NewGeneral(name, model.DEBUNCED, model.STOP_LOSS, point.NewPrice, newThreshold, point.UpdatedAt, millis(timeThreshold))
+				result.Pair = pair
+				result.Trace = point.Trace
+				result.Ratchets = ratchets(strategy)
+				csm.Metrics.RecordDecision(name, point.Trace)
+				if err == nil {
+					result.SetTriggered(shouldTrigger)
+				} else {
+					result.SetError(err)
+				}
+				deliver(csm, csm.execution.generalResults, *result, model.DEBUNCED, model.STOP_LOSS, ctx)
+			}
+		}, ctx)
 	}
 }
 
-func (csm *StrategyEngine) handleDebouncedProfit(ctx context.Context) {
-	ticker := time.NewTicker(csm.Config.HeartbeatInterval)
-	defer ticker.Stop()
-	for {
-		select {
-		case <-ctx.Done():
-			log.Println("[handleDebouncedProfit] stopped")
-			return
-		case update := <-csm.execution.DebouncedTakeProfitChannel:
-			csm.processDebouncedProfitStrategies(update, ctx)
-		case <-ticker.C:
-			log.Println("[handleDebouncedProfit] heartbeat")
+func (csm *StrategyEngine) processFixedProfitStrategies(update tick, ctx context.Context) {
+	for _, e := range csm.portfolio.fixedTakeProfitEntries() {
+		if !e.accepts(update.pair) {
 			continue
 		}
+		name, strategy, point, pair := e.name, e.strategy, update.point, update.pair
+		csm.submit(e.key(csm.Config.ShardBy), model.FIXED, model.TAKE_PROFIT, func(ctx context.Context) {
+			point.Trace.DequeuedAt = csm.clock.Now()
+			shouldTrigger, err := strategy.ShouldTriggerTakeProfit(point.NewPrice)
+			feedTrade(name, strategy, point)
+			newThreshold, calcErr := strategy.CalculateTakeProfit(point.NewPrice)
+			point.Trace.DecidedAt = csm.clock.Now()
+			if calcErr == nil {
+				result := result.NewGeneral(name, model.FIXED, model.TAKE_PROFIT, point.NewPrice, newThreshold, point.UpdatedAt, time.Duration(0))
+				result.Pair = pair
+				result.Trace = point.Trace
+				result.Ratchets = ratchets(strategy)
+				result.Exit = partialExit(strategy)
+				csm.Metrics.RecordDecision(name, point.Trace)
+				if err == nil {
+					result.SetTriggered(shouldTrigger)
+				} else {
+					result.SetError(err)
+				}
+				deliver(csm, csm.execution.generalResults, *result, model.FIXED, model.TAKE_PROFIT, ctx)
+			}
+		}, ctx)
 	}
 }
 
-func (csm *StrategyEngine) handleFixedHybrid(ctx context.Context) {
-	ticker := time.NewTicker(csm.Config.HeartbeatInterval)
-	defer ticker.Stop()
-	for {
-		select {
-		case <-ctx.Done():
-			log.Println("[handleFixedHybrid] stopped")
-			return
-		case update := <-csm.execution.hybridFixedChannel:
-			csm.processHybridFixedStrategies(update, ctx)
-		case <-ticker.C:
-			log.Println("[handleFixedHybrid] heartbeat")
+func (csm *StrategyEngine) processDebouncedProfitStrategies(update tick, ctx context.Context) {
+	for _, e := range csm.portfolio.debouncedTakeProfitEntries() {
+		if !e.accepts(update.pair) {
 			continue
 		}
+		name, strategy, point, pair := e.name, e.strategy, update.point, update.pair
+		csm.submit(e.key(csm.Config.ShardBy), model.DEBUNCED, model.TAKE_PROFIT, func(ctx context.Context) {
+			point.Trace.DequeuedAt = csm.clock.Now()
+			timeThreshold, _ := strategy.GetTimeThreshold()
+			shouldTrigger, err := strategy.ShouldTriggerTakeProfit(point.NewPrice, point.UpdatedAt.UnixMilli())
+			feedTrade(name, strategy, point)
+			newThreshold, calcErr := strategy.CalculateTakeProfit(point.NewPrice)
+			point.Trace.DecidedAt = csm.clock.Now()
+			if calcErr == nil {
+				result := result.NewGeneral(name, model.DEBUNCED, model.TAKE_PROFIT, point.NewPrice, newThreshold, point.UpdatedAt, millis(timeThreshold))
+				result.Pair = pair
+				result.Trace = point.Trace
+				result.Ratchets = ratchets(strategy)
+				result.Exit = partialExit(strategy)
+				csm.Metrics.RecordDecision(name, point.Trace)
+				if err == nil {
+					result.SetTriggered(shouldTrigger)
+				} else {
+					result.SetError(err)
+				}
+				deliver(csm, csm.execution.generalResults, *result, model.DEBUNCED, model.TAKE_PROFIT, ctx)
+			}
+		}, ctx)
 	}
 }
 
-func (csm *StrategyEngine) handleDebouncedHybrid(ctx context.Context) {
-	ticker := time.NewTicker(csm.Config.HeartbeatInterval)
-	defer ticker.Stop()
-	for {
-		select {
-		case <-ctx.Done():
-			log.Println("[handleDebouncedHybrid] stopped")
-			return
-		case update := <-csm.execution.hybridDebouncedChannel:
-			csm.processHybridDebouncedStrategies(update, ctx)
-		case <-ticker.C:
-			log.Println("[handleDebouncedHybrid] heartbeat")
+func (csm *StrategyEngine) processHybridFixedStrategies(update tick, ctx context.Context) {
+	for _, e := range csm.portfolio.hybridFixedEntries() {
+		if !e.accepts(update.pair) {
 			continue
 		}
+		name, strategy, point, pair := e.name, e.strategy, update.point, update.pair
+		csm.submit(e.key(csm.Config.ShardBy), model.HYBRID_FIXED, "", func(ctx context.Context) {
+			point.Trace.DequeuedAt = csm.clock.Now()
+			shouldTriggerSL, errSL := strategy.ShouldTriggerStopLoss(point.NewPrice)
+			shouldTriggerTP, errTP := strategy.ShouldTriggerTakeProfit(point.NewPrice)
+			feedTrade(name, strategy, point)
+			newStop, newProfit, calcErr := strategy.Calculate(point.NewPrice)
+			point.Trace.DecidedAt = csm.clock.Now()
+			if calcErr == nil {
+				result := result.NewHybrid(name, model.HYBRID_FIXED, point.NewPrice, newStop, newProfit, point.UpdatedAt, time.Duration(0))
+				result.Pair = pair
+				result.Trace = point.Trace
+				csm.Metrics.RecordDecision(name, point.Trace)
+				if errSL == nil && shouldTriggerSL {
+					result.SetTriggered(true, model.STOP_LOSS)
+				} else if errTP == nil && shouldTriggerTP {
+					result.SetTriggered(true, model.TAKE_PROFIT)
+				} else if errSL != nil {
+					result.SetError(errSL)
+				} else if errTP != nil {
+					result.SetError(errTP)
+				}
+
+				deliver(csm, csm.execution.hybridResults, *result, model.HYBRID_FIXED, "", ctx)
+			}
+		}, ctx)
 	}
 }
 
-func (csm *StrategyEngine) processFixedStopStrategies(update model.PricePoint, ctx context.Context) {
-	strategies := csm.portfolio.GetFixedStoplossStrategies()
-	for name, strategy := range strategies {
-		shouldTrigger, err := strategy.ShouldTriggerStopLoss(update.NewPrice)
-		newThreshold, calcErr := strategy.CalculateStopLoss(update.NewPrice)
-		if calcErr == nil {
-			result := result.NewGeneral(name, model.FIXED, model.STOP_LOSS, update.NewPrice, newThreshold, update.UpdatedAt, time.Duration(0))
-			if err == nil {
-				result.SetTriggered(shouldTrigger)
-			} else {
-				result.SetError(err)
-			}
-			select {
-			case csm.execution.generalResults <- *result:
-				// successfully sent
-			case <-time.After(csm.Config.ReadTimeout):
-				csm.Metrics.RecordChannelTimeout(model.FIXED, model.STOP_LOSS)
-			case <-ctx.Done():
-				return
-			}
+func (csm *StrategyEngine) processHybridDebouncedStrategies(update tick, ctx context.Context) {
+	for _, e := range csm.portfolio.hybridDebouncedEntries() {
+		if !e.accepts(update.pair) {
+			continue
 		}
+		name, strategy, point, pair := e.name, e.strategy, update.point, update.pair
+		csm.submit(e.key(csm.Config.ShardBy), model.HYBRID_DEBUNCED, "", func(ctx context.Context) {
+			point.Trace.DequeuedAt = csm.clock.Now()
+			timeThreshold, _ := strategy.GetTimeThreshold()
+			shouldTriggerSL, errSL := strategy.ShouldTriggerStopLoss(point.NewPrice, point.UpdatedAt.UnixMilli())
+			shouldTriggerTP, errTP := strategy.ShouldTriggerTakeProfit(point.NewPrice, point.UpdatedAt.UnixMilli())
+			feedTrade(name, strategy, point)
+			newStop, newProfit, calcErr := strategy.Calculate(point.NewPrice)
+			point.Trace.DecidedAt = csm.clock.Now()
+			if calcErr == nil {
+				result := result.NewHybrid(name, model.HYBRID_DEBUNCED, point.NewPrice, newStop, newProfit, point.UpdatedAt, millis(timeThreshold))
+				result.Pair = pair
+				result.Trace = point.Trace
+				csm.Metrics.RecordDecision(name, point.Trace)
+				if errSL == nil && shouldTriggerSL {
+					result.SetTriggered(true, model.STOP_LOSS)
+				} else if errTP == nil && shouldTriggerTP {
+					result.SetTriggered(true, model.TAKE_PROFIT)
+				} else if errSL != nil {
+					result.SetError(errSL)
+				} else if errTP != nil {
+					result.SetError(errTP)
+				}
+
+				deliver(csm, csm.execution.hybridResults, *result, model.HYBRID_DEBUNCED, "", ctx)
+			}
+		}, ctx)
 	}
 }
 
-func (csm *StrategyEngine) processDebouncedStopStrategies(update model.PricePoint, ctx context.Context) {
-	strategies := csm.portfolio.GetDebouncedStoplossStrategies()
-	for name, strategy := range strategies {
-		timeThreshold, _ := strategy.GetTimeThreshold()
-		shouldTrigger, err := strategy.ShouldTriggerStopLoss(update.NewPrice, update.UpdatedAt.UnixMilli())
-		newThreshold, calcErr := strategy.CalculateStopLoss(update.NewPrice)
-		if calcErr == nil {
-			result := result.NewGeneral(name, model.DEBUNCED, model.STOP_LOSS, update.NewPrice, newThreshold, update.UpdatedAt, time.Duration(timeThreshold))
+// processGuards marks every price against the account guards and reports the account PnL as the threshold
+func (csm *StrategyEngine) processGuards(update tick, ctx context.Context) {
+	for _, e := range csm.portfolio.guardEntries() {
+		if !e.accepts(update.pair) {
+			continue
+		}
+		name, guard, point, pair := e.name, e.strategy, update.point, update.pair
+		csm.submit(e.key(csm.Config.ShardBy), model.GUARD, model.CIRCUIT_BREAKER, func(ctx context.Context) {
+			point.Trace.DequeuedAt = csm.clock.Now()
+			err := guard.Mark(pair, point.NewPrice, point.UpdatedAt.UnixMilli())
+			halt := false
 			if err == nil {
-				result.SetTriggered(shouldTrigger)
-			} else {
-				result.SetError(err)
+				halt, err = guard.ShouldHalt(point.UpdatedAt.UnixMilli())
 			}
-			select {
-			case csm.execution.generalResults <- *result:
-				// successfully sent
-			case <-time.After(csm.Config.ReadTimeout):
-				csm.Metrics.RecordChannelTimeout(model.DEBUNCED, model.STOP_LOSS)
-			case <-ctx.Done():
-				return
+			pnl, pnlErr := guard.GetPnL()
+			point.Trace.DecidedAt = csm.clock.Now()
+			if pnlErr == nil {
+				result := result.NewGeneral(name, model.GUARD, model.CIRCUIT_BREAKER, point.NewPrice, pnl, point.UpdatedAt, time.Duration(0))
+				result.Pair = pair
+				result.Trace = point.Trace
+				csm.Metrics.RecordDecision(name, point.Trace)
+				if err == nil {
+					result.SetTriggered(halt)
+				} else {
+					result.SetError(err)
+				}
+				deliver(csm, csm.execution.generalResults, *result, model.GUARD, model.CIRCUIT_BREAKER, ctx)
 			}
-		}
+		}, ctx)
 	}
 }
 
-func (csm *StrategyEngine) processFixedProfitStrategies(update model.PricePoint, ctx context.Context) {
-	strategies := csm.portfolio.GetFixedTakeProfitStrategies()
-	for name, strategy := range strategies {
-		shouldTrigger, err := strategy.ShouldTriggerTakeProfit(update.NewPrice)
-		newThreshold, calcErr := strategy.CalculateTakeProfit(update.NewPrice)
-		if calcErr == nil {
-			result := result.NewGeneral(name, model.FIXED, model.TAKE_PROFIT, update.NewPrice, newThreshold, update.UpdatedAt, time.Duration(0))
-			if err == nil {
-				result.SetTriggered(shouldTrigger)
-			} else {
-				result.SetError(err)
-			}
-			select {
-			case csm.execution.generalResults <- *result:
-				// successfully sent
-			case <-time.After(csm.Config.ReadTimeout):
-				csm.Metrics.RecordChannelTimeout(model.FIXED, model.TAKE_PROFIT)
-			case <-ctx.Done():
-				return
-			}
+// EntriesBlocked reports whether a registered account guard halted and blocks new entries
+func (csm *StrategyEngine) EntriesBlocked() bool {
+	for _, e := range csm.portfolio.guardEntries() {
+		if e.strategy.Blocked() {
+			return true
 		}
 	}
+	return false
 }
 
-func (csm *StrategyEngine) processDebouncedProfitStrategies(update model.PricePoint, ctx context.Context) {
-	strategies := csm.portfolio.GetDebouncedTakeProfitStrategies()
-	for name, strategy := range strategies {
-		timeThreshold, _ := strategy.GetTimeThreshold()
-		shouldTrigger, err := strategy.ShouldTriggerTakeProfit(update.NewPrice, update.UpdatedAt.UnixMilli())
-		newThreshold, calcErr := strategy.CalculateTakeProfit(update.NewPrice)
-		if calcErr == nil {
-			result := result.NewGeneral(name, model.DEBUNCED, model.TAKE_PROFIT, update.NewPrice, newThreshold, update.UpdatedAt, time.Duration(timeThreshold))
-			if err == nil {
-				result.SetTriggered(shouldTrigger)
-			} else {
-				result.SetError(err)
-			}
-			select {
-			case csm.execution.generalResults <- *result:
-				// successfully sent
-			case <-time.After(csm.Config.ReadTimeout):
-				csm.Metrics.RecordChannelTimeout(model.DEBUNCED, model.TAKE_PROFIT)
-			case <-ctx.Done():
-				return
-			}
+func (csm *StrategyEngine) Collect(pricePoint model.PricePoint, callback func()) {
+	pricePoint.Trace.EnqueuedAt = csm.clock.Now()
+	csm.collect(tick{point: pricePoint}, callback)
+}
+
+// CollectPair feeds a price update for pair, reaching unbound strategies and the ones registered for pair.
+// A point with Size set is also a trade for strategies taking trades, the stream providers leave it zero.
+func (csm *StrategyEngine) CollectPair(pair model.QuotesPair, pricePoint model.PricePoint, callback func()) {
+	key := PairKey(pair)
+	csm.Metrics.RecordPair(key)
+	pricePoint.Trace.EnqueuedAt = csm.clock.Now()
+	csm.collect(tick{pair: key, point: pricePoint}, callback)
+}
+
+// CollectBar feeds a closed bar to every strategy implementing stoploss.BarUpdater, queued behind the ticks collected before it.
+// Before Start the bar is applied at once, so strategies can be warmed up from history.
+func (csm *StrategyEngine) CollectBar(bar model.PriceInterval) {
+	csm.collectBar("", bar)
+}
+
+// CollectPairBar feeds a closed bar of pair to unbound bar-fed strategies and the ones registered for pair
+func (csm *StrategyEngine) CollectPairBar(pair model.QuotesPair, bar model.PriceInterval) {
+	csm.collectBar(PairKey(pair), bar)
+}
+
+func (csm *StrategyEngine) collectBar(pair string, bar model.PriceInterval) {
+	csm.collectUpdate(pair, "update bar", func(strategy any) func() error {
+		if u, ok := strategy.(stoploss.BarUpdater); ok {
+			return func() error { return u.UpdateBar(bar) }
 		}
-	}
+		return nil
+	})
 }
 
-func (csm *StrategyEngine) processHybridFixedStrategies(update model.PricePoint, ctx context.Context) {
-	strategies := csm.portfolio.GetHybridStrategies()
-	for name, strategy := range strategies {
-		shouldTriggerSL, errSL := strategy.ShouldTriggerStopLoss(update.NewPrice)
-		shouldTriggerTP, errTP := strategy.ShouldTriggerTakeProfit(update.NewPrice)
-		newStop, newProfit, calcErr := strategy.Calculate(update.NewPrice)
-		if calcErr == nil {
-			result := result.NewHybrid(name, model.HYBRID_FIXED, update.NewPrice, newStop, newProfit, update.UpdatedAt, time.Duration(0))
-			if errSL == nil && shouldTriggerSL {
-				result.SetTriggered(true, model.STOP_LOSS)
-			} else if errTP == nil && shouldTriggerTP {
-				result.SetTriggered(true, model.TAKE_PROFIT)
-			} else if errSL != nil {
-				result.SetError(errSL)
-			} else if errTP != nil {
-				result.SetError(errTP)
-			}
+// CollectOrderBook feeds an order book snapshot to every strategy implementing stoploss.OrderBookUpdater,
+// queued behind the ticks collected before it. Before Start the snapshot is applied at once.
+func (csm *StrategyEngine) CollectOrderBook(book model.OrderBook) {
+	csm.collectOrderBook("", book)
+}
 
-			select {
-			case csm.execution.hybridResults <- *result:
-				// successfully sent
-			case <-time.After(csm.Config.ReadTimeout):
-				csm.Metrics.RecordChannelTimeout(model.HYBRID_FIXED, "")
-			case <-ctx.Done():
-				return
-			}
+// CollectPairOrderBook feeds an order book snapshot of pair to unbound book-fed strategies and the ones registered for pair
+func (csm *StrategyEngine) CollectPairOrderBook(pair model.QuotesPair, book model.OrderBook) {
+	csm.collectOrderBook(PairKey(pair), book)
+}
+
+func (csm *StrategyEngine) collectOrderBook(pair string, book model.OrderBook) {
+	csm.collectUpdate(pair, "update order book", func(strategy any) func() error {
+		if u, ok := strategy.(stoploss.OrderBookUpdater); ok {
+			return func() error { return u.UpdateOrderBook(book) }
+		}
+		return nil
+	})
+}
+
+// CollectFill feeds an executed fill to every strategy implementing stoploss.FillUpdater, such as account guards,
+// queued behind the ticks collected before it. Before Start the fill is applied at once.
+func (csm *StrategyEngine) CollectFill(fill model.Fill) {
+	csm.collectFill("", fill)
+}
+
+// CollectPairFill feeds a fill of pair, guards book it against the position in pair
+func (csm *StrategyEngine) CollectPairFill(pair model.QuotesPair, fill model.Fill) {
+	csm.collectFill(PairKey(pair), fill)
+}
+
+func (csm *StrategyEngine) collectFill(pair string, fill model.Fill) {
+	csm.collectUpdate(pair, "update fill", func(strategy any) func() error {
+		if u, ok := strategy.(stoploss.FillUpdater); ok {
+			return func() error { return u.UpdateFill(pair, fill) }
 		}
+		return nil
+	})
+}
+
+// updater returns the update of a strategy, nil when the strategy does not consume it
+type updater func(strategy any) func() error
+
+func (csm *StrategyEngine) collectUpdate(pair, what string, update updater) {
+	csm.mu.RLock()
+	defer csm.mu.RUnlock()
+	if state := csm.State(); state == StateDraining || state == StateStopped {
+		return
 	}
+	e := csm.execution
+	route(csm, e.fixedStoplossChannel, csm.portfolio.fixedStoplossEntries(), pair, what, update)
+	route(csm, e.DebouncedStoplossChannel, csm.portfolio.debouncedStoplossEntries(), pair, what, update)
+	route(csm, e.fixedTakeProfitChannel, csm.portfolio.fixedTakeProfitEntries(), pair, what, update)
+	route(csm, e.DebouncedTakeProfitChannel, csm.portfolio.debouncedTakeProfitEntries(), pair, what, update)
+	route(csm, e.hybridFixedChannel, csm.portfolio.hybridFixedEntries(), pair, what, update)
+	route(csm, e.hybridDebouncedChannel, csm.portfolio.hybridDebouncedEntries(), pair, what, update)
+	route(csm, e.guardChannel, csm.portfolio.guardEntries(), pair, what, update)
 }
 
-func (csm *StrategyEngine) processHybridDebouncedStrategies(update model.PricePoint, ctx context.Context) {
-	strategies := csm.portfolio.GetHybridStrategies()
-	for name, strategy := range strategies {
-		shouldTriggerSL, errSL := strategy.ShouldTriggerStopLoss(update.NewPrice)
-		shouldTriggerTP, errTP := strategy.ShouldTriggerTakeProfit(update.NewPrice)
-		newStop, newProfit, calcErr := strategy.Calculate(update.NewPrice)
-		if calcErr == nil {
-			result := result.NewHybrid(name, model.HYBRID_DEBUNCED, update.NewPrice, newStop, newProfit, update.UpdatedAt, time.Duration(0))
-			if errSL == nil && shouldTriggerSL {
-				result.SetTriggered(true, model.STOP_LOSS)
-			} else if errTP == nil && shouldTriggerTP {
-				result.SetTriggered(true, model.TAKE_PROFIT)
-			} else if errSL != nil {
-				result.SetError(errSL)
-			} else if errTP != nil {
-				result.SetError(errTP)
-			}
+// route queues the update on the inbox of entries behind the ticks collected before it, so the shards apply it
+// in order with them. Before Start it is applied at once.
+func route[T any](csm *StrategyEngine, in *inbox, entries []entry[T], pair, what string, update updater) {
+	consumed := false
+	for _, e := range entries {
+		consumed = consumed || (e.accepts(pair) && update(e.strategy) != nil)
+	}
+	if !consumed {
+		return
+	}
+	if csm.State() == StateCreated {
+		feed(csm, entries, pair, what, update, in.typ, in.category)
+		return
+	}
+	apply := func(context.Context) { feed(csm, entries, pair, what, update, in.typ, in.category) }
+	if !in.pushUpdate(tick{pair: pair, apply: apply}, csm.Config.ReadTimeout, csm.Metrics) {
+		log.Printf("[StrategyEngine] %s: %s timed out", in.name, what)
+	}
+}
 
-			select {
-			case csm.execution.hybridResults <- *result:
-				// successfully sent
-			case <-time.After(csm.Config.ReadTimeout):
-				csm.Metrics.RecordChannelTimeout(model.HYBRID_DEBUNCED, "")
-			case <-ctx.Done():
-				return
+// feed queues the update of the entries consuming it and accepting pair, a failed update is logged
+func feed[T any](csm *StrategyEngine, entries []entry[T], pair, what string, update updater, typ model.StrategyType, category model.StrategyCategory) {
+	for _, e := range entries {
+		if !e.accepts(pair) {
+			continue
+		}
+		do := update(e.strategy)
+		if do == nil {
+			continue
+		}
+		name := e.name
+		apply := func(context.Context) {
+			if err := do(); err != nil {
+				log.Printf("[StrategyEngine] %s: %s: %v", name, what, err)
 			}
 		}
+		if csm.State() == StateCreated {
+			apply(context.Background())
+			continue
+		}
+		// submit gives up after ReadTimeout, so it needs no cancellation of its own
+		csm.submit(e.key(csm.Config.ShardBy), typ, category, apply, context.Background())
 	}
 }
 
-func (csm *StrategyEngine) Collect(pricePoint model.PricePoint, callback func()) {
+func (csm *StrategyEngine) collect(update tick, callback func()) {
+	csm.mu.RLock()
+	defer csm.mu.RUnlock()
 	csm.Metrics.RecordReceived()
+	if state := csm.State(); state == StateDraining || state == StateStopped {
+		csm.Metrics.RecordDropped()
+		if callback != nil {
+			callback()
+		}
+		return
+	}
 
 	if len(csm.portfolio.fixedStoplossStrategies) > 0 {
-		dataFeedWithMetrics(pricePoint, csm.execution.fixedStoplossChannel, model.FIXED, model.STOP_LOSS, csm.Metrics, callback)
+		dataFeedWithMetrics(update, csm.execution.fixedStoplossChannel, csm.Metrics, callback)
 	}
 	if len(csm.portfolio.DebouncedStoplossStrategies) > 0 {
-		dataFeedWithMetrics(pricePoint, csm.execution.DebouncedStoplossChannel, model.DEBUNCED, model.STOP_LOSS, csm.Metrics, callback)
+		dataFeedWithMetrics(update, csm.execution.DebouncedStoplossChannel, csm.Metrics, callback)
 	}
 	if len(csm.portfolio.fixedTakeProfitStrategies) > 0 {
-		dataFeedWithMetrics(pricePoint, csm.execution.fixedTakeProfitChannel, model.FIXED, model.TAKE_PROFIT, csm.Metrics, callback)
+		dataFeedWithMetrics(update, csm.execution.fixedTakeProfitChannel, csm.Metrics, callback)
 	}
 	if len(csm.portfolio.DebouncedTakeProfitStrategies) > 0 {
-		dataFeedWithMetrics(pricePoint, csm.execution.DebouncedTakeProfitChannel, model.DEBUNCED, model.TAKE_PROFIT, csm.Metrics, callback)
+		dataFeedWithMetrics(update, csm.execution.DebouncedTakeProfitChannel, csm.Metrics, callback)
 	}
 	if len(csm.portfolio.hybridFixedStrategies) > 0 {
-		dataFeedWithMetrics(pricePoint, csm.execution.hybridFixedChannel, model.HYBRID_FIXED, "", csm.Metrics, callback)
+		dataFeedWithMetrics(update, csm.execution.hybridFixedChannel, csm.Metrics, callback)
 	}
 	if len(csm.portfolio.hybridDebouncedStrategies) > 0 {
-		dataFeedWithMetrics(pricePoint, csm.execution.hybridDebouncedChannel, model.HYBRID_DEBUNCED, "", csm.Metrics, callback)
+		dataFeedWithMetrics(update, csm.execution.hybridDebouncedChannel, csm.Metrics, callback)
+	}
+	if len(csm.portfolio.guards) > 0 {
+		dataFeedWithMetrics(update, csm.execution.guardChannel, csm.Metrics, callback)
 	}
 }
 
+// Stop rejects new ticks, waits for in-flight ones to be evaluated, then stops every goroutine and closes the channels
 func (csm *StrategyEngine) Stop() {
+	csm.mu.Lock()
+	state := csm.State()
+	if state == StateDraining || state == StateStopped {
+		csm.mu.Unlock()
+		return
+	}
+	csm.state.Store(int32(StateDraining))
+	csm.mu.Unlock()
+
+	if state != StateCreated {
+		csm.drain()
+	}
 	csm.engine.Stop()
 	csm.execution.closeChannels()
+	if err := csm.Reporter.Close(); err != nil {
+		log.Printf("[StrategyEngine] close sink: %v", err)
+	}
+	csm.state.Store(int32(StateStopped))
+	log.Println(csm.Snapshot())
 }
 
-func dataFeedWithMetrics(pricePoint model.PricePoint, channel chan model.PricePoint, typ model.StrategyType, category model.StrategyCategory, metrics *Metrics, callback func()) {
-	select {
-	case channel <- pricePoint:
-		metrics.RecordChannelSend(typ, category)
-	default:
-		// Channel is full
-		metrics.RecordChannelDrop(typ, category)
-		metrics.RecordDropped()
-		callback()
+func dataFeedWithMetrics(update tick, in *inbox, metrics *Metrics, callback func()) {
+	in.push(update, metrics, callback)
+}
+
+// feedTrade passes trades to strategies consuming them, after the trade price was evaluated
+func feedTrade(name string, strategy interface{}, point model.PricePoint) {
+	updater, ok := strategy.(stoploss.TradeUpdater)
+	if !ok || !point.Size.IsPositive() {
+		return
+	}
+	if err := updater.UpdateTrade(point.NewPrice, point.Size, point.UpdatedAt.UnixMilli()); err != nil {
+		log.Printf("[StrategyEngine] %s: update trade: %v", name, err)
+	}
+}
+
+// ratchets drains the stop moves of strategies reporting them
+func ratchets(strategy interface{}) []result.Ratchet {
+	if r, ok := strategy.(stoploss.Ratcheter); ok {
+		return r.Ratchets()
+	}
+	return nil
+}
+
+// partialExit drains the part of the position laddered strategies close
+func partialExit(strategy interface{}) *result.PartialExit {
+	if p, ok := strategy.(stoploss.PartialExiter); ok {
+		return p.PartialExit()
 	}
+	return nil
+}
+
+// millis converts a debounce threshold to a duration, thresholds share the millisecond unit of the timestamps strategies are fed
+func millis(threshold int64) time.Duration {
+	return time.Duration(threshold) * time.Millisecond
 }
@@ -0,0 +1,102 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package enginetest
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/wang900115/quant/stoploss/engine/sink"
+)
+
+// Capture is a sink recording every event in arrival order, it can be paused to stall the reporter
+type Capture struct {
+	mu     sync.Mutex
+	events []sink.Event
+	gate   chan struct{}
+	closed bool
+	// blocked counts Emit calls held back by Pause
+	blocked atomic.Int64
+}
+
+// NewCapture creates an empty running Capture
+func NewCapture() *Capture {
+	return &Capture{}
+}
+
+func (c *Capture) Emit(e sink.Event) error {
+	c.mu.Lock()
+	gate := c.gate
+	c.mu.Unlock()
+	if gate != nil {
+		c.blocked.Add(1)
+		<-gate
+		c.blocked.Add(-1)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return sink.ErrClosed
+	}
+	c.events = append(c.events, e)
+	return nil
+}
+
+// Pause holds back every following Emit until Resume
+func (c *Capture) Pause() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.gate == nil {
+		c.gate = make(chan struct{})
+	}
+}
+
+// Resume releases the Emit calls held back by Pause
+func (c *Capture) Resume() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.gate != nil {
+		close(c.gate)
+		c.gate = nil
+	}
+}
+
+// Blocked returns how many Emit calls are waiting for Resume
+func (c *Capture) Blocked() int {
+	return int(c.blocked.Load())
+}
+
+// Events returns a copy of the recorded events
+func (c *Capture) Events() []sink.Event {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make([]sink.Event, len(c.events))
+	copy(out, c.events)
+	return out
+}
+
+// Reset forgets the recorded events
+func (c *Capture) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.events = nil
+}
+
+func (c *Capture) Close() error {
+	c.Resume()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	return nil
+}
@@ -0,0 +1,290 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+// Package enginetest runs a StrategyEngine on a fake clock with a capturing sink,
+// so strategies can be tested against the engine semantics with scripted prices.
+package enginetest
+
+import (
+	"fmt"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/wang900115/quant/common/clock"
+	"github.com/wang900115/quant/model"
+	"github.com/wang900115/quant/stoploss/engine"
+	"github.com/wang900115/quant/stoploss/engine/sink"
+)
+
+// Epoch is the time the fake clock of every harness starts at
+var Epoch = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// WaitTimeout bounds how long the harness waits in real time for the engine to catch up
+var WaitTimeout = 5 * time.Second
+
+// Step is one scripted price, fed After the clock advanced by the given duration
+type Step struct {
+	After time.Duration
+	Price decimal.Decimal
+	// Size makes the step a trade of that quantity
+	Size decimal.Decimal
+	// Pair routes the price through CollectPair, nil uses Collect
+	Pair *model.QuotesPair
+}
+
+// Prices scripts one step per price, each every apart
+func Prices(every time.Duration, prices ...float64) []Step {
+	steps := make([]Step, len(prices))
+	for i, p := range prices {
+		steps[i] = Step{After: every, Price: decimal.NewFromFloat(p)}
+	}
+	return steps
+}
+
+// Trades scripts one trade per price and size pair, each every apart
+func Trades(every time.Duration, trades ...[2]float64) []Step {
+	steps := make([]Step, len(trades))
+	for i, t := range trades {
+		steps[i] = Step{After: every, Price: decimal.NewFromFloat(t[0]), Size: decimal.NewFromFloat(t[1])}
+	}
+	return steps
+}
+
+// PairPrices scripts one step per price for pair, each every apart
+func PairPrices(pair model.QuotesPair, every time.Duration, prices ...float64) []Step {
+	steps := Prices(every, prices...)
+	for i := range steps {
+		steps[i].Pair = &pair
+	}
+	return steps
+}
+
+// Harness owns an engine wired to a fake clock and a capturing sink
+type Harness struct {
+	Engine *engine.StrategyEngine
+	Clock  *clock.Fake
+	Sink   *Capture
+
+	t testing.TB
+	// rejected counts the Collect callbacks, one per tick an input channel refused
+	rejected atomic.Int64
+}
+
+// Config returns the engine defaults with a single shard, so results of every strategy arrive in feed order
+func Config() engine.Config {
+	cfg := engine.DefaultConfig()
+	cfg.Shards = 1
+	cfg.BufferSize = 64
+	cfg.ShardQueueSize = 64
+	return cfg
+}
+
+// New builds an engine from cfg on a fake clock starting at Epoch, capturing its events.
+// The clock and sink of cfg are replaced, the engine is stopped when the test ends.
+func New(t testing.TB, cfg engine.Config) *Harness {
+	t.Helper()
+	h := &Harness{t: t, Clock: clock.NewFake(Epoch), Sink: NewCapture()}
+	cfg.Clock = h.Clock
+	cfg.Sink = h.Sink
+	h.Engine = engine.New(cfg)
+	t.Cleanup(func() {
+		h.Sink.Resume()
+		h.Engine.Stop()
+	})
+	return h
+}
+
+// Register registers a strategy for every pair, failing the test on error
+func (h *Harness) Register(name string, strategy interface{}) {
+	h.t.Helper()
+	if err := h.Engine.RegisterStrategy(name, strategy); err != nil {
+		h.t.Fatalf("enginetest: register %s: %v", name, err)
+	}
+}
+
+// RegisterPair registers a strategy fed only by pair and unbound prices, failing the test on error
+func (h *Harness) RegisterPair(pair model.QuotesPair, name string, strategy interface{}) {
+	h.t.Helper()
+	if err := h.Engine.RegisterPairStrategy(pair, name, strategy); err != nil {
+		h.t.Fatalf("enginetest: register %s: %v", name, err)
+	}
+}
+
+// Start starts the engine, failing the test on error
+func (h *Harness) Start() {
+	h.t.Helper()
+	if err := h.Engine.Start(); err != nil {
+		h.t.Fatalf("enginetest: start: %v", err)
+	}
+}
+
+// Feed pushes every step without waiting for the engine, so bursts exercise the back-pressure policies
+func (h *Harness) Feed(steps ...Step) {
+	for _, s := range steps {
+		h.collect(s)
+	}
+}
+
+// Play pushes the steps one at a time and settles after each, so no tick is dropped or conflated
+func (h *Harness) Play(steps ...Step) {
+	h.t.Helper()
+	for _, s := range steps {
+		h.collect(s)
+		h.Settle()
+	}
+}
+
+// Bars feeds closed bars through CollectBar, strategies see them after the ticks fed before and before the ones fed after.
+// It does not settle, so a tick the engine dropped or timed out before the bar is not evaluated at all.
+func (h *Harness) Bars(bars ...model.PriceInterval) {
+	for _, b := range bars {
+		h.Engine.CollectBar(b)
+	}
+}
+
+// Books feeds order book snapshots through CollectOrderBook, ordered against fed ticks like Bars
+func (h *Harness) Books(books ...model.OrderBook) {
+	for _, b := range books {
+		h.Engine.CollectOrderBook(b)
+	}
+}
+
+// Fills feeds executed fills through CollectFill, ordered against fed ticks like Bars
+func (h *Harness) Fills(fills ...model.Fill) {
+	for _, f := range fills {
+		h.Engine.CollectFill(f)
+	}
+}
+
+func (h *Harness) collect(s Step) {
+	h.Clock.Advance(s.After)
+	point := model.PricePoint{NewPrice: s.Price, UpdatedAt: h.Clock.Now(), Size: s.Size}
+	reject := func() { h.rejected.Add(1) }
+	if s.Pair != nil {
+		h.Engine.CollectPair(*s.Pair, point, reject)
+		return
+	}
+	h.Engine.Collect(point, reject)
+}
+
+// Settle waits until every fed tick was evaluated and reported
+func (h *Harness) Settle() {
+	h.t.Helper()
+	h.Eventually("engine to settle", h.Engine.Idle)
+}
+
+// Eventually polls cond in real time until it holds, failing the test after WaitTimeout
+func (h *Harness) Eventually(what string, cond func() bool) {
+	h.t.Helper()
+	deadline := time.Now().Add(WaitTimeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			h.t.Fatalf("enginetest: timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// Expire waits until n sends are stalled on their ReadTimeout, then advances the clock past it
+func (h *Harness) Expire(n int) {
+	h.t.Helper()
+	if !h.Clock.BlockUntilTimers(n, WaitTimeout) {
+		h.t.Fatalf("enginetest: expected %d stalled sends, got %d", n, h.Clock.Timers())
+	}
+	h.Clock.Advance(h.Engine.Config.ReadTimeout)
+}
+
+// Events returns the captured events of strategy in arrival order, every event when strategy is empty
+func (h *Harness) Events(strategy string) []sink.Event {
+	events := h.Sink.Events()
+	if strategy == "" {
+		return events
+	}
+	return slices.DeleteFunc(events, func(e sink.Event) bool { return e.Strategy != strategy })
+}
+
+// ExpectSequence fails the test unless the events of strategy have exactly the given types in order
+func (h *Harness) ExpectSequence(strategy string, types ...sink.EventType) {
+	h.t.Helper()
+	events := h.Events(strategy)
+	got := make([]sink.EventType, len(events))
+	for i, e := range events {
+		got[i] = e.Type
+	}
+	if !slices.Equal(got, types) {
+		h.t.Fatalf("enginetest: %s: expected events %v, got %v", strategy, types, got)
+	}
+}
+
+// ExpectTrigger fails the test unless strategy triggered, and returns its first trigger
+func (h *Harness) ExpectTrigger(strategy string) sink.Event {
+	h.t.Helper()
+	for _, e := range h.Events(strategy) {
+		if e.Type == sink.EventTrigger {
+			return e
+		}
+	}
+	h.t.Fatalf("enginetest: %s: expected a trigger, got %s", strategy, describe(h.Events(strategy)))
+	return sink.Event{}
+}
+
+// ExpectNoTrigger fails the test if strategy triggered
+func (h *Harness) ExpectNoTrigger(strategy string) {
+	h.t.Helper()
+	for _, e := range h.Events(strategy) {
+		if e.Type == sink.EventTrigger {
+			h.t.Fatalf("enginetest: %s: unexpected trigger at %v", strategy, e.Time)
+		}
+	}
+}
+
+// Received returns how many ticks were collected
+func (h *Harness) Received() int64 {
+	return h.Engine.Metrics.TotalReceived.Snapshot().Count()
+}
+
+// Dropped returns how many ticks were dropped, by a full input channel or a stopped engine
+func (h *Harness) Dropped() int64 {
+	return h.Engine.Metrics.TotalDropped.Snapshot().Count()
+}
+
+// Rejected returns how many times Collect reported a dropped tick through its callback
+func (h *Harness) Rejected() int64 {
+	return h.rejected.Load()
+}
+
+// Timeouts returns how many jobs or results were given up after ReadTimeout
+func (h *Harness) Timeouts() int64 {
+	return h.Engine.Metrics.Timeout.Sum()
+}
+
+// Evicted returns how many queued ticks DropOldest discarded
+func (h *Harness) Evicted() int64 {
+	return h.Engine.Metrics.Evicted.Sum()
+}
+
+// Conflated returns how many ticks ConflateLatest folded into a newer one
+func (h *Harness) Conflated() int64 {
+	return h.Engine.Metrics.Conflated.Sum()
+}
+
+func describe(events []sink.Event) string {
+	types := make([]sink.EventType, len(events))
+	for i, e := range events {
+		types[i] = e.Type
+	}
+	return fmt.Sprint(types)
+}
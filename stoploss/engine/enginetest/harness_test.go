@@ -0,0 +1,483 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package enginetest_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/wang900115/quant/common/sys"
+	"github.com/wang900115/quant/model"
+	"github.com/wang900115/quant/model/currency"
+	"github.com/wang900115/quant/model/trade"
+	"github.com/wang900115/quant/stoploss/engine"
+	"github.com/wang900115/quant/stoploss/engine/enginetest"
+	"github.com/wang900115/quant/stoploss/engine/sink"
+	"github.com/wang900115/quant/stoploss/strategy"
+)
+
+// floorStop is the kind of custom stop loss a downstream team tests, it triggers at or below a fixed floor
+type floorStop struct {
+	floor decimal.Decimal
+}
+
+func (f *floorStop) CalculateStopLoss(decimal.Decimal) (decimal.Decimal, error) { return f.floor, nil }
+func (f *floorStop) ShouldTriggerStopLoss(price decimal.Decimal) (bool, error) {
+	return price.LessThanOrEqual(f.floor), nil
+}
+func (f *floorStop) Trigger(string) error                  { return nil }
+func (f *floorStop) GetStopLoss() (decimal.Decimal, error) { return f.floor, nil }
+func (f *floorStop) ReSetStopLosser(decimal.Decimal) error { return nil }
+func (f *floorStop) Deactivate() error                     { return nil }
+
+func TestHarness_KeepsFeedOrderPerStrategy(t *testing.T) {
+	cfg := enginetest.Config()
+	cfg.Shards = 4
+	h := enginetest.New(t, cfg)
+	for _, name := range []string{"a", "b", "c"} {
+		h.Register(name, &floorStop{floor: decimal.NewFromInt(90)})
+	}
+	h.Start()
+
+	prices := []float64{100, 99, 98, 97, 96, 95, 94, 93, 92, 91}
+	h.Feed(enginetest.Prices(time.Second, prices...)...)
+	h.Settle()
+
+	for _, name := range []string{"a", "b", "c"} {
+		events := h.Events(name)
+		if len(events) != len(prices) {
+			t.Fatalf("%s: expected %d events, got %d", name, len(prices), len(events))
+		}
+		for i, e := range events {
+			if want := enginetest.Epoch.Add(time.Duration(i+1) * time.Second); !e.Time.Equal(want) {
+				t.Fatalf("%s: event %d stamped %v, want %v", name, i, e.Time, want)
+			}
+			if !e.General.LastPrice.Equal(decimal.NewFromFloat(prices[i])) {
+				t.Fatalf("%s: event %d priced %v, want %v", name, i, e.General.LastPrice, prices[i])
+			}
+		}
+		h.ExpectNoTrigger(name)
+	}
+	if h.Received() != int64(len(prices)) || h.Dropped() != 0 {
+		t.Fatalf("expected %d received and none dropped, got %d and %d", len(prices), h.Received(), h.Dropped())
+	}
+}
+
+func TestHarness_DebounceFollowsFakeClock(t *testing.T) {
+	h := enginetest.New(t, enginetest.Config())
+	slow, err := strategy.NewDebouncedPercentStop(decimal.NewFromInt(100), decimal.NewFromFloat(0.05), 3000, nil)
+	if err != nil {
+		t.Fatalf("create strategy: %v", err)
+	}
+	fast, err := strategy.NewDebouncedPercentStop(decimal.NewFromInt(100), decimal.NewFromFloat(0.05), 1000, nil)
+	if err != nil {
+		t.Fatalf("create strategy: %v", err)
+	}
+	h.Register("slow", slow)
+	h.Register("fast", fast)
+	h.Start()
+
+	// each price breaks the stop of the previous one, one second apart on the fake clock
+	h.Play(enginetest.Prices(time.Second, 94, 89)...)
+
+	h.ExpectSequence("fast", sink.EventUpdate, sink.EventTrigger)
+	h.ExpectSequence("slow", sink.EventUpdate, sink.EventUpdate)
+	if got := h.ExpectTrigger("fast").General.TimeThreshold; got != time.Second {
+		t.Fatalf("expected a 1s debounce window on the result, got %v", got)
+	}
+}
+
+func TestHarness_CountsDropsOfAFullInbox(t *testing.T) {
+	cfg := enginetest.Config()
+	cfg.BufferSize = 1
+	cfg.ShardQueueSize = 1
+	h := enginetest.New(t, cfg)
+	h.Register("floor", &floorStop{floor: decimal.NewFromInt(90)})
+	h.Start()
+
+	// a stalled sink backs the pipeline up to the input channel, no time passes so nothing times out
+	h.Sink.Pause()
+	const ticks = 20
+	h.Feed(enginetest.Prices(0, make([]float64, ticks)...)...)
+	h.Sink.Resume()
+	h.Settle()
+
+	if h.Dropped() == 0 {
+		t.Fatal("expected a burst into a stalled engine to drop ticks")
+	}
+	if h.Rejected() != h.Dropped() {
+		t.Fatalf("expected every drop to reach the Collect callback, got %d callbacks for %d drops", h.Rejected(), h.Dropped())
+	}
+	if got := int64(len(h.Events("floor"))) + h.Dropped(); got != ticks {
+		t.Fatalf("expected reported plus dropped to equal %d ticks, got %d", ticks, got)
+	}
+	if h.Timeouts() != 0 {
+		t.Fatalf("expected no timeouts without the clock moving, got %d", h.Timeouts())
+	}
+}
+
+func TestHarness_TimesOutStalledResults(t *testing.T) {
+	h := enginetest.New(t, enginetest.Config())
+	h.Register("floor", &floorStop{floor: decimal.NewFromInt(90)})
+	h.Start()
+
+	h.Sink.Pause()
+	h.Feed(enginetest.Prices(time.Second, 100)...)
+	h.Eventually("reporter to stall", func() bool { return h.Sink.Blocked() == 1 })
+	h.Feed(enginetest.Prices(time.Second, 89)...)
+	h.Expire(1)
+	h.Eventually("result to time out", func() bool { return h.Timeouts() == 1 })
+	h.Sink.Resume()
+	h.Settle()
+
+	// the trigger was lost to the timeout, only the first update reached the sink
+	h.ExpectSequence("floor", sink.EventUpdate)
+}
+
+func TestHarness_DrainTimeoutFollowsFakeClock(t *testing.T) {
+	cfg := enginetest.Config()
+	cfg.DrainTimeout = 10 * time.Millisecond
+	h := enginetest.New(t, cfg)
+	h.Register("floor", &floorStop{floor: decimal.NewFromInt(90)})
+	h.Start()
+
+	h.Sink.Pause()
+	h.Feed(enginetest.Prices(time.Second, 100)...)
+	h.Eventually("reporter to stall", func() bool { return h.Sink.Blocked() == 1 })
+	stopped := make(chan struct{})
+	go func() {
+		h.Engine.Stop()
+		close(stopped)
+	}()
+	monitorStopped := func() bool {
+		for _, task := range h.Engine.Health().Tasks {
+			if task.Name == "monitor" {
+				return task.State == sys.TaskStopped
+			}
+		}
+		return false
+	}
+
+	// the drain outlasts DrainTimeout of real time, only the clock ends it
+	if !h.Clock.BlockUntilTimers(1, enginetest.WaitTimeout) {
+		t.Fatal("Stop never started its drain deadline")
+	}
+	time.Sleep(50 * time.Millisecond)
+	if monitorStopped() {
+		t.Fatal("expected Stop to keep draining until the clock passes DrainTimeout")
+	}
+	h.Clock.Advance(cfg.DrainTimeout)
+	h.Eventually("drain to give up", monitorStopped)
+	h.Sink.Resume()
+	select {
+	case <-stopped:
+	case <-time.After(enginetest.WaitTimeout):
+		t.Fatal("Stop did not return")
+	}
+}
+
+func TestHarness_CustomStopLossPerPair(t *testing.T) {
+	btc := model.QuotesPair{ExchangeID: model.BINANCE, Base: currency.BTCSymbol, Quote: currency.USDTSymbol, Category: trade.SPOT}
+	eth := model.QuotesPair{ExchangeID: model.BINANCE, Base: currency.ETHSymbol, Quote: currency.USDTSymbol, Category: trade.SPOT}
+	h := enginetest.New(t, enginetest.Config())
+	h.RegisterPair(btc, "btc-floor", &floorStop{floor: decimal.NewFromInt(90)})
+	h.Start()
+
+	h.Play(enginetest.PairPrices(eth, time.Minute, 80)...)
+	h.Play(enginetest.PairPrices(btc, time.Minute, 95, 90)...)
+
+	h.ExpectSequence("btc-floor", sink.EventUpdate, sink.EventTrigger)
+	trigger := h.ExpectTrigger("btc-floor")
+	if trigger.Pair != engine.PairKey(btc) {
+		t.Fatalf("expected the trigger to carry %q, got %q", engine.PairKey(btc), trigger.Pair)
+	}
+	if want := enginetest.Epoch.Add(3 * time.Minute); !trigger.Time.Equal(want) {
+		t.Fatalf("expected the trigger at %v, got %v", want, trigger.Time)
+	}
+	if h.Received() != 3 {
+		t.Fatalf("expected 3 received ticks, got %d", h.Received())
+	}
+}
+
+func TestHarness_BarsMoveChandelierStop(t *testing.T) {
+	h := enginetest.New(t, enginetest.Config())
+	chandelier, err := strategy.NewFixedChandelierStop(decimal.NewFromInt(100), decimal.NewFromInt(2), decimal.NewFromInt(2), 3, true, nil)
+	if err != nil {
+		t.Fatalf("create strategy: %v", err)
+	}
+	h.Register("chandelier", chandelier)
+
+	bar := func(high, low, close int64) model.PriceInterval {
+		return model.PriceInterval{HighestPrice: decimal.NewFromInt(high), LowestPrice: decimal.NewFromInt(low), ClosingPrice: decimal.NewFromInt(close)}
+	}
+	// warm-up bars before Start apply at once
+	h.Bars(bar(102, 98, 100), bar(104, 100, 103))
+	h.Start()
+
+	h.Play(enginetest.Prices(time.Minute, 97)...)
+	h.Bars(bar(106, 102, 105))
+	h.Play(enginetest.Prices(time.Minute, 99, 98)...)
+
+	h.ExpectSequence("chandelier", sink.EventUpdate, sink.EventUpdate, sink.EventTrigger)
+	events := h.Events("chandelier")
+	if got := events[0].General.Stat.PriceThreshold; !got.Equal(decimal.NewFromInt(96)) {
+		t.Fatalf("expected the entry stop of 96 before the window filled, got %v", got)
+	}
+	if got := events[1].General.Stat.PriceThreshold; !got.Equal(decimal.NewFromInt(98)) {
+		t.Fatalf("expected the third bar to ratchet the stop to 98, got %v", got)
+	}
+}
+
+func TestHarness_BarsReachBothHybridKinds(t *testing.T) {
+	h := enginetest.New(t, enginetest.Config())
+	fixed, err := strategy.NewSupertrend(decimal.NewFromInt(100), 2, decimal.NewFromInt(1), true, nil)
+	if err != nil {
+		t.Fatalf("create strategy: %v", err)
+	}
+	debounced, err := strategy.NewSupertrendDebounced(decimal.NewFromInt(100), 2, decimal.NewFromInt(1), true, 1000, nil)
+	if err != nil {
+		t.Fatalf("create strategy: %v", err)
+	}
+	h.Register("supertrend", fixed)
+	h.Register("supertrend-debounced", debounced)
+	h.Start()
+
+	bar := func(high, low, close int64) model.PriceInterval {
+		return model.PriceInterval{HighestPrice: decimal.NewFromInt(high), LowestPrice: decimal.NewFromInt(low), ClosingPrice: decimal.NewFromInt(close)}
+	}
+	h.Bars(bar(102, 98, 100), bar(104, 100, 103), bar(106, 102, 105))
+	h.Play(enginetest.Prices(time.Second, 104, 99, 99)...)
+
+	h.ExpectSequence("supertrend", sink.EventUpdate, sink.EventTrigger, sink.EventTrigger)
+	h.ExpectSequence("supertrend-debounced", sink.EventUpdate, sink.EventUpdate, sink.EventTrigger)
+	update := h.Events("supertrend")[0].Hybrid
+	if !update.StopStat.PriceThreshold.Equal(decimal.NewFromInt(100)) || !update.ProfitStat.PriceThreshold.Equal(decimal.NewFromInt(106)) {
+		t.Fatalf("expected bands 100/106 from the bars, got %v/%v", update.StopStat.PriceThreshold, update.ProfitStat.PriceThreshold)
+	}
+}
+
+func TestHarness_RatchetsReachTheSink(t *testing.T) {
+	h := enginetest.New(t, enginetest.Config())
+	config := strategy.BreakEvenConfig{
+		EntryPrice:   decimal.NewFromInt(100),
+		IsLong:       true,
+		StopPct:      decimal.NewFromFloat(0.05),
+		TriggerPct:   decimal.NewFromFloat(0.04),
+		LockFraction: decimal.NewFromFloat(0.5),
+		LockStep:     decimal.NewFromFloat(0.02),
+	}
+	fixed, err := strategy.NewFixedBreakEvenStop(config, nil)
+	if err != nil {
+		t.Fatalf("create strategy: %v", err)
+	}
+	debounced, err := strategy.NewDebouncedBreakEvenStop(config, 1000, nil)
+	if err != nil {
+		t.Fatalf("create strategy: %v", err)
+	}
+	h.Register("be", fixed)
+	h.Register("be-debounced", debounced)
+	h.Start()
+
+	h.Play(enginetest.Prices(time.Second, 102, 104, 107, 105, 103, 103)...)
+
+	h.ExpectSequence("be", sink.EventUpdate, sink.EventRatchet, sink.EventRatchet, sink.EventUpdate, sink.EventTrigger, sink.EventTrigger)
+	h.ExpectSequence("be-debounced", sink.EventUpdate, sink.EventRatchet, sink.EventRatchet, sink.EventUpdate, sink.EventUpdate, sink.EventTrigger)
+	events := h.Events("be")
+	moves := append(events[1].General.Ratchets, events[2].General.Ratchets...)
+	if len(moves) != 2 || !moves[0].To.Equal(decimal.NewFromInt(100)) || !moves[1].From.Equal(decimal.NewFromInt(100)) || !moves[1].To.Equal(decimal.NewFromInt(103)) {
+		t.Fatalf("expected the stop to move 95 -> 100 -> 103, got %+v", moves)
+	}
+	if got := h.Engine.Reporter.Stats()["ratchets"]; got != 4 {
+		t.Fatalf("expected the reporter to count 4 ratchet events, got %d", got)
+	}
+}
+
+func TestHarness_LadderCarriesPartialExits(t *testing.T) {
+	h := enginetest.New(t, enginetest.Config())
+	ladder, err := strategy.NewFixedLadderProfit(strategy.LadderConfig{
+		EntryPrice: decimal.NewFromInt(100),
+		IsLong:     true,
+		Quantity:   decimal.NewFromInt(4),
+		StopPrice:  decimal.NewFromInt(95),
+		Targets: []strategy.LadderTarget{
+			{Pct: decimal.NewFromFloat(0.02), Fraction: decimal.NewFromFloat(0.5)},
+			{R: decimal.NewFromInt(1), Fraction: decimal.NewFromFloat(0.5)},
+		},
+		MoveStop: strategy.LadderStopBreakEven,
+	}, nil)
+	if err != nil {
+		t.Fatalf("create strategy: %v", err)
+	}
+	h.Register("ladder", ladder)
+	h.Start()
+
+	h.Play(enginetest.Prices(time.Second, 101, 102, 103, 105)...)
+
+	h.ExpectSequence("ladder", sink.EventUpdate, sink.EventTrigger, sink.EventUpdate, sink.EventTrigger)
+	events := h.Events("ladder")
+	first, second := events[1].General, events[3].General
+	if first.Exit == nil || first.Exit.Rung != 1 || !first.Exit.Quantity.Equal(decimal.NewFromInt(2)) {
+		t.Fatalf("expected the first rung to close 2, got %+v", first.Exit)
+	}
+	if len(first.Ratchets) != 1 || !first.Ratchets[0].To.Equal(decimal.NewFromInt(100)) {
+		t.Fatalf("expected the first fill to move the stop to entry, got %+v", first.Ratchets)
+	}
+	if second.Exit == nil || second.Exit.Rung != 2 || !second.Exit.Fraction.Equal(decimal.NewFromFloat(0.5)) {
+		t.Fatalf("expected the second rung to close the other half, got %+v", second.Exit)
+	}
+	if events[2].General.Exit != nil {
+		t.Fatal("expected updates between fills to carry no exit")
+	}
+}
+
+func TestHarness_TimeExitsFollowTickTimestamps(t *testing.T) {
+	h := enginetest.New(t, enginetest.Config())
+	hold, err := strategy.NewMaxHoldProfit(0, 2*time.Minute.Milliseconds(), nil)
+	if err != nil {
+		t.Fatalf("create strategy: %v", err)
+	}
+	session, err := strategy.NewSessionCloseStop(strategy.SessionClose{Location: time.UTC, Minute: 4, Lead: time.Minute.Milliseconds()}, nil)
+	if err != nil {
+		t.Fatalf("create strategy: %v", err)
+	}
+	h.Register("max-hold", hold)
+	h.Register("session", session)
+	h.Start()
+
+	// ticks at 00:01, 00:02 and 00:03 UTC on the fake clock
+	h.Play(enginetest.Prices(time.Minute, 100, 100, 100)...)
+
+	h.ExpectSequence("max-hold", sink.EventUpdate, sink.EventUpdate, sink.EventTrigger)
+	h.ExpectSequence("session", sink.EventUpdate, sink.EventUpdate, sink.EventTrigger)
+	exit := h.ExpectTrigger("max-hold").General
+	if exit.TriggerType != model.TAKE_PROFIT || exit.TimeThreshold != 2*time.Minute {
+		t.Fatalf("expected a take profit result with the 2m hold, got %v and %v", exit.TriggerType, exit.TimeThreshold)
+	}
+}
+
+func TestHarness_BarsFeedBandStopAndProfit(t *testing.T) {
+	h := enginetest.New(t, enginetest.Config())
+	config := strategy.BandConfig{Kind: strategy.BollingerBand, Period: 4, Width: decimal.NewFromInt(1), IsLong: true}
+	stop, err := strategy.NewFixedBandStop(config, nil)
+	if err != nil {
+		t.Fatalf("create strategy: %v", err)
+	}
+	profit, err := strategy.NewFixedBandProfit(config, nil)
+	if err != nil {
+		t.Fatalf("create strategy: %v", err)
+	}
+	h.Register("band-stop", stop)
+	h.Register("band-profit", profit)
+	h.Start()
+
+	bar := func(close int64) model.PriceInterval {
+		c := decimal.NewFromInt(close)
+		return model.PriceInterval{HighestPrice: c, LowestPrice: c, ClosingPrice: c}
+	}
+	h.Bars(bar(100), bar(102), bar(100), bar(102))
+	h.Play(enginetest.Prices(time.Second, 101, 100, 102)...)
+
+	h.ExpectSequence("band-stop", sink.EventUpdate, sink.EventTrigger, sink.EventUpdate)
+	h.ExpectSequence("band-profit", sink.EventUpdate, sink.EventUpdate, sink.EventTrigger)
+	if got := h.ExpectTrigger("band-profit").General.Stat.PriceThreshold; !got.Equal(decimal.NewFromInt(102)) {
+		t.Fatalf("expected the upper band at 102, got %v", got)
+	}
+}
+
+func TestHarness_TradesFeedVWAP(t *testing.T) {
+	h := enginetest.New(t, enginetest.Config())
+	vwap, err := strategy.NewVWAPBands(strategy.VWAPConfig{StopWidth: decimal.NewFromInt(1), ProfitWidth: decimal.NewFromInt(2), IsLong: true}, nil)
+	if err != nil {
+		t.Fatalf("create strategy: %v", err)
+	}
+	h.Register("vwap", vwap)
+	h.Start()
+
+	// trades carry their size into the VWAP, the quote after them only gets evaluated
+	steps := append(enginetest.Trades(time.Second, [2]float64{98, 1}, [2]float64{102, 1}), enginetest.Prices(time.Second, 97)...)
+	h.Play(steps...)
+
+	h.ExpectSequence("vwap", sink.EventUpdate, sink.EventUpdate, sink.EventTrigger)
+	update := h.Events("vwap")[1].Hybrid
+	if !update.StopStat.PriceThreshold.Equal(decimal.NewFromInt(98)) || !update.ProfitStat.PriceThreshold.Equal(decimal.NewFromInt(104)) {
+		t.Fatalf("expected bands 98/104 once both trades were folded in, got %v/%v", update.StopStat.PriceThreshold, update.ProfitStat.PriceThreshold)
+	}
+	if got := h.ExpectTrigger("vwap").Hybrid.TriggerType; got != model.STOP_LOSS {
+		t.Fatalf("expected the quote below the band to stop, got %v", got)
+	}
+}
+
+func TestHarness_OrderBooksMoveLiquidityStop(t *testing.T) {
+	h := enginetest.New(t, enginetest.Config())
+	stop, err := strategy.NewFixedLiquidityStop(strategy.LiquidityConfig{
+		EntryPrice: decimal.NewFromInt(100),
+		IsLong:     true,
+		StopPrice:  decimal.NewFromInt(95),
+		Quantity:   decimal.NewFromInt(4),
+	}, nil)
+	if err != nil {
+		t.Fatalf("create strategy: %v", err)
+	}
+	h.Register("liquidity", stop)
+	h.Start()
+
+	h.Play(enginetest.Prices(time.Second, 96)...)
+	// selling 4 into this book fills at 98 on average, one below the best bid
+	h.Books(model.OrderBook{Bids: []model.OrderBookBid{
+		{Price: decimal.NewFromInt(99), Quantity: decimal.NewFromInt(2)},
+		{Price: decimal.NewFromInt(97), Quantity: decimal.NewFromInt(2)},
+	}})
+	h.Play(enginetest.Prices(time.Second, 96)...)
+
+	h.ExpectSequence("liquidity", sink.EventUpdate, sink.EventTrigger)
+	if got := h.ExpectTrigger("liquidity").General.Stat.PriceThreshold; !got.Equal(decimal.NewFromInt(96)) {
+		t.Fatalf("expected the book to move the trigger to 96, got %v", got)
+	}
+}
+
+func TestHarness_CircuitBreakerFlattensAndBlocksEntries(t *testing.T) {
+	h := enginetest.New(t, enginetest.Config())
+	var flattened []string
+	guard, err := strategy.NewCircuitBreaker(strategy.CircuitBreakerConfig{
+		StartEquity: decimal.NewFromInt(1000),
+		DailyLoss:   decimal.NewFromInt(50),
+	}, func(reason string) error { flattened = append(flattened, reason); return nil })
+	if err != nil {
+		t.Fatalf("create guard: %v", err)
+	}
+	h.Register("breaker", guard)
+	h.Start()
+
+	h.Fills(model.Fill{Side: trade.BUY, Price: decimal.NewFromInt(100), Quantity: decimal.NewFromInt(10), UpdateTime: h.Clock.Now().UnixMilli()})
+	h.Settle()
+	h.Play(enginetest.Prices(time.Second, 98, 94, 93)...)
+
+	h.ExpectSequence("breaker", sink.EventUpdate, sink.EventTrigger, sink.EventUpdate)
+	if got := h.ExpectTrigger("breaker").General.Stat.PriceThreshold; !got.Equal(decimal.NewFromInt(-60)) {
+		t.Fatalf("expected the account PnL as the threshold, got %v", got)
+	}
+	if len(flattened) != 1 {
+		t.Fatalf("expected one flatten, got %v", flattened)
+	}
+	if !h.Engine.EntriesBlocked() {
+		t.Fatal("expected new entries to be blocked after the halt")
+	}
+	if err := guard.Reset(); err != nil {
+		t.Fatalf("reset: %v", err)
+	}
+	if h.Engine.EntriesBlocked() {
+		t.Fatal("expected the manual reset to unblock entries")
+	}
+}
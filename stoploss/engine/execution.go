@@ -14,17 +14,21 @@
 package engine
 
 import (
+	"time"
+
+	"github.com/wang900115/quant/common/clock"
 	"github.com/wang900115/quant/model"
 	"github.com/wang900115/quant/model/result"
 )
 
 type Execution struct {
-	fixedStoplossChannel       chan model.PricePoint
-	DebouncedStoplossChannel   chan model.PricePoint
-	fixedTakeProfitChannel     chan model.PricePoint
-	DebouncedTakeProfitChannel chan model.PricePoint
-	hybridFixedChannel         chan model.PricePoint
-	hybridDebouncedChannel     chan model.PricePoint
+	fixedStoplossChannel       *inbox
+	DebouncedStoplossChannel   *inbox
+	fixedTakeProfitChannel     *inbox
+	DebouncedTakeProfitChannel *inbox
+	hybridFixedChannel         *inbox
+	hybridDebouncedChannel     *inbox
+	guardChannel               *inbox
 
 	generalResults chan result.StrategyGeneralResult
 	hybridResults  chan result.StrategyHybridResult
@@ -32,28 +36,52 @@ type Execution struct {
 
 func NewExecutionManager(bufferSize int, bufferRSize int) *Execution {
 	return &Execution{
-		fixedStoplossChannel:       make(chan model.PricePoint, bufferSize),
-		DebouncedStoplossChannel:   make(chan model.PricePoint, bufferSize),
-		fixedTakeProfitChannel:     make(chan model.PricePoint, bufferSize),
-		DebouncedTakeProfitChannel: make(chan model.PricePoint, bufferSize),
-		hybridFixedChannel:         make(chan model.PricePoint, bufferSize),
-		hybridDebouncedChannel:     make(chan model.PricePoint, bufferSize),
+		fixedStoplossChannel:       newInbox(InboxFixedStop, model.FIXED, model.STOP_LOSS, bufferSize),
+		DebouncedStoplossChannel:   newInbox(InboxDebouncedStop, model.DEBUNCED, model.STOP_LOSS, bufferSize),
+		fixedTakeProfitChannel:     newInbox(InboxFixedProfit, model.FIXED, model.TAKE_PROFIT, bufferSize),
+		DebouncedTakeProfitChannel: newInbox(InboxDebouncedProfit, model.DEBUNCED, model.TAKE_PROFIT, bufferSize),
+		hybridFixedChannel:         newInbox(InboxHybridFixed, model.HYBRID_FIXED, "", bufferSize),
+		hybridDebouncedChannel:     newInbox(InboxHybridDebounced, model.HYBRID_DEBUNCED, "", bufferSize),
+		guardChannel:               newInbox(InboxGuard, model.GUARD, model.CIRCUIT_BREAKER, bufferSize),
 		generalResults:             make(chan result.StrategyGeneralResult, bufferRSize),
 		hybridResults:              make(chan result.StrategyHybridResult, bufferRSize),
 	}
 }
 
+// inboxes returns every input channel keyed by name
+func (e *Execution) inboxes() map[Inbox]*inbox {
+	return map[Inbox]*inbox{
+		InboxFixedStop:       e.fixedStoplossChannel,
+		InboxDebouncedStop:   e.DebouncedStoplossChannel,
+		InboxFixedProfit:     e.fixedTakeProfitChannel,
+		InboxDebouncedProfit: e.DebouncedTakeProfitChannel,
+		InboxHybridFixed:     e.hybridFixedChannel,
+		InboxHybridDebounced: e.hybridDebouncedChannel,
+		InboxGuard:           e.guardChannel,
+	}
+}
+
+// setPolicies applies the configured back-pressure policies, inboxes left out keep DropNewest
+func (e *Execution) setPolicies(policies map[Inbox]Policy, deadline time.Duration, clk clock.Clock) {
+	for name, in := range e.inboxes() {
+		in.policy = policies[name]
+		in.deadline = deadline
+		in.clock = clk
+	}
+}
+
 func (e *Execution) getResult() (<-chan result.StrategyGeneralResult, <-chan result.StrategyHybridResult) {
 	return e.generalResults, e.hybridResults
 }
 
 func (e *Execution) closeChannels() {
-	close(e.fixedStoplossChannel)
-	close(e.DebouncedStoplossChannel)
-	close(e.fixedTakeProfitChannel)
-	close(e.DebouncedTakeProfitChannel)
-	close(e.hybridFixedChannel)
-	close(e.hybridDebouncedChannel)
+	close(e.fixedStoplossChannel.ch)
+	close(e.DebouncedStoplossChannel.ch)
+	close(e.fixedTakeProfitChannel.ch)
+	close(e.DebouncedTakeProfitChannel.ch)
+	close(e.hybridFixedChannel.ch)
+	close(e.hybridDebouncedChannel.ch)
+	close(e.guardChannel.ch)
 	close(e.generalResults)
 	close(e.hybridResults)
 }
@@ -0,0 +1,90 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/wang900115/quant/model"
+	"github.com/wang900115/quant/stoploss/engine/sink"
+	"github.com/wang900115/quant/stoploss/strategy"
+)
+
+func TestEngine_HybridKindsEvaluatedSeparately(t *testing.T) {
+	ring := sink.NewRing(16)
+	cfg := DefaultConfig()
+	cfg.Sink = ring
+	csm := New(cfg)
+	fixed, err := strategy.NewRiskRewardRatio(decimal.NewFromInt(100), decimal.NewFromFloat(0.05), decimal.NewFromFloat(0.1), nil)
+	if err != nil {
+		t.Fatalf("create fixed: %v", err)
+	}
+	debounced, err := strategy.NewRiskRewardRatioDebounced(decimal.NewFromInt(100), decimal.NewFromFloat(0.05), decimal.NewFromFloat(0.1), 1000, nil)
+	if err != nil {
+		t.Fatalf("create debounced: %v", err)
+	}
+	if err := csm.RegisterStrategy("rr-fixed", fixed); err != nil {
+		t.Fatalf("register fixed: %v", err)
+	}
+	if err := csm.RegisterStrategy("rr-debounced", debounced); err != nil {
+		t.Fatalf("register debounced: %v", err)
+	}
+	if err := csm.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+
+	base := time.UnixMilli(1700000000000)
+	for _, p := range []struct {
+		price int64
+		at    time.Duration
+	}{
+		{100, 0},
+		{94, 100 * time.Millisecond},  // below the stop, the debounce window opens
+		{89, 1200 * time.Millisecond}, // still below the trailing stop after more than a second
+	} {
+		csm.Collect(model.PricePoint{NewPrice: decimal.NewFromInt(p.price), UpdatedAt: base.Add(p.at)}, nil)
+	}
+	csm.Stop()
+
+	byStrategy := map[string][]sink.Event{}
+	for _, e := range ring.Events() {
+		byStrategy[e.Strategy] = append(byStrategy[e.Strategy], e)
+	}
+	for name, want := range map[string]struct {
+		typ       model.StrategyType
+		threshold time.Duration
+		events    []sink.EventType
+	}{
+		"rr-fixed":     {model.HYBRID_FIXED, 0, []sink.EventType{sink.EventUpdate, sink.EventTrigger, sink.EventTrigger}},
+		"rr-debounced": {model.HYBRID_DEBUNCED, time.Second, []sink.EventType{sink.EventUpdate, sink.EventUpdate, sink.EventTrigger}},
+	} {
+		events := byStrategy[name]
+		if len(events) != len(want.events) {
+			t.Fatalf("%s: expected %d results, got %d", name, len(want.events), len(events))
+		}
+		for i, e := range events {
+			if e.Type != want.events[i] {
+				t.Errorf("%s: result %d expected %s, got %s", name, i, want.events[i], e.Type)
+			}
+			if e.Hybrid.StrategyType != want.typ || e.Hybrid.TimeThreshold != want.threshold {
+				t.Errorf("%s: result %d reported %s with threshold %v", name, i, e.Hybrid.StrategyType, e.Hybrid.TimeThreshold)
+			}
+		}
+		if last := events[len(events)-1].Hybrid; last.TriggerType != model.STOP_LOSS {
+			t.Errorf("%s: expected a stop loss trigger, got %q", name, last.TriggerType)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package engine
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/wang900115/quant/common/clock"
+	"github.com/wang900115/quant/model"
+)
+
+// Inbox names one of the engine input channels, matching the keys of Metrics.Stats
+type Inbox string
+
+const (
+	InboxFixedStop       Inbox = "fixed_stop"
+	InboxDebouncedStop   Inbox = "Debounced_stop"
+	InboxFixedProfit     Inbox = "fixed_profit"
+	InboxDebouncedProfit Inbox = "Debounced_profit"
+	InboxHybridFixed     Inbox = "hybrid_fixed"
+	InboxHybridDebounced Inbox = "hybrid_Debounced"
+	InboxGuard           Inbox = "guard"
+)
+
+// Inboxes lists every input channel of the engine
+var Inboxes = []Inbox{InboxFixedStop, InboxDebouncedStop, InboxFixedProfit, InboxDebouncedProfit, InboxHybridFixed, InboxHybridDebounced, InboxGuard}
+
+// inboxKinds maps every input channel to the strategies it feeds
+var inboxKinds = map[Inbox]struct {
+	typ      model.StrategyType
+	category model.StrategyCategory
+}{
+	InboxFixedStop:       {model.FIXED, model.STOP_LOSS},
+	InboxDebouncedStop:   {model.DEBUNCED, model.STOP_LOSS},
+	InboxFixedProfit:     {model.FIXED, model.TAKE_PROFIT},
+	InboxDebouncedProfit: {model.DEBUNCED, model.TAKE_PROFIT},
+	InboxHybridFixed:     {model.HYBRID_FIXED, ""},
+	InboxHybridDebounced: {model.HYBRID_DEBUNCED, ""},
+	InboxGuard:           {model.GUARD, model.CIRCUIT_BREAKER},
+}
+
+// Policy decides what happens to a tick when its inbox is full
+type Policy int
+
+const (
+	// DropNewest discards the incoming tick
+	DropNewest Policy = iota
+	// DropOldest evicts the oldest queued tick to make room for the incoming one, dropping the incoming one
+	// instead while a bar, order book or fill update is queued
+	DropOldest
+	// ConflateLatest keeps one pending tick per pair, replacing it with every newer price
+	ConflateLatest
+	// BlockWithDeadline waits up to Config.BlockDeadline for room, then discards the incoming tick
+	BlockWithDeadline
+)
+
+func (p Policy) String() string {
+	switch p {
+	case DropNewest:
+		return "drop_newest"
+	case DropOldest:
+		return "drop_oldest"
+	case ConflateLatest:
+		return "conflate_latest"
+	case BlockWithDeadline:
+		return "block_with_deadline"
+	}
+	return "unknown"
+}
+
+// inbox is an input channel together with its back-pressure policy
+type inbox struct {
+	name     Inbox
+	typ      model.StrategyType
+	category model.StrategyCategory
+	policy   Policy
+	deadline time.Duration
+	clock    clock.Clock
+	ch       chan tick
+
+	// pending holds the latest tick per pair while ConflateLatest has one queued
+	mu      sync.Mutex
+	pending map[string]tick
+	// updates counts the bar, order book and fill updates queued
+	updates atomic.Int64
+	// epoch counts the updates pushed, a pending tick only replaces ticks queued in its epoch
+	epoch uint64
+
+	// queued counts ticks sent but not yet evaluated, used to drain on stop
+	queued atomic.Int64
+}
+
+func newInbox(name Inbox, typ model.StrategyType, category model.StrategyCategory, size int) *inbox {
+	return &inbox{
+		name:     name,
+		typ:      typ,
+		category: category,
+		ch:       make(chan tick, size),
+		pending:  make(map[string]tick),
+	}
+}
+
+// push enqueues a tick according to the policy, callback runs whenever a tick is discarded
+func (in *inbox) push(update tick, metrics *Metrics, callback func()) {
+	switch in.policy {
+	case DropOldest:
+		for i := 0; i < cap(in.ch)+1; i++ {
+			if in.offer(update) {
+				metrics.RecordChannelSend(in.typ, in.category)
+				return
+			}
+			if in.updates.Load() > 0 {
+				// a queued update is never evicted, the incoming price is dropped instead
+				break
+			}
+			select {
+			case old := <-in.ch:
+				if old.apply != nil {
+					// the update raced in after the check, it is applied rather than lost
+					old.apply(context.Background())
+					in.applied()
+					continue
+				}
+				in.done()
+				metrics.RecordChannelEvict(in.typ, in.category)
+				metrics.RecordDropped()
+				if callback != nil {
+					callback()
+				}
+			default:
+			}
+		}
+		in.drop(metrics, callback)
+	case ConflateLatest:
+		in.mu.Lock()
+		update.epoch = in.epoch
+		if _, ok := in.pending[update.pair]; ok {
+			in.pending[update.pair] = update
+			in.mu.Unlock()
+			metrics.RecordChannelConflate(in.typ, in.category)
+			return
+		}
+		if in.offer(update) {
+			in.pending[update.pair] = update
+			in.mu.Unlock()
+			metrics.RecordChannelSend(in.typ, in.category)
+			return
+		}
+		in.mu.Unlock()
+		in.drop(metrics, callback)
+	case BlockWithDeadline:
+		timer := clock.Or(in.clock).NewTimer(in.deadline)
+		defer timer.Stop()
+		in.queued.Add(1)
+		select {
+		case in.ch <- update:
+			metrics.RecordChannelSend(in.typ, in.category)
+		case <-timer.C():
+			in.queued.Add(-1)
+			in.drop(metrics, callback)
+		}
+	default:
+		if in.offer(update) {
+			metrics.RecordChannelSend(in.typ, in.category)
+			return
+		}
+		in.drop(metrics, callback)
+	}
+}
+
+// pushUpdate queues a bar, order book or fill update behind the ticks already queued, waiting at most timeout
+func (in *inbox) pushUpdate(update tick, timeout time.Duration, metrics *Metrics) bool {
+	in.queued.Add(1)
+	in.updates.Add(1)
+	select {
+	case in.ch <- update:
+	default:
+		timer := clock.Or(in.clock).NewTimer(timeout)
+		defer timer.Stop()
+		select {
+		case in.ch <- update:
+		case <-timer.C():
+			in.queued.Add(-1)
+			in.updates.Add(-1)
+			metrics.RecordChannelTimeout(in.typ, in.category)
+			return false
+		}
+	}
+	// a price collected from now on must not be conflated into a tick queued ahead of the update
+	in.mu.Lock()
+	clear(in.pending)
+	in.epoch++
+	in.mu.Unlock()
+	return true
+}
+
+// offer sends without blocking, counting the tick as queued until done is called
+func (in *inbox) offer(update tick) bool {
+	in.queued.Add(1)
+	select {
+	case in.ch <- update:
+		return true
+	default:
+		in.queued.Add(-1)
+		return false
+	}
+}
+
+// applied marks a queued update as applied
+func (in *inbox) applied() {
+	in.updates.Add(-1)
+	in.done()
+}
+
+// done marks a queued tick as evaluated or evicted
+func (in *inbox) done() {
+	in.queued.Add(-1)
+}
+
+// latest swaps a received tick for the newest price conflated onto it
+func (in *inbox) latest(update tick) tick {
+	if in.policy != ConflateLatest {
+		return update
+	}
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if newest, ok := in.pending[update.pair]; ok && newest.epoch == update.epoch {
+		delete(in.pending, update.pair)
+		return newest
+	}
+	return update
+}
+
+func (in *inbox) drop(metrics *Metrics, callback func()) {
+	metrics.RecordChannelDrop(in.typ, in.category)
+	metrics.RecordDropped()
+	if callback != nil {
+		callback()
+	}
+}
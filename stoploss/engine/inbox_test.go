@@ -0,0 +1,254 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package engine
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/wang900115/quant/model"
+	"github.com/wang900115/quant/stoploss/engine/sink"
+)
+
+func priceTick(pair string, price int64) tick {
+	return tick{pair: pair, point: model.PricePoint{NewPrice: decimal.NewFromInt(price), UpdatedAt: time.Now()}}
+}
+
+func newTestInbox(policy Policy, size int) *inbox {
+	in := newInbox(InboxFixedStop, model.FIXED, model.STOP_LOSS, size)
+	in.policy = policy
+	in.deadline = 20 * time.Millisecond
+	return in
+}
+
+func drain(in *inbox) []int64 {
+	var prices []int64
+	for {
+		select {
+		case t := <-in.ch:
+			prices = append(prices, in.latest(t).point.NewPrice.IntPart())
+		default:
+			return prices
+		}
+	}
+}
+
+func TestInbox_DropNewest(t *testing.T) {
+	m := NewMetrics()
+	in := newTestInbox(DropNewest, 2)
+	drops := 0
+	for i := int64(1); i <= 3; i++ {
+		in.push(priceTick("", i), m, func() { drops++ })
+	}
+	if got := drain(in); len(got) != 2 || got[0] != 1 || got[1] != 2 {
+		t.Fatalf("expected [1 2], got %v", got)
+	}
+	if drops != 1 || m.Dropped.With("Fixed", "stop_loss").Snapshot().Count() != 1 {
+		t.Fatalf("expected one drop, callback=%d metric=%d", drops, m.Dropped.With("Fixed", "stop_loss").Snapshot().Count())
+	}
+}
+
+func TestInbox_DropOldest(t *testing.T) {
+	m := NewMetrics()
+	in := newTestInbox(DropOldest, 2)
+	for i := int64(1); i <= 4; i++ {
+		in.push(priceTick("", i), m, nil)
+	}
+	if got := drain(in); len(got) != 2 || got[0] != 3 || got[1] != 4 {
+		t.Fatalf("expected [3 4], got %v", got)
+	}
+	if n := m.Evicted.With("Fixed", "stop_loss").Snapshot().Count(); n != 2 {
+		t.Fatalf("expected 2 evictions, got %d", n)
+	}
+	if n := m.TotalDropped.Snapshot().Count(); n != 2 {
+		t.Fatalf("expected 2 total drops, got %d", n)
+	}
+}
+
+func TestInbox_ConflateLatest(t *testing.T) {
+	m := NewMetrics()
+	in := newTestInbox(ConflateLatest, 4)
+	in.push(priceTick("btc", 1), m, nil)
+	in.push(priceTick("eth", 10), m, nil)
+	in.push(priceTick("btc", 2), m, nil)
+	in.push(priceTick("btc", 3), m, nil)
+
+	if got := drain(in); len(got) != 2 || got[0] != 3 || got[1] != 10 {
+		t.Fatalf("expected [3 10], got %v", got)
+	}
+	if n := m.Conflated.With("Fixed", "stop_loss").Snapshot().Count(); n != 2 {
+		t.Fatalf("expected 2 conflated ticks, got %d", n)
+	}
+
+	// once evaluated, the next tick for the pair is queued again
+	in.push(priceTick("btc", 4), m, nil)
+	if got := drain(in); len(got) != 1 || got[0] != 4 {
+		t.Fatalf("expected [4], got %v", got)
+	}
+}
+
+func TestInbox_BlockWithDeadline(t *testing.T) {
+	m := NewMetrics()
+	in := newTestInbox(BlockWithDeadline, 1)
+	in.push(priceTick("", 1), m, nil)
+
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		<-in.ch
+	}()
+	in.push(priceTick("", 2), m, nil)
+	if n := m.Dropped.With("Fixed", "stop_loss").Snapshot().Count(); n != 0 {
+		t.Fatalf("expected the blocked push to succeed, got %d drops", n)
+	}
+
+	start := time.Now()
+	in.push(priceTick("", 3), m, nil)
+	if elapsed := time.Since(start); elapsed < in.deadline {
+		t.Fatalf("expected push to wait for the deadline, returned after %v", elapsed)
+	}
+	if n := m.Dropped.With("Fixed", "stop_loss").Snapshot().Count(); n != 1 {
+		t.Fatalf("expected one drop after the deadline, got %d", n)
+	}
+}
+
+func TestEngine_BackpressureConfig(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Backpressure = map[Inbox]Policy{InboxFixedStop: ConflateLatest, InboxHybridFixed: DropOldest}
+	csm := New(cfg)
+	for name, in := range csm.execution.inboxes() {
+		if want := cfg.Backpressure[name]; in.policy != want {
+			t.Errorf("%s: expected %s, got %s", name, want, in.policy)
+		}
+	}
+}
+
+func TestInbox_UpdateEndsConflation(t *testing.T) {
+	m := NewMetrics()
+	in := newTestInbox(ConflateLatest, 4)
+	in.push(priceTick("", 1), m, nil)
+	if !in.pushUpdate(tick{apply: func(context.Context) {}}, time.Second, m) {
+		t.Fatal("expected room for the update")
+	}
+	in.push(priceTick("", 2), m, nil)
+	in.push(priceTick("", 3), m, nil)
+
+	var got []string
+	for len(in.ch) > 0 {
+		u := <-in.ch
+		if u.apply != nil {
+			got = append(got, "update")
+			continue
+		}
+		got = append(got, in.latest(u).point.NewPrice.String())
+	}
+	if want := []string{"1", "update", "3"}; len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+// barRecorder is a fixed stop fed by bars that records what it saw in order, its first tick waits for gate
+type barRecorder struct {
+	mu   sync.Mutex
+	seen []string
+	gate chan struct{}
+}
+
+func (b *barRecorder) record(s string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.seen = append(b.seen, s)
+}
+
+func (b *barRecorder) ShouldTriggerStopLoss(price decimal.Decimal) (bool, error) {
+	if b.gate != nil {
+		<-b.gate
+		b.gate = nil
+	}
+	b.record(price.String())
+	return false, nil
+}
+func (b *barRecorder) UpdateBar(model.PriceInterval) error {
+	b.record("bar")
+	return nil
+}
+func (b *barRecorder) CalculateStopLoss(decimal.Decimal) (decimal.Decimal, error) {
+	return decimal.Zero, nil
+}
+func (b *barRecorder) Trigger(string) error                  { return nil }
+func (b *barRecorder) GetStopLoss() (decimal.Decimal, error) { return decimal.Zero, nil }
+func (b *barRecorder) ReSetStopLosser(decimal.Decimal) error { return nil }
+func (b *barRecorder) Deactivate() error                     { return nil }
+
+func TestEngine_BarsQueueBehindTicks(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Shards = 4
+	cfg.Sink = sink.NewRing(64)
+	cfg.Backpressure = map[Inbox]Policy{InboxFixedStop: ConflateLatest}
+	csm := New(cfg)
+	gate := make(chan struct{})
+	rec := &barRecorder{gate: gate}
+	if err := csm.RegisterStrategy("recorder", rec); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if err := csm.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer csm.Stop()
+
+	// the first tick holds the shard, so the ones after it queue up and conflate
+	for i := int64(1); i <= 3; i++ {
+		csm.Collect(priceTick("", i).point, nil)
+	}
+	csm.CollectBar(model.PriceInterval{})
+	for i := int64(4); i <= 5; i++ {
+		csm.Collect(priceTick("", i).point, nil)
+	}
+	close(gate)
+	deadline := time.Now().Add(2 * time.Second)
+	for !csm.Idle() && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	bar := -1
+	for i, s := range rec.seen {
+		if s == "bar" {
+			bar = i
+		} else if p, _ := decimal.NewFromString(s); (bar < 0) != p.LessThan(decimal.NewFromInt(4)) {
+			t.Fatalf("expected prices up to 3 before the bar and later ones after it, got %v", rec.seen)
+		}
+	}
+	if bar < 0 || rec.seen[len(rec.seen)-1] != "5" {
+		t.Fatalf("expected the bar and the last price to be evaluated, got %v", rec.seen)
+	}
+}
+
+func TestInbox_DropOldestKeepsUpdates(t *testing.T) {
+	m := NewMetrics()
+	in := newTestInbox(DropOldest, 2)
+	in.pushUpdate(tick{apply: func(context.Context) {}}, time.Second, m)
+	in.push(priceTick("", 1), m, nil)
+	drops := 0
+	in.push(priceTick("", 2), m, func() { drops++ })
+	if first := <-in.ch; first.apply == nil {
+		t.Fatal("expected the queued update to survive a full inbox")
+	}
+	if got := drain(in); drops != 1 || len(got) != 1 || got[0] != 1 {
+		t.Fatalf("expected the incoming price to be dropped, got %v with %d drops", got, drops)
+	}
+}
@@ -0,0 +1,270 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/wang900115/quant/common/clock"
+	"github.com/wang900115/quant/common/sys"
+)
+
+// State is a step of the engine lifecycle
+type State int32
+
+// drainPoll is how often Stop checks whether in-flight ticks are done
+const drainPoll = 5 * time.Millisecond
+
+const (
+	StateCreated State = iota
+	StateStarting
+	StateRunning
+	StateDraining
+	StateStopped
+)
+
+func (s State) String() string {
+	switch s {
+	case StateCreated:
+		return "created"
+	case StateStarting:
+		return "starting"
+	case StateRunning:
+		return "running"
+	case StateDraining:
+		return "draining"
+	case StateStopped:
+		return "stopped"
+	}
+	return "unknown"
+}
+
+func (s State) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// HandlerHealth is the liveness of one processing goroutine
+type HandlerHealth struct {
+	Name     string    `json:"name"`
+	LastBeat time.Time `json:"last_beat"`
+	Alive    bool      `json:"alive"`
+}
+
+// Health is a point in time view of the engine and its handlers
+type Health struct {
+	State    State           `json:"state"`
+	Healthy  bool            `json:"healthy"`
+	Ready    bool            `json:"ready"`
+	Handlers []HandlerHealth `json:"handlers"`
+	// Tasks are the supervised goroutines, a failed task makes the engine unhealthy
+	Tasks []sys.TaskStatus `json:"tasks"`
+}
+
+// heartbeats records the last time every handler showed progress, registering counts as the first beat
+type heartbeats struct {
+	mu    sync.RWMutex
+	names []string
+	last  map[string]*atomic.Int64
+	clock clock.Clock
+}
+
+func newHeartbeats(clk clock.Clock) *heartbeats {
+	return &heartbeats{last: make(map[string]*atomic.Int64), clock: clk}
+}
+
+func (hb *heartbeats) register(name string) {
+	hb.mu.Lock()
+	defer hb.mu.Unlock()
+	if _, ok := hb.last[name]; ok {
+		return
+	}
+	last := new(atomic.Int64)
+	last.Store(hb.clock.Now().UnixNano())
+	hb.names = append(hb.names, name)
+	hb.last[name] = last
+}
+
+func (hb *heartbeats) beat(name string) {
+	hb.mu.RLock()
+	defer hb.mu.RUnlock()
+	if last, ok := hb.last[name]; ok {
+		last.Store(hb.clock.Now().UnixNano())
+	}
+}
+
+// snapshot reports every handler in registration order, alive when it beat within window
+func (hb *heartbeats) snapshot(window time.Duration) []HandlerHealth {
+	hb.mu.RLock()
+	defer hb.mu.RUnlock()
+	now := hb.clock.Now()
+	handlers := make([]HandlerHealth, 0, len(hb.names))
+	for _, name := range hb.names {
+		h := HandlerHealth{Name: name}
+		if ns := hb.last[name].Load(); ns > 0 {
+			h.LastBeat = time.Unix(0, ns)
+			h.Alive = now.Sub(h.LastBeat) <= window
+		}
+		handlers = append(handlers, h)
+	}
+	return handlers
+}
+
+// State returns the current lifecycle state
+func (csm *StrategyEngine) State() State {
+	return State(csm.state.Load())
+}
+
+// Health reports the lifecycle state and per-handler liveness, a handler is alive when it beat within HeartbeatInterval plus CheckInterval
+func (csm *StrategyEngine) Health() Health {
+	state := csm.State()
+	h := Health{
+		State:    state,
+		Handlers: csm.heartbeats.snapshot(csm.Config.HeartbeatInterval + csm.Config.CheckInterval),
+		Tasks:    csm.engine.Status(),
+	}
+	alive := true
+	for _, handler := range h.Handlers {
+		alive = alive && handler.Alive
+	}
+	for _, task := range h.Tasks {
+		alive = alive && task.State != sys.TaskFailed
+	}
+	h.Healthy = alive && (state == StateRunning || state == StateDraining)
+	h.Ready = alive && state == StateRunning && len(h.Handlers) > 0
+	return h
+}
+
+// Ready reports whether the engine is running and every handler is alive
+func (csm *StrategyEngine) Ready() bool {
+	return csm.Health().Ready
+}
+
+// HealthHandler serves Health as JSON, answering 503 while the engine is unhealthy
+func (csm *StrategyEngine) HealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h := csm.Health()
+		writeHealth(w, h, h.Healthy)
+	})
+}
+
+// ReadyHandler serves Health as JSON, answering 503 until the engine is ready
+func (csm *StrategyEngine) ReadyHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h := csm.Health()
+		writeHealth(w, h, h.Ready)
+	})
+}
+
+func writeHealth(w http.ResponseWriter, h Health, ok bool) {
+	w.Header().Set("Content-Type", "application/json")
+	if ok {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(h)
+}
+
+// handle feeds one input channel to its process function and beats on every update and heartbeat tick
+func (csm *StrategyEngine) handle(ctx context.Context, name string, in *inbox, process func(tick, context.Context)) {
+	ticker := csm.clock.NewTicker(csm.Config.HeartbeatInterval)
+	defer ticker.Stop()
+	csm.heartbeats.beat(name)
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("[%s] stopped", name)
+			return
+		case update, ok := <-in.ch:
+			if !ok {
+				return
+			}
+			if update.apply != nil {
+				update.apply(ctx)
+				in.applied()
+			} else {
+				process(in.latest(update), ctx)
+				in.done()
+			}
+			csm.heartbeats.beat(name)
+		case <-ticker.C():
+			csm.heartbeats.beat(name)
+		}
+	}
+}
+
+// monitor logs handlers that missed their heartbeat, checked every sys.Engine.HealthCheck
+func (csm *StrategyEngine) monitor(ctx context.Context) error {
+	ticker := csm.clock.NewTicker(csm.engine.HealthCheck)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C():
+			for _, h := range csm.Health().Handlers {
+				if !h.Alive {
+					log.Printf("[%s] no heartbeat since %s", h.Name, h.LastBeat.Format(time.RFC3339))
+				}
+			}
+		}
+	}
+}
+
+// Idle reports whether no tick is queued or being evaluated and every result was reported
+func (csm *StrategyEngine) Idle() bool {
+	return csm.drained()
+}
+
+// drained reports whether no tick is queued or being evaluated and every result was reported
+func (csm *StrategyEngine) drained() bool {
+	for _, in := range csm.execution.inboxes() {
+		if in.queued.Load() > 0 {
+			return false
+		}
+	}
+	if csm.workers.pending.Load() > 0 {
+		return false
+	}
+	if csm.portfolio.openGeneral && len(csm.execution.generalResults) > 0 {
+		return false
+	}
+	if csm.portfolio.openHybrid && len(csm.execution.hybridResults) > 0 {
+		return false
+	}
+	return csm.Reporter.inFlight.Load() <= 0
+}
+
+// drain waits until in-flight ticks are evaluated or DrainTimeout passes on the engine clock.
+// The goroutines it waits for make progress in real time, so they are polled on a real ticker.
+func (csm *StrategyEngine) drain() {
+	deadline := csm.clock.NewTimer(csm.Config.DrainTimeout)
+	defer deadline.Stop()
+	poll := time.NewTicker(drainPoll)
+	defer poll.Stop()
+	for !csm.drained() {
+		select {
+		case <-deadline.C():
+			log.Printf("[StrategyEngine] drain timed out after %s", csm.Config.DrainTimeout)
+			return
+		case <-poll.C:
+		}
+	}
+}
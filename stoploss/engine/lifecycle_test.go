@@ -0,0 +1,278 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package engine
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/wang900115/quant/common/clock"
+	"github.com/wang900115/quant/common/sys"
+	"github.com/wang900115/quant/model"
+	"github.com/wang900115/quant/model/currency"
+	"github.com/wang900115/quant/model/trade"
+	"github.com/wang900115/quant/stoploss/engine/sink"
+	"github.com/wang900115/quant/stoploss/strategy"
+)
+
+func newStopEngine(t *testing.T, cfg Config) *StrategyEngine {
+	t.Helper()
+	csm := New(cfg)
+	s, err := strategy.NewFixedPercentStop(decimal.NewFromInt(100), decimal.NewFromFloat(0.05), nil)
+	if err != nil {
+		t.Fatalf("create strategy: %v", err)
+	}
+	if err := csm.RegisterStrategy("stop-5%", s); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	return csm
+}
+
+func TestEngine_StartIsNonBlocking(t *testing.T) {
+	csm := newStopEngine(t, DefaultConfig())
+	if csm.State() != StateCreated {
+		t.Fatalf("expected created, got %s", csm.State())
+	}
+
+	start := time.Now()
+	if err := csm.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Start blocked for %v", elapsed)
+	}
+	if csm.State() != StateRunning {
+		t.Fatalf("expected running, got %s", csm.State())
+	}
+	if err := csm.Start(); err == nil {
+		t.Fatal("expected second Start to fail")
+	}
+	if !csm.Ready() {
+		t.Fatalf("expected engine to be ready: %+v", csm.Health())
+	}
+
+	csm.Stop()
+	if csm.State() != StateStopped {
+		t.Fatalf("expected stopped, got %s", csm.State())
+	}
+	if csm.Ready() {
+		t.Fatal("stopped engine reported ready")
+	}
+	csm.Stop()
+}
+
+// countingStop is a fixed stop loss that records every evaluation and takes a while doing it
+type countingStop struct {
+	evaluated atomic.Int64
+}
+
+func (c *countingStop) CalculateStopLoss(decimal.Decimal) (decimal.Decimal, error) {
+	return decimal.NewFromInt(95), nil
+}
+func (c *countingStop) ShouldTriggerStopLoss(decimal.Decimal) (bool, error) {
+	time.Sleep(time.Millisecond)
+	c.evaluated.Add(1)
+	return false, nil
+}
+func (c *countingStop) Trigger(string) error                  { return nil }
+func (c *countingStop) GetStopLoss() (decimal.Decimal, error) { return decimal.NewFromInt(95), nil }
+func (c *countingStop) ReSetStopLosser(decimal.Decimal) error { return nil }
+func (c *countingStop) Deactivate() error                     { return nil }
+
+func TestEngine_StopDrainsInFlightTicks(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.BufferRSize = 4
+	csm := New(cfg)
+	s := &countingStop{}
+	if err := csm.RegisterStrategy("counting", s); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if err := csm.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+
+	const ticks = 50
+	for i := 0; i < ticks; i++ {
+		csm.Collect(model.PricePoint{NewPrice: decimal.NewFromInt(90), UpdatedAt: time.Now()}, func() {
+			t.Error("tick dropped while running")
+		})
+	}
+	csm.Stop()
+
+	if n := s.evaluated.Load(); n != ticks {
+		t.Fatalf("expected %d evaluations after drain, got %d", ticks, n)
+	}
+	if len(csm.execution.generalResults) != 0 {
+		t.Fatalf("expected every result to be consumed, %d left", len(csm.execution.generalResults))
+	}
+}
+
+func TestEngine_CollectAfterStop(t *testing.T) {
+	csm := newStopEngine(t, DefaultConfig())
+	if err := csm.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	csm.Stop()
+
+	dropped := false
+	csm.Collect(model.PricePoint{NewPrice: decimal.NewFromInt(90), UpdatedAt: time.Now()}, func() { dropped = true })
+	if !dropped {
+		t.Fatal("expected tick collected after Stop to be dropped")
+	}
+	if n := csm.Metrics.TotalDropped.Snapshot().Count(); n != 1 {
+		t.Fatalf("expected one dropped tick, got %d", n)
+	}
+}
+
+func TestEngine_HealthHandlers(t *testing.T) {
+	csm := newStopEngine(t, DefaultConfig())
+
+	rec := httptest.NewRecorder()
+	csm.ReadyHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 before Start, got %d", rec.Code)
+	}
+
+	if err := csm.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer csm.Stop()
+	deadline := time.Now().Add(time.Second)
+	for !csm.Ready() && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	for _, h := range []http.Handler{csm.HealthHandler(), csm.ReadyHandler()} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestHeartbeats_Liveness(t *testing.T) {
+	clk := clock.NewFake(time.Unix(1700000000, 0))
+	hb := newHeartbeats(clk)
+	hb.register("a")
+	hb.register("b")
+	clk.Advance(20 * time.Millisecond)
+	hb.beat("a")
+
+	handlers := hb.snapshot(10 * time.Millisecond)
+	if len(handlers) != 2 || handlers[0].Name != "a" || handlers[1].Name != "b" {
+		t.Fatalf("unexpected handlers %+v", handlers)
+	}
+	if !handlers[0].Alive || handlers[1].Alive {
+		t.Fatalf("expected only a to be alive: %+v", handlers)
+	}
+}
+
+func TestEngine_SinkReceivesEvents(t *testing.T) {
+	ring := sink.NewRing(16)
+	cfg := DefaultConfig()
+	cfg.Sink = ring
+	csm := newStopEngine(t, cfg)
+	if err := csm.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	btc := model.QuotesPair{ExchangeID: model.BINANCE, Base: currency.BTCSymbol, Quote: currency.USDTSymbol, Category: trade.SPOT}
+	csm.CollectPair(btc, model.PricePoint{NewPrice: decimal.NewFromInt(101), UpdatedAt: time.Now()}, nil)
+	csm.CollectPair(btc, model.PricePoint{NewPrice: decimal.NewFromInt(90), UpdatedAt: time.Now()}, nil)
+	csm.Stop()
+
+	events := ring.Events()
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+	if events[0].Type != sink.EventUpdate || events[1].Type != sink.EventTrigger {
+		t.Errorf("expected update then trigger, got %s then %s", events[0].Type, events[1].Type)
+	}
+	if events[1].Pair != "Binance:BTC/USDT:SPOT" {
+		t.Errorf("unexpected pair %q", events[1].Pair)
+	}
+}
+
+// failingSink rejects every event
+type failingSink struct{}
+
+func (failingSink) Emit(sink.Event) error { return errors.New("disk full") }
+func (failingSink) Close() error          { return nil }
+
+func TestEngine_CountsSinkErrors(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Sink = failingSink{}
+	csm := newStopEngine(t, cfg)
+	if err := csm.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	csm.Collect(model.PricePoint{NewPrice: decimal.NewFromInt(101), UpdatedAt: time.Now()}, nil)
+	csm.Collect(model.PricePoint{NewPrice: decimal.NewFromInt(90), UpdatedAt: time.Now()}, nil)
+	csm.Stop()
+
+	stats := csm.Reporter.Stats()
+	if stats["emit_errors"] != 2 || stats["triggers"] != 1 {
+		t.Errorf("expected both events counted as emit errors and the trigger still counted, got %v", stats)
+	}
+}
+
+// panickingStop panics on every evaluation
+type panickingStop struct{ countingStop }
+
+func (p *panickingStop) ShouldTriggerStopLoss(decimal.Decimal) (bool, error) {
+	panic("evaluation failed")
+}
+
+func TestEngine_FailedTaskMakesUnhealthy(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Shards = 1
+	cfg.Restart = sys.Policy{MinBackoff: time.Millisecond, MaxRestarts: 1}
+	// the failed shard never drains its queue
+	cfg.DrainTimeout = 10 * time.Millisecond
+	csm := New(cfg)
+	if err := csm.RegisterStrategy("panicking", &panickingStop{}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if err := csm.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer csm.Stop()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		csm.Collect(model.PricePoint{NewPrice: decimal.NewFromInt(90), UpdatedAt: time.Now()}, nil)
+		h := csm.Health()
+		if !h.Healthy {
+			var shard sys.TaskStatus
+			for _, task := range h.Tasks {
+				if task.Name == "shard-0" {
+					shard = task
+				}
+			}
+			if shard.State != sys.TaskFailed || shard.LastPanic != "evaluation failed" {
+				t.Errorf("expected shard-0 to have failed, got %+v", h.Tasks)
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("engine stayed healthy: %+v", h.Tasks)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
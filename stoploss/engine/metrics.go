@@ -27,44 +27,23 @@ type Metrics struct {
 	// TotalDropped counts the total number of messages dropped
 	TotalDropped *metric.CounterInt64
 
-	// FixedStopReceived counts the number of fixed stop loss messages received
-	FixedStopReceived *metric.CounterInt64
-	// DebouncedStopReceived counts the number of Debounced stop loss messages received
-	DebouncedStopReceived *metric.CounterInt64
-	// FixedProfitReceived counts the number of fixed profit messages received
-	FixedProfitReceived *metric.CounterInt64
-	// DebouncedProfitReceived counts the number of Debounced profit messages received
-	DebouncedProfitReceived *metric.CounterInt64
-	// HybridFixedReceived counts the number of hybrid fixed messages received
-	HybridFixedReceived *metric.CounterInt64
-	// HybridDebouncedReceived counts the number of hybrid Debounced messages received
-	HybridDebouncedReceived *metric.CounterInt64
-
-	// FixedStopDropped counts the number of fixed stop loss messages dropped
-	FixedStopDropped *metric.CounterInt64
-	// DebouncedStopDropped counts the number of Debounced stop loss messages dropped
-	DebouncedStopDropped *metric.CounterInt64
-	// FixedProfitDropped counts the number of fixed profit messages dropped
-	FixedProfitDropped *metric.CounterInt64
-	// DebouncedProfitDropped counts the number of Debounced profit messages dropped
-	DebouncedProfitDropped *metric.CounterInt64
-	// HybridFixedDropped counts the number of hybrid fixed messages dropped
-	HybridFixedDropped *metric.CounterInt64
-	// HybridDebouncedDropped counts the number of hybrid Debounced messages dropped
-	HybridDebouncedDropped *metric.CounterInt64
-
-	// FixedStopTimeout counts the number of fixed stop loss messages Debounced out
-	FixedStopTimeout *metric.CounterInt64
-	// DebouncedStopTimeout counts the number of Debounced stop loss messages Debounced out
-	DebouncedStopTimeout *metric.CounterInt64
-	// FixedProfitTimeout counts the number of fixed profit messages Debounced out
-	FixedProfitTimeout *metric.CounterInt64
-	// DebouncedProfitTimeout counts the number of Debounced profit messages Debounced out
-	DebouncedProfitTimeout *metric.CounterInt64
-	// HybridFixedTimeout counts the number of hybrid fixed messages Debounced out
-	HybridFixedTimeout *metric.CounterInt64
-	// HybridDebouncedTimeout counts the number of hybrid Debounced messages Debounced out
-	HybridDebouncedTimeout *metric.CounterInt64
+	// Received counts the messages queued per strategy type and category
+	Received *metric.CounterInt64Vec
+	// Dropped counts the messages dropped per strategy type and category
+	Dropped *metric.CounterInt64Vec
+	// Timeout counts the messages timed out per strategy type and category
+	Timeout *metric.CounterInt64Vec
+	// Evicted counts queued messages discarded by DropOldest per strategy type and category
+	Evicted *metric.CounterInt64Vec
+	// Conflated counts messages folded into a newer price for the same pair by ConflateLatest per strategy type and category
+	Conflated *metric.CounterInt64Vec
+	// PairReceived counts the messages collected per pair through CollectPair
+	PairReceived *metric.CounterInt64Vec
+
+	// Info describes the engine configuration
+	Info *metric.GaugeInfo
+	// Registry owns the per strategy latency timers
+	Registry *metric.Registry
 
 	// StartTime records the time when the metrics tracking started
 	StartTime time.Time
@@ -73,27 +52,17 @@ type Metrics struct {
 // NewMetrics creates a new Metrics instance
 func NewMetrics() *Metrics {
 	return &Metrics{
-		StartTime:               time.Now(),
-		TotalReceived:           metric.NewCounterInt64(),
-		TotalDropped:            metric.NewCounterInt64(),
-		FixedStopReceived:       metric.NewCounterInt64(),
-		DebouncedStopReceived:   metric.NewCounterInt64(),
-		FixedProfitReceived:     metric.NewCounterInt64(),
-		DebouncedProfitReceived: metric.NewCounterInt64(),
-		HybridFixedReceived:     metric.NewCounterInt64(),
-		HybridDebouncedReceived: metric.NewCounterInt64(),
-		FixedStopDropped:        metric.NewCounterInt64(),
-		DebouncedStopDropped:    metric.NewCounterInt64(),
-		FixedProfitDropped:      metric.NewCounterInt64(),
-		DebouncedProfitDropped:  metric.NewCounterInt64(),
-		HybridFixedDropped:      metric.NewCounterInt64(),
-		HybridDebouncedDropped:  metric.NewCounterInt64(),
-		FixedStopTimeout:        metric.NewCounterInt64(),
-		DebouncedStopTimeout:    metric.NewCounterInt64(),
-		FixedProfitTimeout:      metric.NewCounterInt64(),
-		DebouncedProfitTimeout:  metric.NewCounterInt64(),
-		HybridFixedTimeout:      metric.NewCounterInt64(),
-		HybridDebouncedTimeout:  metric.NewCounterInt64(),
+		StartTime:     time.Now(),
+		TotalReceived: metric.NewCounterInt64(),
+		TotalDropped:  metric.NewCounterInt64(),
+		Received:      metric.NewCounterInt64Vec("strategy_type", "category"),
+		Dropped:       metric.NewCounterInt64Vec("strategy_type", "category"),
+		Timeout:       metric.NewCounterInt64Vec("strategy_type", "category"),
+		Evicted:       metric.NewCounterInt64Vec("strategy_type", "category"),
+		Conflated:     metric.NewCounterInt64Vec("strategy_type", "category"),
+		PairReceived:  metric.NewCounterInt64Vec("pair"),
+		Info:          metric.NewGaugeInfo(),
+		Registry:      metric.NewRegistry(),
 	}
 }
 
@@ -107,78 +76,62 @@ func (m *Metrics) RecordDropped() {
 	m.TotalDropped.Inc(1)
 }
 
+// RecordPair records a message collected for a pair
+func (m *Metrics) RecordPair(pair string) {
+	m.PairReceived.With(pair).Inc(1)
+}
+
 // RecordChannelSend records a successful send to a specific channel
 func (m *Metrics) RecordChannelSend(typ model.StrategyType, channel model.StrategyCategory) {
-	switch typ {
-	case model.HYBRID_FIXED:
-		m.HybridFixedReceived.Inc(1)
-	case model.HYBRID_DEBUNCED:
-		m.HybridDebouncedReceived.Inc(1)
-	case model.FIXED:
-		switch channel {
-		case model.STOP_LOSS:
-			m.FixedStopReceived.Inc(1)
-		case model.TAKE_PROFIT:
-			m.FixedProfitReceived.Inc(1)
-		}
-	case model.DEBUNCED:
-		switch channel {
-		case model.STOP_LOSS:
-			m.DebouncedStopReceived.Inc(1)
-		case model.TAKE_PROFIT:
-			m.DebouncedProfitReceived.Inc(1)
-		}
-	}
+	m.Received.With(typ.String(), channel.String()).Inc(1)
 }
 
 // RecordChannelDrop records a dropped message for a specific channel
 func (m *Metrics) RecordChannelDrop(typ model.StrategyType, channel model.StrategyCategory) {
-	switch typ {
-	case model.HYBRID_FIXED:
-		m.HybridFixedDropped.Inc(1)
-	case model.HYBRID_DEBUNCED:
-		m.HybridDebouncedDropped.Inc(1)
-	case model.FIXED:
-		switch channel {
-		case model.STOP_LOSS:
-			m.FixedStopDropped.Inc(1)
-		case model.TAKE_PROFIT:
-			m.FixedProfitDropped.Inc(1)
-		}
-	case model.DEBUNCED:
-		switch channel {
-		case model.STOP_LOSS:
-			m.DebouncedStopDropped.Inc(1)
-		case model.TAKE_PROFIT:
-			m.DebouncedProfitDropped.Inc(1)
-		}
-	}
+	m.Dropped.With(typ.String(), channel.String()).Inc(1)
 }
 
 // RecordChannelTimeout records a timeout for a specific channel
 func (m *Metrics) RecordChannelTimeout(typ model.StrategyType, channel model.StrategyCategory) {
-	switch typ {
-	case model.HYBRID_FIXED:
-		m.HybridFixedTimeout.Inc(1)
-	case model.HYBRID_DEBUNCED:
-		m.HybridDebouncedTimeout.Inc(1)
-	case model.FIXED:
-		switch channel {
-		case model.STOP_LOSS:
-			m.FixedStopTimeout.Inc(1)
-		case model.TAKE_PROFIT:
-			m.FixedProfitTimeout.Inc(1)
+	m.Timeout.With(typ.String(), channel.String()).Inc(1)
+}
+
+// RecordChannelEvict records a queued message evicted to make room for a newer one
+func (m *Metrics) RecordChannelEvict(typ model.StrategyType, channel model.StrategyCategory) {
+	m.Evicted.With(typ.String(), channel.String()).Inc(1)
+}
+
+// RecordChannelConflate records a message replaced by a newer price before it was evaluated
+func (m *Metrics) RecordChannelConflate(typ model.StrategyType, channel model.StrategyCategory) {
+	m.Conflated.With(typ.String(), channel.String()).Inc(1)
+}
+
+// RecordDecision records the time from Collect to a strategy decision and the latency of every hop the price took
+func (m *Metrics) RecordDecision(strategy string, trace model.Trace) {
+	if !trace.EnqueuedAt.IsZero() && !trace.DecidedAt.IsZero() {
+		m.Registry.Timer("quant_engine_decision_latency_seconds", "Time from Collect to a strategy decision.", nil, metric.Labels{"strategy": strategy}).Update(trace.DecidedAt.Sub(trace.EnqueuedAt))
+	}
+	for _, hop := range model.Hops {
+		d, ok := trace.Get(hop)
+		if !ok {
+			continue
 		}
-	case model.DEBUNCED:
-		switch channel {
-		case model.STOP_LOSS:
-			m.DebouncedStopTimeout.Inc(1)
-		case model.TAKE_PROFIT:
-			m.DebouncedProfitTimeout.Inc(1)
+		if hop == model.HopExchange {
+			// negative when the venue clock runs ahead, so it is exposed as is rather than bucketed
+			m.Registry.Gauge("quant_engine_exchange_lag_seconds", "Venue event time to local receive of the latest decided price.", nil).Set(d.Seconds())
+			if d < 0 {
+				continue
+			}
 		}
+		m.Registry.Timer("quant_engine_hop_latency_seconds", "Latency of each hop from the venue to a decision.", nil, metric.Labels{"hop": string(hop)}).Update(d)
 	}
 }
 
+// RecordCallback records how long the report callback took for a strategy
+func (m *Metrics) RecordCallback(strategy string, d time.Duration) {
+	m.Registry.Timer("quant_engine_callback_duration_seconds", "Duration of the report callback on triggers.", nil, metric.Labels{"strategy": strategy}).Update(d)
+}
+
 // Stats returns a snapshot of current statistics
 func (m *Metrics) Stats() map[string]interface{} {
 	uptime := time.Since(m.StartTime)
@@ -186,49 +139,25 @@ func (m *Metrics) Stats() map[string]interface{} {
 	totalReceived := m.TotalReceived.Snapshot()
 	totalDropped := m.TotalDropped.Snapshot()
 
-	dropRate := float64(0)
-	if totalReceived > 0 {
-		dropRate = float64(totalDropped) / float64(totalReceived) * 100
+	channels := make(map[string]interface{}, len(Inboxes))
+	for _, name := range Inboxes {
+		kind := inboxKinds[name]
+		labels := []string{kind.typ.String(), kind.category.String()}
+		channels[string(name)] = map[string]int64{
+			"received":  m.Received.With(labels...).Snapshot().Count(),
+			"dropped":   m.Dropped.With(labels...).Snapshot().Count(),
+			"timeout":   m.Timeout.With(labels...).Snapshot().Count(),
+			"evicted":   m.Evicted.With(labels...).Snapshot().Count(),
+			"conflated": m.Conflated.With(labels...).Snapshot().Count(),
+		}
 	}
 
 	return map[string]interface{}{
 		"uptime_seconds":    uptime.Seconds(),
 		"total_received":    totalReceived,
 		"total_dropped":     totalDropped,
-		"drop_rate_percent": dropRate,
-
-		"channels": map[string]interface{}{
-			"fixed_stop": map[string]int64{
-				"received": m.FixedStopReceived.Snapshot().Count(),
-				"dropped":  m.FixedStopDropped.Snapshot().Count(),
-				"timeout":  m.FixedStopTimeout.Snapshot().Count(),
-			},
-			"Debounced_stop": map[string]int64{
-				"received": m.DebouncedStopReceived.Snapshot().Count(),
-				"dropped":  m.DebouncedStopDropped.Snapshot().Count(),
-				"timeout":  m.DebouncedStopTimeout.Snapshot().Count(),
-			},
-			"fixed_profit": map[string]int64{
-				"received": m.FixedProfitReceived.Snapshot().Count(),
-				"dropped":  m.FixedProfitDropped.Snapshot().Count(),
-				"timeout":  m.FixedProfitTimeout.Snapshot().Count(),
-			},
-			"Debounced_profit": map[string]int64{
-				"received": m.DebouncedProfitReceived.Snapshot().Count(),
-				"dropped":  m.DebouncedProfitDropped.Snapshot().Count(),
-				"timeout":  m.DebouncedProfitTimeout.Snapshot().Count(),
-			},
-			"hybrid_fixed": map[string]int64{
-				"received": m.HybridFixedReceived.Snapshot().Count(),
-				"dropped":  m.HybridFixedDropped.Snapshot().Count(),
-				"timeout":  m.HybridFixedTimeout.Snapshot().Count(),
-			},
-			"hybrid_Debounced": map[string]int64{
-				"received": m.HybridDebouncedReceived.Snapshot().Count(),
-				"dropped":  m.HybridDebouncedDropped.Snapshot().Count(),
-				"timeout":  m.HybridDebouncedTimeout.Snapshot().Count(),
-			},
-		},
+		"drop_rate_percent": m.GetDropRate(),
+		"channels":          channels,
 	}
 }
 
@@ -241,3 +170,20 @@ func (m *Metrics) GetDropRate() float64 {
 	dropped := m.TotalDropped.Snapshot()
 	return float64(dropped) / float64(received) * 100
 }
+
+// Collect exposes the metrics as Prometheus families
+func (m *Metrics) Collect() []metric.Family {
+	return append([]metric.Family{
+		metric.GaugeFamily("quant_engine_uptime_seconds", "Seconds since the engine metrics were created.", time.Since(m.StartTime).Seconds(), nil),
+		metric.CounterFamily("quant_engine_ticks_received_total", "Price updates passed to Collect.", m.TotalReceived, nil),
+		metric.CounterFamily("quant_engine_ticks_dropped_total", "Price updates lost to back-pressure or shutdown.", m.TotalDropped, nil),
+		metric.GaugeFamily("quant_engine_drop_rate_ratio", "Dropped over received price updates.", m.GetDropRate()/100, nil),
+		m.Received.Family("quant_engine_channel_received_total", "Price updates queued on a strategy channel."),
+		m.Dropped.Family("quant_engine_channel_dropped_total", "Price updates a full strategy channel dropped."),
+		m.Timeout.Family("quant_engine_channel_timeout_total", "Evaluations or results that timed out on a strategy channel."),
+		m.Evicted.Family("quant_engine_channel_evicted_total", "Queued price updates evicted by DropOldest."),
+		m.Conflated.Family("quant_engine_channel_conflated_total", "Price updates replaced by a newer price by ConflateLatest."),
+		m.PairReceived.Family("quant_engine_pair_ticks_received_total", "Price updates collected per pair."),
+		metric.InfoFamily("quant_engine_info", "Engine configuration.", m.Info),
+	}, m.Registry.Collect()...)
+}
@@ -0,0 +1,129 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package engine
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/wang900115/quant/metric"
+	"github.com/wang900115/quant/model"
+	"github.com/wang900115/quant/model/currency"
+	"github.com/wang900115/quant/model/trade"
+	"github.com/wang900115/quant/stoploss/engine/sink"
+)
+
+func TestEngine_MetricsHandler(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.BufferSize = 1
+	cfg.Backpressure = map[Inbox]Policy{InboxFixedStop: DropOldest}
+	csm := newStopEngine(t, cfg)
+	btc := model.QuotesPair{ExchangeID: model.BINANCE, Base: currency.BTCSymbol, Quote: currency.USDTSymbol, Category: trade.SPOT}
+	for i := 0; i < 4; i++ {
+		csm.CollectPair(btc, model.PricePoint{NewPrice: decimal.NewFromInt(100), UpdatedAt: time.Now()}, nil)
+	}
+
+	rec := httptest.NewRecorder()
+	csm.MetricsHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+	for _, line := range []string{
+		"quant_engine_ticks_received_total 4",
+		"quant_engine_ticks_dropped_total 3",
+		"quant_engine_drop_rate_ratio 0.75",
+		`quant_engine_channel_received_total{category="stop_loss",strategy_type="Fixed"} 4`,
+		`quant_engine_channel_evicted_total{category="stop_loss",strategy_type="Fixed"} 3`,
+		`quant_engine_pair_ticks_received_total{pair="Binance:BTC/USDT:SPOT"} 4`,
+		"# TYPE quant_engine_uptime_seconds gauge",
+		"# TYPE process_uptime_seconds gauge",
+	} {
+		if !strings.Contains(body, line) {
+			t.Errorf("missing %q in:\n%s", line, body)
+		}
+	}
+	if !strings.Contains(body, `policy_fixed_stop="drop_oldest"`) || !strings.Contains(body, `shard_by="strategy"`) {
+		t.Errorf("missing engine info labels in:\n%s", body)
+	}
+}
+
+func TestEngine_RecordsLatency(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Sink = sink.NewRing(4)
+	csm := newStopEngine(t, cfg)
+	csm.Reporter.Callback = func(interface{}) { time.Sleep(5 * time.Millisecond) }
+	if err := csm.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	csm.Collect(model.PricePoint{NewPrice: decimal.NewFromInt(101), UpdatedAt: time.Now()}, nil)
+	csm.Collect(model.PricePoint{NewPrice: decimal.NewFromInt(90), UpdatedAt: time.Now()}, nil)
+	csm.Stop()
+
+	labels := metric.Labels{"strategy": "stop-5%"}
+	decision, ok := csm.Metrics.Registry.Get("quant_engine_decision_latency_seconds", labels)
+	if !ok {
+		t.Fatal("missing decision latency timer")
+	}
+	if n := decision.(*metric.Timer).Snapshot().Count(); n != 2 {
+		t.Errorf("expected 2 decisions timed, got %d", n)
+	}
+	callback, ok := csm.Metrics.Registry.Get("quant_engine_callback_duration_seconds", labels)
+	if !ok {
+		t.Fatal("missing callback duration timer")
+	}
+	if s := callback.(*metric.Timer).Snapshot(); s.Count() != 1 || s.Sum() < 0.005 {
+		t.Errorf("expected one callback of at least 5ms, got %d totalling %vs", s.Count(), s.Sum())
+	}
+}
+
+func TestEngine_TracesDecisions(t *testing.T) {
+	ring := sink.NewRing(4)
+	cfg := DefaultConfig()
+	cfg.Sink = ring
+	csm := newStopEngine(t, cfg)
+	if err := csm.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	received := time.Now()
+	csm.Collect(model.PricePoint{
+		NewPrice:  decimal.NewFromInt(90),
+		UpdatedAt: received.Add(-20 * time.Millisecond),
+		Trace:     model.Trace{EventTime: received.Add(-20 * time.Millisecond), ReceivedAt: received, DispatchedAt: received},
+	}, nil)
+	csm.Stop()
+
+	events := ring.Events()
+	if len(events) != 1 {
+		t.Fatalf("expected one event, got %d", len(events))
+	}
+	trace := events[0].General.Trace
+	if trace.EnqueuedAt.Before(trace.DispatchedAt) || trace.DequeuedAt.Before(trace.EnqueuedAt) || trace.DecidedAt.Before(trace.DequeuedAt) {
+		t.Errorf("expected engine stamps in order, got %+v", trace)
+	}
+	if l := events[0].General.Latency(); l.Exchange != 20*time.Millisecond || l.Total < l.Exchange {
+		t.Errorf("unexpected latency %+v", l)
+	}
+
+	for _, hop := range model.Hops {
+		if _, ok := csm.Metrics.Registry.Get("quant_engine_hop_latency_seconds", metric.Labels{"hop": string(hop)}); !ok {
+			t.Errorf("missing %s hop latency", hop)
+		}
+	}
+	lag, ok := csm.Metrics.Registry.Get("quant_engine_exchange_lag_seconds", nil)
+	if !ok || lag.(*metric.Gauge).Snapshot().Value() != 0.02 {
+		t.Errorf("expected exchange lag of 20ms, got %v", lag)
+	}
+}
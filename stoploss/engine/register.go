@@ -19,14 +19,54 @@ import (
 	"github.com/wang900115/quant/stoploss"
 )
 
+// entry is a registered strategy, pair is empty when it evaluates every tick
+type entry[T any] struct {
+	name     string
+	pair     string
+	strategy T
+}
+
+// accepts reports whether the strategy should see a tick collected for pair
+func (e entry[T]) accepts(pair string) bool {
+	return e.pair == "" || e.pair == pair
+}
+
+// key returns the shard key of the strategy
+func (e entry[T]) key(by ShardKey) string {
+	if by == ShardByPair && e.pair != "" {
+		return e.pair
+	}
+	return e.name
+}
+
+// upsert replaces a strategy registered under the same name or appends it, keeping registration order
+func upsert[T any](entries []entry[T], e entry[T]) ([]entry[T], bool) {
+	for i := range entries {
+		if entries[i].name == e.name {
+			entries[i] = e
+			return entries, false
+		}
+	}
+	return append(entries, e), true
+}
+
+func toMap[T any](entries []entry[T]) map[string]T {
+	copyMap := make(map[string]T, len(entries))
+	for _, e := range entries {
+		copyMap[e.name] = e.strategy
+	}
+	return copyMap
+}
+
 type Portfolio struct {
 	mutex                         sync.Mutex
-	fixedStoplossStrategies       map[string]stoploss.FixedStopLoss
-	DebouncedStoplossStrategies   map[string]stoploss.DebouncedStopLoss
-	fixedTakeProfitStrategies     map[string]stoploss.FixedTakeProfit
-	DebouncedTakeProfitStrategies map[string]stoploss.DebouncedTakeProfit
-	hybridFixedStrategies         map[string]stoploss.HybridWithoutTime
-	hybridDebouncedStrategies     map[string]stoploss.HybridWithTime
+	fixedStoplossStrategies       []entry[stoploss.FixedStopLoss]
+	DebouncedStoplossStrategies   []entry[stoploss.DebouncedStopLoss]
+	fixedTakeProfitStrategies     []entry[stoploss.FixedTakeProfit]
+	DebouncedTakeProfitStrategies []entry[stoploss.DebouncedTakeProfit]
+	hybridFixedStrategies         []entry[stoploss.HybridWithoutTime]
+	hybridDebouncedStrategies     []entry[stoploss.HybridWithTime]
+	guards                        []entry[stoploss.AccountGuard]
 	openGeneral                   bool
 	openHybrid                    bool
 	count                         int
@@ -34,69 +74,113 @@ type Portfolio struct {
 
 func NewPortfolio() *Portfolio {
 	return &Portfolio{
-		fixedStoplossStrategies:       make(map[string]stoploss.FixedStopLoss),
-		DebouncedStoplossStrategies:   make(map[string]stoploss.DebouncedStopLoss),
-		fixedTakeProfitStrategies:     make(map[string]stoploss.FixedTakeProfit),
-		DebouncedTakeProfitStrategies: make(map[string]stoploss.DebouncedTakeProfit),
-		hybridFixedStrategies:         make(map[string]stoploss.HybridWithoutTime),
-		hybridDebouncedStrategies:     make(map[string]stoploss.HybridWithTime),
-		openGeneral:                   false,
-		openHybrid:                    false,
-		count:                         0,
+		openGeneral: false,
+		openHybrid:  false,
+		count:       0,
 	}
 }
 
 func (p *Portfolio) RegistFixedStoplossStrategy(name string, strategy stoploss.FixedStopLoss) {
+	p.registFixedStoploss(name, "", strategy)
+}
+
+func (p *Portfolio) RegistDebouncedStoplossStrategy(name string, strategy stoploss.DebouncedStopLoss) {
+	p.registDebouncedStoploss(name, "", strategy)
+}
+
+func (p *Portfolio) RegistFixedTakeProfitStrategy(name string, strategy stoploss.FixedTakeProfit) {
+	p.registFixedTakeProfit(name, "", strategy)
+}
+
+func (p *Portfolio) RegistDebouncedTakeProfitStrategy(name string, strategy stoploss.DebouncedTakeProfit) {
+	p.registDebouncedTakeProfit(name, "", strategy)
+}
+
+func (p *Portfolio) RegistHybridFixedStrategy(name string, strategy stoploss.HybridWithoutTime) {
+	p.registHybridFixed(name, "", strategy)
+}
+
+func (p *Portfolio) RegistHybridDebouncedStrategy(name string, strategy stoploss.HybridWithTime) {
+	p.registHybridDebounced(name, "", strategy)
+}
+
+func (p *Portfolio) RegistGuard(name string, guard stoploss.AccountGuard) {
+	p.registGuard(name, "", guard)
+}
+
+func (p *Portfolio) registFixedStoploss(name, pair string, strategy stoploss.FixedStopLoss) {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 
-	p.fixedStoplossStrategies[name] = strategy
-	p.openGeneral = true
-	p.count++
+	var added bool
+	p.fixedStoplossStrategies, added = upsert(p.fixedStoplossStrategies, entry[stoploss.FixedStopLoss]{name, pair, strategy})
+	p.added(added, false)
 }
 
-func (p *Portfolio) RegistDebouncedStoplossStrategy(name string, strategy stoploss.DebouncedStopLoss) {
+func (p *Portfolio) registDebouncedStoploss(name, pair string, strategy stoploss.DebouncedStopLoss) {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 
-	p.DebouncedStoplossStrategies[name] = strategy
-	p.openGeneral = true
-	p.count++
+	var added bool
+	p.DebouncedStoplossStrategies, added = upsert(p.DebouncedStoplossStrategies, entry[stoploss.DebouncedStopLoss]{name, pair, strategy})
+	p.added(added, false)
 }
 
-func (p *Portfolio) RegistFixedTakeProfitStrategy(name string, strategy stoploss.FixedTakeProfit) {
+func (p *Portfolio) registFixedTakeProfit(name, pair string, strategy stoploss.FixedTakeProfit) {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 
-	p.fixedTakeProfitStrategies[name] = strategy
-	p.openGeneral = true
-	p.count++
+	var added bool
+	p.fixedTakeProfitStrategies, added = upsert(p.fixedTakeProfitStrategies, entry[stoploss.FixedTakeProfit]{name, pair, strategy})
+	p.added(added, false)
 }
 
-func (p *Portfolio) RegistDebouncedTakeProfitStrategy(name string, strategy stoploss.DebouncedTakeProfit) {
+func (p *Portfolio) registDebouncedTakeProfit(name, pair string, strategy stoploss.DebouncedTakeProfit) {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 
-	p.DebouncedTakeProfitStrategies[name] = strategy
-	p.openGeneral = true
-	p.count++
+	var added bool
+	p.DebouncedTakeProfitStrategies, added = upsert(p.DebouncedTakeProfitStrategies, entry[stoploss.DebouncedTakeProfit]{name, pair, strategy})
+	p.added(added, false)
 }
 
-func (p *Portfolio) RegistHybridFixedStrategy(name string, strategy stoploss.HybridWithoutTime) {
+func (p *Portfolio) registHybridFixed(name, pair string, strategy stoploss.HybridWithoutTime) {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 
-	p.hybridFixedStrategies[name] = strategy
-	p.openHybrid = true
-	p.count++
+	var added bool
+	p.hybridFixedStrategies, added = upsert(p.hybridFixedStrategies, entry[stoploss.HybridWithoutTime]{name, pair, strategy})
+	p.added(added, true)
 }
 
-func (p *Portfolio) RegistHybridDebouncedStrategy(name string, strategy stoploss.HybridWithTime) {
+func (p *Portfolio) registHybridDebounced(name, pair string, strategy stoploss.HybridWithTime) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	var added bool
+	p.hybridDebouncedStrategies, added = upsert(p.hybridDebouncedStrategies, entry[stoploss.HybridWithTime]{name, pair, strategy})
+	p.added(added, true)
+}
+
+func (p *Portfolio) registGuard(name, pair string, guard stoploss.AccountGuard) {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
-	p.hybridDebouncedStrategies[name] = strategy
-	p.openHybrid = true
-	p.count++
+
+	var added bool
+	p.guards, added = upsert(p.guards, entry[stoploss.AccountGuard]{name, pair, guard})
+	p.added(added, false)
+}
+
+// added updates the bookkeeping after a registration, callers hold the mutex
+func (p *Portfolio) added(added, hybrid bool) {
+	if hybrid {
+		p.openHybrid = true
+	} else {
+		p.openGeneral = true
+	}
+	if added {
+		p.count++
+	}
 }
 
 func (p *Portfolio) GetFixedStoplossStrategies() map[string]stoploss.FixedStopLoss {
@@ -104,11 +188,7 @@ func (p *Portfolio) GetFixedStoplossStrategies() map[string]stoploss.FixedStopLo
 	defer p.mutex.Unlock()
 
 	// Return a copy to avoid race conditions
-	copyMap := make(map[string]stoploss.FixedStopLoss)
-	for k, v := range p.fixedStoplossStrategies {
-		copyMap[k] = v
-	}
-	return copyMap
+	return toMap(p.fixedStoplossStrategies)
 }
 
 func (p *Portfolio) GetDebouncedStoplossStrategies() map[string]stoploss.DebouncedStopLoss {
@@ -116,11 +196,7 @@ func (p *Portfolio) GetDebouncedStoplossStrategies() map[string]stoploss.Debounc
 	defer p.mutex.Unlock()
 
 	// Return a copy
-	copyMap := make(map[string]stoploss.DebouncedStopLoss)
-	for k, v := range p.DebouncedStoplossStrategies {
-		copyMap[k] = v
-	}
-	return copyMap
+	return toMap(p.DebouncedStoplossStrategies)
 }
 
 func (p *Portfolio) GetFixedTakeProfitStrategies() map[string]stoploss.FixedTakeProfit {
@@ -128,11 +204,7 @@ func (p *Portfolio) GetFixedTakeProfitStrategies() map[string]stoploss.FixedTake
 	defer p.mutex.Unlock()
 
 	// Return a copy to avoid race conditions
-	copyMap := make(map[string]stoploss.FixedTakeProfit)
-	for k, v := range p.fixedTakeProfitStrategies {
-		copyMap[k] = v
-	}
-	return copyMap
+	return toMap(p.fixedTakeProfitStrategies)
 }
 
 func (p *Portfolio) GetDebouncedTakeProfitStrategies() map[string]stoploss.DebouncedTakeProfit {
@@ -140,11 +212,7 @@ func (p *Portfolio) GetDebouncedTakeProfitStrategies() map[string]stoploss.Debou
 	defer p.mutex.Unlock()
 
 	// Return a copy to avoid race conditions
-	copyMap := make(map[string]stoploss.DebouncedTakeProfit)
-	for k, v := range p.DebouncedTakeProfitStrategies {
-		copyMap[k] = v
-	}
-	return copyMap
+	return toMap(p.DebouncedTakeProfitStrategies)
 }
 
 func (p *Portfolio) GetHybridStrategies() map[string]stoploss.HybridWithoutTime {
@@ -152,11 +220,7 @@ func (p *Portfolio) GetHybridStrategies() map[string]stoploss.HybridWithoutTime
 	defer p.mutex.Unlock()
 
 	// Return a copy to avoid race conditions
-	copyMap := make(map[string]stoploss.HybridWithoutTime)
-	for k, v := range p.hybridFixedStrategies {
-		copyMap[k] = v
-	}
-	return copyMap
+	return toMap(p.hybridFixedStrategies)
 }
 
 func (p *Portfolio) GetHybridDebouncedStrategies() map[string]stoploss.HybridWithTime {
@@ -164,9 +228,57 @@ func (p *Portfolio) GetHybridDebouncedStrategies() map[string]stoploss.HybridWit
 	defer p.mutex.Unlock()
 
 	// Return a copy to avoid race conditions
-	copyMap := make(map[string]stoploss.HybridWithTime)
-	for k, v := range p.hybridDebouncedStrategies {
-		copyMap[k] = v
-	}
-	return copyMap
+	return toMap(p.hybridDebouncedStrategies)
+}
+
+func (p *Portfolio) GetGuards() map[string]stoploss.AccountGuard {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	// Return a copy to avoid race conditions
+	return toMap(p.guards)
+}
+
+// The ordered getters return a copy in registration order, used for evaluation
+
+func (p *Portfolio) fixedStoplossEntries() []entry[stoploss.FixedStopLoss] {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return append([]entry[stoploss.FixedStopLoss](nil), p.fixedStoplossStrategies...)
+}
+
+func (p *Portfolio) debouncedStoplossEntries() []entry[stoploss.DebouncedStopLoss] {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return append([]entry[stoploss.DebouncedStopLoss](nil), p.DebouncedStoplossStrategies...)
+}
+
+func (p *Portfolio) fixedTakeProfitEntries() []entry[stoploss.FixedTakeProfit] {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return append([]entry[stoploss.FixedTakeProfit](nil), p.fixedTakeProfitStrategies...)
+}
+
+func (p *Portfolio) debouncedTakeProfitEntries() []entry[stoploss.DebouncedTakeProfit] {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return append([]entry[stoploss.DebouncedTakeProfit](nil), p.DebouncedTakeProfitStrategies...)
+}
+
+func (p *Portfolio) hybridFixedEntries() []entry[stoploss.HybridWithoutTime] {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return append([]entry[stoploss.HybridWithoutTime](nil), p.hybridFixedStrategies...)
+}
+
+func (p *Portfolio) hybridDebouncedEntries() []entry[stoploss.HybridWithTime] {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return append([]entry[stoploss.HybridWithTime](nil), p.hybridDebouncedStrategies...)
+}
+
+func (p *Portfolio) guardEntries() []entry[stoploss.AccountGuard] {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return append([]entry[stoploss.AccountGuard](nil), p.guards...)
 }
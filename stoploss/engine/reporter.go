@@ -15,18 +15,31 @@ package engine
 
 import (
 	"context"
-	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
 
 	"github.com/wang900115/quant/metric"
 	"github.com/wang900115/quant/model/result"
+	"github.com/wang900115/quant/stoploss/engine/sink"
 )
 
+// Report turns strategy results into sink events and keeps counters over them
 type Report struct {
 	generalCount metric.CounterInt64
 	hybridCount  metric.CounterInt64
 	triggerCount metric.CounterInt64
 	errorCount   metric.CounterInt64
-	Callback     func(interface{})
+	ratchetCount metric.CounterInt64
+	// emitErrorCount counts events the sink failed to take
+	emitErrorCount metric.CounterInt64
+	Callback       func(interface{})
+	// Sink receives every result as an event, nil logs them through slog
+	Sink sink.Sink
+	// Metrics records the callback duration when set
+	Metrics *Metrics
+	// inFlight counts results sent by workers and not yet reported, it may dip below zero until the sender counts
+	inFlight atomic.Int64
 }
 
 func NewReport(Callback func(interface{})) *Report {
@@ -35,82 +48,101 @@ func NewReport(Callback func(interface{})) *Report {
 	}
 }
 
+func (rp *Report) sink() sink.Sink {
+	if rp.Sink == nil {
+		return sink.NewSlog(nil)
+	}
+	return rp.Sink
+}
+
 func (rp *Report) ProcessGeneralResult(res <-chan result.StrategyGeneralResult, ctx context.Context) {
 	rp.generalCount.Inc(1)
+	out := rp.sink()
 	for {
 		select {
 		case <-ctx.Done():
-			fmt.Println("🔄 GeneralResult processor shutting down...")
 			return
 		case r, ok := <-res:
 			if !ok {
-				fmt.Println("🔄 GeneralResult channel closed")
 				return
 			}
-
-			if r.Error != nil {
-				rp.errorCount.Inc(1)
-				fmt.Printf("🔴 ERROR in %s (%s): %v\n",
-					r.StrategyName, r.StrategyType, r.Error)
-				continue
-			}
-
-			if r.Triggered {
-				rp.triggerCount.Inc(1)
-				fmt.Printf("🔔 TRIGGER: %s (%s) - %s at price %s\n",
-					r.StrategyName, r.StrategyType, r.TriggerType,
-					r.LastPrice.String())
-				if rp.Callback != nil {
-					rp.Callback(r)
-				}
-			} else {
-				fmt.Printf("📊 UPDATE: %s (%s) - threshold: %s, price: %s\n",
-					r.StrategyName, r.StrategyType,
-					r.Stat.PriceThreshold.String(), r.LastPrice.String())
+			e := sink.FromGeneral(r)
+			rp.count(e)
+			rp.emit(out, e)
+			if e.Type == sink.EventTrigger {
+				rp.callback(r.StrategyName, r)
 			}
+			rp.inFlight.Add(-1)
 		}
 	}
 }
 
 func (rp *Report) ProcessHybridResult(res <-chan result.StrategyHybridResult, ctx context.Context) {
 	rp.hybridCount.Inc(1)
+	out := rp.sink()
 	for {
 		select {
 		case <-ctx.Done():
-			fmt.Println("🔄 HybridResult processor shutting down...")
 			return
 		case r, ok := <-res:
 			if !ok {
-				fmt.Println("🔄 HybridResult channel closed")
 				return
 			}
-
-			if r.Error != nil {
-				rp.errorCount.Inc(1)
-				fmt.Printf("🔴 HYBRID ERROR in %s: %v\n", r.StrategyName, r.Error)
-				continue
-			}
-
-			if r.Triggered {
-				rp.triggerCount.Inc(1)
-				fmt.Printf("🔔 HYBRID TRIGGER: %s at price %s stoploss at %s take profit at %s\n",
-					r.StrategyName, r.LastPrice.String(), r.StopStat.PriceThreshold.String(), r.ProfitStat.PriceThreshold.String())
-				if rp.Callback != nil {
-					rp.Callback(r)
-				}
-			} else {
-				fmt.Printf("📊 HYBRID UPDATE: %s at price %s stoploss at %s take profit at %s\n",
-					r.StrategyName, r.LastPrice.String(), r.StopStat.PriceThreshold.String(), r.ProfitStat.PriceThreshold.String())
+			e := sink.FromHybrid(r)
+			rp.count(e)
+			rp.emit(out, e)
+			if e.Type == sink.EventTrigger {
+				rp.callback(r.StrategyName, r)
 			}
+			rp.inFlight.Add(-1)
 		}
 	}
 }
 
+func (rp *Report) emit(out sink.Sink, e sink.Event) {
+	if err := out.Emit(e); err != nil {
+		rp.emitErrorCount.Inc(1)
+		log.Printf("[Report] %s: emit %s event: %v", e.Strategy, e.Type, err)
+	}
+}
+
+func (rp *Report) callback(strategy string, r interface{}) {
+	if rp.Callback == nil {
+		return
+	}
+	start := time.Now()
+	rp.Callback(r)
+	if rp.Metrics != nil {
+		rp.Metrics.RecordCallback(strategy, time.Since(start))
+	}
+}
+
+func (rp *Report) count(e sink.Event) {
+	switch e.Type {
+	case sink.EventError:
+		rp.errorCount.Inc(1)
+	case sink.EventTrigger:
+		rp.triggerCount.Inc(1)
+	case sink.EventRatchet:
+		rp.ratchetCount.Inc(1)
+	}
+}
+
+// Close closes the sink, flushing buffered sinks
+func (rp *Report) Close() error {
+	if rp.Sink == nil {
+		return nil
+	}
+	return rp.Sink.Close()
+}
+
 func (rp *Report) Stats() map[string]int64 {
 	return map[string]int64{
 		"general_results": rp.generalCount.Snapshot().Count(),
 		"hybrid_results":  rp.hybridCount.Snapshot().Count(),
 		"triggers":        rp.triggerCount.Snapshot().Count(),
 		"errors":          rp.errorCount.Snapshot().Count(),
+		"ratchets":        rp.ratchetCount.Snapshot().Count(),
+		"emit_errors":     rp.emitErrorCount.Snapshot().Count(),
 	}
 }
@@ -0,0 +1,182 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package sink
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+)
+
+// Slog writes events as structured log records, updates at debug, triggers at info and errors at error level
+type Slog struct {
+	logger *slog.Logger
+}
+
+// NewSlog logs to logger, nil selects slog.Default
+func NewSlog(logger *slog.Logger) *Slog {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Slog{logger: logger}
+}
+
+func (s *Slog) Emit(e Event) error {
+	attrs := []any{slog.String("strategy", e.Strategy), slog.Time("time", e.Time)}
+	if e.Pair != "" {
+		attrs = append(attrs, slog.String("pair", e.Pair))
+	}
+	switch {
+	case e.General != nil:
+		attrs = append(attrs,
+			slog.String("strategy_type", e.General.StrategyType.String()),
+			slog.String("trigger_type", e.General.TriggerType.String()),
+			slog.String("price", e.General.LastPrice.String()),
+			slog.String("threshold", e.General.Stat.PriceThreshold.String()),
+		)
+		if x := e.General.Exit; x != nil {
+			attrs = append(attrs,
+				slog.Int("rung", x.Rung),
+				slog.String("fraction", x.Fraction.String()),
+				slog.String("quantity", x.Quantity.String()),
+				slog.String("reason", x.Reason),
+			)
+		}
+	case e.Hybrid != nil:
+		attrs = append(attrs,
+			slog.String("strategy_type", e.Hybrid.StrategyType.String()),
+			slog.String("trigger_type", e.Hybrid.TriggerType.String()),
+			slog.String("price", e.Hybrid.LastPrice.String()),
+			slog.String("stop_threshold", e.Hybrid.StopStat.PriceThreshold.String()),
+			slog.String("profit_threshold", e.Hybrid.ProfitStat.PriceThreshold.String()),
+		)
+	}
+	switch e.Type {
+	case EventError:
+		s.logger.Error("strategy error", append(attrs, slog.Any("error", e.Err()))...)
+	case EventTrigger:
+		s.logger.Info("strategy trigger", attrs...)
+	case EventRatchet:
+		for _, r := range e.General.Ratchets {
+			s.logger.Info("strategy ratchet", append(attrs, slog.String("from", r.From.String()), slog.String("to", r.To.String()), slog.String("reason", r.Reason))...)
+		}
+	default:
+		s.logger.Debug("strategy update", attrs...)
+	}
+	return nil
+}
+
+func (s *Slog) Close() error {
+	return nil
+}
+
+// JSONL writes one JSON object per event
+type JSONL struct {
+	mu     sync.Mutex
+	w      *bufio.Writer
+	closer io.Closer
+}
+
+// NewJSONL writes events to w, closing it on Close when it is an io.Closer
+func NewJSONL(w io.Writer) *JSONL {
+	j := &JSONL{w: bufio.NewWriter(w)}
+	if c, ok := w.(io.Closer); ok {
+		j.closer = c
+	}
+	return j
+}
+
+// OpenJSONL appends events to the file at path, creating it when missing
+func OpenJSONL(path string) (*JSONL, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return NewJSONL(f), nil
+}
+
+func (j *JSONL) Emit(e Event) error {
+	return j.write(e)
+}
+
+func (j *JSONL) write(v any) error {
+	line, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if _, err := j.w.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return j.w.Flush()
+}
+
+func (j *JSONL) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err := j.w.Flush(); err != nil {
+		return err
+	}
+	if j.closer != nil {
+		return j.closer.Close()
+	}
+	return nil
+}
+
+// Ring keeps the most recent events in memory, for UIs polling the latest activity
+type Ring struct {
+	mu     sync.Mutex
+	events []Event
+	next   int
+	full   bool
+}
+
+// NewRing keeps up to capacity events, zero selects 1024
+func NewRing(capacity int) *Ring {
+	if capacity <= 0 {
+		capacity = 1024
+	}
+	return &Ring{events: make([]Event, capacity)}
+}
+
+func (r *Ring) Emit(e Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events[r.next] = e
+	r.next = (r.next + 1) % len(r.events)
+	if r.next == 0 {
+		r.full = true
+	}
+	return nil
+}
+
+// Events returns the kept events from oldest to newest
+func (r *Ring) Events() []Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.full {
+		return append([]Event(nil), r.events[:r.next]...)
+	}
+	out := make([]Event, 0, len(r.events))
+	out = append(out, r.events[r.next:]...)
+	return append(out, r.events[:r.next]...)
+}
+
+func (r *Ring) Close() error {
+	return nil
+}
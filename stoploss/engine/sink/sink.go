@@ -0,0 +1,296 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package sink
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/wang900115/quant/model"
+	"github.com/wang900115/quant/model/result"
+)
+
+var ErrClosed = errors.New("sink: closed")
+
+// EventType tells what a strategy result means for the consumer
+type EventType string
+
+const (
+	// EventUpdate is an evaluation that moved thresholds without triggering
+	EventUpdate EventType = "update"
+	// EventTrigger is an evaluation that hit a stop loss or take profit
+	EventTrigger EventType = "trigger"
+	// EventError is an evaluation that failed
+	EventError EventType = "error"
+	// EventRatchet is an evaluation where the strategy moved its own stop, such as to break-even
+	EventRatchet EventType = "ratchet"
+)
+
+// Event is one strategy result, exactly one of General and Hybrid is set
+type Event struct {
+	Type     EventType
+	Strategy string
+	Pair     string
+	Time     time.Time
+	General  *result.StrategyGeneralResult
+	Hybrid   *result.StrategyHybridResult
+}
+
+// FromGeneral builds the event of a general result
+func FromGeneral(r result.StrategyGeneralResult) Event {
+	typ := eventType(r.Triggered, r.Error)
+	if typ == EventUpdate && len(r.Ratchets) > 0 {
+		typ = EventRatchet
+	}
+	return Event{Type: typ, Strategy: r.StrategyName, Pair: r.Pair, Time: r.LastTime, General: &r}
+}
+
+// FromHybrid builds the event of a hybrid result
+func FromHybrid(r result.StrategyHybridResult) Event {
+	return Event{Type: eventType(r.Triggered, r.Error), Strategy: r.StrategyName, Pair: r.Pair, Time: r.LastTime, Hybrid: &r}
+}
+
+func eventType(triggered bool, err error) EventType {
+	switch {
+	case err != nil:
+		return EventError
+	case triggered:
+		return EventTrigger
+	}
+	return EventUpdate
+}
+
+// Err returns the evaluation error carried by the result
+func (e Event) Err() error {
+	if e.General != nil {
+		return e.General.Error
+	}
+	if e.Hybrid != nil {
+		return e.Hybrid.Error
+	}
+	return nil
+}
+
+// Trace returns the latency trace carried by the result
+func (e Event) Trace() model.Trace {
+	if e.General != nil {
+		return e.General.Trace
+	}
+	if e.Hybrid != nil {
+		return e.Hybrid.Trace
+	}
+	return model.Trace{}
+}
+
+func (e Event) MarshalJSON() ([]byte, error) {
+	out := map[string]interface{}{
+		"type":     e.Type,
+		"strategy": e.Strategy,
+		"pair":     e.Pair,
+		"time":     e.Time,
+	}
+	switch {
+	case e.General != nil:
+		out["strategy_type"] = e.General.StrategyType
+		out["trigger_type"] = e.General.TriggerType
+		out["price"] = e.General.LastPrice
+		out["threshold"] = e.General.Stat.PriceThreshold
+		if len(e.General.Ratchets) > 0 {
+			out["ratchets"] = e.General.Ratchets
+		}
+		if e.General.Exit != nil {
+			out["exit"] = e.General.Exit
+		}
+	case e.Hybrid != nil:
+		out["strategy_type"] = e.Hybrid.StrategyType
+		out["trigger_type"] = e.Hybrid.TriggerType
+		out["price"] = e.Hybrid.LastPrice
+		out["stop_threshold"] = e.Hybrid.StopStat.PriceThreshold
+		out["profit_threshold"] = e.Hybrid.ProfitStat.PriceThreshold
+	}
+	if err := e.Err(); err != nil {
+		out["error"] = err.Error()
+	}
+	return json.Marshal(out)
+}
+
+// Sink consumes strategy events
+type Sink interface {
+	Emit(e Event) error
+	Close() error
+}
+
+// Filter selects the events a sink receives
+type Filter func(e Event) bool
+
+// ByStrategy passes events of the named strategies
+func ByStrategy(names ...string) Filter {
+	return func(e Event) bool { return slices.Contains(names, e.Strategy) }
+}
+
+// ByPair passes events of the given pair keys, as reported in Event.Pair
+func ByPair(pairs ...string) Filter {
+	return func(e Event) bool { return slices.Contains(pairs, e.Pair) }
+}
+
+// ByType passes events of the given types
+func ByType(types ...EventType) Filter {
+	return func(e Event) bool { return slices.Contains(types, e.Type) }
+}
+
+// Buffered decouples a sink behind its own queue, events are dropped while the queue is full.
+// Errors of the underlying sink are logged and counted, as no caller is left to return them to.
+type Buffered struct {
+	sink    Sink
+	ch      chan Event
+	done    chan struct{}
+	mu      sync.RWMutex
+	closed  bool
+	dropped atomic.Int64
+	failed  atomic.Int64
+}
+
+// NewBuffered starts delivering to sink from a queue of size events
+func NewBuffered(sink Sink, size int) *Buffered {
+	if size <= 0 {
+		size = 1
+	}
+	b := &Buffered{
+		sink: sink,
+		ch:   make(chan Event, size),
+		done: make(chan struct{}),
+	}
+	go b.run()
+	return b
+}
+
+func (b *Buffered) run() {
+	defer close(b.done)
+	for e := range b.ch {
+		if err := b.sink.Emit(e); err != nil {
+			b.failed.Add(1)
+			log.Printf("[sink] %s: emit %s event: %v", e.Strategy, e.Type, err)
+		}
+	}
+}
+
+func (b *Buffered) Emit(e Event) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return ErrClosed
+	}
+	select {
+	case b.ch <- e:
+	default:
+		b.dropped.Add(1)
+	}
+	return nil
+}
+
+// Dropped returns how many events were discarded because the queue was full
+func (b *Buffered) Dropped() int64 {
+	return b.dropped.Load()
+}
+
+// Failed returns how many queued events the underlying sink failed to emit
+func (b *Buffered) Failed() int64 {
+	return b.failed.Load()
+}
+
+// Close delivers the queued events and closes the underlying sink
+func (b *Buffered) Close() error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	close(b.ch)
+	b.mu.Unlock()
+	<-b.done
+	return b.sink.Close()
+}
+
+// Route is one destination of a FanOut, a nil Filter passes every event
+type Route struct {
+	Sink   Sink
+	Filter Filter
+	// Buffer is the queue size of the route, zero selects 256
+	Buffer int
+}
+
+// FanOut delivers every event to the routes whose filter accepts it, each route has its own queue
+type FanOut struct {
+	routes  []Route
+	buffers []*Buffered
+}
+
+func NewFanOut(routes ...Route) *FanOut {
+	f := &FanOut{routes: routes, buffers: make([]*Buffered, len(routes))}
+	for i, r := range routes {
+		size := r.Buffer
+		if size <= 0 {
+			size = 256
+		}
+		f.buffers[i] = NewBuffered(r.Sink, size)
+	}
+	return f
+}
+
+func (f *FanOut) Emit(e Event) error {
+	var errs []error
+	for i, r := range f.routes {
+		if r.Filter != nil && !r.Filter(e) {
+			continue
+		}
+		if err := f.buffers[i].Emit(e); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Dropped returns the events each route discarded, in route order
+func (f *FanOut) Dropped() []int64 {
+	dropped := make([]int64, len(f.buffers))
+	for i, b := range f.buffers {
+		dropped[i] = b.Dropped()
+	}
+	return dropped
+}
+
+// Failed returns the events each route's sink failed to emit, in route order
+func (f *FanOut) Failed() []int64 {
+	failed := make([]int64, len(f.buffers))
+	for i, b := range f.buffers {
+		failed[i] = b.Failed()
+	}
+	return failed
+}
+
+func (f *FanOut) Close() error {
+	var errs []error
+	for _, b := range f.buffers {
+		if err := b.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,243 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package sink
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/wang900115/quant/model"
+	"github.com/wang900115/quant/model/result"
+)
+
+func general(name, pair string, price int64, triggered bool, err error) Event {
+	r := result.NewGeneral(name, model.FIXED, model.STOP_LOSS, decimal.NewFromInt(price), decimal.NewFromInt(95), time.Unix(0, 0).UTC(), 0)
+	r.Pair = pair
+	r.SetTriggered(triggered)
+	r.SetError(err)
+	return FromGeneral(*r)
+}
+
+func TestFromGeneral_Type(t *testing.T) {
+	cases := []struct {
+		e    Event
+		want EventType
+	}{
+		{general("a", "", 100, false, nil), EventUpdate},
+		{general("a", "", 90, true, nil), EventTrigger},
+		{general("a", "", 90, true, errors.New("boom")), EventError},
+	}
+	for _, c := range cases {
+		if c.e.Type != c.want {
+			t.Errorf("expected %s, got %s", c.want, c.e.Type)
+		}
+	}
+}
+
+func TestFromGeneral_Ratchet(t *testing.T) {
+	r := result.NewGeneral("be", model.FIXED, model.STOP_LOSS, decimal.NewFromInt(104), decimal.NewFromInt(100), time.Unix(0, 0).UTC(), 0)
+	r.Ratchets = []result.Ratchet{{From: decimal.NewFromInt(95), To: decimal.NewFromInt(100), Reason: "break-even"}}
+	e := FromGeneral(*r)
+	if e.Type != EventRatchet {
+		t.Fatalf("expected %s, got %s", EventRatchet, e.Type)
+	}
+	b, err := json.Marshal(e)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if !strings.Contains(string(b), `"ratchets":[{"From":"95","To":"100","Reason":"break-even"}]`) {
+		t.Fatalf("expected the ratchets in the JSON, got %s", b)
+	}
+
+	// a trigger on the same tick wins over the ratchet
+	r.SetTriggered(true)
+	if e := FromGeneral(*r); e.Type != EventTrigger {
+		t.Fatalf("expected %s, got %s", EventTrigger, e.Type)
+	}
+}
+
+func TestEvent_MarshalsPartialExit(t *testing.T) {
+	r := result.NewGeneral("ladder", model.FIXED, model.TAKE_PROFIT, decimal.NewFromInt(102), decimal.NewFromInt(105), time.Unix(0, 0).UTC(), 0)
+	r.SetTriggered(true)
+	r.Exit = &result.PartialExit{Rung: 1, Target: decimal.NewFromInt(102), Fraction: decimal.NewFromFloat(0.5), Quantity: decimal.NewFromInt(1), Reason: "target"}
+	e := FromGeneral(*r)
+	if e.Type != EventTrigger {
+		t.Fatalf("expected %s, got %s", EventTrigger, e.Type)
+	}
+	b, err := json.Marshal(e)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if !strings.Contains(string(b), `"exit":{"Rung":1,"Target":"102","Fraction":"0.5","Quantity":"1","Reason":"target"}`) {
+		t.Fatalf("expected the exit in the JSON, got %s", b)
+	}
+}
+
+func TestRing(t *testing.T) {
+	r := NewRing(3)
+	for i := int64(1); i <= 5; i++ {
+		r.Emit(general("s", "", i, false, nil))
+	}
+	events := r.Events()
+	if len(events) != 3 {
+		t.Fatalf("expected 3 events, got %d", len(events))
+	}
+	for i, e := range events {
+		if got := e.General.LastPrice.IntPart(); got != int64(i+3) {
+			t.Errorf("event %d: expected price %d, got %d", i, i+3, got)
+		}
+	}
+}
+
+func TestJSONL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	j, err := OpenJSONL(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	j.Emit(general("stop", "Binance:BTC/USDT:SPOT", 90, true, nil))
+	j.Emit(general("stop", "", 80, false, errors.New("boom")))
+	if err := j.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d: %q", len(lines), data)
+	}
+	var first, second map[string]interface{}
+	json.Unmarshal([]byte(lines[0]), &first)
+	json.Unmarshal([]byte(lines[1]), &second)
+	if first["type"] != "trigger" || first["pair"] != "Binance:BTC/USDT:SPOT" || first["price"] != "90" {
+		t.Errorf("unexpected first line %v", first)
+	}
+	if second["type"] != "error" || second["error"] != "boom" {
+		t.Errorf("unexpected second line %v", second)
+	}
+}
+
+func TestSlog(t *testing.T) {
+	var buf bytes.Buffer
+	s := NewSlog(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo})))
+	s.Emit(general("quiet", "", 100, false, nil))
+	s.Emit(general("loud", "", 90, true, nil))
+
+	out := buf.String()
+	if strings.Contains(out, "quiet") {
+		t.Errorf("update should log at debug level: %s", out)
+	}
+	if !strings.Contains(out, `"msg":"strategy trigger"`) || !strings.Contains(out, `"strategy":"loud"`) {
+		t.Errorf("missing trigger record: %s", out)
+	}
+}
+
+func TestFanOut_Filters(t *testing.T) {
+	all, triggers, btc := NewRing(10), NewRing(10), NewRing(10)
+	f := NewFanOut(
+		Route{Sink: all},
+		Route{Sink: triggers, Filter: ByType(EventTrigger)},
+		Route{Sink: btc, Filter: ByPair("Binance:BTC/USDT:SPOT")},
+	)
+	f.Emit(general("a", "Binance:BTC/USDT:SPOT", 100, false, nil))
+	f.Emit(general("b", "Binance:ETH/USDT:SPOT", 90, true, nil))
+	if err := f.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	if n := len(all.Events()); n != 2 {
+		t.Errorf("expected 2 events on unfiltered route, got %d", n)
+	}
+	if e := triggers.Events(); len(e) != 1 || e[0].Strategy != "b" {
+		t.Errorf("unexpected trigger route events %+v", e)
+	}
+	if e := btc.Events(); len(e) != 1 || e[0].Strategy != "a" {
+		t.Errorf("unexpected pair route events %+v", e)
+	}
+	if err := f.Emit(general("c", "", 1, false, nil)); !errors.Is(err, ErrClosed) {
+		t.Errorf("expected ErrClosed after Close, got %v", err)
+	}
+}
+
+// blockingSink holds every Emit until released
+type blockingSink struct {
+	release chan struct{}
+	once    sync.Once
+}
+
+func (b *blockingSink) Emit(Event) error { <-b.release; return nil }
+func (b *blockingSink) Close() error     { b.once.Do(func() { close(b.release) }); return nil }
+
+func TestFanOut_SlowSinkDoesNotStallOthers(t *testing.T) {
+	slow := &blockingSink{release: make(chan struct{})}
+	fast := NewRing(100)
+	f := NewFanOut(Route{Sink: slow, Buffer: 2}, Route{Sink: fast, Buffer: 100}, Route{Sink: NewRing(1), Filter: ByStrategy("none")})
+
+	done := make(chan struct{})
+	go func() {
+		for i := int64(0); i < 50; i++ {
+			f.Emit(general("s", "", i, false, nil))
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Emit blocked on a slow sink")
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for len(fast.Events()) < 50 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := len(fast.Events()); n != 50 {
+		t.Errorf("expected fast sink to get 50 events, got %d", n)
+	}
+	if dropped := f.Dropped(); dropped[0] == 0 || dropped[1] != 0 {
+		t.Errorf("expected drops only on the slow route, got %v", dropped)
+	}
+	slow.Close()
+	f.Close()
+}
+
+// failingSink rejects every event, like a JSONL sink whose file was closed under it
+type failingSink struct{}
+
+func (failingSink) Emit(Event) error { return errors.New("disk full") }
+func (failingSink) Close() error     { return nil }
+
+func TestFanOut_CountsSinkErrors(t *testing.T) {
+	f := NewFanOut(Route{Sink: failingSink{}}, Route{Sink: NewRing(10)})
+	for i := int64(0); i < 3; i++ {
+		if err := f.Emit(general("s", "", i, false, nil)); err != nil {
+			t.Fatalf("expected queued emits to succeed, got %v", err)
+		}
+	}
+	f.Close()
+	if failed := f.Failed(); failed[0] != 3 || failed[1] != 0 {
+		t.Errorf("expected the failing route to count 3 errors, got %v", failed)
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package sink
+
+import (
+	"io"
+	"sync/atomic"
+	"time"
+
+	"github.com/wang900115/quant/model"
+)
+
+// Span is the latency trace of one decision as exported by Tracer
+type Span struct {
+	Strategy string    `json:"strategy"`
+	Pair     string    `json:"pair,omitempty"`
+	Type     EventType `json:"type"`
+	// Stamps are the non zero trace stamps keyed by hop boundary
+	Stamps map[string]time.Time `json:"stamps"`
+	// Hops are the hop latencies in seconds, hops missing a stamp are left out
+	Hops map[model.Hop]float64 `json:"hops"`
+}
+
+// NewSpan builds the span of an event
+func NewSpan(e Event) Span {
+	trace := e.Trace()
+	span := Span{Strategy: e.Strategy, Pair: e.Pair, Type: e.Type, Stamps: make(map[string]time.Time), Hops: make(map[model.Hop]float64)}
+	for name, stamp := range map[string]time.Time{
+		"event":      trace.EventTime,
+		"received":   trace.ReceivedAt,
+		"dispatched": trace.DispatchedAt,
+		"enqueued":   trace.EnqueuedAt,
+		"dequeued":   trace.DequeuedAt,
+		"decided":    trace.DecidedAt,
+	} {
+		if !stamp.IsZero() {
+			span.Stamps[name] = stamp
+		}
+	}
+	for _, hop := range model.Hops {
+		if d, ok := trace.Get(hop); ok {
+			span.Hops[hop] = d.Seconds()
+		}
+	}
+	return span
+}
+
+// Tracer exports the latency trace of events as JSON lines.
+// Triggers and errors are always exported, updates are sampled.
+type Tracer struct {
+	out    *JSONL
+	sample uint64
+	seen   atomic.Uint64
+}
+
+// NewTracer writes spans to w, exporting one in sample updates, zero or one exports every update
+func NewTracer(w io.Writer, sample int) *Tracer {
+	if sample < 1 {
+		sample = 1
+	}
+	return &Tracer{out: NewJSONL(w), sample: uint64(sample)}
+}
+
+func (t *Tracer) Emit(e Event) error {
+	if e.Type == EventUpdate && (t.seen.Add(1)-1)%t.sample != 0 {
+		return nil
+	}
+	return t.out.write(NewSpan(e))
+}
+
+func (t *Tracer) Close() error {
+	return t.out.Close()
+}
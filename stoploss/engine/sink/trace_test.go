@@ -0,0 +1,77 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package sink
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wang900115/quant/model"
+)
+
+func TestTracer_SamplesUpdates(t *testing.T) {
+	var buf bytes.Buffer
+	tracer := NewTracer(&buf, 3)
+	for i := 0; i < 6; i++ {
+		tracer.Emit(general("trail", "Binance:BTC/USDT:SPOT", 100, false, nil))
+	}
+	tracer.Emit(general("trail", "Binance:BTC/USDT:SPOT", 90, true, nil))
+	if err := tracer.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected two sampled updates and the trigger, got %d lines:\n%s", len(lines), buf.String())
+	}
+	var span Span
+	if err := json.Unmarshal([]byte(lines[2]), &span); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if span.Type != EventTrigger || span.Strategy != "trail" {
+		t.Errorf("unexpected span %+v", span)
+	}
+}
+
+func TestNewSpan_Hops(t *testing.T) {
+	e := general("trail", "", 100, true, nil)
+	at := time.Unix(1700000000, 0)
+	e.General.Trace = model.Trace{
+		EventTime:    at.Add(5 * time.Millisecond), // venue clock ahead of ours
+		ReceivedAt:   at,
+		DispatchedAt: at.Add(time.Millisecond),
+		EnqueuedAt:   at.Add(2 * time.Millisecond),
+		DequeuedAt:   at.Add(4 * time.Millisecond),
+		DecidedAt:    at.Add(5 * time.Millisecond),
+	}
+	span := NewSpan(e)
+	want := map[model.Hop]float64{
+		model.HopExchange: -0.005,
+		model.HopDispatch: 0.001,
+		model.HopIngest:   0.001,
+		model.HopQueue:    0.002,
+		model.HopEvaluate: 0.001,
+		model.HopTotal:    0,
+	}
+	for hop, seconds := range want {
+		if got := span.Hops[hop]; got != seconds {
+			t.Errorf("expected %s hop to take %vs, got %vs", hop, seconds, got)
+		}
+	}
+	if len(span.Stamps) != 6 {
+		t.Errorf("expected 6 stamps, got %v", span.Stamps)
+	}
+}
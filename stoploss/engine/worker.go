@@ -0,0 +1,109 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"runtime"
+	"sync/atomic"
+
+	"github.com/wang900115/quant/common/sys"
+	"github.com/wang900115/quant/model"
+)
+
+// ShardKey selects what strategies are grouped by when assigned to a worker shard
+type ShardKey int
+
+const (
+	// ShardByStrategy gives every strategy its own key, spreading them across shards
+	ShardByStrategy ShardKey = iota
+	// ShardByPair keeps strategies bound to the same pair on one shard, unbound ones fall back to their name
+	ShardByPair
+)
+
+func (k ShardKey) String() string {
+	if k == ShardByPair {
+		return "pair"
+	}
+	return "strategy"
+}
+
+// tick is a price update tagged with the pair it was collected for, empty when unbound.
+// A tick with apply carries a bar, order book or fill update queued behind the prices before it.
+type tick struct {
+	pair  string
+	point model.PricePoint
+	apply job
+	// epoch is the inbox epoch a conflated tick was queued in
+	epoch uint64
+}
+
+// job is one strategy evaluation queued on a shard
+type job func(ctx context.Context)
+
+// workerPool runs jobs on a fixed set of shards, each shard executes its queue in FIFO order
+type workerPool struct {
+	queues []chan job
+	// pending counts submitted jobs that have not finished, used to drain on stop
+	pending atomic.Int64
+}
+
+func newWorkerPool(shards, queueSize int) *workerPool {
+	if shards <= 0 {
+		shards = runtime.NumCPU()
+	}
+	if queueSize <= 0 {
+		queueSize = 1
+	}
+	queues := make([]chan job, shards)
+	for i := range queues {
+		queues[i] = make(chan job, queueSize)
+	}
+	return &workerPool{queues: queues}
+}
+
+// start launches one supervised goroutine per shard
+func (wp *workerPool) start(engine *sys.Engine) {
+	for i, queue := range wp.queues {
+		engine.Supervise(sys.Task{Name: fmt.Sprintf("shard-%d", i), Run: func(ctx context.Context) error {
+			for {
+				select {
+				case <-ctx.Done():
+					return nil
+				case j := <-queue:
+					wp.run(j, ctx)
+				}
+			}
+		}})
+	}
+}
+
+func (wp *workerPool) run(j job, ctx context.Context) {
+	defer wp.pending.Add(-1)
+	j(ctx)
+}
+
+// shard returns the queue a key is pinned to
+func (wp *workerPool) shard(key string) chan job {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return wp.queues[h.Sum32()%uint32(len(wp.queues))]
+}
+
+// PairKey identifies a pair in results and sink events, e.g. "Binance:BTC/USDT:SPOT"
+func PairKey(pair model.QuotesPair) string {
+	return fmt.Sprintf("%s:%s:%s", model.GetExchange(pair.ExchangeID).Name, pair.Symbol(), pair.Category)
+}
@@ -0,0 +1,146 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/wang900115/quant/common/sys"
+	"github.com/wang900115/quant/model"
+	"github.com/wang900115/quant/model/currency"
+	"github.com/wang900115/quant/model/trade"
+	"github.com/wang900115/quant/stoploss/strategy"
+)
+
+func TestWorkerPool_KeyOrder(t *testing.T) {
+	engine := sys.NewEngine(time.Millisecond, time.Second)
+	defer engine.Stop()
+	wp := newWorkerPool(4, 16)
+	wp.start(engine)
+
+	var mu sync.Mutex
+	seen := map[string][]int{}
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		for _, key := range []string{"a", "b", "c"} {
+			wg.Add(1)
+			wp.shard(key) <- func(ctx context.Context) {
+				defer wg.Done()
+				mu.Lock()
+				seen[key] = append(seen[key], i)
+				mu.Unlock()
+			}
+		}
+	}
+	wg.Wait()
+
+	for key, order := range seen {
+		for i, v := range order {
+			if v != i {
+				t.Fatalf("key %s: expected tick %d at position %d, got %d", key, i, i, v)
+			}
+		}
+	}
+}
+
+func TestWorkerPool_SlowShardDoesNotBlockOthers(t *testing.T) {
+	engine := sys.NewEngine(time.Millisecond, time.Second)
+	defer engine.Stop()
+	wp := newWorkerPool(2, 4)
+	wp.start(engine)
+
+	slow, fast := "slow", ""
+	for i := 0; fast == ""; i++ {
+		if key := fmt.Sprintf("fast-%d", i); wp.shard(key) != wp.shard(slow) {
+			fast = key
+		}
+	}
+
+	release := make(chan struct{})
+	defer close(release)
+	wp.shard(slow) <- func(ctx context.Context) { <-release }
+
+	done := make(chan struct{})
+	wp.shard(fast) <- func(ctx context.Context) { close(done) }
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("job on an idle shard waited for a blocked shard")
+	}
+}
+
+func TestEngine_ResultsFollowRegistrationOrder(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Shards = 1
+	cfg.BufferRSize = 16
+	csm := New(cfg)
+	names := []string{"stop-c", "stop-a", "stop-b"}
+	for _, name := range names {
+		s, _ := strategy.NewFixedPercentStop(decimal.NewFromInt(100), decimal.NewFromFloat(0.05), nil)
+		if err := csm.RegisterStrategy(name, s); err != nil {
+			t.Fatalf("register %s: %v", name, err)
+		}
+	}
+	csm.workers.start(csm.engine)
+	defer csm.engine.Stop()
+
+	csm.processFixedStopStrategies(tick{point: model.PricePoint{NewPrice: decimal.NewFromInt(101), UpdatedAt: time.Now()}}, context.Background())
+	for _, want := range names {
+		select {
+		case r := <-csm.execution.generalResults:
+			if r.StrategyName != want {
+				t.Fatalf("expected result for %s, got %s", want, r.StrategyName)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("no result for %s", want)
+		}
+	}
+}
+
+func TestEngine_PairStrategiesOnlySeeTheirPair(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.ShardBy = ShardByPair
+	cfg.BufferRSize = 16
+	csm := New(cfg)
+	btc := model.QuotesPair{ExchangeID: model.BINANCE, Base: currency.BTCSymbol, Quote: currency.USDTSymbol, Category: trade.SPOT}
+	eth := model.QuotesPair{ExchangeID: model.BINANCE, Base: currency.ETHSymbol, Quote: currency.USDTSymbol, Category: trade.SPOT}
+	s, _ := strategy.NewFixedPercentStop(decimal.NewFromInt(100), decimal.NewFromFloat(0.05), nil)
+	if err := csm.RegisterPairStrategy(btc, "btc-stop", s); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	csm.workers.start(csm.engine)
+	defer csm.engine.Stop()
+
+	point := model.PricePoint{NewPrice: decimal.NewFromInt(101), UpdatedAt: time.Now()}
+	csm.processFixedStopStrategies(tick{pair: PairKey(eth), point: point}, context.Background())
+	csm.processFixedStopStrategies(tick{pair: PairKey(btc), point: point}, context.Background())
+	select {
+	case r := <-csm.execution.generalResults:
+		if r.StrategyName != "btc-stop" {
+			t.Fatalf("unexpected result %s", r.StrategyName)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no result for the bound pair")
+	}
+	select {
+	case r := <-csm.execution.generalResults:
+		t.Fatalf("strategy evaluated an update for another pair: %+v", r)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -17,6 +17,8 @@ import (
 	"errors"
 
 	"github.com/shopspring/decimal"
+	"github.com/wang900115/quant/model"
+	"github.com/wang900115/quant/model/result"
 )
 
 var (
@@ -36,6 +38,18 @@ type FixedStopLoss interface {
 	StopLossCond
 }
 
+// Fixed strategy fed by closed bars
+type FixedBarStopLoss interface {
+	FixedStopLoss
+	BarUpdater
+}
+
+// Time-based strategy fed by closed bars
+type DebouncedBarStopLoss interface {
+	DebouncedStopLoss
+	BarUpdater
+}
+
 // Fixed-ATR
 type FixedVolatilityStopLoss interface {
 	FixedStopLoss
@@ -48,6 +62,18 @@ type DebouncedVolatilityStopLoss interface {
 	UpdateATR(currentATR decimal.Decimal) error
 }
 
+// Fixed-ATR fed by closed bars
+type FixedBarVolatilityStopLoss interface {
+	FixedVolatilityStopLoss
+	BarUpdater
+}
+
+// Debounced-ATR fed by closed bars
+type DebouncedBarVolatilityStopLoss interface {
+	DebouncedVolatilityStopLoss
+	BarUpdater
+}
+
 // Fixed-Moving Average
 type FixedMAStopLoss interface {
 	FixedStopLoss
@@ -60,6 +86,70 @@ type DebouncedMAStopLoss interface {
 	SetMA(value decimal.Decimal)
 }
 
+// Bar-fed strategies rebuild their levels from closed candles
+type BarUpdater interface {
+	UpdateBar(bar model.PriceInterval) error
+}
+
+// Strategies fed by trades, timestamp is the trade time in milliseconds
+type TradeUpdater interface {
+	UpdateTrade(price, size decimal.Decimal, timestamp int64) error
+}
+
+// Strategies fed by order book snapshots
+type OrderBookUpdater interface {
+	UpdateOrderBook(book model.OrderBook) error
+}
+
+// Fixed strategy fed by order book snapshots
+type FixedBookStopLoss interface {
+	FixedStopLoss
+	OrderBookUpdater
+}
+
+// Time-based strategy fed by order book snapshots
+type DebouncedBookStopLoss interface {
+	DebouncedStopLoss
+	OrderBookUpdater
+}
+
+// Strategies fed by executed fills, pair is the key the fill was collected for
+type FillUpdater interface {
+	UpdateFill(pair string, fill model.Fill) error
+}
+
+// Account-level guard tracking the PnL of every position together. On a breach it fires its
+// callback once to flatten everything and blocks new entries until Reset or its timed reset.
+type AccountGuard interface {
+	FillUpdater
+	// Mark revalues the position held in pair at price, timestamp in milliseconds
+	Mark(pair string, price decimal.Decimal, timestamp int64) error
+	ShouldHalt(timestamp int64) (bool, error)
+	// GetPnL returns the realized plus unrealized PnL net of fees
+	GetPnL() (decimal.Decimal, error)
+	Blocked() bool
+	Reset() error
+	Trigger(reason string) error
+	Deactivate() error
+}
+
+// Strategies moving their own stop report every move, Ratchets returns the moves since the last call
+type Ratcheter interface {
+	Ratchets() []result.Ratchet
+}
+
+// Fixed strategy reporting its stop moves
+type FixedRatchetStopLoss interface {
+	FixedStopLoss
+	Ratcheter
+}
+
+// Time-based strategy reporting its stop moves
+type DebouncedRatchetStopLoss interface {
+	DebouncedStopLoss
+	Ratcheter
+}
+
 // general StopLoss interface
 type StopLoss interface {
 	CalculateStopLoss(currentPrice decimal.Decimal) (decimal.Decimal, error)
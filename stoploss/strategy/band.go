@@ -0,0 +1,375 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package strategy
+
+import (
+	"errors"
+
+	"github.com/shopspring/decimal"
+	"github.com/wang900115/quant/model"
+	"github.com/wang900115/quant/stoploss"
+)
+
+var (
+	errBandKindInvalid  = errors.New("band kind must be Bollinger or Keltner")
+	errBandWidthInvalid = errors.New("band width must be greater than 0")
+)
+
+// BandKind selects how band strategies compute their channel
+type BandKind int
+
+const (
+	// BollingerBand is the SMA of closes ± Width standard deviations
+	BollingerBand BandKind = iota
+	// KeltnerChannel is the EMA of closes ± Width ATRs
+	KeltnerChannel
+)
+
+// BandConfig configures a band stop or take profit
+type BandConfig struct {
+	Kind   BandKind
+	Period int
+	// Width is σ for Bollinger bands and the ATR multiple for Keltner channels
+	Width  decimal.Decimal
+	IsLong bool
+	// FromTicks builds the window from tick prices instead of closed bars, bars are then ignored
+	FromTicks bool
+}
+
+// band holds the channel shared by band stops and take profits
+type band struct {
+	stoploss.BaseResolver
+	config    BandConfig
+	channel   channel
+	lastPrice decimal.Decimal
+}
+
+// FixedBandStop exits a long when price breaks below the lower band, a short above the upper band
+type FixedBandStop struct {
+	band
+}
+
+// FixedBandProfit takes profit at the opposite band, the upper one for a long
+type FixedBandProfit struct {
+	band
+}
+
+// DebouncedBandStop represents a time-based band stop loss
+type DebouncedBandStop struct {
+	FixedBandStop
+	TimeThreshold int64
+	TriggerTime   int64
+}
+
+// DebouncedBandProfit represents a time-based band take profit
+type DebouncedBandProfit struct {
+	FixedBandProfit
+	TimeThreshold int64
+	TriggerTime   int64
+}
+
+// NewFixedBandStop creates a Bollinger or Keltner band stop loss
+func NewFixedBandStop(config BandConfig, callback stoploss.DefaultCallback) (stoploss.FixedBarStopLoss, error) {
+	b, err := newBand(config, callback)
+	if err != nil {
+		return nil, err
+	}
+	return &FixedBandStop{b}, nil
+}
+
+// NewDebouncedBandStop creates a band stop loss that must stay breached for timeThreshold
+func NewDebouncedBandStop(config BandConfig, timeThreshold int64, callback stoploss.DefaultCallback) (stoploss.DebouncedBarStopLoss, error) {
+	if timeThreshold <= 0 {
+		return nil, errTimeThresholdInvalid
+	}
+	b, err := newBand(config, callback)
+	if err != nil {
+		return nil, err
+	}
+	return &DebouncedBandStop{FixedBandStop: FixedBandStop{b}, TimeThreshold: timeThreshold}, nil
+}
+
+// NewFixedBandProfit creates a Bollinger or Keltner band take profit
+func NewFixedBandProfit(config BandConfig, callback stoploss.DefaultCallback) (stoploss.FixedBarTakeProfit, error) {
+	b, err := newBand(config, callback)
+	if err != nil {
+		return nil, err
+	}
+	return &FixedBandProfit{b}, nil
+}
+
+// NewDebouncedBandProfit creates a band take profit that must stay reached for timeThreshold
+func NewDebouncedBandProfit(config BandConfig, timeThreshold int64, callback stoploss.DefaultCallback) (stoploss.DebouncedBarTakeProfit, error) {
+	if timeThreshold <= 0 {
+		return nil, errTimeThresholdInvalid
+	}
+	b, err := newBand(config, callback)
+	if err != nil {
+		return nil, err
+	}
+	return &DebouncedBandProfit{FixedBandProfit: FixedBandProfit{b}, TimeThreshold: timeThreshold}, nil
+}
+
+func newBand(config BandConfig, callback stoploss.DefaultCallback) (band, error) {
+	if config.Period <= 0 {
+		return band{}, errPeriodInvalid
+	}
+	if !config.Width.IsPositive() {
+		return band{}, errBandWidthInvalid
+	}
+	var ch channel
+	switch config.Kind {
+	case BollingerBand:
+		ch = newBollinger(config.Period, config.Width)
+	case KeltnerChannel:
+		ch = newKeltner(config.Period, config.Width)
+	default:
+		return band{}, errBandKindInvalid
+	}
+	return band{
+		config:  config,
+		channel: ch,
+		BaseResolver: stoploss.BaseResolver{
+			Active:   true,
+			Callback: callback,
+		},
+	}, nil
+}
+
+// UpdateBar folds a closed bar into the channel
+func (b *band) UpdateBar(bar model.PriceInterval) error {
+	if !b.Active {
+		return stoploss.ErrStatusInvalid
+	}
+	if err := validBar(bar); err != nil {
+		return err
+	}
+	if !b.config.FromTicks {
+		b.channel.update(bar)
+	}
+	return nil
+}
+
+// Ready reports whether the window is full and the bands are set
+func (b *band) Ready() bool {
+	return b.channel.ready()
+}
+
+// tick records the price and, when fed by ticks, folds it into the channel after it was evaluated
+func (b *band) tick(price decimal.Decimal) {
+	b.lastPrice = price
+	if b.config.FromTicks && price.IsPositive() {
+		b.channel.update(model.PriceInterval{HighestPrice: price, LowestPrice: price, ClosingPrice: price})
+	}
+}
+
+// stopLevel returns the band a stop exits at, zero until the window is full
+func (b *band) stopLevel() decimal.Decimal {
+	if !b.channel.ready() {
+		return decimal.Zero
+	}
+	lower, upper := b.channel.bands()
+	if b.config.IsLong {
+		return lower
+	}
+	return upper
+}
+
+// profitLevel returns the opposite band, zero until the window is full
+func (b *band) profitLevel() decimal.Decimal {
+	if !b.channel.ready() {
+		return decimal.Zero
+	}
+	lower, upper := b.channel.bands()
+	if b.config.IsLong {
+		return upper
+	}
+	return lower
+}
+
+// stopHit reports whether price broke through the stop band
+func (b *band) stopHit(price decimal.Decimal) bool {
+	if !b.channel.ready() {
+		return false
+	}
+	level := b.stopLevel()
+	if b.config.IsLong {
+		return price.LessThanOrEqual(level)
+	}
+	return price.GreaterThanOrEqual(level)
+}
+
+// profitHit reports whether price reached the opposite band
+func (b *band) profitHit(price decimal.Decimal) bool {
+	if !b.channel.ready() {
+		return false
+	}
+	level := b.profitLevel()
+	if b.config.IsLong {
+		return price.GreaterThanOrEqual(level)
+	}
+	return price.LessThanOrEqual(level)
+}
+
+// fire notifies the callback of a trigger
+func (b *band) fire(reason string) (bool, error) {
+	if err := b.Trigger(reason); err != nil {
+		return true, stoploss.ErrCallBackFail
+	}
+	return true, nil
+}
+
+// CalculateStopLoss returns the stop band
+func (s *FixedBandStop) CalculateStopLoss(currentPrice decimal.Decimal) (decimal.Decimal, error) {
+	if !s.Active {
+		return decimal.Zero, stoploss.ErrStatusInvalid
+	}
+	s.tick(currentPrice)
+	return s.stopLevel(), nil
+}
+
+// ShouldTriggerStopLoss checks if the stop loss should be triggered
+func (s *FixedBandStop) ShouldTriggerStopLoss(currentPrice decimal.Decimal) (bool, error) {
+	if !s.Active {
+		return false, stoploss.ErrStatusInvalid
+	}
+	if s.stopHit(currentPrice) {
+		return s.fire(stoploss.TRIGGERED_REASON_FIXED_BAND_STOPLOSS)
+	}
+	return false, nil
+}
+
+// GetStopLoss returns the stop band, zero until the window is full
+func (s *FixedBandStop) GetStopLoss() (decimal.Decimal, error) {
+	if !s.Active {
+		return decimal.Zero, stoploss.ErrStatusInvalid
+	}
+	return s.stopLevel(), nil
+}
+
+// ReSetStopLosser keeps the bands, they follow the market rather than the entry
+func (s *FixedBandStop) ReSetStopLosser(currentPrice decimal.Decimal) error {
+	if !s.Active {
+		return stoploss.ErrStatusInvalid
+	}
+	s.lastPrice = currentPrice
+	return nil
+}
+
+// CalculateTakeProfit returns the take profit band
+func (p *FixedBandProfit) CalculateTakeProfit(currentPrice decimal.Decimal) (decimal.Decimal, error) {
+	if !p.Active {
+		return decimal.Zero, stoploss.ErrStatusInvalid
+	}
+	p.tick(currentPrice)
+	return p.profitLevel(), nil
+}
+
+// ShouldTriggerTakeProfit checks if the take profit should be triggered
+func (p *FixedBandProfit) ShouldTriggerTakeProfit(currentPrice decimal.Decimal) (bool, error) {
+	if !p.Active {
+		return false, stoploss.ErrStatusInvalid
+	}
+	if p.profitHit(currentPrice) {
+		return p.fire(stoploss.TRIGGERED_REASON_FIXED_BAND_TAKEPROFIT)
+	}
+	return false, nil
+}
+
+// GetTakeProfit returns the take profit band, zero until the window is full
+func (p *FixedBandProfit) GetTakeProfit() (decimal.Decimal, error) {
+	if !p.Active {
+		return decimal.Zero, stoploss.ErrStatusInvalid
+	}
+	return p.profitLevel(), nil
+}
+
+// ReSetTakeProfiter keeps the bands, they follow the market rather than the entry
+func (p *FixedBandProfit) ReSetTakeProfiter(currentPrice decimal.Decimal) error {
+	if !p.Active {
+		return stoploss.ErrStatusInvalid
+	}
+	p.lastPrice = currentPrice
+	return nil
+}
+
+// GetTimeThreshold returns the time threshold for Debounced strategies
+func (t *DebouncedBandStop) GetTimeThreshold() (int64, error) {
+	if !t.Active {
+		return 0, stoploss.ErrStatusInvalid
+	}
+	return t.TimeThreshold, nil
+}
+
+// ShouldTriggerStopLoss checks if the Debounced stop loss should be triggered
+func (t *DebouncedBandStop) ShouldTriggerStopLoss(currentPrice decimal.Decimal, currentTime int64) (bool, error) {
+	if !t.Active {
+		return false, stoploss.ErrStatusInvalid
+	}
+	if t.stopHit(currentPrice) {
+		if t.TriggerTime == 0 {
+			t.TriggerTime = currentTime
+		}
+		if currentTime-t.TriggerTime >= t.TimeThreshold {
+			return t.fire(stoploss.TRIGGERED_REASON_DEBOUNCED_BAND_STOPLOSS)
+		}
+	} else {
+		t.TriggerTime = 0
+	}
+	return false, nil
+}
+
+// ReSetStopLosser keeps the bands and clears the debounce window
+func (t *DebouncedBandStop) ReSetStopLosser(currentPrice decimal.Decimal) error {
+	if err := t.FixedBandStop.ReSetStopLosser(currentPrice); err != nil {
+		return err
+	}
+	t.TriggerTime = 0
+	return nil
+}
+
+// GetTimeThreshold returns the time threshold for Debounced strategies
+func (t *DebouncedBandProfit) GetTimeThreshold() (int64, error) {
+	if !t.Active {
+		return 0, stoploss.ErrStatusInvalid
+	}
+	return t.TimeThreshold, nil
+}
+
+// ShouldTriggerTakeProfit checks if the Debounced take profit should be triggered
+func (t *DebouncedBandProfit) ShouldTriggerTakeProfit(currentPrice decimal.Decimal, currentTime int64) (bool, error) {
+	if !t.Active {
+		return false, stoploss.ErrStatusInvalid
+	}
+	if t.profitHit(currentPrice) {
+		if t.TriggerTime == 0 {
+			t.TriggerTime = currentTime
+		}
+		if currentTime-t.TriggerTime >= t.TimeThreshold {
+			return t.fire(stoploss.TRIGGERED_REASON_DEBOUNCED_BAND_TAKEPROFIT)
+		}
+	} else {
+		t.TriggerTime = 0
+	}
+	return false, nil
+}
+
+// ReSetTakeProfiter keeps the bands and clears the debounce window
+func (t *DebouncedBandProfit) ReSetTakeProfiter(currentPrice decimal.Decimal) error {
+	if err := t.FixedBandProfit.ReSetTakeProfiter(currentPrice); err != nil {
+		return err
+	}
+	t.TriggerTime = 0
+	return nil
+}
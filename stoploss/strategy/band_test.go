@@ -0,0 +1,178 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package strategy
+
+import (
+	"testing"
+)
+
+func TestNewFixedBandStop_InvalidParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  BandConfig
+		wantErr bool
+	}{
+		{"Valid Bollinger", BandConfig{Kind: BollingerBand, Period: 20, Width: d(2), IsLong: true}, false},
+		{"Valid Keltner", BandConfig{Kind: KeltnerChannel, Period: 20, Width: d(1.5)}, false},
+		{"Zero Period", BandConfig{Kind: BollingerBand, Width: d(2)}, true},
+		{"Zero Width", BandConfig{Kind: KeltnerChannel, Period: 20}, true},
+		{"Unknown Kind", BandConfig{Kind: BandKind(7), Period: 20, Width: d(2)}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewFixedBandStop(tt.config, nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("wantErr=%v, got err=%v", tt.wantErr, err)
+			}
+			if tt.wantErr && s != nil {
+				t.Errorf("expected a nil strategy on error, got %v", s)
+			}
+		})
+	}
+	valid := BandConfig{Kind: BollingerBand, Period: 20, Width: d(2)}
+	if _, err := NewDebouncedBandStop(valid, 0, nil); err == nil {
+		t.Error("expected a zero time threshold to be rejected")
+	}
+	if _, err := NewDebouncedBandProfit(valid, 0, nil); err == nil {
+		t.Error("expected a zero time threshold to be rejected")
+	}
+}
+
+func TestFixedBandStop_BollingerFromBars(t *testing.T) {
+	config := BandConfig{Kind: BollingerBand, Period: 8, Width: d(2), IsLong: true}
+	stop, _ := NewFixedBandStop(config, nil)
+	profit, _ := NewFixedBandProfit(config, nil)
+
+	closes := []float64{20, 40, 40, 40, 50, 50, 70, 90}
+	for i, c := range closes {
+		if i == len(closes)-1 {
+			if triggered, _ := stop.ShouldTriggerStopLoss(d(1)); triggered {
+				t.Fatal("Did not expect a trigger before the window filled")
+			}
+			if sl, _ := stop.GetStopLoss(); !sl.IsZero() {
+				t.Fatalf("Expected no band before the window filled, got %v", sl)
+			}
+		}
+		stop.UpdateBar(bar(c, c, c))
+		profit.UpdateBar(bar(c, c, c))
+	}
+
+	// mean 50, population deviation 20
+	if sl, _ := stop.CalculateStopLoss(d(60)); !sl.Equal(d(10)) {
+		t.Fatalf("Expected the lower band at 10, got %v", sl)
+	}
+	if tp, _ := profit.CalculateTakeProfit(d(60)); !tp.Equal(d(90)) {
+		t.Fatalf("Expected the upper band at 90, got %v", tp)
+	}
+	if triggered, _ := stop.ShouldTriggerStopLoss(d(11)); triggered {
+		t.Error("Did not expect a trigger above the lower band")
+	}
+	if triggered, _ := stop.ShouldTriggerStopLoss(d(10)); !triggered {
+		t.Error("Expected a trigger at the lower band")
+	}
+	if triggered, _ := profit.ShouldTriggerTakeProfit(d(89)); triggered {
+		t.Error("Did not expect a take profit below the upper band")
+	}
+	if triggered, _ := profit.ShouldTriggerTakeProfit(d(90)); !triggered {
+		t.Error("Expected a take profit at the upper band")
+	}
+}
+
+func TestFixedBandStop_ShortKeltnerFromBars(t *testing.T) {
+	config := BandConfig{Kind: KeltnerChannel, Period: 3, Width: d(2)}
+	stop, _ := NewFixedBandStop(config, nil)
+	profit, _ := NewFixedBandProfit(config, nil)
+	for _, b := range []struct{ h, l, c float64 }{{102, 98, 100}, {104, 100, 102}, {106, 102, 104}} {
+		stop.UpdateBar(bar(b.h, b.l, b.c))
+		profit.UpdateBar(bar(b.h, b.l, b.c))
+	}
+	// EMA seeded at 102, ATR 4
+	if sl, _ := stop.GetStopLoss(); !sl.Equal(d(110)) {
+		t.Fatalf("Expected a short to stop at the upper band 110, got %v", sl)
+	}
+	if tp, _ := profit.GetTakeProfit(); !tp.Equal(d(94)) {
+		t.Fatalf("Expected a short to take profit at the lower band 94, got %v", tp)
+	}
+
+	stop.UpdateBar(bar(108, 104, 106))
+	if sl, _ := stop.GetStopLoss(); !sl.Equal(d(112)) { // EMA 102 + (106-102)/2, ATR (4*2+4)/3
+		t.Fatalf("Expected the upper band at 112, got %v", sl)
+	}
+	if triggered, _ := stop.ShouldTriggerStopLoss(d(111)); triggered {
+		t.Error("Did not expect a short to trigger below the upper band")
+	}
+	if triggered, _ := stop.ShouldTriggerStopLoss(d(112)); !triggered {
+		t.Error("Expected a short to trigger at the upper band")
+	}
+	if err := stop.UpdateBar(bar(90, 95, 92)); err == nil {
+		t.Error("Expected a bar with its high below its low to be rejected")
+	}
+}
+
+func TestFixedBandStop_FromTicks(t *testing.T) {
+	s, _ := NewFixedBandStop(BandConfig{Kind: BollingerBand, Period: 4, Width: d(1), IsLong: true, FromTicks: true}, nil)
+	for _, p := range []float64{100, 102, 100, 102} {
+		if triggered, _ := s.ShouldTriggerStopLoss(d(p)); triggered {
+			t.Fatalf("Did not expect a trigger while the window fills, at %v", p)
+		}
+		s.CalculateStopLoss(d(p))
+	}
+	if sl, _ := s.GetStopLoss(); !sl.Equal(d(100)) {
+		t.Fatalf("Expected the lower band at 100 from ticks, got %v", sl)
+	}
+	if err := s.UpdateBar(bar(200, 150, 180)); err != nil {
+		t.Fatalf("UpdateBar: %v", err)
+	}
+	if sl, _ := s.GetStopLoss(); !sl.Equal(d(100)) {
+		t.Fatalf("Expected tick-fed bands to ignore bars, got %v", sl)
+	}
+	if triggered, _ := s.ShouldTriggerStopLoss(d(100)); !triggered {
+		t.Error("Expected a trigger at the lower band")
+	}
+}
+
+func TestDebouncedBandStop_WaitsForThreshold(t *testing.T) {
+	s, err := NewDebouncedBandStop(BandConfig{Kind: BollingerBand, Period: 4, Width: d(1), IsLong: true}, 1000, nil)
+	if err != nil {
+		t.Fatalf("Failed to create band stop: %v", err)
+	}
+	for _, c := range []float64{100, 102, 100, 102} {
+		s.UpdateBar(bar(c, c, c))
+	}
+	if triggered, _ := s.ShouldTriggerStopLoss(d(99), 1000); triggered {
+		t.Fatal("Did not expect a trigger on the first breach")
+	}
+	if triggered, _ := s.ShouldTriggerStopLoss(d(101), 1500); triggered {
+		t.Fatal("Did not expect a trigger after price recovered")
+	}
+	if triggered, _ := s.ShouldTriggerStopLoss(d(99), 1800); triggered {
+		t.Fatal("Expected the recovery to restart the window")
+	}
+	if triggered, _ := s.ShouldTriggerStopLoss(d(99), 2800); !triggered {
+		t.Fatal("Expected a trigger once the breach lasted the threshold")
+	}
+}
+
+func TestDebouncedBandProfit_WaitsForThreshold(t *testing.T) {
+	p, _ := NewDebouncedBandProfit(BandConfig{Kind: BollingerBand, Period: 4, Width: d(1), IsLong: true}, 1000, nil)
+	for _, c := range []float64{100, 102, 100, 102} {
+		p.UpdateBar(bar(c, c, c))
+	}
+	if triggered, _ := p.ShouldTriggerTakeProfit(d(102), 1000); triggered {
+		t.Fatal("Did not expect a take profit on the first touch")
+	}
+	if triggered, _ := p.ShouldTriggerTakeProfit(d(103), 2000); !triggered {
+		t.Fatal("Expected a take profit once the upper band held for the threshold")
+	}
+}
@@ -0,0 +1,307 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package strategy
+
+import (
+	"errors"
+
+	"github.com/shopspring/decimal"
+	"github.com/wang900115/quant/model/result"
+	"github.com/wang900115/quant/stoploss"
+)
+
+var (
+	errBreakEvenTriggerInvalid = errors.New("break-even trigger must be greater than 0 and beyond entry plus fees")
+	errLockFractionInvalid     = errors.New("lock fraction must be between 0 and 1")
+	errLockStepInvalid         = errors.New("lock step must not be negative")
+	errFeePctInvalid           = errors.New("fee rate must not be negative")
+)
+
+// BreakEvenConfig configures a break-even and profit-lock ratchet stop
+type BreakEvenConfig struct {
+	EntryPrice decimal.Decimal
+	IsLong     bool
+	// StopPct places the initial stop this fraction away from entry, ignored when ATR is set
+	StopPct decimal.Decimal
+	// ATR and ATRMultiplier place the initial stop ATR×ATRMultiplier away from entry
+	ATR           decimal.Decimal
+	ATRMultiplier decimal.Decimal
+	// TriggerPct moves the stop to break-even once price moved this fraction of entry in favor
+	TriggerPct decimal.Decimal
+	// TriggerR moves the stop to break-even at this multiple of the initial risk, used when TriggerPct is zero
+	TriggerR decimal.Decimal
+	// FeePct of entry is added to the break-even level to cover fees
+	FeePct decimal.Decimal
+	// LockFraction of the open profit is locked at every step after break-even, zero stops at break-even
+	LockFraction decimal.Decimal
+	// LockStep is the favorable move between lock levels as a fraction of entry, zero locks on every new extreme
+	LockStep decimal.Decimal
+}
+
+// FixedBreakEvenStop starts as a percent or ATR stop, moves to entry plus fees once price reached the trigger,
+// then locks a fraction of the open profit at every step. The stop never loosens and reports each move.
+type FixedBreakEvenStop struct {
+	stoploss.BaseResolver
+	threshold decimal.Decimal
+	lastPrice decimal.Decimal
+	config    BreakEvenConfig
+
+	breakEvenAt    decimal.Decimal
+	breakEvenLevel decimal.Decimal
+	stepSize       decimal.Decimal
+	extreme        decimal.Decimal
+	atBreakEven    bool
+	// steps counts the lock levels passed after break-even
+	steps   int64
+	pending []result.Ratchet
+}
+
+// DebouncedBreakEvenStop represents a time-based break-even and profit-lock stop
+type DebouncedBreakEvenStop struct {
+	FixedBreakEvenStop
+	TimeThreshold int64
+	TriggerTime   int64
+}
+
+// NewFixedBreakEvenStop creates a break-even and profit-lock ratchet stop
+func NewFixedBreakEvenStop(config BreakEvenConfig, callback stoploss.DefaultCallback) (stoploss.FixedRatchetStopLoss, error) {
+	s, err := newBreakEven(config, callback)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// NewDebouncedBreakEvenStop creates a break-even and profit-lock stop that triggers once price stayed beyond it for timeThreshold
+func NewDebouncedBreakEvenStop(config BreakEvenConfig, timeThreshold int64, callback stoploss.DefaultCallback) (stoploss.DebouncedRatchetStopLoss, error) {
+	if timeThreshold <= 0 {
+		return nil, errTimeThresholdInvalid
+	}
+	s, err := newBreakEven(config, callback)
+	if err != nil {
+		return nil, err
+	}
+	return &DebouncedBreakEvenStop{FixedBreakEvenStop: *s, TimeThreshold: timeThreshold}, nil
+}
+
+func newBreakEven(config BreakEvenConfig, callback stoploss.DefaultCallback) (*FixedBreakEvenStop, error) {
+	if !config.EntryPrice.IsPositive() {
+		return nil, errEntryPriceInvalid
+	}
+	if config.ATR.IsPositive() {
+		if !config.ATRMultiplier.IsPositive() {
+			return nil, errATRStopLossKInvalid
+		}
+	} else if !config.StopPct.IsPositive() || config.StopPct.GreaterThanOrEqual(decimal.NewFromInt(1)) {
+		return nil, errStopLossRateInvalid
+	}
+	if config.LockFraction.IsNegative() || config.LockFraction.GreaterThan(decimal.NewFromInt(1)) {
+		return nil, errLockFractionInvalid
+	}
+	if config.LockStep.IsNegative() {
+		return nil, errLockStepInvalid
+	}
+	if config.FeePct.IsNegative() {
+		return nil, errFeePctInvalid
+	}
+	s := &FixedBreakEvenStop{
+		config: config,
+		BaseResolver: stoploss.BaseResolver{
+			Active:   true,
+			Callback: callback,
+		},
+	}
+	if err := s.open(config.EntryPrice); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// open places the initial stop and the break-even trigger for a position entered at entry
+func (s *FixedBreakEvenStop) open(entry decimal.Decimal) error {
+	c := s.config
+	risk := entry.Mul(c.StopPct)
+	if c.ATR.IsPositive() {
+		risk = c.ATR.Mul(c.ATRMultiplier)
+	}
+	move := entry.Mul(c.TriggerPct)
+	if !c.TriggerPct.IsPositive() {
+		move = risk.Mul(c.TriggerR)
+	}
+	fees := entry.Mul(c.FeePct)
+	if !move.IsPositive() || fees.GreaterThanOrEqual(move) {
+		return errBreakEvenTriggerInvalid
+	}
+	s.config.EntryPrice = entry
+	s.lastPrice = entry
+	s.extreme = entry
+	s.threshold = s.away(entry, risk.Neg())
+	s.breakEvenAt = s.away(entry, move)
+	s.breakEvenLevel = s.away(entry, fees)
+	s.stepSize = entry.Mul(c.LockStep)
+	s.atBreakEven = false
+	s.steps = 0
+	s.pending = nil
+	return nil
+}
+
+// away returns the level distance in favor of the position from anchor, negative distances are adverse
+func (s *FixedBreakEvenStop) away(anchor, distance decimal.Decimal) decimal.Decimal {
+	if s.config.IsLong {
+		return anchor.Add(distance)
+	}
+	return anchor.Sub(distance)
+}
+
+// gain returns how far price moved from anchor in favor of the position
+func (s *FixedBreakEvenStop) gain(anchor, price decimal.Decimal) decimal.Decimal {
+	if s.config.IsLong {
+		return price.Sub(anchor)
+	}
+	return anchor.Sub(price)
+}
+
+// ratchet moves the stop to level when that tightens it and records the move
+func (s *FixedBreakEvenStop) ratchet(level decimal.Decimal, reason string) {
+	if !s.gain(s.threshold, level).IsPositive() {
+		return
+	}
+	s.pending = append(s.pending, result.Ratchet{From: s.threshold, To: level, Reason: reason})
+	s.threshold = level
+}
+
+// advance folds a new price into the extreme and ratchets the stop through break-even and the lock levels
+func (s *FixedBreakEvenStop) advance(price decimal.Decimal) {
+	if s.gain(s.extreme, price).IsPositive() {
+		s.extreme = price
+	}
+	if !s.atBreakEven {
+		if s.gain(s.breakEvenAt, s.extreme).IsNegative() {
+			return
+		}
+		s.atBreakEven = true
+		s.ratchet(s.breakEvenLevel, stoploss.RATCHETED_REASON_BREAK_EVEN)
+	}
+	if !s.config.LockFraction.IsPositive() {
+		return
+	}
+	entry := s.config.EntryPrice
+	peak := s.extreme
+	if s.stepSize.IsPositive() {
+		steps := s.gain(s.breakEvenAt, s.extreme).Div(s.stepSize).IntPart()
+		if steps <= s.steps {
+			return
+		}
+		s.steps = steps
+		peak = s.away(s.breakEvenAt, s.stepSize.Mul(decimal.NewFromInt(steps)))
+	}
+	s.ratchet(s.away(entry, s.gain(entry, peak).Mul(s.config.LockFraction)), stoploss.RATCHETED_REASON_PROFIT_LOCK)
+}
+
+// breached reports whether price is at or beyond the stop
+func (s *FixedBreakEvenStop) breached(price decimal.Decimal) bool {
+	return !s.gain(s.threshold, price).IsPositive()
+}
+
+// AtBreakEven reports whether the stop reached entry plus fees
+func (s *FixedBreakEvenStop) AtBreakEven() bool {
+	return s.atBreakEven
+}
+
+// Ratchets returns the stop moves since the last call
+func (s *FixedBreakEvenStop) Ratchets() []result.Ratchet {
+	moves := s.pending
+	s.pending = nil
+	return moves
+}
+
+// CalculateStopLoss folds the price into the ratchet and returns the stop
+func (s *FixedBreakEvenStop) CalculateStopLoss(currentPrice decimal.Decimal) (decimal.Decimal, error) {
+	if !s.Active {
+		return decimal.Zero, stoploss.ErrStatusInvalid
+	}
+	s.lastPrice = currentPrice
+	s.advance(currentPrice)
+	return s.threshold, nil
+}
+
+// ShouldTriggerStopLoss checks if the stop loss should be triggered
+func (s *FixedBreakEvenStop) ShouldTriggerStopLoss(currentPrice decimal.Decimal) (bool, error) {
+	if !s.Active {
+		return false, stoploss.ErrStatusInvalid
+	}
+	if s.breached(currentPrice) {
+		err := s.Trigger(stoploss.TRIGGERED_REASON_FIXED_BREAK_EVEN_STOPLOSS)
+		if err != nil {
+			return true, stoploss.ErrCallBackFail
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// GetStopLoss returns the current stop loss threshold
+func (s *FixedBreakEvenStop) GetStopLoss() (decimal.Decimal, error) {
+	if !s.Active {
+		return decimal.Zero, stoploss.ErrStatusInvalid
+	}
+	return s.threshold, nil
+}
+
+// ReSetStopLosser restarts the ratchet for a position entered at the current price
+func (s *FixedBreakEvenStop) ReSetStopLosser(currentPrice decimal.Decimal) error {
+	if !s.Active {
+		return stoploss.ErrStatusInvalid
+	}
+	return s.open(currentPrice)
+}
+
+// GetTimeThreshold returns the time threshold for Debounced strategies
+func (t *DebouncedBreakEvenStop) GetTimeThreshold() (int64, error) {
+	if !t.Active {
+		return 0, stoploss.ErrStatusInvalid
+	}
+	return t.TimeThreshold, nil
+}
+
+// ShouldTriggerStopLoss checks if the Debounced stop loss should be triggered
+func (t *DebouncedBreakEvenStop) ShouldTriggerStopLoss(currentPrice decimal.Decimal, currentTime int64) (bool, error) {
+	if !t.Active {
+		return false, stoploss.ErrStatusInvalid
+	}
+	if t.breached(currentPrice) {
+		if t.TriggerTime == 0 {
+			t.TriggerTime = currentTime
+		}
+		if currentTime-t.TriggerTime >= t.TimeThreshold {
+			err := t.Trigger(stoploss.TRIGGERED_REASON_DEBOUNCED_BREAK_EVEN_STOPLOSS)
+			if err != nil {
+				return true, stoploss.ErrCallBackFail
+			}
+			return true, nil
+		}
+	} else {
+		t.TriggerTime = 0
+	}
+	return false, nil
+}
+
+// ReSetStopLosser restarts the ratchet and clears the debounce window
+func (t *DebouncedBreakEvenStop) ReSetStopLosser(currentPrice decimal.Decimal) error {
+	if err := t.FixedBreakEvenStop.ReSetStopLosser(currentPrice); err != nil {
+		return err
+	}
+	t.TriggerTime = 0
+	return nil
+}
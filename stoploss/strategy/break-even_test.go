@@ -0,0 +1,189 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package strategy
+
+import (
+	"testing"
+
+	"github.com/wang900115/quant/model/result"
+	"github.com/wang900115/quant/stoploss"
+)
+
+func TestNewFixedBreakEvenStop_InvalidParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  BreakEvenConfig
+		wantErr bool
+	}{
+		{"Valid Percent", BreakEvenConfig{EntryPrice: d(100), IsLong: true, StopPct: d(0.05), TriggerPct: d(0.05)}, false},
+		{"Valid ATR R", BreakEvenConfig{EntryPrice: d(100), IsLong: true, ATR: d(2), ATRMultiplier: d(2), TriggerR: d(1)}, false},
+		{"Zero Entry", BreakEvenConfig{StopPct: d(0.05), TriggerPct: d(0.05)}, true},
+		{"No Initial Stop", BreakEvenConfig{EntryPrice: d(100), TriggerPct: d(0.05)}, true},
+		{"ATR Without Multiplier", BreakEvenConfig{EntryPrice: d(100), ATR: d(2), TriggerPct: d(0.05)}, true},
+		{"No Trigger", BreakEvenConfig{EntryPrice: d(100), StopPct: d(0.05)}, true},
+		{"Fees Beyond Trigger", BreakEvenConfig{EntryPrice: d(100), StopPct: d(0.05), TriggerPct: d(0.01), FeePct: d(0.01)}, true},
+		{"Negative Fees", BreakEvenConfig{EntryPrice: d(100), StopPct: d(0.05), TriggerPct: d(0.05), FeePct: d(-0.01)}, true},
+		{"Lock Fraction Above One", BreakEvenConfig{EntryPrice: d(100), StopPct: d(0.05), TriggerPct: d(0.05), LockFraction: d(1.5)}, true},
+		{"Negative Lock Step", BreakEvenConfig{EntryPrice: d(100), StopPct: d(0.05), TriggerPct: d(0.05), LockStep: d(-0.01)}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewFixedBreakEvenStop(tt.config, nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("wantErr=%v, got err=%v", tt.wantErr, err)
+			}
+			if tt.wantErr && s != nil {
+				t.Errorf("expected a nil strategy on error, got %v", s)
+			}
+		})
+	}
+	valid := BreakEvenConfig{EntryPrice: d(100), StopPct: d(0.05), TriggerPct: d(0.05)}
+	if _, err := NewDebouncedBreakEvenStop(valid, 0, nil); err == nil {
+		t.Error("expected a zero time threshold to be rejected")
+	}
+}
+
+func TestFixedBreakEvenStop_LongMovesToBreakEvenThenLocks(t *testing.T) {
+	s, err := NewFixedBreakEvenStop(BreakEvenConfig{
+		EntryPrice:   d(100),
+		IsLong:       true,
+		StopPct:      d(0.05),
+		TriggerPct:   d(0.04),
+		FeePct:       d(0.002),
+		LockFraction: d(0.5),
+		LockStep:     d(0.02),
+	}, nil)
+	if err != nil {
+		t.Fatalf("Failed to create break-even stop: %v", err)
+	}
+	if sl, _ := s.GetStopLoss(); !sl.Equal(d(95)) {
+		t.Fatalf("Expected initial SL=95, got %v", sl)
+	}
+	if sl, _ := s.CalculateStopLoss(d(103)); !sl.Equal(d(95)) || len(s.Ratchets()) != 0 {
+		t.Fatalf("Expected no move short of the trigger, got SL=%v", sl)
+	}
+
+	sl, _ := s.CalculateStopLoss(d(104))
+	if !sl.Equal(d(100.2)) || !s.(*FixedBreakEvenStop).AtBreakEven() {
+		t.Fatalf("Expected SL at entry plus fees 100.2, got %v", sl)
+	}
+	moves := s.Ratchets()
+	want := result.Ratchet{From: d(95), To: d(100.2), Reason: stoploss.RATCHETED_REASON_BREAK_EVEN}
+	if len(moves) != 1 || !moves[0].From.Equal(want.From) || !moves[0].To.Equal(want.To) || moves[0].Reason != want.Reason {
+		t.Fatalf("Expected one break-even ratchet, got %+v", moves)
+	}
+	if len(s.Ratchets()) != 0 {
+		t.Fatal("Expected Ratchets to drain the pending moves")
+	}
+
+	// the first lock level is one step past the trigger, 106 locks half of 6
+	if sl, _ := s.CalculateStopLoss(d(105.9)); !sl.Equal(d(100.2)) {
+		t.Fatalf("Expected no lock before the first step, got %v", sl)
+	}
+	sl, _ = s.CalculateStopLoss(d(107))
+	if !sl.Equal(d(103)) {
+		t.Fatalf("Expected SL=103 at the first lock level, got %v", sl)
+	}
+	moves = s.Ratchets()
+	if len(moves) != 1 || moves[0].Reason != stoploss.RATCHETED_REASON_PROFIT_LOCK || !moves[0].From.Equal(d(100.2)) {
+		t.Fatalf("Expected one profit-lock ratchet from 100.2, got %+v", moves)
+	}
+
+	// pullbacks never loosen the stop
+	if sl, _ := s.CalculateStopLoss(d(104)); !sl.Equal(d(103)) || len(s.Ratchets()) != 0 {
+		t.Fatalf("Expected SL to hold at 103, got %v", sl)
+	}
+	if triggered, _ := s.ShouldTriggerStopLoss(d(103.5)); triggered {
+		t.Error("Did not expect a trigger above the stop")
+	}
+	if triggered, _ := s.ShouldTriggerStopLoss(d(103)); !triggered {
+		t.Error("Expected a trigger at the locked stop")
+	}
+}
+
+func TestFixedBreakEvenStop_ShortWithATRAndRTrigger(t *testing.T) {
+	s, err := NewFixedBreakEvenStop(BreakEvenConfig{
+		EntryPrice:    d(100),
+		ATR:           d(2),
+		ATRMultiplier: d(1.5),
+		TriggerR:      d(2),
+		LockFraction:  d(0.5),
+	}, nil)
+	if err != nil {
+		t.Fatalf("Failed to create break-even stop: %v", err)
+	}
+	if sl, _ := s.GetStopLoss(); !sl.Equal(d(103)) {
+		t.Fatalf("Expected initial short SL=103, got %v", sl)
+	}
+	// 2R below entry is 94, without a lock step every new low locks half the profit
+	if sl, _ := s.CalculateStopLoss(d(94)); !sl.Equal(d(97)) {
+		t.Fatalf("Expected SL=97 at the trigger, got %v", sl)
+	}
+	moves := s.Ratchets()
+	if len(moves) != 2 || moves[0].Reason != stoploss.RATCHETED_REASON_BREAK_EVEN || !moves[0].To.Equal(d(100)) ||
+		moves[1].Reason != stoploss.RATCHETED_REASON_PROFIT_LOCK || !moves[1].To.Equal(d(97)) {
+		t.Fatalf("Expected break-even then profit-lock ratchets, got %+v", moves)
+	}
+	if sl, _ := s.CalculateStopLoss(d(90)); !sl.Equal(d(95)) {
+		t.Fatalf("Expected SL=95 after a new low, got %v", sl)
+	}
+	if triggered, _ := s.ShouldTriggerStopLoss(d(94)); triggered {
+		t.Error("Did not expect a short to trigger below the stop")
+	}
+	if triggered, _ := s.ShouldTriggerStopLoss(d(95)); !triggered {
+		t.Error("Expected a short to trigger at the stop")
+	}
+}
+
+func TestFixedBreakEvenStop_ReSetStopLosser(t *testing.T) {
+	s, _ := NewFixedBreakEvenStop(BreakEvenConfig{EntryPrice: d(100), IsLong: true, StopPct: d(0.05), TriggerPct: d(0.04)}, nil)
+	s.CalculateStopLoss(d(110))
+	if err := s.ReSetStopLosser(d(200)); err != nil {
+		t.Fatalf("ReSetStopLosser: %v", err)
+	}
+	if sl, _ := s.GetStopLoss(); !sl.Equal(d(190)) {
+		t.Fatalf("Expected reset SL=190, got %v", sl)
+	}
+	if s.(*FixedBreakEvenStop).AtBreakEven() || len(s.Ratchets()) != 0 {
+		t.Fatal("Expected reset to clear break-even and pending ratchets")
+	}
+	if sl, _ := s.CalculateStopLoss(d(208)); !sl.Equal(d(200)) {
+		t.Fatalf("Expected the trigger to follow the new entry, got %v", sl)
+	}
+}
+
+func TestDebouncedBreakEvenStop_WaitsForThreshold(t *testing.T) {
+	var reason string
+	callback := func(r string) error { reason = r; return nil }
+	s, err := NewDebouncedBreakEvenStop(BreakEvenConfig{EntryPrice: d(100), IsLong: true, StopPct: d(0.05), TriggerPct: d(0.04)}, 1000, callback)
+	if err != nil {
+		t.Fatalf("Failed to create break-even stop: %v", err)
+	}
+	s.CalculateStopLoss(d(105))
+	if triggered, _ := s.ShouldTriggerStopLoss(d(99), 1000); triggered {
+		t.Fatal("Did not expect a trigger on the first breach")
+	}
+	if triggered, _ := s.ShouldTriggerStopLoss(d(101), 1500); triggered {
+		t.Fatal("Did not expect a trigger after price recovered")
+	}
+	if triggered, _ := s.ShouldTriggerStopLoss(d(99), 1800); triggered {
+		t.Fatal("Expected the recovery to restart the window")
+	}
+	if triggered, _ := s.ShouldTriggerStopLoss(d(99), 2800); !triggered {
+		t.Fatal("Expected a trigger once the breach lasted the threshold")
+	}
+	if reason != stoploss.TRIGGERED_REASON_DEBOUNCED_BREAK_EVEN_STOPLOSS {
+		t.Errorf("Unexpected reason %q", reason)
+	}
+}
@@ -0,0 +1,235 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package strategy
+
+import (
+	"github.com/shopspring/decimal"
+	"github.com/wang900115/quant/model"
+	"github.com/wang900115/quant/stoploss"
+)
+
+// FixedChandelierStop hangs the stop k ATRs below the highest high of the last period bars, above the lowest low for shorts.
+// The stop only ratchets in the favorable direction, ticks are checked against the level of the last closed bar.
+type FixedChandelierStop struct {
+	stoploss.BaseResolver
+	threshold  decimal.Decimal
+	lastPrice  decimal.Decimal
+	multiplier decimal.Decimal
+	isLong     bool
+	window     barWindow
+	atr        *wilderATR
+}
+
+// DebouncedChandelierStop represents a time-based Chandelier exit
+type DebouncedChandelierStop struct {
+	FixedChandelierStop
+	TimeThreshold int64
+	TriggerTime   int64
+}
+
+// NewFixedChandelierStop creates a Chandelier exit, protected by entryPrice ∓ k×atr until period bars arrived
+func NewFixedChandelierStop(entryPrice, atr, k decimal.Decimal, period int, isLong bool, callback stoploss.DefaultCallback) (stoploss.FixedBarVolatilityStopLoss, error) {
+	c, err := newChandelier(entryPrice, atr, k, period, isLong, callback)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func newChandelier(entryPrice, atr, k decimal.Decimal, period int, isLong bool, callback stoploss.DefaultCallback) (*FixedChandelierStop, error) {
+	if k.LessThanOrEqual(decimal.Zero) {
+		return nil, errATRStopLossKInvalid
+	}
+	if atr.LessThanOrEqual(decimal.Zero) {
+		return nil, errATRInvalid
+	}
+	if period <= 0 {
+		return nil, errPeriodInvalid
+	}
+	c := &FixedChandelierStop{
+		lastPrice:  entryPrice,
+		multiplier: k,
+		isLong:     isLong,
+		window:     barWindow{size: period},
+		atr:        newWilderATR(period),
+		BaseResolver: stoploss.BaseResolver{
+			Active:   true,
+			Callback: callback,
+		},
+	}
+	c.atr.value = atr
+	c.threshold = c.offset(entryPrice)
+	return c, nil
+}
+
+// NewDebouncedChandelierStop creates a Chandelier exit that triggers once price stayed beyond the stop for timeThreshold
+func NewDebouncedChandelierStop(entryPrice, atr, k decimal.Decimal, period int, isLong bool, timeThreshold int64, callback stoploss.DefaultCallback) (stoploss.DebouncedBarVolatilityStopLoss, error) {
+	if timeThreshold <= 0 {
+		return nil, errTimeThresholdInvalid
+	}
+	fixed, err := newChandelier(entryPrice, atr, k, period, isLong, callback)
+	if err != nil {
+		return nil, err
+	}
+	return &DebouncedChandelierStop{FixedChandelierStop: *fixed, TimeThreshold: timeThreshold}, nil
+}
+
+// offset returns the level k ATRs away from anchor on the losing side
+func (c *FixedChandelierStop) offset(anchor decimal.Decimal) decimal.Decimal {
+	distance := c.atr.value.Mul(c.multiplier)
+	if c.isLong {
+		return anchor.Sub(distance)
+	}
+	return anchor.Add(distance)
+}
+
+// ratchet moves the stop to level when that tightens it
+func (c *FixedChandelierStop) ratchet(level decimal.Decimal) {
+	if c.isLong && level.GreaterThan(c.threshold) || !c.isLong && level.LessThan(c.threshold) {
+		c.threshold = level
+	}
+}
+
+// breached reports whether price is at or beyond the stop
+func (c *FixedChandelierStop) breached(price decimal.Decimal) bool {
+	if c.isLong {
+		return price.LessThanOrEqual(c.threshold)
+	}
+	return price.GreaterThanOrEqual(c.threshold)
+}
+
+// update recomputes the chandelier from the window once it holds period bars
+func (c *FixedChandelierStop) update() {
+	if !c.window.full() || !c.atr.ready() {
+		return
+	}
+	if c.isLong {
+		c.ratchet(c.offset(c.window.highest()))
+		return
+	}
+	c.ratchet(c.offset(c.window.lowest()))
+}
+
+// UpdateBar folds a closed bar into the extreme and the ATR and ratchets the stop
+func (c *FixedChandelierStop) UpdateBar(bar model.PriceInterval) error {
+	if !c.Active {
+		return stoploss.ErrStatusInvalid
+	}
+	if err := validBar(bar); err != nil {
+		return err
+	}
+	c.window.push(bar)
+	c.atr.update(bar)
+	c.update()
+	return nil
+}
+
+// UpdateATR overrides the internal ATR, later bars keep smoothing from it
+func (c *FixedChandelierStop) UpdateATR(currentATR decimal.Decimal) error {
+	if !c.Active {
+		return stoploss.ErrStatusInvalid
+	}
+	if currentATR.LessThanOrEqual(decimal.Zero) {
+		return errATRInvalid
+	}
+	c.atr.set(currentATR)
+	c.update()
+	return nil
+}
+
+// ATR returns the average true range the stop is computed with
+func (c *FixedChandelierStop) ATR() decimal.Decimal {
+	return c.atr.value
+}
+
+// CalculateStopLoss records the last price and returns the stop, which only bars move
+func (c *FixedChandelierStop) CalculateStopLoss(currentPrice decimal.Decimal) (decimal.Decimal, error) {
+	if !c.Active {
+		return decimal.Zero, stoploss.ErrStatusInvalid
+	}
+	c.lastPrice = currentPrice
+	return c.threshold, nil
+}
+
+// ShouldTriggerStopLoss checks if the stop loss should be triggered
+func (c *FixedChandelierStop) ShouldTriggerStopLoss(currentPrice decimal.Decimal) (bool, error) {
+	if !c.Active {
+		return false, stoploss.ErrStatusInvalid
+	}
+	if c.breached(currentPrice) {
+		err := c.Trigger(stoploss.TRIGGERED_REASON_FIXED_CHANDELIER_STOPLOSS)
+		if err != nil {
+			return true, stoploss.ErrCallBackFail
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// GetStopLoss returns the current stop loss threshold
+func (c *FixedChandelierStop) GetStopLoss() (decimal.Decimal, error) {
+	if !c.Active {
+		return decimal.Zero, stoploss.ErrStatusInvalid
+	}
+	return c.threshold, nil
+}
+
+// ReSetStopLosser re-anchors the stop k ATRs from the current price, dropping the ratchet
+func (c *FixedChandelierStop) ReSetStopLosser(currentPrice decimal.Decimal) error {
+	if !c.Active {
+		return stoploss.ErrStatusInvalid
+	}
+	c.lastPrice = currentPrice
+	c.threshold = c.offset(currentPrice)
+	return nil
+}
+
+// GetTimeThreshold returns the time threshold for Debounced strategies
+func (t *DebouncedChandelierStop) GetTimeThreshold() (int64, error) {
+	if !t.Active {
+		return 0, stoploss.ErrStatusInvalid
+	}
+	return t.TimeThreshold, nil
+}
+
+// ShouldTriggerStopLoss checks if the Debounced stop loss should be triggered
+func (t *DebouncedChandelierStop) ShouldTriggerStopLoss(currentPrice decimal.Decimal, currentTime int64) (bool, error) {
+	if !t.Active {
+		return false, stoploss.ErrStatusInvalid
+	}
+	if t.breached(currentPrice) {
+		if t.TriggerTime == 0 {
+			t.TriggerTime = currentTime
+		}
+		if currentTime-t.TriggerTime >= t.TimeThreshold {
+			err := t.Trigger(stoploss.TRIGGERED_REASON_DEBOUNCED_CHANDELIER_STOPLOSS)
+			if err != nil {
+				return true, stoploss.ErrCallBackFail
+			}
+			return true, nil
+		}
+	} else {
+		t.TriggerTime = 0
+	}
+	return false, nil
+}
+
+// ReSetStopLosser re-anchors the stop from the current price and clears the debounce window
+func (t *DebouncedChandelierStop) ReSetStopLosser(currentPrice decimal.Decimal) error {
+	if err := t.FixedChandelierStop.ReSetStopLosser(currentPrice); err != nil {
+		return err
+	}
+	t.TriggerTime = 0
+	return nil
+}
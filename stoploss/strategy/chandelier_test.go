@@ -0,0 +1,163 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package strategy
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestNewFixedChandelierStop_InvalidParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		atr, k  decimal.Decimal
+		period  int
+		wantErr bool
+	}{
+		{"Valid", d(2), d(3), 22, false},
+		{"Zero ATR", d(0), d(3), 22, true},
+		{"Negative K", d(2), d(-3), 22, true},
+		{"Zero Period", d(2), d(3), 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewFixedChandelierStop(d(100), tt.atr, tt.k, tt.period, true, nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("wantErr=%v, got err=%v", tt.wantErr, err)
+			}
+			if tt.wantErr && s != nil {
+				t.Errorf("expected a nil strategy on error, got %v", s)
+			}
+		})
+	}
+	if _, err := NewDebouncedChandelierStop(d(100), d(2), d(3), 22, true, 0, nil); err == nil {
+		t.Error("expected a zero time threshold to be rejected")
+	}
+}
+
+func TestFixedChandelierStop_LongRatchetsUp(t *testing.T) {
+	s, err := NewFixedChandelierStop(d(100), d(2), d(2), 3, true, nil)
+	if err != nil {
+		t.Fatalf("Failed to create chandelier stop: %v", err)
+	}
+	sl, _ := s.GetStopLoss()
+	if !sl.Equal(d(96)) { // 100 - 2*2 until the window fills
+		t.Fatalf("Expected initial SL=96, got %v", sl)
+	}
+
+	for _, b := range []struct{ h, l, c float64 }{{102, 98, 100}, {104, 100, 103}, {106, 102, 105}} {
+		if err := s.UpdateBar(bar(b.h, b.l, b.c)); err != nil {
+			t.Fatalf("UpdateBar: %v", err)
+		}
+	}
+	sl, _ = s.GetStopLoss()
+	if !sl.Equal(d(98)) { // highest high 106 - 2 * ATR 4
+		t.Fatalf("Expected SL=98 after three bars, got %v", sl)
+	}
+
+	// a wide pullback bar raises the ATR, the looser chandelier must not lower the stop
+	if err := s.UpdateBar(bar(105, 95, 96)); err != nil {
+		t.Fatalf("UpdateBar: %v", err)
+	}
+	if atr := s.(*FixedChandelierStop).ATR(); !atr.Equal(d(6)) { // (4*2 + 10) / 3
+		t.Fatalf("Expected ATR=6, got %v", atr)
+	}
+	sl, _ = s.GetStopLoss()
+	if !sl.Equal(d(98)) {
+		t.Fatalf("Expected SL to hold at 98, got %v", sl)
+	}
+	if price, _ := s.CalculateStopLoss(d(99)); !price.Equal(d(98)) {
+		t.Fatalf("Expected ticks to leave the SL at 98, got %v", price)
+	}
+
+	if triggered, _ := s.ShouldTriggerStopLoss(d(99)); triggered {
+		t.Error("Did not expect a trigger above the stop")
+	}
+	if triggered, _ := s.ShouldTriggerStopLoss(d(98)); !triggered {
+		t.Error("Expected a trigger at the stop")
+	}
+}
+
+func TestFixedChandelierStop_ShortRatchetsDown(t *testing.T) {
+	s, err := NewFixedChandelierStop(d(100), d(2), d(2), 3, false, nil)
+	if err != nil {
+		t.Fatalf("Failed to create chandelier stop: %v", err)
+	}
+	sl, _ := s.GetStopLoss()
+	if !sl.Equal(d(104)) {
+		t.Fatalf("Expected initial SL=104, got %v", sl)
+	}
+	for _, b := range []struct{ h, l, c float64 }{{102, 98, 100}, {100, 96, 97}, {98, 94, 95}, {106, 95, 105}} {
+		if err := s.UpdateBar(bar(b.h, b.l, b.c)); err != nil {
+			t.Fatalf("UpdateBar: %v", err)
+		}
+	}
+	sl, _ = s.GetStopLoss()
+	if !sl.Equal(d(102)) { // lowest low 94 + 2 * ATR 4, kept after the spike
+		t.Fatalf("Expected SL=102, got %v", sl)
+	}
+	if triggered, _ := s.ShouldTriggerStopLoss(d(101)); triggered {
+		t.Error("Did not expect a short to trigger below the stop")
+	}
+	if triggered, _ := s.ShouldTriggerStopLoss(d(102)); !triggered {
+		t.Error("Expected a short to trigger at the stop")
+	}
+}
+
+func TestFixedChandelierStop_UpdateATR(t *testing.T) {
+	s, _ := NewFixedChandelierStop(d(100), d(2), d(2), 2, true, nil)
+	s.UpdateBar(bar(110, 100, 108))
+	s.UpdateBar(bar(112, 106, 110))
+	before, _ := s.GetStopLoss()
+
+	if err := s.UpdateATR(d(1)); err != nil {
+		t.Fatalf("UpdateATR: %v", err)
+	}
+	sl, _ := s.GetStopLoss()
+	if !sl.Equal(d(110)) || !sl.GreaterThan(before) { // 112 - 2 * 1
+		t.Fatalf("Expected a tighter ATR to ratchet the SL to 110 from %v, got %v", before, sl)
+	}
+	if err := s.UpdateATR(d(0)); err == nil {
+		t.Error("Expected a zero ATR to be rejected")
+	}
+	if err := s.UpdateBar(bar(90, 95, 92)); err == nil {
+		t.Error("Expected a bar with its high below its low to be rejected")
+	}
+	if err := s.ReSetStopLosser(d(120)); err != nil {
+		t.Fatalf("ReSetStopLosser: %v", err)
+	}
+	if sl, _ := s.GetStopLoss(); !sl.Equal(d(118)) {
+		t.Fatalf("Expected reset SL=118, got %v", sl)
+	}
+}
+
+func TestDebouncedChandelierStop_WaitsForThreshold(t *testing.T) {
+	s, err := NewDebouncedChandelierStop(d(100), d(2), d(2), 3, true, 1000, nil)
+	if err != nil {
+		t.Fatalf("Failed to create chandelier stop: %v", err)
+	}
+	if triggered, _ := s.ShouldTriggerStopLoss(d(95), 1000); triggered {
+		t.Fatal("Did not expect a trigger on the first breach")
+	}
+	if triggered, _ := s.ShouldTriggerStopLoss(d(97), 1500); triggered {
+		t.Fatal("Did not expect a trigger after price recovered")
+	}
+	if triggered, _ := s.ShouldTriggerStopLoss(d(95), 1800); triggered {
+		t.Fatal("Expected the recovery to restart the window")
+	}
+	if triggered, _ := s.ShouldTriggerStopLoss(d(95), 2800); !triggered {
+		t.Fatal("Expected a trigger once the breach lasted the threshold")
+	}
+}
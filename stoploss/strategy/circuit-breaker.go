@@ -0,0 +1,334 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package strategy
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/wang900115/quant/model"
+	"github.com/wang900115/quant/model/trade"
+	"github.com/wang900115/quant/stoploss"
+)
+
+var (
+	errBreakerLimitsMissing = errors.New("circuit breaker needs at least one of daily loss, drawdown or consecutive losses")
+	errBreakerLimitInvalid  = errors.New("circuit breaker limits and reset delay must not be negative")
+	errDrawdownRateInvalid  = errors.New("drawdown rate must be between 0 and 1 and needs a positive start equity")
+	errFillInvalid          = errors.New("fill needs a BUY or SELL side, positive price and quantity and a non-negative fee")
+	errMarkPriceInvalid     = errors.New("mark price must be greater than 0")
+)
+
+// CircuitBreakerConfig configures an account-level circuit breaker, a zero limit is disabled
+type CircuitBreakerConfig struct {
+	// StartEquity is the account value PnL is added to, it anchors the peak
+	StartEquity decimal.Decimal
+	// DailyLoss halts once equity falls this much below where the trading day opened
+	DailyLoss decimal.Decimal
+	// MaxDrawdown halts once equity falls this much below its peak
+	MaxDrawdown decimal.Decimal
+	// MaxDrawdownPct halts once equity falls this fraction of its peak below the peak
+	MaxDrawdownPct decimal.Decimal
+	// MaxConsecutiveLosses halts after this many losing round trips in a row
+	MaxConsecutiveLosses int
+	// ResetAfter lifts a halt this many milliseconds after it fired, zero waits for Reset
+	ResetAfter int64
+	// Location the trading day rolls over in, nil selects UTC
+	Location *time.Location
+}
+
+// position is the net quantity held in one pair, positive for a long
+type position struct {
+	quantity decimal.Decimal
+	average  decimal.Decimal
+	mark     decimal.Decimal
+	// trip is the realized PnL net of fees since the position was last flat
+	trip decimal.Decimal
+}
+
+// CircuitBreaker books fills and marks from every pair and halts the account when the loss of the day,
+// the drawdown from peak equity or the run of losing round trips crosses its limit. It is safe to
+// query, reset and deactivate while the engine feeds it, Active is guarded by its mutex.
+type CircuitBreaker struct {
+	stoploss.BaseResolver
+	config CircuitBreakerConfig
+
+	mu        sync.Mutex
+	positions map[string]*position
+	realized  decimal.Decimal
+	peak      decimal.Decimal
+	dayOpen   decimal.Decimal
+	day       string
+	losses    int
+	halted    bool
+	haltedAt  int64
+	reason    string
+}
+
+// NewCircuitBreaker creates an account guard that flattens and blocks entries through callback when a limit is crossed
+func NewCircuitBreaker(config CircuitBreakerConfig, callback stoploss.DefaultCallback) (stoploss.AccountGuard, error) {
+	if config.DailyLoss.IsNegative() || config.MaxDrawdown.IsNegative() || config.MaxConsecutiveLosses < 0 || config.ResetAfter < 0 {
+		return nil, errBreakerLimitInvalid
+	}
+	if config.MaxDrawdownPct.IsNegative() || config.MaxDrawdownPct.GreaterThanOrEqual(decimal.NewFromInt(1)) ||
+		(config.MaxDrawdownPct.IsPositive() && !config.StartEquity.IsPositive()) {
+		return nil, errDrawdownRateInvalid
+	}
+	if config.DailyLoss.IsZero() && config.MaxDrawdown.IsZero() && config.MaxDrawdownPct.IsZero() && config.MaxConsecutiveLosses == 0 {
+		return nil, errBreakerLimitsMissing
+	}
+	if config.Location == nil {
+		config.Location = time.UTC
+	}
+	return &CircuitBreaker{
+		config:    config,
+		positions: make(map[string]*position),
+		peak:      config.StartEquity,
+		dayOpen:   config.StartEquity,
+		BaseResolver: stoploss.BaseResolver{
+			Active:   true,
+			Callback: callback,
+		},
+	}, nil
+}
+
+// UpdateFill books a fill against the position in pair, realizing PnL on the part that reduces it
+func (c *CircuitBreaker) UpdateFill(pair string, fill model.Fill) error {
+	if (fill.Side != trade.BUY && fill.Side != trade.SELL) || !fill.Price.IsPositive() || !fill.Quantity.IsPositive() || fill.Fee.IsNegative() {
+		return errFillInvalid
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.Active {
+		return stoploss.ErrStatusInvalid
+	}
+	c.roll(fill.UpdateTime)
+
+	p := c.position(pair)
+	delta := fill.Quantity
+	if fill.Side == trade.SELL {
+		delta = delta.Neg()
+	}
+	p.mark = fill.Price
+	p.trip = p.trip.Sub(fill.Fee)
+	c.realized = c.realized.Sub(fill.Fee)
+	if p.quantity.IsZero() || p.quantity.Sign() == delta.Sign() {
+		held := p.quantity.Abs()
+		p.average = held.Mul(p.average).Add(fill.Quantity.Mul(fill.Price)).Div(held.Add(fill.Quantity))
+		p.quantity = p.quantity.Add(delta)
+		c.watermark()
+		return nil
+	}
+
+	closed := decimal.Min(p.quantity.Abs(), fill.Quantity)
+	pnl := fill.Price.Sub(p.average).Mul(closed)
+	if p.quantity.IsNegative() {
+		pnl = pnl.Neg()
+	}
+	p.trip = p.trip.Add(pnl)
+	c.realized = c.realized.Add(pnl)
+	wasLong := p.quantity.IsPositive()
+	p.quantity = p.quantity.Add(delta)
+	if p.quantity.IsZero() || p.quantity.IsPositive() != wasLong {
+		c.closeTrip(p)
+		p.average = fill.Price
+		if p.quantity.IsZero() {
+			p.average = decimal.Zero
+		}
+	}
+	c.watermark()
+	return nil
+}
+
+// closeTrip counts a finished round trip towards the losing streak, callers hold the mutex
+func (c *CircuitBreaker) closeTrip(p *position) {
+	switch {
+	case p.trip.IsNegative():
+		c.losses++
+	case p.trip.IsPositive():
+		c.losses = 0
+	}
+	p.trip = decimal.Zero
+}
+
+// Mark revalues the position held in pair at price
+func (c *CircuitBreaker) Mark(pair string, price decimal.Decimal, timestamp int64) error {
+	if !price.IsPositive() {
+		return errMarkPriceInvalid
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.Active {
+		return stoploss.ErrStatusInvalid
+	}
+	c.roll(timestamp)
+	if p, ok := c.positions[pair]; ok {
+		p.mark = price
+	}
+	c.watermark()
+	return nil
+}
+
+// ShouldHalt reports true on the evaluation a limit is first crossed, firing the callback once per halt.
+// While halted it reports false and Blocked reports true.
+func (c *CircuitBreaker) ShouldHalt(timestamp int64) (bool, error) {
+	c.mu.Lock()
+	if !c.Active {
+		c.mu.Unlock()
+		return false, stoploss.ErrStatusInvalid
+	}
+	c.roll(timestamp)
+	if c.halted && c.config.ResetAfter > 0 && timestamp-c.haltedAt >= c.config.ResetAfter {
+		c.reset()
+	}
+	if c.halted {
+		c.mu.Unlock()
+		return false, nil
+	}
+	reason := c.breach()
+	if reason == "" {
+		c.mu.Unlock()
+		return false, nil
+	}
+	c.halted, c.haltedAt, c.reason = true, timestamp, reason
+	callback := c.Callback
+	c.mu.Unlock()
+
+	// the callback runs unlocked so it may query the guard while flattening
+	if callback != nil && callback(reason) != nil {
+		return true, stoploss.ErrCallBackFail
+	}
+	return true, nil
+}
+
+// Trigger runs the flatten callback with reason
+func (c *CircuitBreaker) Trigger(reason string) error {
+	c.mu.Lock()
+	if !c.Active {
+		c.mu.Unlock()
+		return stoploss.ErrStatusInvalid
+	}
+	callback := c.Callback
+	c.mu.Unlock()
+	if callback != nil {
+		return callback(reason)
+	}
+	return nil
+}
+
+// Deactivate stops the guard, later calls fail with stoploss.ErrStatusInvalid
+func (c *CircuitBreaker) Deactivate() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.BaseResolver.Deactivate()
+}
+
+// breach returns the reason of the first crossed limit, callers hold the mutex
+func (c *CircuitBreaker) breach() string {
+	equity := c.equity()
+	if c.config.DailyLoss.IsPositive() && c.dayOpen.Sub(equity).GreaterThanOrEqual(c.config.DailyLoss) {
+		return stoploss.TRIGGERED_REASON_GUARD_DAILY_LOSS
+	}
+	drawdown := c.peak.Sub(equity)
+	if c.config.MaxDrawdown.IsPositive() && drawdown.GreaterThanOrEqual(c.config.MaxDrawdown) {
+		return stoploss.TRIGGERED_REASON_GUARD_MAX_DRAWDOWN
+	}
+	if c.config.MaxDrawdownPct.IsPositive() && drawdown.GreaterThanOrEqual(c.peak.Mul(c.config.MaxDrawdownPct)) {
+		return stoploss.TRIGGERED_REASON_GUARD_MAX_DRAWDOWN
+	}
+	if c.config.MaxConsecutiveLosses > 0 && c.losses >= c.config.MaxConsecutiveLosses {
+		return stoploss.TRIGGERED_REASON_GUARD_CONSECUTIVE_LOSSES
+	}
+	return ""
+}
+
+// GetPnL returns the realized plus unrealized PnL net of fees
+func (c *CircuitBreaker) GetPnL() (decimal.Decimal, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.Active {
+		return decimal.Zero, stoploss.ErrStatusInvalid
+	}
+	return c.equity().Sub(c.config.StartEquity), nil
+}
+
+// Blocked reports whether new entries are blocked by a halt
+func (c *CircuitBreaker) Blocked() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.halted
+}
+
+// Reason returns the reason of the current halt, empty when not halted
+func (c *CircuitBreaker) Reason() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.reason
+}
+
+// Reset lifts a halt, the peak, the day open and the losing streak restart from the current equity
+func (c *CircuitBreaker) Reset() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.Active {
+		return stoploss.ErrStatusInvalid
+	}
+	c.reset()
+	return nil
+}
+
+func (c *CircuitBreaker) reset() {
+	equity := c.equity()
+	c.halted, c.haltedAt, c.reason = false, 0, ""
+	c.peak, c.dayOpen, c.losses = equity, equity, 0
+}
+
+// roll opens a new trading day at the equity before the update, callers hold the mutex.
+// Days only move forward, a late fill stamped on a previous day is booked on the current one.
+func (c *CircuitBreaker) roll(timestamp int64) {
+	// DateOnly strings sort like the dates they format
+	day := time.UnixMilli(timestamp).In(c.config.Location).Format(time.DateOnly)
+	if day <= c.day {
+		return
+	}
+	if c.day != "" {
+		c.dayOpen = c.equity()
+	}
+	c.day = day
+}
+
+// watermark raises the peak equity, callers hold the mutex
+func (c *CircuitBreaker) watermark() {
+	c.peak = decimal.Max(c.peak, c.equity())
+}
+
+// equity returns the start equity plus realized and unrealized PnL, callers hold the mutex
+func (c *CircuitBreaker) equity() decimal.Decimal {
+	equity := c.config.StartEquity.Add(c.realized)
+	for _, p := range c.positions {
+		equity = equity.Add(p.mark.Sub(p.average).Mul(p.quantity))
+	}
+	return equity
+}
+
+func (c *CircuitBreaker) position(pair string) *position {
+	p, ok := c.positions[pair]
+	if !ok {
+		p = &position{}
+		c.positions[pair] = p
+	}
+	return p
+}
@@ -0,0 +1,220 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package strategy
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wang900115/quant/model"
+	"github.com/wang900115/quant/model/trade"
+	"github.com/wang900115/quant/stoploss"
+)
+
+func buy(price, qty float64, at int64) model.Fill {
+	return model.Fill{Side: trade.BUY, Price: d(price), Quantity: d(qty), UpdateTime: at}
+}
+
+func sell(price, qty float64, at int64) model.Fill {
+	return model.Fill{Side: trade.SELL, Price: d(price), Quantity: d(qty), UpdateTime: at}
+}
+
+func TestNewCircuitBreaker_InvalidParams(t *testing.T) {
+	for _, c := range []CircuitBreakerConfig{
+		{StartEquity: d(1000)},
+		{DailyLoss: d(-1)},
+		{MaxConsecutiveLosses: -1},
+		{DailyLoss: d(10), ResetAfter: -1},
+		{MaxDrawdownPct: d(0.1)},
+		{StartEquity: d(1000), MaxDrawdownPct: d(1)},
+	} {
+		if got, err := NewCircuitBreaker(c, nil); err == nil || got != nil {
+			t.Errorf("expected config %+v to be rejected", c)
+		}
+	}
+}
+
+func TestCircuitBreaker_DailyLossFiresOnce(t *testing.T) {
+	var reasons []string
+	g, err := NewCircuitBreaker(CircuitBreakerConfig{StartEquity: d(1000), DailyLoss: d(50)},
+		func(r string) error { reasons = append(reasons, r); return nil })
+	if err != nil {
+		t.Fatalf("Failed to create circuit breaker: %v", err)
+	}
+	if err := g.UpdateFill("BTC", buy(100, 10, 0)); err != nil {
+		t.Fatalf("UpdateFill failed: %v", err)
+	}
+	g.Mark("BTC", d(96), 1000)
+	if halt, _ := g.ShouldHalt(1000); halt || g.Blocked() {
+		t.Fatal("Did not expect a halt on a 40 loss")
+	}
+	g.Mark("BTC", d(95), 2000)
+	if halt, err := g.ShouldHalt(2000); !halt || err != nil {
+		t.Fatalf("Expected a halt on a 50 loss, got %v, %v", halt, err)
+	}
+	if pnl, _ := g.GetPnL(); !pnl.Equal(d(-50)) {
+		t.Fatalf("Expected a PnL of -50, got %v", pnl)
+	}
+	g.Mark("BTC", d(90), 3000)
+	if halt, _ := g.ShouldHalt(3000); halt {
+		t.Fatal("Expected the halt to fire only once")
+	}
+	if !g.Blocked() || len(reasons) != 1 || reasons[0] != stoploss.TRIGGERED_REASON_GUARD_DAILY_LOSS {
+		t.Fatalf("Expected one daily loss flatten and blocked entries, got %v", reasons)
+	}
+	if got := g.(*CircuitBreaker).Reason(); got != stoploss.TRIGGERED_REASON_GUARD_DAILY_LOSS {
+		t.Fatalf("Expected the halt reason, got %q", got)
+	}
+}
+
+func TestCircuitBreaker_DailyLossRollsOverWithTheDay(t *testing.T) {
+	day := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+	g, _ := NewCircuitBreaker(CircuitBreakerConfig{StartEquity: d(1000), DailyLoss: d(50)}, nil)
+	g.UpdateFill("BTC", buy(100, 10, millisAt(day.Add(9*time.Hour))))
+	g.Mark("BTC", d(96), millisAt(day.Add(23*time.Hour)))
+	if halt, _ := g.ShouldHalt(millisAt(day.Add(23 * time.Hour))); halt {
+		t.Fatal("Did not expect a halt on a 40 loss")
+	}
+	// the next day opens at 960, so another 40 stays within the limit
+	next := day.Add(33 * time.Hour)
+	g.Mark("BTC", d(92), millisAt(next))
+	if halt, _ := g.ShouldHalt(millisAt(next)); halt {
+		t.Fatal("Expected the daily loss to restart from the new day's open")
+	}
+	g.Mark("BTC", d(91), millisAt(next))
+	if halt, _ := g.ShouldHalt(millisAt(next)); !halt {
+		t.Fatal("Expected a halt on a 50 loss within the day")
+	}
+}
+
+func TestCircuitBreaker_DrawdownFromPeak(t *testing.T) {
+	g, _ := NewCircuitBreaker(CircuitBreakerConfig{StartEquity: d(1000), MaxDrawdownPct: d(0.1)}, nil)
+	g.UpdateFill("BTC", buy(100, 10, 0))
+	g.UpdateFill("ETH", sell(50, 10, 0))
+	// peak 1000 + 200 long - 50 short = 1150
+	g.Mark("ETH", d(55), 1)
+	g.Mark("BTC", d(120), 1)
+	if halt, _ := g.ShouldHalt(1); halt {
+		t.Fatal("Did not expect a halt at the peak")
+	}
+	g.Mark("BTC", d(110), 2)
+	if halt, _ := g.ShouldHalt(2); halt {
+		t.Fatal("Did not expect a halt on a 100 drawdown from 1150")
+	}
+	g.Mark("ETH", d(70), 3)
+	halt, _ := g.ShouldHalt(3)
+	if !halt || g.(*CircuitBreaker).Reason() != stoploss.TRIGGERED_REASON_GUARD_MAX_DRAWDOWN {
+		t.Fatal("Expected a halt on a 250 drawdown from 1150")
+	}
+}
+
+func TestCircuitBreaker_ConsecutiveLossesWithFees(t *testing.T) {
+	g, _ := NewCircuitBreaker(CircuitBreakerConfig{MaxConsecutiveLosses: 2}, nil)
+	// a flat round trip loses its fees
+	fill := buy(100, 1, 0)
+	fill.Fee = d(0.1)
+	g.UpdateFill("BTC", fill)
+	g.UpdateFill("BTC", sell(100, 1, 1))
+	// a partial close only counts once the position is flat
+	g.UpdateFill("BTC", sell(100, 2, 2))
+	g.UpdateFill("BTC", buy(99.5, 1, 3))
+	if halt, _ := g.ShouldHalt(3); halt {
+		t.Fatal("Did not expect a halt before the second round trip closed")
+	}
+	g.UpdateFill("BTC", buy(99, 1, 4))
+	if pnl, _ := g.GetPnL(); !pnl.Equal(d(1.4)) {
+		t.Fatalf("Expected a PnL of 1.4, got %v", pnl)
+	}
+	if halt, _ := g.ShouldHalt(4); halt {
+		t.Fatal("Expected the winning round trip to end the streak")
+	}
+	g.UpdateFill("BTC", buy(100, 1, 5))
+	// selling 2 closes the long at a loss and flips to a short
+	g.UpdateFill("BTC", sell(99, 2, 6))
+	g.UpdateFill("BTC", buy(100, 1, 7))
+	if halt, _ := g.ShouldHalt(7); !halt {
+		t.Fatal("Expected a halt after two losing round trips in a row")
+	}
+}
+
+func TestCircuitBreaker_TimedAndManualReset(t *testing.T) {
+	g, _ := NewCircuitBreaker(CircuitBreakerConfig{StartEquity: d(1000), MaxDrawdown: d(50), ResetAfter: 60_000}, nil)
+	g.UpdateFill("BTC", buy(100, 10, 0))
+	g.Mark("BTC", d(94), 1000)
+	if halt, _ := g.ShouldHalt(1000); !halt {
+		t.Fatal("Expected a halt on a 60 drawdown")
+	}
+	if halt, _ := g.ShouldHalt(60_999); halt || !g.Blocked() {
+		t.Fatal("Expected entries to stay blocked until the reset delay passed")
+	}
+	if halt, _ := g.ShouldHalt(61_000); halt || g.Blocked() {
+		t.Fatal("Expected the timed reset to unblock entries from the current equity")
+	}
+	g.Mark("BTC", d(89), 62_000)
+	if halt, _ := g.ShouldHalt(62_000); !halt {
+		t.Fatal("Expected a new 50 drawdown from the reset equity to halt again")
+	}
+	if err := g.Reset(); err != nil || g.Blocked() {
+		t.Fatalf("Expected the manual reset to unblock entries, got %v", err)
+	}
+	if err := g.Mark("BTC", d(0), 63_000); err == nil {
+		t.Error("Expected a zero mark to be rejected")
+	}
+	if err := g.UpdateFill("BTC", model.Fill{Side: trade.BUY, Price: d(100)}); err == nil {
+		t.Error("Expected a fill without quantity to be rejected")
+	}
+}
+
+func TestCircuitBreaker_LateFillKeepsTheDay(t *testing.T) {
+	day := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+	g, _ := NewCircuitBreaker(CircuitBreakerConfig{StartEquity: d(1000), DailyLoss: d(50)}, nil)
+	g.UpdateFill("BTC", buy(100, 10, millisAt(day.Add(9*time.Hour))))
+	next := day.Add(33 * time.Hour)
+	g.Mark("BTC", d(98), millisAt(next))
+	// a fill stamped on the previous day arrives after the next day opened at 1000
+	g.UpdateFill("BTC", sell(97, 10, millisAt(day.Add(23*time.Hour))))
+	g.Mark("BTC", d(90), millisAt(next))
+	g.UpdateFill("ETH", sell(50, 1, millisAt(next)))
+	g.Mark("ETH", d(70), millisAt(next))
+	if halt, _ := g.ShouldHalt(millisAt(next)); !halt {
+		t.Fatal("Expected the late fill's loss to count towards the current day")
+	}
+}
+
+func TestCircuitBreaker_ConcurrentResetAndDeactivate(t *testing.T) {
+	g, _ := NewCircuitBreaker(CircuitBreakerConfig{StartEquity: d(1000), MaxDrawdown: d(1)}, func(string) error { return nil })
+	g.UpdateFill("BTC", buy(100, 1, 0))
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := int64(1); i < 200; i++ {
+			g.Mark("BTC", d(float64(100-i%3)), i)
+			g.ShouldHalt(i)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 200; i++ {
+			g.Blocked()
+			g.Reset()
+		}
+		g.Deactivate()
+	}()
+	wg.Wait()
+	if _, err := g.ShouldHalt(1000); err != stoploss.ErrStatusInvalid {
+		t.Fatalf("Expected a deactivated guard to fail, got %v", err)
+	}
+}
@@ -0,0 +1,192 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package strategy
+
+import (
+	"errors"
+	"math"
+
+	"github.com/shopspring/decimal"
+	"github.com/wang900115/quant/model"
+)
+
+var (
+	errPeriodInvalid = errors.New("period must be greater than 0")
+	errBarInvalid    = errors.New("bar high must not be below its low and prices must be positive")
+)
+
+// validBar rejects bars with inverted or non-positive prices
+func validBar(bar model.PriceInterval) error {
+	if bar.HighestPrice.LessThan(bar.LowestPrice) || !bar.LowestPrice.IsPositive() || !bar.ClosingPrice.IsPositive() {
+		return errBarInvalid
+	}
+	return nil
+}
+
+// wilderATR keeps Wilder's average true range, seeded with the mean of the first period true ranges
+type wilderATR struct {
+	period    int
+	value     decimal.Decimal
+	prevClose decimal.Decimal
+	sum       decimal.Decimal
+	seen      int
+}
+
+func newWilderATR(period int) *wilderATR {
+	return &wilderATR{period: period}
+}
+
+// update folds a closed bar into the average
+func (a *wilderATR) update(bar model.PriceInterval) {
+	tr := bar.HighestPrice.Sub(bar.LowestPrice)
+	if !a.prevClose.IsZero() {
+		tr = decimal.Max(tr, bar.HighestPrice.Sub(a.prevClose).Abs(), bar.LowestPrice.Sub(a.prevClose).Abs())
+	}
+	a.prevClose = bar.ClosingPrice
+	n := decimal.NewFromInt(int64(a.period))
+	switch {
+	case a.seen < a.period:
+		a.seen++
+		a.sum = a.sum.Add(tr)
+		if a.seen == a.period {
+			a.value = a.sum.Div(n)
+		}
+	default:
+		a.value = a.value.Mul(n.Sub(decimal.NewFromInt(1))).Add(tr).Div(n)
+	}
+}
+
+// set overrides the average, later bars keep smoothing from it
+func (a *wilderATR) set(value decimal.Decimal) {
+	a.value = value
+	a.seen = a.period
+}
+
+func (a *wilderATR) ready() bool {
+	return a.seen >= a.period
+}
+
+// barWindow holds the last size bars
+type barWindow struct {
+	size int
+	bars []model.PriceInterval
+}
+
+func (w *barWindow) push(bar model.PriceInterval) {
+	w.bars = append(w.bars, bar)
+	if len(w.bars) > w.size {
+		w.bars = w.bars[len(w.bars)-w.size:]
+	}
+}
+
+func (w *barWindow) full() bool {
+	return len(w.bars) >= w.size
+}
+
+func (w *barWindow) highest() decimal.Decimal {
+	high := w.bars[0].HighestPrice
+	for _, b := range w.bars[1:] {
+		high = decimal.Max(high, b.HighestPrice)
+	}
+	return high
+}
+
+func (w *barWindow) lowest() decimal.Decimal {
+	low := w.bars[0].LowestPrice
+	for _, b := range w.bars[1:] {
+		low = decimal.Min(low, b.LowestPrice)
+	}
+	return low
+}
+
+// channel computes bands around a moving center from closed bars
+type channel interface {
+	update(bar model.PriceInterval)
+	ready() bool
+	bands() (lower, upper decimal.Decimal)
+}
+
+// bollinger is the simple moving average of closes ± width population standard deviations
+type bollinger struct {
+	period int
+	width  decimal.Decimal
+	closes []decimal.Decimal
+}
+
+func newBollinger(period int, width decimal.Decimal) *bollinger {
+	return &bollinger{period: period, width: width}
+}
+
+func (b *bollinger) update(bar model.PriceInterval) {
+	b.closes = append(b.closes, bar.ClosingPrice)
+	if len(b.closes) > b.period {
+		b.closes = b.closes[len(b.closes)-b.period:]
+	}
+}
+
+func (b *bollinger) ready() bool {
+	return len(b.closes) >= b.period
+}
+
+func (b *bollinger) bands() (decimal.Decimal, decimal.Decimal) {
+	n := decimal.NewFromInt(int64(len(b.closes)))
+	mean := decimal.Sum(decimal.Zero, b.closes...).Div(n)
+	variance := decimal.Zero
+	for _, c := range b.closes {
+		d := c.Sub(mean)
+		variance = variance.Add(d.Mul(d))
+	}
+	// decimal has no square root, the deviation goes through float64
+	deviation := decimal.NewFromFloat(math.Sqrt(variance.Div(n).InexactFloat64())).Mul(b.width)
+	return mean.Sub(deviation), mean.Add(deviation)
+}
+
+// keltner is the exponential moving average of closes ± width ATRs, both over period bars
+type keltner struct {
+	period int
+	width  decimal.Decimal
+	ema    decimal.Decimal
+	sum    decimal.Decimal
+	seen   int
+	atr    *wilderATR
+}
+
+func newKeltner(period int, width decimal.Decimal) *keltner {
+	return &keltner{period: period, width: width, atr: newWilderATR(period)}
+}
+
+// update seeds the EMA with the mean of the first period closes, then smooths with 2/(period+1)
+func (k *keltner) update(bar model.PriceInterval) {
+	k.atr.update(bar)
+	n := decimal.NewFromInt(int64(k.period))
+	if k.seen < k.period {
+		k.seen++
+		k.sum = k.sum.Add(bar.ClosingPrice)
+		if k.seen == k.period {
+			k.ema = k.sum.Div(n)
+		}
+		return
+	}
+	alpha := decimal.NewFromInt(2).Div(n.Add(decimal.NewFromInt(1)))
+	k.ema = k.ema.Add(bar.ClosingPrice.Sub(k.ema).Mul(alpha))
+}
+
+func (k *keltner) ready() bool {
+	return k.seen >= k.period && k.atr.ready()
+}
+
+func (k *keltner) bands() (decimal.Decimal, decimal.Decimal) {
+	offset := k.atr.value.Mul(k.width)
+	return k.ema.Sub(offset), k.ema.Add(offset)
+}
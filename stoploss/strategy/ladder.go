@@ -0,0 +1,409 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package strategy
+
+import (
+	"errors"
+
+	"github.com/shopspring/decimal"
+	"github.com/wang900115/quant/model/result"
+	"github.com/wang900115/quant/stoploss"
+)
+
+var (
+	errLadderEmpty           = errors.New("ladder needs at least one target")
+	errLadderTargetInvalid   = errors.New("each ladder target needs exactly one positive percent, R or ATR offset")
+	errLadderFractionInvalid = errors.New("ladder fractions must be positive and sum to at most 1")
+	errLadderOrderInvalid    = errors.New("ladder targets must move away from entry in order")
+	errLadderStopInvalid     = errors.New("ladder stop must be on the losing side of entry")
+	errLadderQuantityInvalid = errors.New("ladder quantity must not be negative")
+)
+
+// LadderStopMove selects where the protective stop of a ladder moves after each target
+type LadderStopMove int
+
+const (
+	// LadderStopNone leaves the stop where it was placed
+	LadderStopNone LadderStopMove = iota
+	// LadderStopBreakEven moves the stop to entry after the first target
+	LadderStopBreakEven
+	// LadderStopPreviousTarget moves the stop to entry after the first target and to the previous target after later ones
+	LadderStopPreviousTarget
+)
+
+// LadderTarget is one rung of a ladder, its offset from entry is set by exactly one of Pct, R or ATR
+type LadderTarget struct {
+	// Pct of entry
+	Pct decimal.Decimal
+	// R multiples of the initial risk between entry and LadderConfig.StopPrice
+	R decimal.Decimal
+	// ATR multiples of LadderConfig.ATR
+	ATR decimal.Decimal
+	// Fraction of the initial position closed at this target
+	Fraction decimal.Decimal
+}
+
+// LadderConfig configures a scaled take profit
+type LadderConfig struct {
+	EntryPrice decimal.Decimal
+	IsLong     bool
+	// Quantity is the position size, results carry Fraction×Quantity when it is set
+	Quantity decimal.Decimal
+	// StopPrice is the optional protective stop, required by R targets and stop moves
+	StopPrice decimal.Decimal
+	// ATR is the volatility ATR targets are measured in
+	ATR      decimal.Decimal
+	Targets  []LadderTarget
+	MoveStop LadderStopMove
+}
+
+// FixedLadderProfit closes the position in parts at several targets. Each trigger carries the rung that fired and
+// the part to close, an optional protective stop closes the rest and can move up after each target.
+type FixedLadderProfit struct {
+	stoploss.BaseResolver
+	lastPrice decimal.Decimal
+	config    LadderConfig
+
+	levels    []decimal.Decimal
+	risk      decimal.Decimal
+	stop      decimal.Decimal
+	next      int
+	remaining decimal.Decimal
+	exit      *result.PartialExit
+	pending   []result.Ratchet
+}
+
+// DebouncedLadderProfit represents a time-based scaled take profit, targets and the stop must hold for the time threshold
+type DebouncedLadderProfit struct {
+	FixedLadderProfit
+	TimeThreshold   int64
+	TriggerTime     int64
+	StopTriggerTime int64
+}
+
+// NewFixedLadderProfit creates a scaled take profit with one or more targets
+func NewFixedLadderProfit(config LadderConfig, callback stoploss.DefaultCallback) (stoploss.FixedLadderTakeProfit, error) {
+	s, err := newLadder(config, callback)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// NewDebouncedLadderProfit creates a scaled take profit whose targets and stop must hold for timeThreshold
+func NewDebouncedLadderProfit(config LadderConfig, timeThreshold int64, callback stoploss.DefaultCallback) (stoploss.DebouncedLadderTakeProfit, error) {
+	if timeThreshold <= 0 {
+		return nil, errTimeThresholdInvalid
+	}
+	s, err := newLadder(config, callback)
+	if err != nil {
+		return nil, err
+	}
+	return &DebouncedLadderProfit{FixedLadderProfit: *s, TimeThreshold: timeThreshold}, nil
+}
+
+func newLadder(config LadderConfig, callback stoploss.DefaultCallback) (*FixedLadderProfit, error) {
+	if !config.EntryPrice.IsPositive() {
+		return nil, errEntryPriceInvalid
+	}
+	if len(config.Targets) == 0 {
+		return nil, errLadderEmpty
+	}
+	if config.Quantity.IsNegative() {
+		return nil, errLadderQuantityInvalid
+	}
+	total := decimal.Zero
+	needsStop := config.MoveStop != LadderStopNone
+	for _, t := range config.Targets {
+		offsets := 0
+		for _, o := range []decimal.Decimal{t.Pct, t.R, t.ATR} {
+			if o.IsNegative() {
+				return nil, errLadderTargetInvalid
+			}
+			if o.IsPositive() {
+				offsets++
+			}
+		}
+		if offsets != 1 {
+			return nil, errLadderTargetInvalid
+		}
+		if t.ATR.IsPositive() && !config.ATR.IsPositive() {
+			return nil, errATRInvalid
+		}
+		if !t.Fraction.IsPositive() {
+			return nil, errLadderFractionInvalid
+		}
+		total = total.Add(t.Fraction)
+		needsStop = needsStop || t.R.IsPositive()
+	}
+	if total.GreaterThan(decimal.NewFromInt(1)) {
+		return nil, errLadderFractionInvalid
+	}
+	s := &FixedLadderProfit{
+		config: config,
+		BaseResolver: stoploss.BaseResolver{
+			Active:   true,
+			Callback: callback,
+		},
+	}
+	if !config.StopPrice.IsZero() {
+		s.risk = s.gain(config.StopPrice, config.EntryPrice)
+		if !s.risk.IsPositive() {
+			return nil, errLadderStopInvalid
+		}
+	} else if needsStop {
+		return nil, errLadderStopInvalid
+	}
+	if err := s.open(config.EntryPrice); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// open places the targets and the stop for a position entered at entry
+func (s *FixedLadderProfit) open(entry decimal.Decimal) error {
+	levels := make([]decimal.Decimal, len(s.config.Targets))
+	for i, t := range s.config.Targets {
+		offset := entry.Mul(t.Pct)
+		if t.R.IsPositive() {
+			offset = s.risk.Mul(t.R)
+		} else if t.ATR.IsPositive() {
+			offset = s.config.ATR.Mul(t.ATR)
+		}
+		levels[i] = s.away(entry, offset)
+		if i > 0 && !s.gain(levels[i-1], levels[i]).IsPositive() {
+			return errLadderOrderInvalid
+		}
+	}
+	s.config.EntryPrice = entry
+	s.lastPrice = entry
+	s.levels = levels
+	s.stop = decimal.Zero
+	if s.risk.IsPositive() {
+		s.stop = s.away(entry, s.risk.Neg())
+	}
+	s.next = 0
+	s.remaining = decimal.NewFromInt(1)
+	s.exit = nil
+	s.pending = nil
+	return nil
+}
+
+// away returns the level distance in favor of the position from anchor, negative distances are adverse
+func (s *FixedLadderProfit) away(anchor, distance decimal.Decimal) decimal.Decimal {
+	if s.config.IsLong {
+		return anchor.Add(distance)
+	}
+	return anchor.Sub(distance)
+}
+
+// gain returns how far price moved from anchor in favor of the position
+func (s *FixedLadderProfit) gain(anchor, price decimal.Decimal) decimal.Decimal {
+	if s.config.IsLong {
+		return price.Sub(anchor)
+	}
+	return anchor.Sub(price)
+}
+
+// reached returns the number of rungs price reached, counting the ones already filled
+func (s *FixedLadderProfit) reached(price decimal.Decimal) int {
+	n := s.next
+	for n < len(s.levels) && !s.gain(s.levels[n], price).IsNegative() {
+		n++
+	}
+	return n
+}
+
+// stopped reports whether price is at or beyond the stop while part of the position is open
+func (s *FixedLadderProfit) stopped(price decimal.Decimal) bool {
+	return s.risk.IsPositive() && s.remaining.IsPositive() && !s.gain(s.stop, price).IsPositive()
+}
+
+// fill closes the rungs up to n and moves the stop
+func (s *FixedLadderProfit) fill(n int, reason string) {
+	fraction := decimal.Zero
+	for _, t := range s.config.Targets[s.next:n] {
+		fraction = fraction.Add(t.Fraction)
+	}
+	s.exit = s.partial(n, s.levels[n-1], fraction, reason)
+	s.remaining = s.remaining.Sub(fraction)
+	s.next = n
+	s.moveStop(n - 1)
+}
+
+// closeRest closes what the targets left open at the stop
+func (s *FixedLadderProfit) closeRest(reason string) {
+	s.exit = s.partial(0, s.stop, s.remaining, reason)
+	s.remaining = decimal.Zero
+	s.next = len(s.levels)
+}
+
+func (s *FixedLadderProfit) partial(rung int, target, fraction decimal.Decimal, reason string) *result.PartialExit {
+	return &result.PartialExit{
+		Rung:     rung,
+		Target:   target,
+		Fraction: fraction,
+		Quantity: fraction.Mul(s.config.Quantity),
+		Reason:   reason,
+	}
+}
+
+// moveStop tightens the stop after rung i filled
+func (s *FixedLadderProfit) moveStop(i int) {
+	if !s.risk.IsPositive() || s.config.MoveStop == LadderStopNone {
+		return
+	}
+	level, reason := s.config.EntryPrice, stoploss.RATCHETED_REASON_BREAK_EVEN
+	if s.config.MoveStop == LadderStopPreviousTarget && i > 0 {
+		level, reason = s.levels[i-1], stoploss.RATCHETED_REASON_LADDER_RUNG
+	}
+	if !s.gain(s.stop, level).IsPositive() {
+		return
+	}
+	s.pending = append(s.pending, result.Ratchet{From: s.stop, To: level, Reason: reason})
+	s.stop = level
+}
+
+// fire notifies the callback of a fill
+func (s *FixedLadderProfit) fire(reason string) (bool, error) {
+	if err := s.Trigger(reason); err != nil {
+		return true, stoploss.ErrCallBackFail
+	}
+	return true, nil
+}
+
+// target returns the next unfilled target, or the last one once all filled
+func (s *FixedLadderProfit) target() decimal.Decimal {
+	if s.next < len(s.levels) {
+		return s.levels[s.next]
+	}
+	return s.levels[len(s.levels)-1]
+}
+
+// Remaining returns the fraction of the initial position still open
+func (s *FixedLadderProfit) Remaining() decimal.Decimal {
+	return s.remaining
+}
+
+// GetStopLoss returns the protective stop, zero when the ladder has none
+func (s *FixedLadderProfit) GetStopLoss() (decimal.Decimal, error) {
+	if !s.Active {
+		return decimal.Zero, stoploss.ErrStatusInvalid
+	}
+	return s.stop, nil
+}
+
+// PartialExit returns the exit of the last trigger once
+func (s *FixedLadderProfit) PartialExit() *result.PartialExit {
+	exit := s.exit
+	s.exit = nil
+	return exit
+}
+
+// Ratchets returns the stop moves since the last call
+func (s *FixedLadderProfit) Ratchets() []result.Ratchet {
+	moves := s.pending
+	s.pending = nil
+	return moves
+}
+
+// CalculateTakeProfit returns the next target
+func (s *FixedLadderProfit) CalculateTakeProfit(currentPrice decimal.Decimal) (decimal.Decimal, error) {
+	if !s.Active {
+		return decimal.Zero, stoploss.ErrStatusInvalid
+	}
+	s.lastPrice = currentPrice
+	return s.target(), nil
+}
+
+// ShouldTriggerTakeProfit fills the targets price reached, or closes the rest at the stop
+func (s *FixedLadderProfit) ShouldTriggerTakeProfit(currentPrice decimal.Decimal) (bool, error) {
+	if !s.Active {
+		return false, stoploss.ErrStatusInvalid
+	}
+	if s.stopped(currentPrice) {
+		s.closeRest(stoploss.TRIGGERED_REASON_FIXED_LADDER_STOPLOSS)
+		return s.fire(stoploss.TRIGGERED_REASON_FIXED_LADDER_STOPLOSS)
+	}
+	if n := s.reached(currentPrice); n > s.next {
+		s.fill(n, stoploss.TRIGGERED_REASON_FIXED_LADDER_TAKEPROFIT)
+		return s.fire(stoploss.TRIGGERED_REASON_FIXED_LADDER_TAKEPROFIT)
+	}
+	return false, nil
+}
+
+// GetTakeProfit returns the next target
+func (s *FixedLadderProfit) GetTakeProfit() (decimal.Decimal, error) {
+	if !s.Active {
+		return decimal.Zero, stoploss.ErrStatusInvalid
+	}
+	return s.target(), nil
+}
+
+// ReSetTakeProfiter restarts the ladder for a full position entered at the current price
+func (s *FixedLadderProfit) ReSetTakeProfiter(currentPrice decimal.Decimal) error {
+	if !s.Active {
+		return stoploss.ErrStatusInvalid
+	}
+	return s.open(currentPrice)
+}
+
+// GetTimeThreshold returns the time threshold for Debounced strategies
+func (t *DebouncedLadderProfit) GetTimeThreshold() (int64, error) {
+	if !t.Active {
+		return 0, stoploss.ErrStatusInvalid
+	}
+	return t.TimeThreshold, nil
+}
+
+// ShouldTriggerTakeProfit fills the targets or closes the rest once price held beyond them for the time threshold
+func (t *DebouncedLadderProfit) ShouldTriggerTakeProfit(currentPrice decimal.Decimal, currentTime int64) (bool, error) {
+	if !t.Active {
+		return false, stoploss.ErrStatusInvalid
+	}
+	if t.stopped(currentPrice) {
+		if t.StopTriggerTime == 0 {
+			t.StopTriggerTime = currentTime
+		}
+		if currentTime-t.StopTriggerTime >= t.TimeThreshold {
+			t.StopTriggerTime, t.TriggerTime = 0, 0
+			t.closeRest(stoploss.TRIGGERED_REASON_DEBOUNCED_LADDER_STOPLOSS)
+			return t.fire(stoploss.TRIGGERED_REASON_DEBOUNCED_LADDER_STOPLOSS)
+		}
+	} else {
+		t.StopTriggerTime = 0
+	}
+	if n := t.reached(currentPrice); n > t.next {
+		if t.TriggerTime == 0 {
+			t.TriggerTime = currentTime
+		}
+		if currentTime-t.TriggerTime >= t.TimeThreshold {
+			t.TriggerTime = 0
+			t.fill(n, stoploss.TRIGGERED_REASON_DEBOUNCED_LADDER_TAKEPROFIT)
+			return t.fire(stoploss.TRIGGERED_REASON_DEBOUNCED_LADDER_TAKEPROFIT)
+		}
+	} else {
+		t.TriggerTime = 0
+	}
+	return false, nil
+}
+
+// ReSetTakeProfiter restarts the ladder and clears the debounce windows
+func (t *DebouncedLadderProfit) ReSetTakeProfiter(currentPrice decimal.Decimal) error {
+	if err := t.FixedLadderProfit.ReSetTakeProfiter(currentPrice); err != nil {
+		return err
+	}
+	t.TriggerTime, t.StopTriggerTime = 0, 0
+	return nil
+}
@@ -0,0 +1,217 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package strategy
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/wang900115/quant/model/result"
+	"github.com/wang900115/quant/stoploss"
+)
+
+func expectExit(t *testing.T, got *result.PartialExit, rung int, target, fraction, quantity float64, reason string) {
+	t.Helper()
+	if got == nil {
+		t.Fatalf("expected rung %d to exit, got none", rung)
+	}
+	if got.Rung != rung || !got.Target.Equal(d(target)) || !got.Fraction.Equal(d(fraction)) || !got.Quantity.Equal(d(quantity)) || got.Reason != reason {
+		t.Fatalf("expected rung %d at %v closing %v (%v) for %q, got %+v", rung, target, fraction, quantity, reason, got)
+	}
+}
+
+func TestNewFixedLadderProfit_InvalidParams(t *testing.T) {
+	pct := func(p, f float64) LadderTarget { return LadderTarget{Pct: d(p), Fraction: d(f)} }
+	tests := []struct {
+		name    string
+		config  LadderConfig
+		wantErr bool
+	}{
+		{"Valid", LadderConfig{EntryPrice: d(100), IsLong: true, Targets: []LadderTarget{pct(0.02, 0.5), pct(0.04, 0.5)}}, false},
+		{"Valid Runner", LadderConfig{EntryPrice: d(100), IsLong: true, Targets: []LadderTarget{pct(0.02, 0.5)}}, false},
+		{"Zero Entry", LadderConfig{Targets: []LadderTarget{pct(0.02, 0.5)}}, true},
+		{"No Targets", LadderConfig{EntryPrice: d(100)}, true},
+		{"Two Offsets", LadderConfig{EntryPrice: d(100), StopPrice: d(95), IsLong: true, Targets: []LadderTarget{{Pct: d(0.02), R: d(1), Fraction: d(0.5)}}}, true},
+		{"No Offset", LadderConfig{EntryPrice: d(100), Targets: []LadderTarget{{Fraction: d(0.5)}}}, true},
+		{"Fractions Above One", LadderConfig{EntryPrice: d(100), Targets: []LadderTarget{pct(0.02, 0.6), pct(0.04, 0.6)}}, true},
+		{"Zero Fraction", LadderConfig{EntryPrice: d(100), Targets: []LadderTarget{pct(0.02, 0)}}, true},
+		{"Out Of Order", LadderConfig{EntryPrice: d(100), Targets: []LadderTarget{pct(0.04, 0.5), pct(0.02, 0.5)}}, true},
+		{"R Without Stop", LadderConfig{EntryPrice: d(100), Targets: []LadderTarget{{R: d(1), Fraction: d(0.5)}}}, true},
+		{"ATR Without ATR", LadderConfig{EntryPrice: d(100), Targets: []LadderTarget{{ATR: d(1), Fraction: d(0.5)}}}, true},
+		{"Stop On Winning Side", LadderConfig{EntryPrice: d(100), IsLong: true, StopPrice: d(105), Targets: []LadderTarget{pct(0.02, 0.5)}}, true},
+		{"Move Without Stop", LadderConfig{EntryPrice: d(100), MoveStop: LadderStopBreakEven, Targets: []LadderTarget{pct(0.02, 0.5)}}, true},
+		{"Negative Quantity", LadderConfig{EntryPrice: d(100), Quantity: d(-1), Targets: []LadderTarget{pct(0.02, 0.5)}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewFixedLadderProfit(tt.config, nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("wantErr=%v, got err=%v", tt.wantErr, err)
+			}
+			if tt.wantErr && s != nil {
+				t.Errorf("expected a nil strategy on error, got %v", s)
+			}
+		})
+	}
+	valid := LadderConfig{EntryPrice: d(100), Targets: []LadderTarget{pct(0.02, 0.5)}}
+	if _, err := NewDebouncedLadderProfit(valid, 0, nil); err == nil {
+		t.Error("expected a zero time threshold to be rejected")
+	}
+}
+
+func TestFixedLadderProfit_LongFillsRungsAndMovesStop(t *testing.T) {
+	s, err := NewFixedLadderProfit(LadderConfig{
+		EntryPrice: d(100),
+		IsLong:     true,
+		Quantity:   d(2),
+		StopPrice:  d(95),
+		ATR:        d(2),
+		Targets: []LadderTarget{
+			{Pct: d(0.02), Fraction: d(0.5)},
+			{R: d(1), Fraction: d(0.25)},
+			{ATR: d(4), Fraction: d(0.25)},
+		},
+		MoveStop: LadderStopPreviousTarget,
+	}, nil)
+	if err != nil {
+		t.Fatalf("Failed to create ladder: %v", err)
+	}
+	if tp, _ := s.CalculateTakeProfit(d(101)); !tp.Equal(d(102)) {
+		t.Fatalf("Expected the first target at 102, got %v", tp)
+	}
+	if triggered, _ := s.ShouldTriggerTakeProfit(d(101)); triggered || s.PartialExit() != nil {
+		t.Fatal("Did not expect a fill short of the first target")
+	}
+
+	if triggered, _ := s.ShouldTriggerTakeProfit(d(102)); !triggered {
+		t.Fatal("Expected the first target to fill")
+	}
+	expectExit(t, s.PartialExit(), 1, 102, 0.5, 1, stoploss.TRIGGERED_REASON_FIXED_LADDER_TAKEPROFIT)
+	if s.PartialExit() != nil {
+		t.Fatal("Expected PartialExit to drain the exit")
+	}
+	moves := s.Ratchets()
+	if len(moves) != 1 || !moves[0].From.Equal(d(95)) || !moves[0].To.Equal(d(100)) || moves[0].Reason != stoploss.RATCHETED_REASON_BREAK_EVEN {
+		t.Fatalf("Expected the stop to move to entry, got %+v", moves)
+	}
+	if tp, _ := s.CalculateTakeProfit(d(102)); !tp.Equal(d(105)) { // 1R of 5
+		t.Fatalf("Expected the second target at 105, got %v", tp)
+	}
+	if triggered, _ := s.ShouldTriggerTakeProfit(d(103)); triggered {
+		t.Fatal("Did not expect a filled rung to fire again")
+	}
+
+	// a gap through both remaining targets fills them as one exit
+	if triggered, _ := s.ShouldTriggerTakeProfit(d(109)); !triggered {
+		t.Fatal("Expected the gap to fill the remaining targets")
+	}
+	expectExit(t, s.PartialExit(), 3, 108, 0.5, 1, stoploss.TRIGGERED_REASON_FIXED_LADDER_TAKEPROFIT)
+	moves = s.Ratchets()
+	if len(moves) != 1 || !moves[0].To.Equal(d(105)) || moves[0].Reason != stoploss.RATCHETED_REASON_LADDER_RUNG {
+		t.Fatalf("Expected the stop to move to the previous target, got %+v", moves)
+	}
+	if !s.(*FixedLadderProfit).Remaining().IsZero() {
+		t.Fatalf("Expected the position to be closed, %v left", s.(*FixedLadderProfit).Remaining())
+	}
+	if triggered, _ := s.ShouldTriggerTakeProfit(d(90)); triggered {
+		t.Fatal("Did not expect a closed ladder to trigger its stop")
+	}
+}
+
+func TestFixedLadderProfit_ShortStopClosesRest(t *testing.T) {
+	var reasons []string
+	callback := func(r string) error { reasons = append(reasons, r); return nil }
+	s, err := NewFixedLadderProfit(LadderConfig{
+		EntryPrice: d(100),
+		StopPrice:  d(104),
+		Targets:    []LadderTarget{{Pct: d(0.03), Fraction: d(0.6)}, {Pct: d(0.06), Fraction: d(0.4)}},
+		MoveStop:   LadderStopBreakEven,
+	}, callback)
+	if err != nil {
+		t.Fatalf("Failed to create ladder: %v", err)
+	}
+	if triggered, _ := s.ShouldTriggerTakeProfit(d(97)); !triggered {
+		t.Fatal("Expected a short to fill below entry")
+	}
+	expectExit(t, s.PartialExit(), 1, 97, 0.6, 0, stoploss.TRIGGERED_REASON_FIXED_LADDER_TAKEPROFIT)
+	if sl, _ := s.(*FixedLadderProfit).GetStopLoss(); !sl.Equal(d(100)) {
+		t.Fatalf("Expected the stop at entry, got %v", sl)
+	}
+	if triggered, _ := s.ShouldTriggerTakeProfit(d(99)); triggered {
+		t.Fatal("Did not expect a trigger between the stop and the next target")
+	}
+	if triggered, _ := s.ShouldTriggerTakeProfit(d(100)); !triggered {
+		t.Fatal("Expected the stop to close the rest")
+	}
+	expectExit(t, s.PartialExit(), 0, 100, 0.4, 0, stoploss.TRIGGERED_REASON_FIXED_LADDER_STOPLOSS)
+	if len(reasons) != 2 || reasons[1] != stoploss.TRIGGERED_REASON_FIXED_LADDER_STOPLOSS {
+		t.Fatalf("Expected the callback for the fill and the stop, got %v", reasons)
+	}
+}
+
+func TestFixedLadderProfit_ReSetTakeProfiter(t *testing.T) {
+	s, _ := NewFixedLadderProfit(LadderConfig{
+		EntryPrice: d(100),
+		IsLong:     true,
+		StopPrice:  d(95),
+		Targets:    []LadderTarget{{R: d(1), Fraction: d(1)}},
+		MoveStop:   LadderStopBreakEven,
+	}, nil)
+	s.ShouldTriggerTakeProfit(d(105))
+	if err := s.ReSetTakeProfiter(d(200)); err != nil {
+		t.Fatalf("ReSetTakeProfiter: %v", err)
+	}
+	if tp, _ := s.GetTakeProfit(); !tp.Equal(d(205)) {
+		t.Fatalf("Expected the target to keep its 1R of 5 from the new entry, got %v", tp)
+	}
+	if sl, _ := s.(*FixedLadderProfit).GetStopLoss(); !sl.Equal(d(195)) {
+		t.Fatalf("Expected the stop to keep its risk from the new entry, got %v", sl)
+	}
+	if !s.(*FixedLadderProfit).Remaining().Equal(decimal.NewFromInt(1)) || s.PartialExit() != nil || len(s.Ratchets()) != 0 {
+		t.Fatal("Expected reset to reopen the full position")
+	}
+}
+
+func TestDebouncedLadderProfit_WaitsForThreshold(t *testing.T) {
+	s, err := NewDebouncedLadderProfit(LadderConfig{
+		EntryPrice: d(100),
+		IsLong:     true,
+		StopPrice:  d(95),
+		Targets:    []LadderTarget{{Pct: d(0.02), Fraction: d(0.5)}},
+	}, 1000, nil)
+	if err != nil {
+		t.Fatalf("Failed to create ladder: %v", err)
+	}
+	if triggered, _ := s.ShouldTriggerTakeProfit(d(102), 1000); triggered {
+		t.Fatal("Did not expect a fill on the first touch")
+	}
+	if triggered, _ := s.ShouldTriggerTakeProfit(d(101), 1500); triggered {
+		t.Fatal("Did not expect a fill after price fell back")
+	}
+	if triggered, _ := s.ShouldTriggerTakeProfit(d(102), 1800); triggered {
+		t.Fatal("Expected the pullback to restart the window")
+	}
+	if triggered, _ := s.ShouldTriggerTakeProfit(d(103), 2800); !triggered {
+		t.Fatal("Expected a fill once the target held for the threshold")
+	}
+	expectExit(t, s.PartialExit(), 1, 102, 0.5, 0, stoploss.TRIGGERED_REASON_DEBOUNCED_LADDER_TAKEPROFIT)
+
+	if triggered, _ := s.ShouldTriggerTakeProfit(d(95), 3000); triggered {
+		t.Fatal("Did not expect the stop to close the rest on the first touch")
+	}
+	if triggered, _ := s.ShouldTriggerTakeProfit(d(94), 4000); !triggered {
+		t.Fatal("Expected the stop to close the rest once it held for the threshold")
+	}
+	expectExit(t, s.PartialExit(), 0, 95, 0.5, 0, stoploss.TRIGGERED_REASON_DEBOUNCED_LADDER_STOPLOSS)
+}
@@ -0,0 +1,290 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package strategy
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/shopspring/decimal"
+	"github.com/wang900115/quant/model"
+	"github.com/wang900115/quant/stoploss"
+)
+
+var (
+	errLiquidityStopInvalid     = errors.New("stop price must be greater than 0 and on the losing side of entry")
+	errLiquidityQuantityInvalid = errors.New("quantity must be greater than 0")
+	errLiquidityDepthInvalid    = errors.New("depth rate must be between 0 and 1 and set together with a positive minimum depth")
+	errOrderBookInvalid         = errors.New("order book levels need positive prices and non-negative quantities")
+)
+
+// LiquidityConfig configures a liquidity-aware stop
+type LiquidityConfig struct {
+	EntryPrice decimal.Decimal
+	IsLong     bool
+	// StopPrice is the price the exit is meant to fill at
+	StopPrice decimal.Decimal
+	// Quantity is the position size the exit walks the book for
+	Quantity decimal.Decimal
+	// DepthPct and MinDepth trigger early when the exit side within DepthPct of its best price holds less than MinDepth
+	DepthPct decimal.Decimal
+	MinDepth decimal.Decimal
+}
+
+// FixedLiquidityStop moves its trigger ahead of the stop price by the slippage expected when exiting Quantity
+// into the current book, so the expected fill lands near StopPrice. It sells into bids for a long, buys from asks for a short.
+type FixedLiquidityStop struct {
+	stoploss.BaseResolver
+	config    LiquidityConfig
+	lastPrice decimal.Decimal
+
+	threshold decimal.Decimal
+	expected  decimal.Decimal
+	depth     decimal.Decimal
+	booked    bool
+}
+
+// DebouncedLiquidityStop represents a time-based liquidity-aware stop
+type DebouncedLiquidityStop struct {
+	FixedLiquidityStop
+	TimeThreshold    int64
+	TriggerTime      int64
+	DepthTriggerTime int64
+}
+
+// NewFixedLiquidityStop creates a stop whose trigger follows the order book depth
+func NewFixedLiquidityStop(config LiquidityConfig, callback stoploss.DefaultCallback) (stoploss.FixedBookStopLoss, error) {
+	s, err := newLiquidityStop(config, callback)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// NewDebouncedLiquidityStop creates a liquidity-aware stop whose conditions must hold for timeThreshold
+func NewDebouncedLiquidityStop(config LiquidityConfig, timeThreshold int64, callback stoploss.DefaultCallback) (stoploss.DebouncedBookStopLoss, error) {
+	if timeThreshold <= 0 {
+		return nil, errTimeThresholdInvalid
+	}
+	s, err := newLiquidityStop(config, callback)
+	if err != nil {
+		return nil, err
+	}
+	return &DebouncedLiquidityStop{FixedLiquidityStop: *s, TimeThreshold: timeThreshold}, nil
+}
+
+func newLiquidityStop(config LiquidityConfig, callback stoploss.DefaultCallback) (*FixedLiquidityStop, error) {
+	if !config.EntryPrice.IsPositive() {
+		return nil, errEntryPriceInvalid
+	}
+	if !config.StopPrice.IsPositive() || (config.IsLong && config.StopPrice.GreaterThanOrEqual(config.EntryPrice)) ||
+		(!config.IsLong && config.StopPrice.LessThanOrEqual(config.EntryPrice)) {
+		return nil, errLiquidityStopInvalid
+	}
+	if !config.Quantity.IsPositive() {
+		return nil, errLiquidityQuantityInvalid
+	}
+	if config.DepthPct.IsNegative() || config.DepthPct.GreaterThanOrEqual(decimal.NewFromInt(1)) || config.MinDepth.IsNegative() ||
+		config.DepthPct.IsPositive() != config.MinDepth.IsPositive() {
+		return nil, errLiquidityDepthInvalid
+	}
+	return &FixedLiquidityStop{
+		config:    config,
+		lastPrice: config.EntryPrice,
+		threshold: config.StopPrice,
+		BaseResolver: stoploss.BaseResolver{
+			Active:   true,
+			Callback: callback,
+		},
+	}, nil
+}
+
+// exitSide returns the levels the exit fills against, best price first
+func (s *FixedLiquidityStop) exitSide(book model.OrderBook) []model.OrderBookBase {
+	var levels []model.OrderBookBase
+	if s.config.IsLong {
+		for _, b := range book.Bids {
+			levels = append(levels, model.OrderBookBase(b))
+		}
+		sort.SliceStable(levels, func(i, j int) bool { return levels[i].Price.GreaterThan(levels[j].Price) })
+		return levels
+	}
+	for _, a := range book.Asks {
+		levels = append(levels, model.OrderBookBase(a))
+	}
+	sort.SliceStable(levels, func(i, j int) bool { return levels[i].Price.LessThan(levels[j].Price) })
+	return levels
+}
+
+// UpdateOrderBook walks the exit side for Quantity and moves the trigger ahead of the stop by the expected slippage
+func (s *FixedLiquidityStop) UpdateOrderBook(book model.OrderBook) error {
+	if !s.Active {
+		return stoploss.ErrStatusInvalid
+	}
+	levels := s.exitSide(book)
+	for _, l := range levels {
+		if !l.Price.IsPositive() || l.Quantity.IsNegative() {
+			return errOrderBookInvalid
+		}
+	}
+	s.booked = true
+	s.expected, s.depth, s.threshold = decimal.Zero, decimal.Zero, s.config.StopPrice
+	if len(levels) == 0 {
+		return nil
+	}
+	best := levels[0].Price
+	band := best.Mul(s.config.DepthPct)
+	left, cost := s.config.Quantity, decimal.Zero
+	last := best
+	for _, l := range levels {
+		if s.config.DepthPct.IsPositive() && l.Price.Sub(best).Abs().LessThanOrEqual(band) {
+			s.depth = s.depth.Add(l.Quantity)
+		}
+		if left.IsPositive() && l.Quantity.IsPositive() {
+			take := decimal.Min(left, l.Quantity)
+			cost = cost.Add(take.Mul(l.Price))
+			left = left.Sub(take)
+			last = l.Price
+		}
+	}
+	// a book too thin for the whole size fills the rest at its last level
+	cost = cost.Add(left.Mul(last))
+	s.expected = cost.Div(s.config.Quantity)
+	slippage := s.expected.Sub(best).Abs()
+	if s.config.IsLong {
+		s.threshold = s.config.StopPrice.Add(slippage)
+	} else {
+		s.threshold = s.config.StopPrice.Sub(slippage)
+	}
+	return nil
+}
+
+// ExpectedFill returns the average price exiting Quantity into the last book would fill at, zero before a book
+func (s *FixedLiquidityStop) ExpectedFill() decimal.Decimal {
+	return s.expected
+}
+
+// Depth returns the exit side quantity within DepthPct of its best price in the last book
+func (s *FixedLiquidityStop) Depth() decimal.Decimal {
+	return s.depth
+}
+
+// breached reports whether price crossed the liquidity adjusted trigger
+func (s *FixedLiquidityStop) breached(price decimal.Decimal) bool {
+	if s.config.IsLong {
+		return price.LessThanOrEqual(s.threshold)
+	}
+	return price.GreaterThanOrEqual(s.threshold)
+}
+
+// thin reports whether the exit side depth collapsed below MinDepth
+func (s *FixedLiquidityStop) thin() bool {
+	return s.booked && s.config.MinDepth.IsPositive() && s.depth.LessThan(s.config.MinDepth)
+}
+
+func (s *FixedLiquidityStop) fire(reason string) (bool, error) {
+	if err := s.Trigger(reason); err != nil {
+		return true, stoploss.ErrCallBackFail
+	}
+	return true, nil
+}
+
+// CalculateStopLoss returns the liquidity adjusted trigger
+func (s *FixedLiquidityStop) CalculateStopLoss(currentPrice decimal.Decimal) (decimal.Decimal, error) {
+	if !s.Active {
+		return decimal.Zero, stoploss.ErrStatusInvalid
+	}
+	s.lastPrice = currentPrice
+	return s.threshold, nil
+}
+
+// ShouldTriggerStopLoss checks the adjusted trigger, then the depth of the exit side
+func (s *FixedLiquidityStop) ShouldTriggerStopLoss(currentPrice decimal.Decimal) (bool, error) {
+	if !s.Active {
+		return false, stoploss.ErrStatusInvalid
+	}
+	if s.breached(currentPrice) {
+		return s.fire(stoploss.TRIGGERED_REASON_FIXED_LIQUIDITY_STOPLOSS)
+	}
+	if s.thin() {
+		return s.fire(stoploss.TRIGGERED_REASON_FIXED_LIQUIDITY_DEPTH)
+	}
+	return false, nil
+}
+
+// GetStopLoss returns the liquidity adjusted trigger
+func (s *FixedLiquidityStop) GetStopLoss() (decimal.Decimal, error) {
+	if !s.Active {
+		return decimal.Zero, stoploss.ErrStatusInvalid
+	}
+	return s.threshold, nil
+}
+
+// ReSetStopLosser moves entry to the current price and keeps the stop distance, the next book adjusts the trigger again
+func (s *FixedLiquidityStop) ReSetStopLosser(currentPrice decimal.Decimal) error {
+	if !s.Active {
+		return stoploss.ErrStatusInvalid
+	}
+	s.config.StopPrice = currentPrice.Add(s.config.StopPrice.Sub(s.config.EntryPrice))
+	s.config.EntryPrice = currentPrice
+	s.lastPrice = currentPrice
+	s.threshold = s.config.StopPrice
+	s.expected, s.depth, s.booked = decimal.Zero, decimal.Zero, false
+	return nil
+}
+
+// GetTimeThreshold returns the time threshold for Debounced strategies
+func (t *DebouncedLiquidityStop) GetTimeThreshold() (int64, error) {
+	if !t.Active {
+		return 0, stoploss.ErrStatusInvalid
+	}
+	return t.TimeThreshold, nil
+}
+
+// ShouldTriggerStopLoss checks if the adjusted trigger or the depth collapse held for the time threshold
+func (t *DebouncedLiquidityStop) ShouldTriggerStopLoss(currentPrice decimal.Decimal, currentTime int64) (bool, error) {
+	if !t.Active {
+		return false, stoploss.ErrStatusInvalid
+	}
+	if t.breached(currentPrice) {
+		if t.TriggerTime == 0 {
+			t.TriggerTime = currentTime
+		}
+		if currentTime-t.TriggerTime >= t.TimeThreshold {
+			return t.fire(stoploss.TRIGGERED_REASON_DEBOUNCED_LIQUIDITY_STOPLOSS)
+		}
+	} else {
+		t.TriggerTime = 0
+	}
+	if t.thin() {
+		if t.DepthTriggerTime == 0 {
+			t.DepthTriggerTime = currentTime
+		}
+		if currentTime-t.DepthTriggerTime >= t.TimeThreshold {
+			return t.fire(stoploss.TRIGGERED_REASON_DEBOUNCED_LIQUIDITY_DEPTH)
+		}
+	} else {
+		t.DepthTriggerTime = 0
+	}
+	return false, nil
+}
+
+// ReSetStopLosser moves the stop with the entry and clears the debounce windows
+func (t *DebouncedLiquidityStop) ReSetStopLosser(currentPrice decimal.Decimal) error {
+	if err := t.FixedLiquidityStop.ReSetStopLosser(currentPrice); err != nil {
+		return err
+	}
+	t.TriggerTime, t.DepthTriggerTime = 0, 0
+	return nil
+}
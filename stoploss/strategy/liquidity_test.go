@@ -0,0 +1,181 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package strategy
+
+import (
+	"testing"
+
+	"github.com/wang900115/quant/model"
+	"github.com/wang900115/quant/stoploss"
+)
+
+func book(bids, asks [][2]float64) model.OrderBook {
+	var b model.OrderBook
+	for _, l := range bids {
+		b.Bids = append(b.Bids, model.OrderBookBid{Price: d(l[0]), Quantity: d(l[1])})
+	}
+	for _, l := range asks {
+		b.Asks = append(b.Asks, model.OrderBookAsk{Price: d(l[0]), Quantity: d(l[1])})
+	}
+	return b
+}
+
+func TestNewFixedLiquidityStop_InvalidParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  LiquidityConfig
+		wantErr bool
+	}{
+		{"Valid", LiquidityConfig{EntryPrice: d(100), IsLong: true, StopPrice: d(95), Quantity: d(1)}, false},
+		{"Valid Depth", LiquidityConfig{EntryPrice: d(100), StopPrice: d(105), Quantity: d(1), DepthPct: d(0.01), MinDepth: d(5)}, false},
+		{"Zero Entry", LiquidityConfig{StopPrice: d(95), Quantity: d(1)}, true},
+		{"Long Stop Above Entry", LiquidityConfig{EntryPrice: d(100), IsLong: true, StopPrice: d(101), Quantity: d(1)}, true},
+		{"Short Stop Below Entry", LiquidityConfig{EntryPrice: d(100), StopPrice: d(99), Quantity: d(1)}, true},
+		{"Zero Quantity", LiquidityConfig{EntryPrice: d(100), IsLong: true, StopPrice: d(95)}, true},
+		{"Depth Without Minimum", LiquidityConfig{EntryPrice: d(100), IsLong: true, StopPrice: d(95), Quantity: d(1), DepthPct: d(0.01)}, true},
+		{"Depth Rate Of One", LiquidityConfig{EntryPrice: d(100), IsLong: true, StopPrice: d(95), Quantity: d(1), DepthPct: d(1), MinDepth: d(1)}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewFixedLiquidityStop(tt.config, nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("wantErr=%v, got err=%v", tt.wantErr, err)
+			}
+			if tt.wantErr && s != nil {
+				t.Errorf("expected a nil strategy on error, got %v", s)
+			}
+		})
+	}
+	valid := LiquidityConfig{EntryPrice: d(100), IsLong: true, StopPrice: d(95), Quantity: d(1)}
+	if _, err := NewDebouncedLiquidityStop(valid, 0, nil); err == nil {
+		t.Error("expected a zero time threshold to be rejected")
+	}
+}
+
+func TestFixedLiquidityStop_LongMovesTriggerBySlippage(t *testing.T) {
+	var reason string
+	s, err := NewFixedLiquidityStop(LiquidityConfig{
+		EntryPrice: d(100),
+		IsLong:     true,
+		StopPrice:  d(95),
+		Quantity:   d(4),
+		DepthPct:   d(0.01),
+		MinDepth:   d(2),
+	}, func(r string) error { reason = r; return nil })
+	if err != nil {
+		t.Fatalf("Failed to create liquidity stop: %v", err)
+	}
+	if sl, _ := s.GetStopLoss(); !sl.Equal(d(95)) {
+		t.Fatalf("Expected the stop price before any book, got %v", sl)
+	}
+
+	// unsorted bids, selling 4 fills 2 at 99 and 2 at 97
+	if err := s.UpdateOrderBook(book([][2]float64{{97, 2}, {99, 2}}, [][2]float64{{101, 5}})); err != nil {
+		t.Fatalf("UpdateOrderBook: %v", err)
+	}
+	ls := s.(*FixedLiquidityStop)
+	if !ls.ExpectedFill().Equal(d(98)) || !ls.Depth().Equal(d(2)) {
+		t.Fatalf("Expected a fill at 98 with depth 2, got %v and %v", ls.ExpectedFill(), ls.Depth())
+	}
+	if sl, _ := s.CalculateStopLoss(d(99)); !sl.Equal(d(96)) {
+		t.Fatalf("Expected the trigger one slippage ahead at 96, got %v", sl)
+	}
+	if triggered, _ := s.ShouldTriggerStopLoss(d(96.5)); triggered {
+		t.Error("Did not expect a trigger above the adjusted stop")
+	}
+	if triggered, _ := s.ShouldTriggerStopLoss(d(96)); !triggered || reason != stoploss.TRIGGERED_REASON_FIXED_LIQUIDITY_STOPLOSS {
+		t.Errorf("Expected a trigger at the adjusted stop, got reason %q", reason)
+	}
+	if err := s.UpdateOrderBook(book([][2]float64{{99, -1}}, nil)); err == nil {
+		t.Error("Expected a negative quantity to be rejected")
+	}
+}
+
+func TestFixedLiquidityStop_DepthCollapseTriggersEarly(t *testing.T) {
+	var reason string
+	s, _ := NewFixedLiquidityStop(LiquidityConfig{
+		EntryPrice: d(100),
+		IsLong:     true,
+		StopPrice:  d(95),
+		Quantity:   d(1),
+		DepthPct:   d(0.01),
+		MinDepth:   d(2),
+	}, func(r string) error { reason = r; return nil })
+
+	s.UpdateOrderBook(book([][2]float64{{99, 3}, {90, 50}}, nil))
+	if triggered, _ := s.ShouldTriggerStopLoss(d(99)); triggered {
+		t.Fatal("Did not expect a trigger with enough depth near the best bid")
+	}
+	// 50 lots sit 9% away, only 1 within 1% of the best bid
+	s.UpdateOrderBook(book([][2]float64{{99, 1}, {90, 50}}, nil))
+	if triggered, _ := s.ShouldTriggerStopLoss(d(99)); !triggered || reason != stoploss.TRIGGERED_REASON_FIXED_LIQUIDITY_DEPTH {
+		t.Fatalf("Expected the collapsed depth to trigger early, got reason %q", reason)
+	}
+	if sl, _ := s.GetStopLoss(); !sl.Equal(d(95)) {
+		t.Fatalf("Expected a size filled at the best bid to leave the stop at 95, got %v", sl)
+	}
+}
+
+func TestFixedLiquidityStop_ShortAndReset(t *testing.T) {
+	s, _ := NewFixedLiquidityStop(LiquidityConfig{EntryPrice: d(100), StopPrice: d(105), Quantity: d(2)}, nil)
+	s.UpdateOrderBook(book(nil, [][2]float64{{101, 1}, {103, 1}}))
+	if sl, _ := s.GetStopLoss(); !sl.Equal(d(104)) { // buying 2 fills at 102 on average
+		t.Fatalf("Expected a short trigger at 104, got %v", sl)
+	}
+	if triggered, _ := s.ShouldTriggerStopLoss(d(103.9)); triggered {
+		t.Error("Did not expect a short to trigger below the adjusted stop")
+	}
+	if triggered, _ := s.ShouldTriggerStopLoss(d(104)); !triggered {
+		t.Error("Expected a short to trigger at the adjusted stop")
+	}
+
+	if err := s.ReSetStopLosser(d(200)); err != nil {
+		t.Fatalf("ReSetStopLosser: %v", err)
+	}
+	if sl, _ := s.GetStopLoss(); !sl.Equal(d(205)) {
+		t.Fatalf("Expected reset to keep the 5 stop distance, got %v", sl)
+	}
+	if !s.(*FixedLiquidityStop).ExpectedFill().IsZero() {
+		t.Fatal("Expected reset to drop the stale book")
+	}
+}
+
+func TestDebouncedLiquidityStop_WaitsForThreshold(t *testing.T) {
+	s, err := NewDebouncedLiquidityStop(LiquidityConfig{
+		EntryPrice: d(100),
+		IsLong:     true,
+		StopPrice:  d(95),
+		Quantity:   d(1),
+		DepthPct:   d(0.01),
+		MinDepth:   d(2),
+	}, 1000, nil)
+	if err != nil {
+		t.Fatalf("Failed to create liquidity stop: %v", err)
+	}
+	s.UpdateOrderBook(book([][2]float64{{99, 1}}, nil))
+	if triggered, _ := s.ShouldTriggerStopLoss(d(99), 1000); triggered {
+		t.Fatal("Did not expect a trigger when the depth first collapsed")
+	}
+	s.UpdateOrderBook(book([][2]float64{{99, 5}}, nil))
+	if triggered, _ := s.ShouldTriggerStopLoss(d(99), 1500); triggered {
+		t.Fatal("Did not expect a trigger after the depth recovered")
+	}
+	s.UpdateOrderBook(book([][2]float64{{99, 1}}, nil))
+	if triggered, _ := s.ShouldTriggerStopLoss(d(99), 1800); triggered {
+		t.Fatal("Expected the recovery to restart the window")
+	}
+	if triggered, _ := s.ShouldTriggerStopLoss(d(99), 2800); !triggered {
+		t.Fatal("Expected a trigger once the collapse lasted the threshold")
+	}
+}
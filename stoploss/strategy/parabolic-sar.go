@@ -0,0 +1,292 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package strategy
+
+import (
+	"errors"
+
+	"github.com/shopspring/decimal"
+	"github.com/wang900115/quant/model"
+	"github.com/wang900115/quant/stoploss"
+)
+
+var (
+	errSARFactorInvalid = errors.New("acceleration factor start and step must be greater than 0 and start must not exceed max, which must not exceed 1")
+)
+
+// FixedParabolicSARStop trails the position with Wilder's Parabolic SAR computed from bar highs and lows.
+// It triggers when a tick crosses the SAR, or when a bar flips the SAR trend against the position.
+// The entry price gives no range to start from, so the position is unprotected until the first bar:
+// feed the last closed bar right after registering, or pair it with a fixed stop until then.
+type FixedParabolicSARStop struct {
+	stoploss.BaseResolver
+	lastPrice decimal.Decimal
+	isLong    bool
+	afStart   decimal.Decimal
+	afStep    decimal.Decimal
+	afMax     decimal.Decimal
+
+	// seeded is set by the first bar, there is no SAR before it
+	seeded  bool
+	uptrend bool
+	sar     decimal.Decimal
+	ep      decimal.Decimal
+	af      decimal.Decimal
+	// prev holds the last two bars, newest last, the SAR may not penetrate their range
+	prev []model.PriceInterval
+}
+
+// DebouncedParabolicSARStop represents a time-based Parabolic SAR stop
+type DebouncedParabolicSARStop struct {
+	FixedParabolicSARStop
+	TimeThreshold int64
+	TriggerTime   int64
+}
+
+// NewFixedParabolicSARStop creates a Parabolic SAR stop, typically with afStart 0.02, afStep 0.02 and afMax 0.2.
+// It has no stop until its first bar.
+func NewFixedParabolicSARStop(entryPrice, afStart, afStep, afMax decimal.Decimal, isLong bool, callback stoploss.DefaultCallback) (stoploss.FixedBarStopLoss, error) {
+	s, err := newParabolicSAR(entryPrice, afStart, afStep, afMax, isLong, callback)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// NewDebouncedParabolicSARStop creates a Parabolic SAR stop that triggers once price stayed beyond the SAR for timeThreshold.
+// It has no stop until its first bar.
+func NewDebouncedParabolicSARStop(entryPrice, afStart, afStep, afMax decimal.Decimal, isLong bool, timeThreshold int64, callback stoploss.DefaultCallback) (stoploss.DebouncedBarStopLoss, error) {
+	if timeThreshold <= 0 {
+		return nil, errTimeThresholdInvalid
+	}
+	s, err := newParabolicSAR(entryPrice, afStart, afStep, afMax, isLong, callback)
+	if err != nil {
+		return nil, err
+	}
+	return &DebouncedParabolicSARStop{FixedParabolicSARStop: *s, TimeThreshold: timeThreshold}, nil
+}
+
+func newParabolicSAR(entryPrice, afStart, afStep, afMax decimal.Decimal, isLong bool, callback stoploss.DefaultCallback) (*FixedParabolicSARStop, error) {
+	if !afStart.IsPositive() || !afStep.IsPositive() || afStart.GreaterThan(afMax) || afMax.GreaterThan(decimal.NewFromInt(1)) {
+		return nil, errSARFactorInvalid
+	}
+	return &FixedParabolicSARStop{
+		lastPrice: entryPrice,
+		isLong:    isLong,
+		afStart:   afStart,
+		afStep:    afStep,
+		afMax:     afMax,
+		uptrend:   isLong,
+		af:        afStart,
+		BaseResolver: stoploss.BaseResolver{
+			Active:   true,
+			Callback: callback,
+		},
+	}, nil
+}
+
+// UpdateBar advances the SAR by one closed bar, flipping the trend when the bar penetrates it
+func (s *FixedParabolicSARStop) UpdateBar(bar model.PriceInterval) error {
+	if !s.Active {
+		return stoploss.ErrStatusInvalid
+	}
+	if err := validBar(bar); err != nil {
+		return err
+	}
+	defer s.remember(bar)
+	if !s.seeded {
+		s.seed(bar)
+		return nil
+	}
+	s.sar = s.sar.Add(s.af.Mul(s.ep.Sub(s.sar)))
+	if s.uptrend {
+		for _, p := range s.prev {
+			s.sar = decimal.Min(s.sar, p.LowestPrice)
+		}
+		switch {
+		case bar.LowestPrice.LessThanOrEqual(s.sar):
+			s.flip(bar.LowestPrice)
+		case bar.HighestPrice.GreaterThan(s.ep):
+			s.ep = bar.HighestPrice
+			s.accelerate()
+		}
+		return nil
+	}
+	for _, p := range s.prev {
+		s.sar = decimal.Max(s.sar, p.HighestPrice)
+	}
+	switch {
+	case bar.HighestPrice.GreaterThanOrEqual(s.sar):
+		s.flip(bar.HighestPrice)
+	case bar.LowestPrice.LessThan(s.ep):
+		s.ep = bar.LowestPrice
+		s.accelerate()
+	}
+	return nil
+}
+
+// seed starts the SAR at the far side of the first bar, in the direction of the position
+func (s *FixedParabolicSARStop) seed(bar model.PriceInterval) {
+	s.seeded = true
+	s.uptrend = s.isLong
+	s.af = s.afStart
+	if s.isLong {
+		s.sar, s.ep = bar.LowestPrice, bar.HighestPrice
+		return
+	}
+	s.sar, s.ep = bar.HighestPrice, bar.LowestPrice
+}
+
+// flip reverses the trend, the SAR restarts at the old extreme point
+func (s *FixedParabolicSARStop) flip(extreme decimal.Decimal) {
+	s.uptrend = !s.uptrend
+	s.sar = s.ep
+	s.ep = extreme
+	s.af = s.afStart
+}
+
+func (s *FixedParabolicSARStop) accelerate() {
+	s.af = decimal.Min(s.af.Add(s.afStep), s.afMax)
+}
+
+func (s *FixedParabolicSARStop) remember(bar model.PriceInterval) {
+	s.prev = append(s.prev, bar)
+	if len(s.prev) > 2 {
+		s.prev = s.prev[1:]
+	}
+}
+
+// flipped reports whether the SAR trend turned against the position
+func (s *FixedParabolicSARStop) flipped() bool {
+	return s.seeded && s.uptrend != s.isLong
+}
+
+// breached reports whether price crossed the SAR against the position
+func (s *FixedParabolicSARStop) breached(price decimal.Decimal) bool {
+	switch {
+	case !s.seeded:
+		return false
+	case s.flipped():
+		return true
+	case s.isLong:
+		return price.LessThanOrEqual(s.sar)
+	}
+	return price.GreaterThanOrEqual(s.sar)
+}
+
+// reason tells a trend flip apart from a tick crossing the SAR
+func (s *FixedParabolicSARStop) reason(flip, stop string) string {
+	if s.flipped() {
+		return flip
+	}
+	return stop
+}
+
+// Uptrend reports whether the SAR trails a rising market
+func (s *FixedParabolicSARStop) Uptrend() bool {
+	return s.uptrend
+}
+
+// AccelerationFactor returns the current acceleration factor
+func (s *FixedParabolicSARStop) AccelerationFactor() decimal.Decimal {
+	return s.af
+}
+
+// CalculateStopLoss records the last price and returns the SAR, which only bars move
+func (s *FixedParabolicSARStop) CalculateStopLoss(currentPrice decimal.Decimal) (decimal.Decimal, error) {
+	if !s.Active {
+		return decimal.Zero, stoploss.ErrStatusInvalid
+	}
+	s.lastPrice = currentPrice
+	return s.sar, nil
+}
+
+// ShouldTriggerStopLoss checks if the stop loss should be triggered
+func (s *FixedParabolicSARStop) ShouldTriggerStopLoss(currentPrice decimal.Decimal) (bool, error) {
+	if !s.Active {
+		return false, stoploss.ErrStatusInvalid
+	}
+	if s.breached(currentPrice) {
+		err := s.Trigger(s.reason(stoploss.TRIGGERED_REASON_FIXED_PARABOLIC_SAR_FLIP, stoploss.TRIGGERED_REASON_FIXED_PARABOLIC_SAR_STOPLOSS))
+		if err != nil {
+			return true, stoploss.ErrCallBackFail
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// GetStopLoss returns the current SAR, zero before the first bar while the position is unprotected
+func (s *FixedParabolicSARStop) GetStopLoss() (decimal.Decimal, error) {
+	if !s.Active {
+		return decimal.Zero, stoploss.ErrStatusInvalid
+	}
+	return s.sar, nil
+}
+
+// ReSetStopLosser restarts the SAR in the direction of the position from the last bar, the extreme point becomes the current price
+func (s *FixedParabolicSARStop) ReSetStopLosser(currentPrice decimal.Decimal) error {
+	if !s.Active {
+		return stoploss.ErrStatusInvalid
+	}
+	s.lastPrice = currentPrice
+	if len(s.prev) == 0 {
+		s.seeded = false
+		s.uptrend = s.isLong
+		s.af = s.afStart
+		return nil
+	}
+	s.seed(s.prev[len(s.prev)-1])
+	s.ep = currentPrice
+	return nil
+}
+
+// GetTimeThreshold returns the time threshold for Debounced strategies
+func (t *DebouncedParabolicSARStop) GetTimeThreshold() (int64, error) {
+	if !t.Active {
+		return 0, stoploss.ErrStatusInvalid
+	}
+	return t.TimeThreshold, nil
+}
+
+// ShouldTriggerStopLoss checks if the Debounced stop loss should be triggered
+func (t *DebouncedParabolicSARStop) ShouldTriggerStopLoss(currentPrice decimal.Decimal, currentTime int64) (bool, error) {
+	if !t.Active {
+		return false, stoploss.ErrStatusInvalid
+	}
+	if t.breached(currentPrice) {
+		if t.TriggerTime == 0 {
+			t.TriggerTime = currentTime
+		}
+		if currentTime-t.TriggerTime >= t.TimeThreshold {
+			err := t.Trigger(t.reason(stoploss.TRIGGERED_REASON_DEBOUNCED_PARABOLIC_SAR_FLIP, stoploss.TRIGGERED_REASON_DEBOUNCED_PARABOLIC_SAR_STOPLOSS))
+			if err != nil {
+				return true, stoploss.ErrCallBackFail
+			}
+			return true, nil
+		}
+	} else {
+		t.TriggerTime = 0
+	}
+	return false, nil
+}
+
+// ReSetStopLosser restarts the SAR and clears the debounce window
+func (t *DebouncedParabolicSARStop) ReSetStopLosser(currentPrice decimal.Decimal) error {
+	if err := t.FixedParabolicSARStop.ReSetStopLosser(currentPrice); err != nil {
+		return err
+	}
+	t.TriggerTime = 0
+	return nil
+}
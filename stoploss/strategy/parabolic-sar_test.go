@@ -0,0 +1,194 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package strategy
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/wang900115/quant/stoploss"
+)
+
+func TestNewFixedParabolicSARStop_InvalidParams(t *testing.T) {
+	tests := []struct {
+		name             string
+		start, step, max decimal.Decimal
+		wantErr          bool
+	}{
+		{"Valid", d(0.02), d(0.02), d(0.2), false},
+		{"Zero Start", d(0), d(0.02), d(0.2), true},
+		{"Zero Step", d(0.02), d(0), d(0.2), true},
+		{"Start Above Max", d(0.3), d(0.02), d(0.2), true},
+		{"Max Above One", d(0.02), d(0.02), d(1.5), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewFixedParabolicSARStop(d(100), tt.start, tt.step, tt.max, true, nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("wantErr=%v, got err=%v", tt.wantErr, err)
+			}
+			if tt.wantErr && s != nil {
+				t.Errorf("expected a nil strategy on error, got %v", s)
+			}
+		})
+	}
+}
+
+func TestFixedParabolicSARStop_Long(t *testing.T) {
+	var reason string
+	s, err := NewFixedParabolicSARStop(d(100), d(0.02), d(0.02), d(0.2), true, func(r string) error {
+		reason = r
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to create SAR stop: %v", err)
+	}
+	if triggered, _ := s.ShouldTriggerStopLoss(d(1)); triggered {
+		t.Fatal("Did not expect a trigger before the first bar")
+	}
+
+	for _, b := range []struct{ h, l, c float64 }{{102, 98, 101}, {104, 100, 103}, {106, 102, 105}, {108, 104, 107}} {
+		if err := s.UpdateBar(bar(b.h, b.l, b.c)); err != nil {
+			t.Fatalf("UpdateBar: %v", err)
+		}
+	}
+	sar, _ := s.GetStopLoss()
+	if !sar.Equal(d(98.48)) { // 98 + 0.06 * (106 - 98)
+		t.Fatalf("Expected SAR=98.48, got %v", sar)
+	}
+	if af := s.(*FixedParabolicSARStop).AccelerationFactor(); !af.Equal(d(0.08)) {
+		t.Fatalf("Expected AF=0.08 after three new highs, got %v", af)
+	}
+	if triggered, _ := s.ShouldTriggerStopLoss(d(98.5)); triggered {
+		t.Error("Did not expect a trigger above the SAR")
+	}
+	if triggered, _ := s.ShouldTriggerStopLoss(d(98.48)); !triggered || reason != stoploss.TRIGGERED_REASON_FIXED_PARABOLIC_SAR_STOPLOSS {
+		t.Errorf("Expected a stop trigger at the SAR, got %v with %q", triggered, reason)
+	}
+
+	// a bar through the SAR flips the trend, the SAR restarts at the old extreme above price
+	if err := s.UpdateBar(bar(107, 97, 98)); err != nil {
+		t.Fatalf("UpdateBar: %v", err)
+	}
+	if s.(*FixedParabolicSARStop).Uptrend() {
+		t.Fatal("Expected the trend to flip down")
+	}
+	if sar, _ := s.GetStopLoss(); !sar.Equal(d(108)) {
+		t.Fatalf("Expected SAR=108 after the flip, got %v", sar)
+	}
+	if triggered, _ := s.ShouldTriggerStopLoss(d(120)); !triggered || reason != stoploss.TRIGGERED_REASON_FIXED_PARABOLIC_SAR_FLIP {
+		t.Errorf("Expected a flip trigger at any price, got %v with %q", triggered, reason)
+	}
+}
+
+func TestFixedParabolicSARStop_UnprotectedUntilFirstBar(t *testing.T) {
+	s, _ := NewFixedParabolicSARStop(d(100), d(0.02), d(0.02), d(0.2), true, nil)
+	if stop, _ := s.GetStopLoss(); !stop.IsZero() {
+		t.Fatalf("Expected no stop before the first bar, got %v", stop)
+	}
+	if triggered, _ := s.ShouldTriggerStopLoss(d(0.01)); triggered {
+		t.Fatal("Did not expect any price to trigger before the first bar")
+	}
+
+	// the last closed bar before entry arms the stop at its low
+	s.UpdateBar(bar(101, 97, 100))
+	if stop, _ := s.GetStopLoss(); !stop.Equal(d(97)) {
+		t.Fatalf("Expected the first bar to set the stop at its low 97, got %v", stop)
+	}
+	if triggered, _ := s.ShouldTriggerStopLoss(d(96)); !triggered {
+		t.Error("Expected a trigger below the first bar's low")
+	}
+}
+
+func TestFixedParabolicSARStop_Short(t *testing.T) {
+	s, _ := NewFixedParabolicSARStop(d(100), d(0.02), d(0.02), d(0.2), false, nil)
+	if triggered, _ := s.ShouldTriggerStopLoss(d(1000)); triggered {
+		t.Fatal("Did not expect a short to trigger before the first bar")
+	}
+	for _, b := range []struct{ h, l, c float64 }{{102, 98, 99}, {100, 96, 97}, {98, 94, 95}, {96, 92, 93}} {
+		s.UpdateBar(bar(b.h, b.l, b.c))
+	}
+	sar, _ := s.GetStopLoss()
+	if !sar.Equal(d(101.52)) { // 102 + 0.06 * (94 - 102)
+		t.Fatalf("Expected SAR=101.52, got %v", sar)
+	}
+	if triggered, _ := s.ShouldTriggerStopLoss(d(101.5)); triggered {
+		t.Error("Did not expect a short to trigger below the SAR")
+	}
+	if triggered, _ := s.ShouldTriggerStopLoss(d(101.52)); !triggered {
+		t.Error("Expected a short to trigger at the SAR")
+	}
+}
+
+func TestFixedParabolicSARStop_AccelerationCapped(t *testing.T) {
+	s, _ := NewFixedParabolicSARStop(d(100), d(0.02), d(0.02), d(0.2), true, nil)
+	for i := 0; i < 20; i++ {
+		high := 102 + float64(2*i)
+		s.UpdateBar(bar(high, high-4, high-1))
+	}
+	if af := s.(*FixedParabolicSARStop).AccelerationFactor(); !af.Equal(d(0.2)) {
+		t.Fatalf("Expected AF capped at 0.2, got %v", af)
+	}
+	previous := decimal.Zero
+	for i := 20; i < 25; i++ {
+		high := 102 + float64(2*i)
+		s.UpdateBar(bar(high, high-4, high-1))
+		sar, _ := s.GetStopLoss()
+		if !sar.GreaterThan(previous) {
+			t.Fatalf("Expected the SAR to keep rising, %v after %v", sar, previous)
+		}
+		previous = sar
+	}
+}
+
+func TestDebouncedParabolicSARStop_WaitsForThreshold(t *testing.T) {
+	s, err := NewDebouncedParabolicSARStop(d(100), d(0.02), d(0.02), d(0.2), true, 1000, nil)
+	if err != nil {
+		t.Fatalf("Failed to create SAR stop: %v", err)
+	}
+	s.UpdateBar(bar(102, 98, 101))
+	if triggered, _ := s.ShouldTriggerStopLoss(d(97), 1000); triggered {
+		t.Fatal("Did not expect a trigger on the first breach")
+	}
+	if triggered, _ := s.ShouldTriggerStopLoss(d(99), 1500); triggered {
+		t.Fatal("Did not expect a trigger after price recovered")
+	}
+	if triggered, _ := s.ShouldTriggerStopLoss(d(97), 2000); triggered {
+		t.Fatal("Expected the recovery to restart the window")
+	}
+	if triggered, _ := s.ShouldTriggerStopLoss(d(97), 3000); !triggered {
+		t.Fatal("Expected a trigger once the breach lasted the threshold")
+	}
+	if th, _ := s.GetTimeThreshold(); th != 1000 {
+		t.Fatalf("Expected threshold 1000, got %d", th)
+	}
+}
+
+func TestFixedParabolicSARStop_Reset(t *testing.T) {
+	s, _ := NewFixedParabolicSARStop(d(100), d(0.02), d(0.02), d(0.2), true, nil)
+	s.UpdateBar(bar(102, 98, 101))
+	s.UpdateBar(bar(101, 95, 96)) // flips against the long
+	if !s.(*FixedParabolicSARStop).flipped() {
+		t.Fatal("Expected the bar to flip the trend")
+	}
+	if err := s.ReSetStopLosser(d(97)); err != nil {
+		t.Fatalf("ReSetStopLosser: %v", err)
+	}
+	if sar, _ := s.GetStopLoss(); !sar.Equal(d(95)) {
+		t.Fatalf("Expected the reset SAR at the last low 95, got %v", sar)
+	}
+	if triggered, _ := s.ShouldTriggerStopLoss(d(96)); triggered {
+		t.Fatal("Did not expect a trigger above the reset SAR")
+	}
+}
@@ -30,7 +30,10 @@ type RiskRewardRatio struct {
 type RiskRewardRatioDebounced struct {
 	RiskRewardRatio
 	TimeThreshold int64
-	TriggerTime   int64
+	// TriggerTime is when price first crossed the stop loss, zero while it is above
+	TriggerTime int64
+	// ProfitTriggerTime is when price first crossed the take profit, kept apart so checking one side does not reset the other
+	ProfitTriggerTime int64
 }
 
 func NewRiskRewardRatio(entryPrice, riskRatio, rewardRatio decimal.Decimal, callback stoploss.DefaultCallback) (stoploss.HybridWithoutTime, error) {
@@ -74,7 +77,6 @@ func NewRiskRewardRatioDebounced(entryPrice, riskRatio, rewardRatio decimal.Deci
 			},
 		},
 		TimeThreshold: timeThreshold,
-		TriggerTime:   0,
 	}
 	return s, nil
 }
@@ -150,10 +152,10 @@ func (r *RiskRewardRatioDebounced) ShouldTriggerTakeProfit(currentPrice decimal.
 		return false, stoploss.ErrStatusInvalid
 	}
 	if currentPrice.GreaterThanOrEqual(r.takeProfit) {
-		if r.TriggerTime == 0 {
-			r.TriggerTime = currentTime
+		if r.ProfitTriggerTime == 0 {
+			r.ProfitTriggerTime = currentTime
 		}
-		if currentTime-r.TriggerTime >= r.TimeThreshold {
+		if currentTime-r.ProfitTriggerTime >= r.TimeThreshold {
 			err := r.Trigger(stoploss.TRIGGERED_REASON_HYBRID_RISK_REWARD_TAKEPROFIT)
 			if err != nil {
 				return true, stoploss.ErrCallBackFail
@@ -161,7 +163,7 @@ func (r *RiskRewardRatioDebounced) ShouldTriggerTakeProfit(currentPrice decimal.
 			return true, nil
 		}
 	} else {
-		r.TriggerTime = 0
+		r.ProfitTriggerTime = 0
 	}
 	return false, nil
 }
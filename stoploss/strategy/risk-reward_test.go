@@ -104,6 +104,31 @@ func TestRiskRewardRatio_Deactivate(t *testing.T) {
 	}
 }
 
+func TestRiskRewardRatioDebounced_IndependentWindows(t *testing.T) {
+	s, _ := NewRiskRewardRatioDebounced(d(100), d(0.05), d(0.10), 1000, nil)
+	// checking take profit on every tick must not reset the stop loss window
+	for _, tick := range []struct {
+		price float64
+		at    int64
+		want  bool
+	}{
+		{94, 1000, false},
+		{94, 1500, false},
+		{94, 2000, true},
+	} {
+		sl, err := s.ShouldTriggerStopLoss(d(tick.price), tick.at)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if tp, _ := s.ShouldTriggerTakeProfit(d(tick.price), tick.at); tp {
+			t.Errorf("TP should not trigger at %d", tick.at)
+		}
+		if sl != tick.want {
+			t.Errorf("at %d expected SL triggered=%v, got %v", tick.at, tick.want, sl)
+		}
+	}
+}
+
 func BenchmarkNewRiskRewardRatio(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		NewRiskRewardRatio(d(100), d(0.05), d(0.10), nil)
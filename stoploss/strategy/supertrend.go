@@ -0,0 +1,306 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package strategy
+
+import (
+	"github.com/shopspring/decimal"
+	"github.com/wang900115/quant/model"
+	"github.com/wang900115/quant/stoploss"
+)
+
+// Supertrend trades the position against the Supertrend bands computed from bars with an internal Wilder ATR.
+// The stop is the band on the losing side, the take profit fires when the trend flips in the position's favor.
+// Nothing triggers before period bars warmed the ATR up.
+type Supertrend struct {
+	stoploss.BaseResolver
+	LastPrice  decimal.Decimal
+	multiplier decimal.Decimal
+	isLong     bool
+	atr        *wilderATR
+
+	// ready is set once the ATR is warm and the bands exist
+	ready     bool
+	uptrend   bool
+	upper     decimal.Decimal
+	lower     decimal.Decimal
+	prevClose decimal.Decimal
+	// flippedFor and flippedAgainst latch a bar flip of the trend relative to the position until ReSet
+	flippedFor     bool
+	flippedAgainst bool
+}
+
+// SupertrendDebounced represents a time-based Supertrend hybrid
+type SupertrendDebounced struct {
+	Supertrend
+	TimeThreshold int64
+	// TriggerTime is when price first crossed the stop loss, zero while it is on the safe side
+	TriggerTime int64
+	// ProfitTriggerTime is when the take profit condition first held
+	ProfitTriggerTime int64
+}
+
+// NewSupertrend creates a Supertrend hybrid with the ATR period and band multiplier, commonly 10 and 3
+func NewSupertrend(entryPrice decimal.Decimal, period int, multiplier decimal.Decimal, isLong bool, callback stoploss.DefaultCallback) (stoploss.HybridBarWithoutTime, error) {
+	s, err := newSupertrend(entryPrice, period, multiplier, isLong, callback)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// NewSupertrendDebounced creates a Supertrend hybrid whose conditions must hold for timeThreshold
+func NewSupertrendDebounced(entryPrice decimal.Decimal, period int, multiplier decimal.Decimal, isLong bool, timeThreshold int64, callback stoploss.DefaultCallback) (stoploss.HybridBarWithTime, error) {
+	if timeThreshold <= 0 {
+		return nil, errTimeThresholdInvalid
+	}
+	s, err := newSupertrend(entryPrice, period, multiplier, isLong, callback)
+	if err != nil {
+		return nil, err
+	}
+	return &SupertrendDebounced{Supertrend: *s, TimeThreshold: timeThreshold}, nil
+}
+
+func newSupertrend(entryPrice decimal.Decimal, period int, multiplier decimal.Decimal, isLong bool, callback stoploss.DefaultCallback) (*Supertrend, error) {
+	if period <= 0 {
+		return nil, errPeriodInvalid
+	}
+	if multiplier.LessThanOrEqual(decimal.Zero) {
+		return nil, errATRStopLossKInvalid
+	}
+	return &Supertrend{
+		LastPrice:  entryPrice,
+		multiplier: multiplier,
+		isLong:     isLong,
+		atr:        newWilderATR(period),
+		uptrend:    isLong,
+		BaseResolver: stoploss.BaseResolver{
+			Active:   true,
+			Callback: callback,
+		},
+	}, nil
+}
+
+// UpdateBar folds a closed bar into the ATR, ratchets the final bands and flips the trend on a close beyond the active band
+func (s *Supertrend) UpdateBar(bar model.PriceInterval) error {
+	if !s.Active {
+		return stoploss.ErrStatusInvalid
+	}
+	if err := validBar(bar); err != nil {
+		return err
+	}
+	s.atr.update(bar)
+	defer func() { s.prevClose = bar.ClosingPrice }()
+	if !s.atr.ready() {
+		return nil
+	}
+	mid := bar.HighestPrice.Add(bar.LowestPrice).Div(decimal.NewFromInt(2))
+	distance := s.atr.value.Mul(s.multiplier)
+	upper, lower := mid.Add(distance), mid.Sub(distance)
+	if !s.ready {
+		s.ready = true
+		s.upper, s.lower = upper, lower
+		return nil
+	}
+	if upper.LessThan(s.upper) || s.prevClose.GreaterThan(s.upper) {
+		s.upper = upper
+	}
+	if lower.GreaterThan(s.lower) || s.prevClose.LessThan(s.lower) {
+		s.lower = lower
+	}
+
+	switch {
+	case !s.uptrend && bar.ClosingPrice.GreaterThan(s.upper):
+		s.flip(true)
+	case s.uptrend && bar.ClosingPrice.LessThan(s.lower):
+		s.flip(false)
+	}
+	return nil
+}
+
+func (s *Supertrend) flip(up bool) {
+	s.uptrend = up
+	if up == s.isLong {
+		s.flippedFor = true
+		return
+	}
+	s.flippedAgainst = true
+}
+
+// levels returns the stop and take profit bands of the position
+func (s *Supertrend) levels() (decimal.Decimal, decimal.Decimal) {
+	if s.isLong {
+		return s.lower, s.upper
+	}
+	return s.upper, s.lower
+}
+
+// stopped reports whether price crossed the stop band or a bar flipped the trend against the position
+func (s *Supertrend) stopped(price decimal.Decimal) bool {
+	if !s.ready {
+		return false
+	}
+	if s.flippedAgainst {
+		return true
+	}
+	if s.isLong {
+		return price.LessThanOrEqual(s.lower)
+	}
+	return price.GreaterThanOrEqual(s.upper)
+}
+
+// profited reports whether a bar flipped the trend to the position, or price crossed the band that flips it
+func (s *Supertrend) profited(price decimal.Decimal) bool {
+	if !s.ready {
+		return false
+	}
+	if s.flippedFor {
+		return true
+	}
+	if s.isLong {
+		return !s.uptrend && price.GreaterThan(s.upper)
+	}
+	return s.uptrend && price.LessThan(s.lower)
+}
+
+// Uptrend reports whether the Supertrend points up
+func (s *Supertrend) Uptrend() bool {
+	return s.uptrend
+}
+
+// ATR returns the internal average true range
+func (s *Supertrend) ATR() decimal.Decimal {
+	return s.atr.value
+}
+
+func (s *Supertrend) Calculate(currentPrice decimal.Decimal) (decimal.Decimal, decimal.Decimal, error) {
+	if !s.Active {
+		return decimal.Zero, decimal.Zero, stoploss.ErrStatusInvalid
+	}
+	s.LastPrice = currentPrice
+	stop, profit := s.levels()
+	return stop, profit, nil
+}
+
+func (s *Supertrend) ShouldTriggerStopLoss(currentPrice decimal.Decimal) (bool, error) {
+	if !s.Active {
+		return false, stoploss.ErrStatusInvalid
+	}
+	if s.stopped(currentPrice) {
+		err := s.Trigger(stoploss.TRIGGERED_REASON_HYBRID_SUPERTREND_STOPLOSS)
+		if err != nil {
+			return true, stoploss.ErrCallBackFail
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+func (s *Supertrend) ShouldTriggerTakeProfit(currentPrice decimal.Decimal) (bool, error) {
+	if !s.Active {
+		return false, stoploss.ErrStatusInvalid
+	}
+	if s.profited(currentPrice) {
+		err := s.Trigger(stoploss.TRIGGERED_REASON_HYBRID_SUPERTREND_TAKEPROFIT)
+		if err != nil {
+			return true, stoploss.ErrCallBackFail
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+func (s *Supertrend) GetStopLoss() (decimal.Decimal, error) {
+	if !s.Active {
+		return decimal.Zero, stoploss.ErrStatusInvalid
+	}
+	stop, _ := s.levels()
+	return stop, nil
+}
+
+func (s *Supertrend) GetTakeProfit() (decimal.Decimal, error) {
+	if !s.Active {
+		return decimal.Zero, stoploss.ErrStatusInvalid
+	}
+	_, profit := s.levels()
+	return profit, nil
+}
+
+// ReSet clears the flip latches for a new position at newPrice, the bands keep following the bars
+func (s *Supertrend) ReSet(newPrice decimal.Decimal) error {
+	if !s.Active {
+		return stoploss.ErrStatusInvalid
+	}
+	s.LastPrice = newPrice
+	s.flippedFor = false
+	s.flippedAgainst = false
+	return nil
+}
+
+func (s *SupertrendDebounced) GetTimeThreshold() (int64, error) {
+	if !s.Active {
+		return 0, stoploss.ErrStatusInvalid
+	}
+	return s.TimeThreshold, nil
+}
+
+func (s *SupertrendDebounced) ShouldTriggerStopLoss(currentPrice decimal.Decimal, currentTime int64) (bool, error) {
+	if !s.Active {
+		return false, stoploss.ErrStatusInvalid
+	}
+	if s.stopped(currentPrice) {
+		if s.TriggerTime == 0 {
+			s.TriggerTime = currentTime
+		}
+		if currentTime-s.TriggerTime >= s.TimeThreshold {
+			err := s.Trigger(stoploss.TRIGGERED_REASON_HYBRID_SUPERTREND_STOPLOSS)
+			if err != nil {
+				return true, stoploss.ErrCallBackFail
+			}
+			return true, nil
+		}
+	} else {
+		s.TriggerTime = 0
+	}
+	return false, nil
+}
+
+func (s *SupertrendDebounced) ShouldTriggerTakeProfit(currentPrice decimal.Decimal, currentTime int64) (bool, error) {
+	if !s.Active {
+		return false, stoploss.ErrStatusInvalid
+	}
+	if s.profited(currentPrice) {
+		if s.ProfitTriggerTime == 0 {
+			s.ProfitTriggerTime = currentTime
+		}
+		if currentTime-s.ProfitTriggerTime >= s.TimeThreshold {
+			err := s.Trigger(stoploss.TRIGGERED_REASON_HYBRID_SUPERTREND_TAKEPROFIT)
+			if err != nil {
+				return true, stoploss.ErrCallBackFail
+			}
+			return true, nil
+		}
+	} else {
+		s.ProfitTriggerTime = 0
+	}
+	return false, nil
+}
+
+func (s *SupertrendDebounced) ReSet(newPrice decimal.Decimal) error {
+	if err := s.Supertrend.ReSet(newPrice); err != nil {
+		return err
+	}
+	s.TriggerTime = 0
+	s.ProfitTriggerTime = 0
+	return nil
+}
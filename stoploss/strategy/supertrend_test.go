@@ -0,0 +1,137 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package strategy
+
+import (
+	"testing"
+)
+
+func TestNewSupertrend_InvalidParams(t *testing.T) {
+	if _, err := NewSupertrend(d(100), 0, d(3), true, nil); err == nil {
+		t.Error("Expected a zero period to be rejected")
+	}
+	if _, err := NewSupertrend(d(100), 10, d(0), true, nil); err == nil {
+		t.Error("Expected a zero multiplier to be rejected")
+	}
+	if _, err := NewSupertrendDebounced(d(100), 10, d(3), true, 0, nil); err == nil {
+		t.Error("Expected a zero time threshold to be rejected")
+	}
+}
+
+func TestSupertrend_LongStopsOnBandAndFlip(t *testing.T) {
+	s, err := NewSupertrend(d(100), 2, d(1), true, nil)
+	if err != nil {
+		t.Fatalf("Failed to create supertrend: %v", err)
+	}
+	s.UpdateBar(bar(102, 98, 100))
+	if triggered, _ := s.ShouldTriggerStopLoss(d(0.01)); triggered {
+		t.Fatal("Did not expect a trigger before the ATR warmed up")
+	}
+	s.UpdateBar(bar(104, 100, 103))
+	s.UpdateBar(bar(106, 102, 105))
+
+	stop, profit, _ := s.Calculate(d(105))
+	if !stop.Equal(d(100)) || !profit.Equal(d(106)) {
+		t.Fatalf("Expected bands 100/106, got %v/%v", stop, profit)
+	}
+	if triggered, _ := s.ShouldTriggerStopLoss(d(100.5)); triggered {
+		t.Error("Did not expect a stop above the lower band")
+	}
+	if triggered, _ := s.ShouldTriggerStopLoss(d(100)); !triggered {
+		t.Error("Expected a stop at the lower band")
+	}
+	if triggered, _ := s.ShouldTriggerTakeProfit(d(120)); triggered {
+		t.Error("Did not expect a take profit while the trend already agrees with the long")
+	}
+
+	// closing below the lower band flips the trend against the long
+	s.UpdateBar(bar(103, 97, 98))
+	if s.(*Supertrend).Uptrend() {
+		t.Fatal("Expected the trend to flip down")
+	}
+	if triggered, _ := s.ShouldTriggerStopLoss(d(150)); !triggered {
+		t.Error("Expected the adverse flip to stop the long at any price")
+	}
+}
+
+func TestSupertrend_LongTakesProfitOnFavorableFlip(t *testing.T) {
+	s, _ := NewSupertrend(d(100), 2, d(1), true, nil)
+	for _, b := range []struct{ h, l, c float64 }{{102, 98, 100}, {104, 100, 103}, {106, 102, 105}, {103, 97, 98}} {
+		s.UpdateBar(bar(b.h, b.l, b.c))
+	}
+	// re-enter long against the down trend
+	if err := s.ReSet(d(98)); err != nil {
+		t.Fatalf("ReSet: %v", err)
+	}
+	s.UpdateBar(bar(100, 96, 99))
+
+	stop, _ := s.GetStopLoss()
+	profit, _ := s.GetTakeProfit()
+	if !stop.Equal(d(93)) || !profit.Equal(d(103)) {
+		t.Fatalf("Expected bands 93/103, got %v/%v", stop, profit)
+	}
+	if triggered, _ := s.ShouldTriggerStopLoss(d(98)); triggered {
+		t.Error("Did not expect a stop above the reset lower band")
+	}
+	if triggered, _ := s.ShouldTriggerTakeProfit(d(102)); triggered {
+		t.Error("Did not expect a take profit below the upper band")
+	}
+	if triggered, _ := s.ShouldTriggerTakeProfit(d(103.5)); !triggered {
+		t.Error("Expected a take profit once price crossed the upper band")
+	}
+
+	s.UpdateBar(bar(108, 102, 107))
+	if !s.(*Supertrend).Uptrend() {
+		t.Fatal("Expected the trend to flip up")
+	}
+	if triggered, _ := s.ShouldTriggerTakeProfit(d(99)); !triggered {
+		t.Error("Expected the favorable flip to take profit at any price")
+	}
+}
+
+func TestSupertrend_Short(t *testing.T) {
+	s, _ := NewSupertrend(d(100), 2, d(1), false, nil)
+	for _, b := range []struct{ h, l, c float64 }{{102, 98, 100}, {104, 100, 103}, {106, 102, 105}} {
+		s.UpdateBar(bar(b.h, b.l, b.c))
+	}
+	stop, profit, _ := s.Calculate(d(105))
+	if !stop.Equal(d(106)) || !profit.Equal(d(100)) {
+		t.Fatalf("Expected short bands 106/100, got %v/%v", stop, profit)
+	}
+	if triggered, _ := s.ShouldTriggerStopLoss(d(105.5)); triggered {
+		t.Error("Did not expect a short stop below the upper band")
+	}
+	if triggered, _ := s.ShouldTriggerStopLoss(d(106)); !triggered {
+		t.Error("Expected a short stop at the upper band")
+	}
+}
+
+func TestSupertrendDebounced_IndependentWindows(t *testing.T) {
+	s, err := NewSupertrendDebounced(d(100), 2, d(1), true, 1000, nil)
+	if err != nil {
+		t.Fatalf("Failed to create supertrend: %v", err)
+	}
+	for _, b := range []struct{ h, l, c float64 }{{102, 98, 100}, {104, 100, 103}, {106, 102, 105}} {
+		s.UpdateBar(bar(b.h, b.l, b.c))
+	}
+	if triggered, _ := s.ShouldTriggerStopLoss(d(99), 1000); triggered {
+		t.Fatal("Did not expect a stop on the first breach")
+	}
+	if triggered, _ := s.ShouldTriggerTakeProfit(d(99), 1500); triggered {
+		t.Fatal("Did not expect a take profit in an up trend")
+	}
+	if triggered, _ := s.ShouldTriggerStopLoss(d(99), 2000); !triggered {
+		t.Fatal("Expected a stop once the breach lasted the threshold")
+	}
+}
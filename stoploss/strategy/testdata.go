@@ -15,6 +15,7 @@ package strategy
 
 import (
 	"github.com/shopspring/decimal"
+	"github.com/wang900115/quant/model"
 )
 
 // PriceData represents price data for a specific time period
@@ -31,6 +32,11 @@ func d(f float64) decimal.Decimal {
 	return decimal.NewFromFloat(f)
 }
 
+// bar is a helper function to create a closed model.PriceInterval from high, low and close
+func bar(high, low, close float64) model.PriceInterval {
+	return model.PriceInterval{HighestPrice: d(high), LowestPrice: d(low), ClosingPrice: d(close)}
+}
+
 // GetMockHistoricalData
 // price data simulating a BTC downtrend
 func GetMockHistoricalData() []PriceData {
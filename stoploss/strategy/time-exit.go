@@ -0,0 +1,327 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package strategy
+
+import (
+	"errors"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/wang900115/quant/stoploss"
+)
+
+var (
+	errSessionInvalid      = errors.New("session close needs a location, an hour in 0-23, a minute in 0-59, a positive lead and valid weekdays")
+	errProgressRateInvalid = errors.New("progress rate must be between 0 and 1")
+)
+
+// timeRule decides when a time exit is due, timestamps are the tick milliseconds the engine passes
+type timeRule interface {
+	due(price decimal.Decimal, now int64) bool
+	reset(price decimal.Decimal)
+	window() int64
+}
+
+// SessionClose is a wall-clock close, such as 16:00 America/New_York or Friday 23:59 UTC before weekend funding
+type SessionClose struct {
+	Location *time.Location
+	Hour     int
+	Minute   int
+	// Lead exits this many milliseconds before the close, the exit is due from then until the close
+	Lead int64
+	// Weekdays the session closes on, every day when empty
+	Weekdays []time.Weekday
+}
+
+// timeExit exits on time alone, the Stop and Profit wrappers register it as either kind
+type timeExit struct {
+	stoploss.BaseResolver
+	rule      timeRule
+	reason    string
+	lastPrice decimal.Decimal
+}
+
+// TimeExitStop is a time exit registered as a stop loss
+type TimeExitStop struct {
+	timeExit
+}
+
+// TimeExitProfit is a time exit registered as a take profit
+type TimeExitProfit struct {
+	timeExit
+}
+
+func newTimeExit(rule timeRule, reason string, callback stoploss.DefaultCallback) timeExit {
+	return timeExit{
+		rule:   rule,
+		reason: reason,
+		BaseResolver: stoploss.BaseResolver{
+			Active:   true,
+			Callback: callback,
+		},
+	}
+}
+
+// NewMaxHoldStop exits holdMillis after entryTime, a zero entryTime starts the clock at the first tick
+func NewMaxHoldStop(entryTime, holdMillis int64, callback stoploss.DefaultCallback) (stoploss.DebouncedStopLoss, error) {
+	rule, err := newMaxHold(entryTime, holdMillis)
+	if err != nil {
+		return nil, err
+	}
+	return &TimeExitStop{newTimeExit(rule, stoploss.TRIGGERED_REASON_TIME_EXIT_MAX_HOLD, callback)}, nil
+}
+
+// NewMaxHoldProfit is NewMaxHoldStop registered as a take profit
+func NewMaxHoldProfit(entryTime, holdMillis int64, callback stoploss.DefaultCallback) (stoploss.DebouncedTakeProfit, error) {
+	rule, err := newMaxHold(entryTime, holdMillis)
+	if err != nil {
+		return nil, err
+	}
+	return &TimeExitProfit{newTimeExit(rule, stoploss.TRIGGERED_REASON_TIME_EXIT_MAX_HOLD, callback)}, nil
+}
+
+// NewSessionCloseStop exits from the next session close minus its lead until that close
+func NewSessionCloseStop(session SessionClose, callback stoploss.DefaultCallback) (stoploss.DebouncedStopLoss, error) {
+	rule, err := newSessionRule(session)
+	if err != nil {
+		return nil, err
+	}
+	return &TimeExitStop{newTimeExit(rule, stoploss.TRIGGERED_REASON_TIME_EXIT_SESSION_CLOSE, callback)}, nil
+}
+
+// NewSessionCloseProfit is NewSessionCloseStop registered as a take profit
+func NewSessionCloseProfit(session SessionClose, callback stoploss.DefaultCallback) (stoploss.DebouncedTakeProfit, error) {
+	rule, err := newSessionRule(session)
+	if err != nil {
+		return nil, err
+	}
+	return &TimeExitProfit{newTimeExit(rule, stoploss.TRIGGERED_REASON_TIME_EXIT_SESSION_CLOSE, callback)}, nil
+}
+
+// NewNoProgressStop exits when price stayed within ±progressPct of entryPrice for windowMillis,
+// a zero entryPrice anchors at the first tick
+func NewNoProgressStop(entryPrice, progressPct decimal.Decimal, windowMillis int64, callback stoploss.DefaultCallback) (stoploss.DebouncedStopLoss, error) {
+	rule, err := newNoProgress(entryPrice, progressPct, windowMillis)
+	if err != nil {
+		return nil, err
+	}
+	return &TimeExitStop{newTimeExit(rule, stoploss.TRIGGERED_REASON_TIME_EXIT_NO_PROGRESS, callback)}, nil
+}
+
+// NewNoProgressProfit is NewNoProgressStop registered as a take profit
+func NewNoProgressProfit(entryPrice, progressPct decimal.Decimal, windowMillis int64, callback stoploss.DefaultCallback) (stoploss.DebouncedTakeProfit, error) {
+	rule, err := newNoProgress(entryPrice, progressPct, windowMillis)
+	if err != nil {
+		return nil, err
+	}
+	return &TimeExitProfit{newTimeExit(rule, stoploss.TRIGGERED_REASON_TIME_EXIT_NO_PROGRESS, callback)}, nil
+}
+
+// maxHold is due once the position was held for hold milliseconds
+type maxHold struct {
+	entry int64
+	hold  int64
+}
+
+func newMaxHold(entryTime, holdMillis int64) (*maxHold, error) {
+	if holdMillis <= 0 || entryTime < 0 {
+		return nil, errTimeThresholdInvalid
+	}
+	return &maxHold{entry: entryTime, hold: holdMillis}, nil
+}
+
+func (m *maxHold) due(_ decimal.Decimal, now int64) bool {
+	if m.entry == 0 {
+		m.entry = now
+	}
+	return now-m.entry >= m.hold
+}
+
+func (m *maxHold) reset(decimal.Decimal) { m.entry = 0 }
+
+func (m *maxHold) window() int64 { return m.hold }
+
+// sessionRule is due from the next close minus the lead until that close
+type sessionRule struct {
+	session SessionClose
+}
+
+func newSessionRule(session SessionClose) (*sessionRule, error) {
+	if session.Location == nil || session.Hour < 0 || session.Hour > 23 || session.Minute < 0 || session.Minute > 59 || session.Lead <= 0 {
+		return nil, errSessionInvalid
+	}
+	for _, d := range session.Weekdays {
+		if d < time.Sunday || d > time.Saturday {
+			return nil, errSessionInvalid
+		}
+	}
+	return &sessionRule{session: session}, nil
+}
+
+func (s *sessionRule) due(_ decimal.Decimal, now int64) bool {
+	t := time.UnixMilli(now).In(s.session.Location)
+	closeAt := s.next(t)
+	return t.Before(closeAt) && !t.Before(closeAt.Add(-time.Duration(s.session.Lead)*time.Millisecond))
+}
+
+// next returns the first close after t on a session weekday, so a lead may reach back across midnight
+func (s *sessionRule) next(t time.Time) time.Time {
+	for i := 0; ; i++ {
+		// time.Date resolves the close in the location, so daylight saving shifts move it with the wall clock
+		closeAt := time.Date(t.Year(), t.Month(), t.Day()+i, s.session.Hour, s.session.Minute, 0, 0, s.session.Location)
+		if closeAt.After(t) && s.closesOn(closeAt.Weekday()) {
+			return closeAt
+		}
+	}
+}
+
+func (s *sessionRule) closesOn(day time.Weekday) bool {
+	if len(s.session.Weekdays) == 0 {
+		return true
+	}
+	for _, d := range s.session.Weekdays {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *sessionRule) reset(decimal.Decimal) {}
+
+func (s *sessionRule) window() int64 { return s.session.Lead }
+
+// noProgress is due when price did not leave ±pct of the anchor within the window
+type noProgress struct {
+	anchor decimal.Decimal
+	pct    decimal.Decimal
+	span   int64
+	start  int64
+	moved  bool
+}
+
+func newNoProgress(entryPrice, progressPct decimal.Decimal, windowMillis int64) (*noProgress, error) {
+	if !progressPct.IsPositive() || progressPct.GreaterThanOrEqual(decimal.NewFromInt(1)) {
+		return nil, errProgressRateInvalid
+	}
+	if windowMillis <= 0 {
+		return nil, errTimeThresholdInvalid
+	}
+	if entryPrice.IsNegative() {
+		return nil, errEntryPriceInvalid
+	}
+	return &noProgress{anchor: entryPrice, pct: progressPct, span: windowMillis}, nil
+}
+
+func (n *noProgress) due(price decimal.Decimal, now int64) bool {
+	if n.start == 0 {
+		n.start = now
+		if n.anchor.IsZero() {
+			n.anchor = price
+		}
+	}
+	if !n.moved && price.Sub(n.anchor).Abs().GreaterThanOrEqual(n.anchor.Mul(n.pct)) {
+		n.moved = true
+	}
+	return !n.moved && now-n.start >= n.span
+}
+
+func (n *noProgress) reset(price decimal.Decimal) {
+	n.anchor = price
+	n.start = 0
+	n.moved = false
+}
+
+func (n *noProgress) window() int64 { return n.span }
+
+// check triggers the exit once its rule is due
+func (e *timeExit) check(price decimal.Decimal, now int64) (bool, error) {
+	if !e.Active {
+		return false, stoploss.ErrStatusInvalid
+	}
+	if !e.rule.due(price, now) {
+		return false, nil
+	}
+	if err := e.Trigger(e.reason); err != nil {
+		return true, stoploss.ErrCallBackFail
+	}
+	return true, nil
+}
+
+// GetTimeThreshold returns the hold, lead or window of the rule in milliseconds
+func (e *timeExit) GetTimeThreshold() (int64, error) {
+	if !e.Active {
+		return 0, stoploss.ErrStatusInvalid
+	}
+	return e.rule.window(), nil
+}
+
+// threshold records the price, time exits have no price level and report zero
+func (e *timeExit) threshold(price decimal.Decimal) (decimal.Decimal, error) {
+	if !e.Active {
+		return decimal.Zero, stoploss.ErrStatusInvalid
+	}
+	e.lastPrice = price
+	return decimal.Zero, nil
+}
+
+// restart restarts the rule for a position entered at price
+func (e *timeExit) restart(price decimal.Decimal) error {
+	if !e.Active {
+		return stoploss.ErrStatusInvalid
+	}
+	e.lastPrice = price
+	e.rule.reset(price)
+	return nil
+}
+
+// CalculateStopLoss returns zero, time exits have no price level
+func (s *TimeExitStop) CalculateStopLoss(currentPrice decimal.Decimal) (decimal.Decimal, error) {
+	return s.threshold(currentPrice)
+}
+
+// ShouldTriggerStopLoss checks if the time exit is due at the tick timestamp
+func (s *TimeExitStop) ShouldTriggerStopLoss(currentPrice decimal.Decimal, currentTime int64) (bool, error) {
+	return s.check(currentPrice, currentTime)
+}
+
+// GetStopLoss returns zero, time exits have no price level
+func (s *TimeExitStop) GetStopLoss() (decimal.Decimal, error) {
+	return s.threshold(s.lastPrice)
+}
+
+// ReSetStopLosser restarts the exit for a position entered at the current price
+func (s *TimeExitStop) ReSetStopLosser(currentPrice decimal.Decimal) error {
+	return s.restart(currentPrice)
+}
+
+// CalculateTakeProfit returns zero, time exits have no price level
+func (p *TimeExitProfit) CalculateTakeProfit(currentPrice decimal.Decimal) (decimal.Decimal, error) {
+	return p.threshold(currentPrice)
+}
+
+// ShouldTriggerTakeProfit checks if the time exit is due at the tick timestamp
+func (p *TimeExitProfit) ShouldTriggerTakeProfit(currentPrice decimal.Decimal, currentTime int64) (bool, error) {
+	return p.check(currentPrice, currentTime)
+}
+
+// GetTakeProfit returns zero, time exits have no price level
+func (p *TimeExitProfit) GetTakeProfit() (decimal.Decimal, error) {
+	return p.threshold(p.lastPrice)
+}
+
+// ReSetTakeProfiter restarts the exit for a position entered at the current price
+func (p *TimeExitProfit) ReSetTakeProfiter(currentPrice decimal.Decimal) error {
+	return p.restart(currentPrice)
+}
@@ -0,0 +1,229 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package strategy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wang900115/quant/stoploss"
+)
+
+func millisAt(t time.Time) int64 { return t.UnixMilli() }
+
+func TestNewTimeExits_InvalidParams(t *testing.T) {
+	if _, err := NewMaxHoldStop(0, 0, nil); err == nil {
+		t.Error("expected a zero hold to be rejected")
+	}
+	if _, err := NewMaxHoldProfit(-1, 1000, nil); err == nil {
+		t.Error("expected a negative entry time to be rejected")
+	}
+	for _, s := range []SessionClose{
+		{Hour: 16},
+		{Location: time.UTC, Hour: 24},
+		{Location: time.UTC, Hour: 16, Minute: 60},
+		{Location: time.UTC, Hour: 16, Lead: -1},
+		{Location: time.UTC, Hour: 16},
+		{Location: time.UTC, Hour: 16, Lead: 1000, Weekdays: []time.Weekday{7}},
+	} {
+		if got, err := NewSessionCloseStop(s, nil); err == nil || got != nil {
+			t.Errorf("expected session %+v to be rejected", s)
+		}
+	}
+	if _, err := NewNoProgressStop(d(100), d(0), 1000, nil); err == nil {
+		t.Error("expected a zero progress rate to be rejected")
+	}
+	if _, err := NewNoProgressProfit(d(100), d(0.01), 0, nil); err == nil {
+		t.Error("expected a zero window to be rejected")
+	}
+}
+
+func TestMaxHoldStop(t *testing.T) {
+	var reason string
+	s, err := NewMaxHoldStop(10_000, 60_000, func(r string) error { reason = r; return nil })
+	if err != nil {
+		t.Fatalf("Failed to create max hold stop: %v", err)
+	}
+	if window, _ := s.GetTimeThreshold(); window != 60_000 {
+		t.Fatalf("Expected the hold as the time threshold, got %d", window)
+	}
+	if triggered, _ := s.ShouldTriggerStopLoss(d(100), 69_999); triggered {
+		t.Fatal("Did not expect an exit before the hold elapsed")
+	}
+	if triggered, _ := s.ShouldTriggerStopLoss(d(100), 70_000); !triggered || reason != stoploss.TRIGGERED_REASON_TIME_EXIT_MAX_HOLD {
+		t.Fatalf("Expected an exit once the hold elapsed, got reason %q", reason)
+	}
+	if sl, _ := s.CalculateStopLoss(d(100)); !sl.IsZero() {
+		t.Fatalf("Expected no price level, got %v", sl)
+	}
+
+	// a reset restarts the clock at the next tick
+	if err := s.ReSetStopLosser(d(100)); err != nil {
+		t.Fatalf("ReSetStopLosser: %v", err)
+	}
+	if triggered, _ := s.ShouldTriggerStopLoss(d(100), 100_000); triggered {
+		t.Fatal("Did not expect an exit on the first tick after reset")
+	}
+	if triggered, _ := s.ShouldTriggerStopLoss(d(100), 160_000); !triggered {
+		t.Fatal("Expected an exit one hold after the first tick")
+	}
+}
+
+func TestMaxHoldProfit_RegistersAsTakeProfit(t *testing.T) {
+	p, err := NewMaxHoldProfit(0, 1000, nil)
+	if err != nil {
+		t.Fatalf("Failed to create max hold profit: %v", err)
+	}
+	if _, ok := interface{}(p).(stoploss.DebouncedStopLoss); ok {
+		t.Fatal("Expected the take profit flavor not to look like a stop loss")
+	}
+	if triggered, _ := p.ShouldTriggerTakeProfit(d(100), 5000); triggered {
+		t.Fatal("Did not expect an exit on the first tick")
+	}
+	if triggered, _ := p.ShouldTriggerTakeProfit(d(100), 6000); !triggered {
+		t.Fatal("Expected an exit one hold after the first tick")
+	}
+}
+
+func TestSessionCloseStop(t *testing.T) {
+	est := time.FixedZone("EST", -5*60*60)
+	s, err := NewSessionCloseStop(SessionClose{Location: est, Hour: 16, Lead: 5 * 60_000}, nil)
+	if err != nil {
+		t.Fatalf("Failed to create session close stop: %v", err)
+	}
+	day := time.Date(2025, 1, 9, 0, 0, 0, 0, est)
+	cases := []struct {
+		at   time.Duration
+		want bool
+	}{
+		{9*time.Hour + 30*time.Minute, false},
+		{15*time.Hour + 54*time.Minute, false},
+		{15*time.Hour + 55*time.Minute, true},
+		{15*time.Hour + 59*time.Minute, true},
+		{16 * time.Hour, false},
+		{17 * time.Hour, false}, // a position re-entered after the close is not exited
+		{33 * time.Hour, false}, // 09:00 the next day
+		{39*time.Hour + 56*time.Minute, true},
+	}
+	for _, c := range cases {
+		if triggered, _ := s.ShouldTriggerStopLoss(d(100), millisAt(day.Add(c.at))); triggered != c.want {
+			t.Errorf("at %v: expected %v, got %v", day.Add(c.at), c.want, triggered)
+		}
+	}
+
+	weekly, _ := NewSessionCloseStop(SessionClose{Location: time.UTC, Hour: 23, Minute: 30, Lead: 30 * 60_000, Weekdays: []time.Weekday{time.Friday}}, nil)
+	if triggered, _ := weekly.ShouldTriggerStopLoss(d(100), millisAt(time.Date(2025, 1, 9, 23, 15, 0, 0, time.UTC))); triggered {
+		t.Error("Did not expect a Friday close to fire on Thursday")
+	}
+	if triggered, _ := weekly.ShouldTriggerStopLoss(d(100), millisAt(time.Date(2025, 1, 10, 23, 15, 0, 0, time.UTC))); !triggered {
+		t.Error("Expected the Friday close to fire")
+	}
+	if triggered, _ := weekly.ShouldTriggerStopLoss(d(100), millisAt(time.Date(2025, 1, 10, 23, 45, 0, 0, time.UTC))); triggered {
+		t.Error("Did not expect the Friday close to fire once it passed")
+	}
+}
+
+func TestSessionCloseStop_LeadCrossesMidnight(t *testing.T) {
+	s, err := NewSessionCloseStop(SessionClose{Location: time.UTC, Lead: 5 * 60_000}, nil)
+	if err != nil {
+		t.Fatalf("Failed to create session close stop: %v", err)
+	}
+	day := time.Date(2025, 1, 9, 0, 0, 0, 0, time.UTC)
+	for _, c := range []struct {
+		at   time.Duration
+		want bool
+	}{
+		{0, false},
+		{time.Hour, false},
+		{9 * time.Hour, false},
+		{12 * time.Hour, false},
+		{23 * time.Hour, false},
+		{23*time.Hour + 55*time.Minute, true},
+		{23*time.Hour + 59*time.Minute, true},
+		{24 * time.Hour, false},
+	} {
+		if triggered, _ := s.ShouldTriggerStopLoss(d(100), millisAt(day.Add(c.at))); triggered != c.want {
+			t.Errorf("at %v: expected %v, got %v", day.Add(c.at), c.want, triggered)
+		}
+	}
+
+	// a Friday 00:05 close is due from Thursday 23:55, the weekday is the one of the close
+	friday, _ := NewSessionCloseStop(SessionClose{Location: time.UTC, Minute: 5, Lead: 10 * 60_000, Weekdays: []time.Weekday{time.Friday}}, nil)
+	for _, c := range []struct {
+		at   time.Time
+		want bool
+	}{
+		{time.Date(2025, 1, 8, 23, 58, 0, 0, time.UTC), false}, // Wednesday
+		{time.Date(2025, 1, 9, 23, 54, 0, 0, time.UTC), false},
+		{time.Date(2025, 1, 9, 23, 58, 0, 0, time.UTC), true}, // Thursday
+		{time.Date(2025, 1, 10, 0, 2, 0, 0, time.UTC), true},
+		{time.Date(2025, 1, 10, 0, 5, 0, 0, time.UTC), false},
+		{time.Date(2025, 1, 10, 23, 58, 0, 0, time.UTC), false}, // Friday night closes nothing
+	} {
+		if triggered, _ := friday.ShouldTriggerStopLoss(d(100), millisAt(c.at)); triggered != c.want {
+			t.Errorf("at %v: expected %v, got %v", c.at, c.want, triggered)
+		}
+	}
+}
+
+func TestSessionCloseStop_FollowsDaylightSaving(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone database unavailable: %v", err)
+	}
+	s, _ := NewSessionCloseStop(SessionClose{Location: ny, Hour: 16, Lead: 60 * 60_000}, nil)
+	// 19:30 UTC is 15:30 in summer and 14:30 in winter
+	if triggered, _ := s.ShouldTriggerStopLoss(d(100), millisAt(time.Date(2025, 7, 10, 19, 30, 0, 0, time.UTC))); !triggered {
+		t.Error("Expected the exit to be due in summer")
+	}
+	if triggered, _ := s.ShouldTriggerStopLoss(d(100), millisAt(time.Date(2025, 1, 9, 19, 30, 0, 0, time.UTC))); triggered {
+		t.Error("Did not expect the exit to be due in winter")
+	}
+}
+
+func TestNoProgressStop(t *testing.T) {
+	s, err := NewNoProgressStop(d(100), d(0.01), 60_000, nil)
+	if err != nil {
+		t.Fatalf("Failed to create no progress stop: %v", err)
+	}
+	if triggered, _ := s.ShouldTriggerStopLoss(d(100.5), 1000); triggered {
+		t.Fatal("Did not expect an exit on the first tick")
+	}
+	if triggered, _ := s.ShouldTriggerStopLoss(d(99.2), 60_999); triggered {
+		t.Fatal("Did not expect an exit before the window elapsed")
+	}
+	if triggered, _ := s.ShouldTriggerStopLoss(d(100.2), 61_000); !triggered {
+		t.Fatal("Expected an exit when price stayed within 1% for the window")
+	}
+
+	// a move of 1% either way within the window disarms the exit until reset
+	if err := s.ReSetStopLosser(d(200)); err != nil {
+		t.Fatalf("ReSetStopLosser: %v", err)
+	}
+	s.ShouldTriggerStopLoss(d(200), 100_000)
+	if triggered, _ := s.ShouldTriggerStopLoss(d(198), 110_000); triggered {
+		t.Fatal("Did not expect an exit on the move")
+	}
+	if triggered, _ := s.ShouldTriggerStopLoss(d(200), 200_000); triggered {
+		t.Fatal("Did not expect an exit after price made progress")
+	}
+}
+
+func TestNoProgressProfit_AnchorsAtFirstTick(t *testing.T) {
+	p, _ := NewNoProgressProfit(d(0), d(0.01), 1000, nil)
+	p.ShouldTriggerTakeProfit(d(50), 1000)
+	if triggered, _ := p.ShouldTriggerTakeProfit(d(50.4), 2000); !triggered {
+		t.Fatal("Expected the first tick to anchor the range")
+	}
+}
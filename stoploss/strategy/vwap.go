@@ -0,0 +1,370 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package strategy
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/wang900115/quant/model"
+	"github.com/wang900115/quant/stoploss"
+)
+
+var (
+	errVWAPAnchorInvalid = errors.New("VWAP anchor must be Session or Entry")
+	errVWAPWidthInvalid  = errors.New("VWAP band widths must be greater than 0")
+	errVWAPTradeInvalid  = errors.New("trade price and size must be greater than 0")
+)
+
+// VWAPAnchor selects where a VWAP starts accumulating
+type VWAPAnchor int
+
+const (
+	// SessionVWAP restarts at every session open
+	SessionVWAP VWAPAnchor = iota
+	// EntryVWAP accumulates from the entry time, a reset anchors it again
+	EntryVWAP
+)
+
+// VWAPConfig configures a VWAP hybrid.
+// The stream providers publish quotes without a Size, so trades reach UpdateTrade only from callers
+// that collect points with Size set from their own trade feed; otherwise feed candles through UpdateBar.
+type VWAPConfig struct {
+	Anchor VWAPAnchor
+	// Location, SessionHour and SessionMinute set the session open of a session VWAP, UTC midnight by default
+	Location      *time.Location
+	SessionHour   int
+	SessionMinute int
+	// EntryTime in milliseconds anchors an entry VWAP, zero anchors at the first update
+	EntryTime int64
+	// StopWidth and ProfitWidth are the σ multiples of the stop and take profit bands
+	StopWidth   decimal.Decimal
+	ProfitWidth decimal.Decimal
+	IsLong      bool
+}
+
+// VWAPBands stops a long below VWAP − StopWidth·σ and takes profit above VWAP + ProfitWidth·σ, mirrored for shorts.
+// It is fed by candles through UpdateBar, weighted at their typical price, and by trades through UpdateTrade.
+type VWAPBands struct {
+	stoploss.BaseResolver
+	config    VWAPConfig
+	lastPrice decimal.Decimal
+
+	anchor  int64
+	session int64
+	pv      decimal.Decimal
+	ppv     decimal.Decimal
+	volume  decimal.Decimal
+}
+
+// VWAPBandsDebounced represents a time-based VWAP hybrid
+type VWAPBandsDebounced struct {
+	VWAPBands
+	TimeThreshold     int64
+	TriggerTime       int64
+	ProfitTriggerTime int64
+}
+
+// NewVWAPBands creates a session or entry anchored VWAP hybrid
+func NewVWAPBands(config VWAPConfig, callback stoploss.DefaultCallback) (stoploss.HybridVolumeWithoutTime, error) {
+	v, err := newVWAP(config, callback)
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// NewVWAPBandsDebounced creates a VWAP hybrid whose bands must stay crossed for timeThreshold
+func NewVWAPBandsDebounced(config VWAPConfig, timeThreshold int64, callback stoploss.DefaultCallback) (stoploss.HybridVolumeWithTime, error) {
+	if timeThreshold <= 0 {
+		return nil, errTimeThresholdInvalid
+	}
+	v, err := newVWAP(config, callback)
+	if err != nil {
+		return nil, err
+	}
+	return &VWAPBandsDebounced{VWAPBands: *v, TimeThreshold: timeThreshold}, nil
+}
+
+func newVWAP(config VWAPConfig, callback stoploss.DefaultCallback) (*VWAPBands, error) {
+	if config.Anchor != SessionVWAP && config.Anchor != EntryVWAP {
+		return nil, errVWAPAnchorInvalid
+	}
+	if !config.StopWidth.IsPositive() || !config.ProfitWidth.IsPositive() {
+		return nil, errVWAPWidthInvalid
+	}
+	if config.SessionHour < 0 || config.SessionHour > 23 || config.SessionMinute < 0 || config.SessionMinute > 59 {
+		return nil, errSessionInvalid
+	}
+	if config.Location == nil {
+		config.Location = time.UTC
+	}
+	return &VWAPBands{
+		config: config,
+		anchor: config.EntryTime,
+		BaseResolver: stoploss.BaseResolver{
+			Active:   true,
+			Callback: callback,
+		},
+	}, nil
+}
+
+// sessionOpen returns the open of the session holding ts, in milliseconds
+func (v *VWAPBands) sessionOpen(ts int64) int64 {
+	t := time.UnixMilli(ts).In(v.config.Location)
+	open := time.Date(t.Year(), t.Month(), t.Day(), v.config.SessionHour, v.config.SessionMinute, 0, 0, v.config.Location)
+	if t.Before(open) {
+		open = time.Date(t.Year(), t.Month(), t.Day()-1, v.config.SessionHour, v.config.SessionMinute, 0, 0, v.config.Location)
+	}
+	return open.UnixMilli()
+}
+
+// add folds a volume at price into the VWAP, ts zero keeps the current session and anchor.
+// Sessions only roll forward, a late update from an earlier session is dropped.
+func (v *VWAPBands) add(price, volume decimal.Decimal, ts int64) {
+	if ts != 0 {
+		switch v.config.Anchor {
+		case SessionVWAP:
+			open := v.sessionOpen(ts)
+			if open < v.session {
+				return
+			}
+			if open > v.session {
+				v.session = open
+				v.clear()
+			}
+		case EntryVWAP:
+			if v.anchor == 0 {
+				v.anchor = ts
+			}
+			if ts < v.anchor {
+				return
+			}
+		}
+	}
+	pv := price.Mul(volume)
+	v.pv = v.pv.Add(pv)
+	v.ppv = v.ppv.Add(pv.Mul(price))
+	v.volume = v.volume.Add(volume)
+}
+
+func (v *VWAPBands) clear() {
+	v.pv, v.ppv, v.volume = decimal.Zero, decimal.Zero, decimal.Zero
+}
+
+// VWAP returns the volume weighted average price and its standard deviation, both zero before any volume
+func (v *VWAPBands) VWAP() (decimal.Decimal, decimal.Decimal) {
+	if !v.volume.IsPositive() {
+		return decimal.Zero, decimal.Zero
+	}
+	vwap := v.pv.Div(v.volume)
+	variance := v.ppv.Div(v.volume).Sub(vwap.Mul(vwap))
+	if !variance.IsPositive() {
+		return vwap, decimal.Zero
+	}
+	// decimal has no square root, the deviation goes through float64
+	return vwap, decimal.NewFromFloat(math.Sqrt(variance.InexactFloat64()))
+}
+
+// bands returns the stop and take profit levels, zero until prices spread around the VWAP
+func (v *VWAPBands) bands() (decimal.Decimal, decimal.Decimal) {
+	vwap, sigma := v.VWAP()
+	if !sigma.IsPositive() {
+		return decimal.Zero, decimal.Zero
+	}
+	stop, profit := sigma.Mul(v.config.StopWidth).Neg(), sigma.Mul(v.config.ProfitWidth)
+	if !v.config.IsLong {
+		stop, profit = stop.Neg(), profit.Neg()
+	}
+	return vwap.Add(stop), vwap.Add(profit)
+}
+
+func (v *VWAPBands) stopHit(price decimal.Decimal) bool {
+	stop, _ := v.bands()
+	if stop.IsZero() {
+		return false
+	}
+	if v.config.IsLong {
+		return price.LessThanOrEqual(stop)
+	}
+	return price.GreaterThanOrEqual(stop)
+}
+
+func (v *VWAPBands) profitHit(price decimal.Decimal) bool {
+	_, profit := v.bands()
+	if profit.IsZero() {
+		return false
+	}
+	if v.config.IsLong {
+		return price.GreaterThanOrEqual(profit)
+	}
+	return price.LessThanOrEqual(profit)
+}
+
+func (v *VWAPBands) fire(reason string) (bool, error) {
+	if err := v.Trigger(reason); err != nil {
+		return true, stoploss.ErrCallBackFail
+	}
+	return true, nil
+}
+
+// UpdateBar folds a closed candle at its typical price, bars without volume are skipped
+func (v *VWAPBands) UpdateBar(bar model.PriceInterval) error {
+	if !v.Active {
+		return stoploss.ErrStatusInvalid
+	}
+	if err := validBar(bar); err != nil {
+		return err
+	}
+	if !bar.Volume.IsPositive() {
+		return nil
+	}
+	var ts int64
+	if open, err := time.Parse(time.RFC3339, bar.OpenTime); err == nil {
+		ts = open.UnixMilli()
+	}
+	typical := bar.HighestPrice.Add(bar.LowestPrice).Add(bar.ClosingPrice).Div(decimal.NewFromInt(3))
+	v.add(typical, bar.Volume, ts)
+	return nil
+}
+
+// UpdateTrade folds a trade of size at price
+func (v *VWAPBands) UpdateTrade(price, size decimal.Decimal, timestamp int64) error {
+	if !v.Active {
+		return stoploss.ErrStatusInvalid
+	}
+	if !price.IsPositive() || !size.IsPositive() {
+		return errVWAPTradeInvalid
+	}
+	v.add(price, size, timestamp)
+	return nil
+}
+
+// Calculate returns the stop and take profit bands
+func (v *VWAPBands) Calculate(currentPrice decimal.Decimal) (decimal.Decimal, decimal.Decimal, error) {
+	if !v.Active {
+		return decimal.Zero, decimal.Zero, stoploss.ErrStatusInvalid
+	}
+	v.lastPrice = currentPrice
+	stop, profit := v.bands()
+	return stop, profit, nil
+}
+
+// ShouldTriggerStopLoss checks if price crossed the stop band
+func (v *VWAPBands) ShouldTriggerStopLoss(currentPrice decimal.Decimal) (bool, error) {
+	if !v.Active {
+		return false, stoploss.ErrStatusInvalid
+	}
+	if v.stopHit(currentPrice) {
+		return v.fire(stoploss.TRIGGERED_REASON_HYBRID_VWAP_STOPLOSS)
+	}
+	return false, nil
+}
+
+// ShouldTriggerTakeProfit checks if price reached the take profit band
+func (v *VWAPBands) ShouldTriggerTakeProfit(currentPrice decimal.Decimal) (bool, error) {
+	if !v.Active {
+		return false, stoploss.ErrStatusInvalid
+	}
+	if v.profitHit(currentPrice) {
+		return v.fire(stoploss.TRIGGERED_REASON_HYBRID_VWAP_TAKEPROFIT)
+	}
+	return false, nil
+}
+
+// GetStopLoss returns the stop band, zero until prices spread around the VWAP
+func (v *VWAPBands) GetStopLoss() (decimal.Decimal, error) {
+	if !v.Active {
+		return decimal.Zero, stoploss.ErrStatusInvalid
+	}
+	stop, _ := v.bands()
+	return stop, nil
+}
+
+// GetTakeProfit returns the take profit band, zero until prices spread around the VWAP
+func (v *VWAPBands) GetTakeProfit() (decimal.Decimal, error) {
+	if !v.Active {
+		return decimal.Zero, stoploss.ErrStatusInvalid
+	}
+	_, profit := v.bands()
+	return profit, nil
+}
+
+// ReSet anchors an entry VWAP again at the next update, a session VWAP keeps its session
+func (v *VWAPBands) ReSet(currentPrice decimal.Decimal) error {
+	if !v.Active {
+		return stoploss.ErrStatusInvalid
+	}
+	v.lastPrice = currentPrice
+	if v.config.Anchor == EntryVWAP {
+		v.anchor = 0
+		v.clear()
+	}
+	return nil
+}
+
+// GetTimeThreshold returns the time threshold for Debounced strategies
+func (t *VWAPBandsDebounced) GetTimeThreshold() (int64, error) {
+	if !t.Active {
+		return 0, stoploss.ErrStatusInvalid
+	}
+	return t.TimeThreshold, nil
+}
+
+// ShouldTriggerStopLoss checks if price stayed beyond the stop band for the time threshold
+func (t *VWAPBandsDebounced) ShouldTriggerStopLoss(currentPrice decimal.Decimal, currentTime int64) (bool, error) {
+	if !t.Active {
+		return false, stoploss.ErrStatusInvalid
+	}
+	if t.stopHit(currentPrice) {
+		if t.TriggerTime == 0 {
+			t.TriggerTime = currentTime
+		}
+		if currentTime-t.TriggerTime >= t.TimeThreshold {
+			return t.fire(stoploss.TRIGGERED_REASON_HYBRID_VWAP_STOPLOSS)
+		}
+	} else {
+		t.TriggerTime = 0
+	}
+	return false, nil
+}
+
+// ShouldTriggerTakeProfit checks if price stayed beyond the take profit band for the time threshold
+func (t *VWAPBandsDebounced) ShouldTriggerTakeProfit(currentPrice decimal.Decimal, currentTime int64) (bool, error) {
+	if !t.Active {
+		return false, stoploss.ErrStatusInvalid
+	}
+	if t.profitHit(currentPrice) {
+		if t.ProfitTriggerTime == 0 {
+			t.ProfitTriggerTime = currentTime
+		}
+		if currentTime-t.ProfitTriggerTime >= t.TimeThreshold {
+			return t.fire(stoploss.TRIGGERED_REASON_HYBRID_VWAP_TAKEPROFIT)
+		}
+	} else {
+		t.ProfitTriggerTime = 0
+	}
+	return false, nil
+}
+
+// ReSet anchors an entry VWAP again and clears the debounce windows
+func (t *VWAPBandsDebounced) ReSet(currentPrice decimal.Decimal) error {
+	if err := t.VWAPBands.ReSet(currentPrice); err != nil {
+		return err
+	}
+	t.TriggerTime, t.ProfitTriggerTime = 0, 0
+	return nil
+}
@@ -0,0 +1,187 @@
+// Copyright (C) 2025 Quantive
+//
+// SPDX-License-Identifier: MIT OR AGPL-3.0-or-later
+//
+// This file is part of the Decision Engine project.
+// You may choose to use this file under the terms of either
+// the MIT License or the GNU Affero General Public License v3.0 or later.
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the LICENSE files for more details.
+
+package strategy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wang900115/quant/model"
+	"github.com/wang900115/quant/stoploss"
+)
+
+func candle(open time.Time, high, low, close, volume float64) model.PriceInterval {
+	b := bar(high, low, close)
+	b.OpenTime = open.Format(time.RFC3339)
+	b.Volume = d(volume)
+	return b
+}
+
+func TestNewVWAPBands_InvalidParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  VWAPConfig
+		wantErr bool
+	}{
+		{"Valid Session", VWAPConfig{StopWidth: d(1), ProfitWidth: d(2), IsLong: true}, false},
+		{"Valid Entry", VWAPConfig{Anchor: EntryVWAP, StopWidth: d(1), ProfitWidth: d(1)}, false},
+		{"Unknown Anchor", VWAPConfig{Anchor: VWAPAnchor(5), StopWidth: d(1), ProfitWidth: d(1)}, true},
+		{"Zero Stop Width", VWAPConfig{ProfitWidth: d(1)}, true},
+		{"Zero Profit Width", VWAPConfig{StopWidth: d(1)}, true},
+		{"Bad Session Hour", VWAPConfig{StopWidth: d(1), ProfitWidth: d(1), SessionHour: 24}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := NewVWAPBands(tt.config, nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("wantErr=%v, got err=%v", tt.wantErr, err)
+			}
+			if tt.wantErr && v != nil {
+				t.Errorf("expected a nil strategy on error, got %v", v)
+			}
+		})
+	}
+	if _, err := NewVWAPBandsDebounced(VWAPConfig{StopWidth: d(1), ProfitWidth: d(1)}, 0, nil); err == nil {
+		t.Error("expected a zero time threshold to be rejected")
+	}
+}
+
+func TestVWAPBands_LongFromTrades(t *testing.T) {
+	v, err := NewVWAPBands(VWAPConfig{StopWidth: d(1), ProfitWidth: d(2), IsLong: true}, nil)
+	if err != nil {
+		t.Fatalf("Failed to create VWAP hybrid: %v", err)
+	}
+	if triggered, _ := v.ShouldTriggerStopLoss(d(1)); triggered {
+		t.Fatal("Did not expect a trigger before any volume")
+	}
+	if err := v.UpdateTrade(d(98), d(1), 1000); err != nil {
+		t.Fatalf("UpdateTrade: %v", err)
+	}
+	if sl, _ := v.GetStopLoss(); !sl.IsZero() {
+		t.Fatalf("Expected no band from a single price, got %v", sl)
+	}
+	v.UpdateTrade(d(102), d(1), 2000)
+
+	// VWAP 100, σ 2
+	if vwap, sigma := v.(*VWAPBands).VWAP(); !vwap.Equal(d(100)) || !sigma.Equal(d(2)) {
+		t.Fatalf("Expected VWAP 100 ± 2, got %v ± %v", vwap, sigma)
+	}
+	stop, profit, _ := v.Calculate(d(100))
+	if !stop.Equal(d(98)) || !profit.Equal(d(104)) {
+		t.Fatalf("Expected bands 98/104, got %v/%v", stop, profit)
+	}
+	if triggered, _ := v.ShouldTriggerStopLoss(d(99)); triggered {
+		t.Error("Did not expect a stop above the lower band")
+	}
+	if triggered, _ := v.ShouldTriggerStopLoss(d(98)); !triggered {
+		t.Error("Expected a stop at VWAP − σ")
+	}
+	if triggered, _ := v.ShouldTriggerTakeProfit(d(104)); !triggered {
+		t.Error("Expected a take profit at VWAP + 2σ")
+	}
+	if err := v.UpdateTrade(d(100), d(0), 3000); err == nil {
+		t.Error("Expected a trade without size to be rejected")
+	}
+}
+
+func TestVWAPBands_ShortFromCandles(t *testing.T) {
+	v, _ := NewVWAPBands(VWAPConfig{StopWidth: d(1), ProfitWidth: d(1)}, nil)
+	open := time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC)
+	// typical prices 98 and 102
+	v.UpdateBar(candle(open, 101, 95, 98, 3))
+	v.UpdateBar(candle(open.Add(time.Minute), 105, 99, 102, 3))
+	v.UpdateBar(candle(open.Add(2*time.Minute), 150, 50, 100, 0)) // no volume, skipped
+	stop, profit, _ := v.Calculate(d(100))
+	if !stop.Equal(d(102)) || !profit.Equal(d(98)) {
+		t.Fatalf("Expected a short to stop at 102 and take profit at 98, got %v/%v", stop, profit)
+	}
+	if triggered, _ := v.ShouldTriggerStopLoss(d(102)); !triggered {
+		t.Error("Expected a short to stop at VWAP + σ")
+	}
+	if triggered, _ := v.ShouldTriggerTakeProfit(d(99)); triggered {
+		t.Error("Did not expect a short to take profit above VWAP − σ")
+	}
+}
+
+func TestVWAPBands_SessionRestarts(t *testing.T) {
+	est := time.FixedZone("EST", -5*60*60)
+	v, _ := NewVWAPBands(VWAPConfig{Location: est, SessionHour: 9, SessionMinute: 30, StopWidth: d(1), ProfitWidth: d(1), IsLong: true}, nil)
+	day := time.Date(2025, 1, 2, 0, 0, 0, 0, est)
+	v.UpdateTrade(d(98), d(1), day.Add(10*time.Hour).UnixMilli())
+	v.UpdateTrade(d(102), d(1), day.Add(33*time.Hour).UnixMilli()) // 09:00 the next day, same session
+	if vwap, _ := v.(*VWAPBands).VWAP(); !vwap.Equal(d(100)) {
+		t.Fatalf("Expected both trades in one session, got VWAP %v", vwap)
+	}
+	v.UpdateTrade(d(110), d(1), day.Add(33*time.Hour+30*time.Minute).UnixMilli())
+	if vwap, sigma := v.(*VWAPBands).VWAP(); !vwap.Equal(d(110)) || !sigma.IsZero() {
+		t.Fatalf("Expected the 09:30 open to restart the VWAP, got %v ± %v", vwap, sigma)
+	}
+}
+
+func TestVWAPBands_LateBarKeepsSession(t *testing.T) {
+	v, _ := NewVWAPBands(VWAPConfig{StopWidth: d(1), ProfitWidth: d(1), IsLong: true}, nil)
+	today := time.Date(2025, 1, 3, 0, 5, 0, 0, time.UTC)
+	// typical prices 98 and 102
+	v.UpdateBar(candle(today, 101, 95, 98, 1))
+	v.UpdateBar(candle(today.Add(-10*time.Minute), 150, 50, 100, 5)) // yesterday's last bar arrives late
+	v.UpdateBar(candle(today.Add(time.Minute), 105, 99, 102, 1))
+	if vwap, _ := v.(*VWAPBands).VWAP(); !vwap.Equal(d(100)) {
+		t.Fatalf("Expected a bar from the previous session to be dropped, got VWAP %v", vwap)
+	}
+}
+
+func TestVWAPBands_EntryAnchor(t *testing.T) {
+	v, _ := NewVWAPBands(VWAPConfig{Anchor: EntryVWAP, EntryTime: 5000, StopWidth: d(1), ProfitWidth: d(1), IsLong: true}, nil)
+	v.UpdateTrade(d(50), d(10), 4000) // before entry, ignored
+	v.UpdateTrade(d(98), d(1), 5000)
+	v.UpdateTrade(d(102), d(1), 6000)
+	if vwap, _ := v.(*VWAPBands).VWAP(); !vwap.Equal(d(100)) {
+		t.Fatalf("Expected trades before entry to be ignored, got VWAP %v", vwap)
+	}
+	if err := v.ReSet(d(120)); err != nil {
+		t.Fatalf("ReSet: %v", err)
+	}
+	v.UpdateTrade(d(120), d(1), 7000)
+	if vwap, _ := v.(*VWAPBands).VWAP(); !vwap.Equal(d(120)) {
+		t.Fatalf("Expected reset to anchor at the next trade, got VWAP %v", vwap)
+	}
+}
+
+func TestVWAPBandsDebounced_WaitsForThreshold(t *testing.T) {
+	var reason string
+	v, err := NewVWAPBandsDebounced(VWAPConfig{StopWidth: d(1), ProfitWidth: d(1), IsLong: true}, 1000, func(r string) error { reason = r; return nil })
+	if err != nil {
+		t.Fatalf("Failed to create VWAP hybrid: %v", err)
+	}
+	v.UpdateTrade(d(98), d(1), 1000)
+	v.UpdateTrade(d(102), d(1), 1000)
+	if triggered, _ := v.ShouldTriggerStopLoss(d(97), 2000); triggered {
+		t.Fatal("Did not expect a stop on the first breach")
+	}
+	if triggered, _ := v.ShouldTriggerStopLoss(d(99), 2500); triggered {
+		t.Fatal("Did not expect a stop after price recovered")
+	}
+	if triggered, _ := v.ShouldTriggerStopLoss(d(97), 2800); triggered {
+		t.Fatal("Expected the recovery to restart the window")
+	}
+	if triggered, _ := v.ShouldTriggerStopLoss(d(97), 3800); !triggered || reason != stoploss.TRIGGERED_REASON_HYBRID_VWAP_STOPLOSS {
+		t.Fatalf("Expected a stop once the breach lasted the threshold, got reason %q", reason)
+	}
+	if triggered, _ := v.ShouldTriggerTakeProfit(d(102), 4000); triggered {
+		t.Fatal("Did not expect a take profit on the first touch")
+	}
+	if triggered, _ := v.ShouldTriggerTakeProfit(d(103), 5000); !triggered {
+		t.Fatal("Expected a take profit once the band held for the threshold")
+	}
+}
@@ -13,7 +13,10 @@
 
 package stoploss
 
-import "github.com/shopspring/decimal"
+import (
+	"github.com/shopspring/decimal"
+	"github.com/wang900115/quant/model/result"
+)
 
 type HybridWithoutTime interface {
 	Hybrid
@@ -27,6 +30,30 @@ type HybridWithTime interface {
 	StopLossCondT
 }
 
+// Hybrid fed by closed bars
+type HybridBarWithoutTime interface {
+	HybridWithoutTime
+	BarUpdater
+}
+
+// Time-based hybrid fed by closed bars
+type HybridBarWithTime interface {
+	HybridWithTime
+	BarUpdater
+}
+
+// Hybrid fed by closed bars and trades
+type HybridVolumeWithoutTime interface {
+	HybridBarWithoutTime
+	TradeUpdater
+}
+
+// Time-based hybrid fed by closed bars and trades
+type HybridVolumeWithTime interface {
+	HybridBarWithTime
+	TradeUpdater
+}
+
 type Hybrid interface {
 	Calculate(currentPrice decimal.Decimal) (decimal.Decimal, decimal.Decimal, error)
 	Trigger(reason string) error
@@ -48,6 +75,18 @@ type FixedTakeProfit interface {
 	TakeProfitCond
 }
 
+// Fixed strategy fed by closed bars
+type FixedBarTakeProfit interface {
+	FixedTakeProfit
+	BarUpdater
+}
+
+// Time-based strategy fed by closed bars
+type DebouncedBarTakeProfit interface {
+	DebouncedTakeProfit
+	BarUpdater
+}
+
 // Fixed-ATR
 type FixedVolatilityTakeProfit interface {
 	FixedTakeProfit
@@ -72,6 +111,25 @@ type DebouncedMATakeProfit interface {
 	SetMA(value decimal.Decimal)
 }
 
+// Strategies closing the position in parts, PartialExit returns the exit of the last trigger once
+type PartialExiter interface {
+	PartialExit() *result.PartialExit
+}
+
+// Fixed take profit closing the position in parts
+type FixedLadderTakeProfit interface {
+	FixedTakeProfit
+	PartialExiter
+	Ratcheter
+}
+
+// Time-based take profit closing the position in parts
+type DebouncedLadderTakeProfit interface {
+	DebouncedTakeProfit
+	PartialExiter
+	Ratcheter
+}
+
 // general TakeProfit interface
 type TakeProfit interface {
 	CalculateTakeProfit(currentPrice decimal.Decimal) (decimal.Decimal, error)